@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bnema/archup/internal/application/commands"
+	apphandlers "github.com/bnema/archup/internal/application/handlers"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/ports"
+	infraassets "github.com/bnema/archup/internal/infrastructure/assets"
+	"github.com/bnema/archup/internal/infrastructure/executor"
+	"github.com/bnema/archup/internal/infrastructure/filesystem"
+	infralogger "github.com/bnema/archup/internal/infrastructure/logger"
+	"github.com/bnema/archup/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newBundleCmd())
+}
+
+func newBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Manage offline installation bundles",
+	}
+	cmd.AddCommand(newBundleCreateCmd())
+	return cmd
+}
+
+func newBundleCreateCmd() *cobra.Command {
+	var output string
+	var assetsDir string
+	var tarball bool
+	var pkgs []string
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Build an offline bundle for `archup install --offline`",
+		Long:  "Builds a directory holding the embedded install assets and a pacman repository of every package in base.packages and in every profile of profiles.packages, the official kernels, the GPU drivers, the power daemons and the laptop packages, dependencies included. Run it on an Arch host with network access.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBundleCreate(cmd.OutOrStdout(), output, assetsDir, pkgs, tarball)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Bundle directory to create")
+	cmd.Flags().StringVar(&assetsDir, "assets-dir", "", "Development: bundle the install assets of a local archup checkout instead of the embedded ones")
+	cmd.Flags().BoolVar(&tarball, "tar", false, "Also pack the bundle into <output>.tar")
+	cmd.Flags().StringArrayVar(&pkgs, "package", nil, "Additional package to bundle (repeatable)")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func runBundleCreate(out io.Writer, output, assetsDir string, pkgs []string, tarball bool) error {
+	oldLog, err := logger.New(config.DefaultLogPath, false)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() {
+		if err := oldLog.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close logger: %v\n", err)
+		}
+	}()
+
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	var assetSource ports.AssetSource = infraassets.NewEmbeddedSource()
+	if assetsDir != "" {
+		if assetsDir, err = filepath.Abs(assetsDir); err != nil {
+			return fmt.Errorf("invalid assets path: %w", err)
+		}
+		assetSource = infraassets.NewDirSource(assetsDir)
+	}
+
+	slogAdapter := infralogger.NewSlogAdapter(oldLog.Slog())
+	bundleHandler := apphandlers.NewBundleHandler(
+		&filesystem.LocalFileSystem{},
+		assetSource,
+		executor.NewShellExecutor(slogAdapter),
+		slogAdapter,
+	)
+
+	result, err := bundleHandler.Handle(context.Background(), commands.CreateBundleCommand{
+		OutputDir: output,
+		Version:   version,
+		Packages:  pkgs,
+		Tarball:   tarball,
+	})
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+
+	fmt.Fprintf(out, "Bundle created: %s\n", result.Path)
+	fmt.Fprintf(out, "Packages: %d (%v)\n", result.Packages, result.Repos)
+	if result.Tarball != "" {
+		fmt.Fprintf(out, "Tarball: %s\n", result.Tarball)
+	}
+	fmt.Fprintf(out, "Install with: archup install --offline %s\n", result.Path)
+	return nil
+}
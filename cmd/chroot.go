@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/bnema/archup/internal/application/commands"
+	apphandlers "github.com/bnema/archup/internal/application/handlers"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/infrastructure/executor"
+	"github.com/bnema/archup/internal/infrastructure/filesystem"
+	infralogger "github.com/bnema/archup/internal/infrastructure/logger"
+	"github.com/bnema/archup/internal/logger"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newChrootCmd())
+}
+
+func newChrootCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "chroot [disk]",
+		Short: "Open and mount an existing install and chroot into it",
+		Long: "Finds an existing ArchUp install by its ARCHUP_LUKS, ROOT and EFI labels, unlocks it, " +
+			"mounts @, @home and the ESP at /mnt and runs arch-chroot. Run `archup cleanup` afterwards.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targetDisk := ""
+			if len(args) == 1 {
+				targetDisk = args[0]
+			}
+			return runChroot(targetDisk)
+		},
+	}
+}
+
+func runChroot(targetDisk string) error {
+	oldLog, err := logger.New(config.DefaultLogPath, false)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() {
+		if err := oldLog.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close logger: %v\n", err)
+		}
+	}()
+
+	ctx := context.Background()
+	slogAdapter := infralogger.NewSlogAdapter(oldLog.Slog())
+	rescueHandler := apphandlers.NewRescueHandler(&filesystem.LocalFileSystem{}, executor.NewShellExecutor(slogAdapter), slogAdapter)
+
+	devices, err := rescueHandler.Detect(ctx, targetDisk)
+	if err != nil {
+		return fmt.Errorf("detect install: %w", err)
+	}
+	fmt.Printf("Found install: %s\n", devices)
+
+	passphrase := ""
+	if devices.Encrypted() {
+		fmt.Printf("Passphrase for %s: ", devices.LUKS)
+		secret, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Println()
+		if err != nil {
+			return fmt.Errorf("read passphrase: %w", err)
+		}
+		passphrase = string(secret)
+	}
+
+	result, err := rescueHandler.Mount(ctx, commands.RescueMountCommand{
+		MountPoint: config.PathMnt,
+		Devices:    devices,
+		Passphrase: passphrase,
+	})
+	if err != nil {
+		return fmt.Errorf("mount install: %w", err)
+	}
+	fmt.Printf("Mounted %v\n", result.MountedAt)
+
+	// arch-chroot needs the terminal, it cannot go through the CommandExecutor
+	shell := exec.CommandContext(ctx, "arch-chroot", config.PathMnt)
+	shell.Stdin, shell.Stdout, shell.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := shell.Run(); err != nil {
+		oldLog.Warn("arch-chroot exited with error", "error", err)
+	}
+
+	fmt.Println("The install is still mounted at " + config.PathMnt + ", run `archup cleanup` to unmount it and close the LUKS container.")
+	return nil
+}
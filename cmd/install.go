@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	apphandlers "github.com/bnema/archup/internal/application/handlers"
 	"github.com/bnema/archup/internal/application/services"
 	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+	infraassets "github.com/bnema/archup/internal/infrastructure/assets"
 	"github.com/bnema/archup/internal/infrastructure/executor"
 	"github.com/bnema/archup/internal/infrastructure/filesystem"
 	infrahttp "github.com/bnema/archup/internal/infrastructure/http"
 	infralogger "github.com/bnema/archup/internal/infrastructure/logger"
+	"github.com/bnema/archup/internal/infrastructure/mdns"
 	"github.com/bnema/archup/internal/infrastructure/persistence"
 	"github.com/bnema/archup/internal/interfaces/tui"
 	"github.com/bnema/archup/internal/logger"
@@ -18,24 +26,41 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// mirrorProbeTimeout bounds each mirror probe, a slow mirror is not worth ranking
+const mirrorProbeTimeout = 10 * time.Second
+
 func init() {
 	rootCmd.AddCommand(newInstallCmd())
 }
 
 func newInstallCmd() *cobra.Command {
 	var dryRun bool
+	var configPath string
+	var offline string
+	var remoteAssets bool
+	var skipVerify bool
+	var pacmanCache string
+	var keepPacmanCache bool
 	cmd := &cobra.Command{
 		Use:   "install",
 		Short: "Run base system installer",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runInstall(dryRun)
+			return runInstall(dryRun, configPath, offline, remoteAssets, skipVerify, pacmanCache, keepPacmanCache)
 		},
 	}
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show TUI but don't execute commands")
+	cmd.Flags().StringVar(&configPath, "config", "", "Answer file with installation defaults (ARCHUP_* KEY=VALUE)")
+	cmd.Flags().StringVar(&offline, "offline", "", "Install from a bundle made by `archup bundle create` instead of the network")
+	cmd.Flags().BoolVar(&remoteAssets, "remote-assets", false, "Development: download install assets from the repository (ENV=dev for the dev branch) instead of using the embedded ones")
+	cmd.Flags().BoolVar(&skipVerify, "skip-verify", false, "Development: do not verify remote assets against the signed release manifest")
+	cmd.Flags().StringVar(&pacmanCache, "pacman-cache", "", "LAN caching proxy URL or custom Server for every repository, \"auto\" to discover it over mDNS")
+	cmd.Flags().BoolVar(&keepPacmanCache, "keep-pacman-cache", false, "Keep the pacman cache configured in the installed system")
+	cmd.MarkFlagsMutuallyExclusive("offline", "remote-assets")
+	cmd.MarkFlagsMutuallyExclusive("offline", "pacman-cache")
 	return cmd
 }
 
-func runInstall(dryRun bool) error {
+func runInstall(dryRun bool, configPath string, offline string, remoteAssets, skipVerify bool, pacmanCache string, keepPacmanCache bool) error {
 	oldLog, err := logger.New(config.DefaultLogPath, dryRun)
 	if err != nil {
 		return fmt.Errorf("failed to create logger: %w", err)
@@ -51,6 +76,38 @@ func runInstall(dryRun bool) error {
 	}
 
 	cfg := config.NewConfig(version)
+	if configPath != "" {
+		// Load falls back to defaults without the file, a given path must exist
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			oldLog.Error("Answer file not found", "path", configPath)
+			return fmt.Errorf("answer file not found: %s", configPath)
+		}
+		cfg, err = config.Load(configPath, version)
+		if err != nil {
+			oldLog.Error("Failed to load answer file", "path", configPath, "error", err)
+			return fmt.Errorf("load answer file: %w", err)
+		}
+	}
+	if _, err := installation.ParseHooks(cfg.Hooks); err != nil {
+		return fmt.Errorf("invalid ARCHUP_HOOKS: %w", err)
+	}
+	if _, err := installation.ParseHookFailurePolicy(cfg.HookPolicy); err != nil {
+		return fmt.Errorf("invalid ARCHUP_HOOK_POLICY: %w", err)
+	}
+	if err := bootloader.ValidateTimeout(cfg.BootTimeout); err != nil {
+		return fmt.Errorf("invalid ARCHUP_BOOT_TIMEOUT: %w", err)
+	}
+	for _, id := range cfg.BootEntries {
+		if _, err := bootloader.LookupExtraEntry(id); err != nil {
+			return fmt.Errorf("invalid ARCHUP_BOOT_ENTRIES: %w", err)
+		}
+	}
+	if err := packages.ValidatePackageNames(cfg.Packages); err != nil {
+		return fmt.Errorf("invalid ARCHUP_PACKAGES: %w", err)
+	}
+	if err := packages.ValidatePackageNames(cfg.AURPackages); err != nil {
+		return fmt.Errorf("invalid ARCHUP_AUR_PACKAGES: %w", err)
+	}
 	oldLog.Info("Config initialized", "version", version, "raw_url", cfg.RawURL)
 
 	oldLog.Info("Initializing DDD architecture components")
@@ -60,7 +117,6 @@ func runInstall(dryRun bool) error {
 	fsAdapter := &filesystem.LocalFileSystem{}
 	shellExec := executor.NewShellExecutor(slogAdapter)
 	chrootExec := executor.NewChrootExecutor(slogAdapter)
-	httpClient := infrahttp.NewHTTPClient()
 	scriptExec := executor.NewScriptExecutor(fsAdapter, shellExec, config.DefaultInstallDir)
 	repoAdapter, err := persistence.NewFileRepository(config.DefaultConfigPath)
 	if err != nil {
@@ -68,14 +124,59 @@ func runInstall(dryRun bool) error {
 		return fmt.Errorf("create repository adapter: %w", err)
 	}
 
-	bootstrapHandler := apphandlers.NewBootstrapHandler(fsAdapter, httpClient, slogAdapter, cfg.RepoURL, cfg.RawURL, cfg.Branch())
+	offlineHandler := apphandlers.NewOfflineHandler(fsAdapter, shellExec, slogAdapter)
+	if offline != "" {
+		cfg.OfflineBundle = offline
+	}
+
+	var assetSource ports.AssetSource = infraassets.NewEmbeddedSource()
+	if remoteAssets {
+		remote := infraassets.NewRemoteSource(infrahttp.NewHTTPClient(), cfg.RawURL)
+		switch {
+		case skipVerify:
+			oldLog.Warn("Remote assets are not verified against the release manifest", "url", cfg.RawURL)
+			assetSource = remote
+		case manifestPublicKey == "":
+			return fmt.Errorf("this build has no manifest public key, remote assets cannot be verified (use --skip-verify for development)")
+		default:
+			assetSource = infraassets.NewVerifiedSource(remote, manifestPublicKey)
+		}
+	}
+	if cfg.OfflineBundle != "" {
+		b, err := offlineHandler.Open(context.Background(), cfg.OfflineBundle)
+		if err != nil {
+			oldLog.Error("Failed to open offline bundle", "path", cfg.OfflineBundle, "error", err)
+			return fmt.Errorf("open offline bundle: %w", err)
+		}
+		cfg.OfflineBundle = b.Root()
+		assetSource = infraassets.NewDirSource(b.AssetsDir())
+		oldLog.Info("Installing from offline bundle", "path", b.Root())
+	}
+	oldLog.Info("Install assets", "source", assetSource.String())
+
+	if pacmanCache != "" {
+		cfg.PacmanCache = pacmanCache
+	}
+	if keepPacmanCache {
+		cfg.KeepPacmanCache = true
+	}
+	if err := resolvePacmanCache(cfg, slogAdapter); err != nil {
+		return err
+	}
+
+	bootstrapHandler := apphandlers.NewBootstrapHandler(fsAdapter, assetSource, slogAdapter)
 	preflightHandler := apphandlers.NewPreflightHandler(fsAdapter, shellExec, slogAdapter)
 	partitionHandler := apphandlers.NewPartitionHandler(shellExec, slogAdapter)
 	baseHandler := apphandlers.NewInstallBaseHandler(fsAdapter, shellExec, chrootExec, slogAdapter)
 	configHandler := apphandlers.NewConfigureSystemHandler(fsAdapter, chrootExec, slogAdapter)
 	bootloaderHandler := apphandlers.NewBootloaderHandler(fsAdapter, shellExec, chrootExec, slogAdapter)
 	reposHandler := apphandlers.NewReposHandler(fsAdapter, chrootExec, slogAdapter)
-	postInstallHandler := apphandlers.NewPostInstallHandler(fsAdapter, httpClient, chrootExec, scriptExec, slogAdapter, cfg.RawURL)
+	gpuDriversHandler := apphandlers.NewGPUDriversHandler(fsAdapter, chrootExec, slogAdapter)
+	cpuPowerHandler := apphandlers.NewCPUPowerHandler(fsAdapter, chrootExec, slogAdapter)
+	laptopHandler := apphandlers.NewLaptopHandler(fsAdapter, chrootExec, slogAdapter)
+	inventoryHandler := apphandlers.NewInventoryHandler(fsAdapter, slogAdapter)
+	mirrorHandler := apphandlers.NewMirrorHandler(fsAdapter, infrahttp.NewHTTPClientWithTimeout(mirrorProbeTimeout), slogAdapter)
+	postInstallHandler := apphandlers.NewPostInstallHandler(fsAdapter, assetSource, shellExec, chrootExec, scriptExec, slogAdapter)
 
 	installService := services.NewInstallationService(
 		repoAdapter,
@@ -87,11 +188,19 @@ func runInstall(dryRun bool) error {
 		configHandler,
 		bootloaderHandler,
 		reposHandler,
+		gpuDriversHandler,
+		cpuPowerHandler,
+		laptopHandler,
+		mirrorHandler,
+		inventoryHandler,
 		postInstallHandler,
+		apphandlers.NewHookHandler(fsAdapter, scriptExec, chrootExec, slogAdapter),
+		offlineHandler,
 	)
 
-	gpuHandler := apphandlers.NewGPUHandler(shellExec, slogAdapter)
-	tuiApp := tui.NewApp(installService, installService.Tracker(), gpuHandler, slogAdapter, version)
+	gpuHandler := apphandlers.NewGPUHandler(fsAdapter, shellExec, slogAdapter)
+	packagesHandler := apphandlers.NewPackagesHandler(fsAdapter, assetSource, shellExec, slogAdapter)
+	tuiApp := tui.NewApp(installService, installService.Tracker(), gpuHandler, laptopHandler, packagesHandler, cfg, slogAdapter, version)
 
 	oldLog.Info("Starting TUI application", "version", version)
 	p := tea.NewProgram(tuiApp, tea.WithAltScreen())
@@ -107,3 +216,31 @@ func runInstall(dryRun bool) error {
 
 	return nil
 }
+
+// resolvePacmanCache discovers an "auto" pacman cache and validates the
+// configured one. A cache that cannot be discovered is not an error, the
+// mirrors are used instead.
+func resolvePacmanCache(cfg *config.Config, logger ports.Logger) error {
+	if cfg.PacmanCache == "" {
+		return nil
+	}
+	if cfg.OfflineBundle != "" {
+		return fmt.Errorf("a pacman cache cannot be used with an offline bundle")
+	}
+
+	if cfg.PacmanCache == packages.PacmanCacheAuto {
+		handler := apphandlers.NewPacmanCacheHandler(mdns.NewBrowser(), logger)
+		url, err := handler.Discover(context.Background())
+		if err != nil {
+			logger.Warn("No pacman cache discovered, using the mirrors", "error", err)
+			cfg.PacmanCache = ""
+			return nil
+		}
+		cfg.PacmanCache = url
+	}
+
+	if _, err := packages.NewPacmanCache(cfg.PacmanCache, cfg.KeepPacmanCache); err != nil {
+		return fmt.Errorf("invalid pacman cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/manifest"
+	infraassets "github.com/bnema/archup/internal/infrastructure/assets"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newManifestCmd())
+}
+
+func newManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest [dir]",
+		Short: "Print the SHA-256 manifest of the install assets",
+		Long:  "Prints the manifest.sha256 of the install assets of an archup checkout (default: the current directory), to be signed with `minisign -S -l -m manifest.sha256` before tagging a release.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			return runManifest(cmd.OutOrStdout(), dir)
+		},
+	}
+	return cmd
+}
+
+func runManifest(out io.Writer, dir string) error {
+	source := infraassets.NewDirSource(dir)
+	assets := map[string][]byte{}
+	for _, asset := range config.InstallAssets() {
+		content, err := source.ReadAsset(asset)
+		if err != nil {
+			return err
+		}
+		assets[asset] = content
+	}
+
+	_, err := fmt.Fprint(out, manifest.New(assets).String())
+	return err
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bnema/archup/internal/application/commands"
+	apphandlers "github.com/bnema/archup/internal/application/handlers"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"github.com/bnema/archup/internal/infrastructure/executor"
+	"github.com/bnema/archup/internal/infrastructure/filesystem"
+	infralogger "github.com/bnema/archup/internal/infrastructure/logger"
+	"github.com/bnema/archup/internal/interfaces/tui"
+	"github.com/bnema/archup/internal/logger"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newRollbackCmd())
+}
+
+func newRollbackCmd() *cobra.Command {
+	var modeName string
+	var number int
+	var baseline bool
+	cmd := &cobra.Command{
+		Use:   "rollback [mountpoint]",
+		Short: "Roll the root filesystem back to a snapper snapshot",
+		Long: "Lists the snapper snapshots of the root config, restores the selected one as the root subvolume " +
+			"and regenerates the Limine snapshot entries. Runs on the installed system (/) or from the ISO " +
+			"against a mounted install (see `archup chroot`). Reboot to apply.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mountPoint := "/"
+			if len(args) == 1 {
+				mountPoint = args[0]
+			}
+			mode, err := snapshot.ParseRollbackMode(modeName)
+			if err != nil {
+				return err
+			}
+			return runRollback(mountPoint, number, baseline, mode)
+		},
+	}
+	cmd.Flags().StringVar(&modeName, "mode", "new-root", "Rollback mode: new-root (replace @) or set-default (btrfs default subvolume)")
+	cmd.Flags().IntVar(&number, "snapshot", 0, "Snapshot number to roll back to, skipping the picker")
+	cmd.Flags().BoolVar(&baseline, "baseline", false, "Roll back to the factory state snapshot taken by the installer")
+	cmd.MarkFlagsMutuallyExclusive("snapshot", "baseline")
+	return cmd
+}
+
+func runRollback(mountPoint string, number int, baseline bool, mode snapshot.RollbackMode) error {
+	oldLog, err := logger.New(config.DefaultLogPath, false)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() {
+		if err := oldLog.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close logger: %v\n", err)
+		}
+	}()
+
+	ctx := context.Background()
+	slogAdapter := infralogger.NewSlogAdapter(oldLog.Slog())
+	rollbackHandler := apphandlers.NewRollbackHandler(
+		&filesystem.LocalFileSystem{},
+		executor.NewShellExecutor(slogAdapter),
+		executor.NewChrootExecutor(slogAdapter),
+		slogAdapter,
+	)
+
+	if number == 0 && !baseline {
+		snapshots, err := rollbackHandler.ListSnapshots(ctx, mountPoint)
+		if err != nil {
+			return fmt.Errorf("list snapshots: %w", err)
+		}
+
+		picker := tui.NewRollbackPicker(snapshots, mode)
+		if _, err := tea.NewProgram(picker, tea.WithAltScreen()).Run(); err != nil {
+			return fmt.Errorf("run snapshot picker: %w", err)
+		}
+		selected, selectedMode, ok := picker.Choice()
+		if !ok {
+			fmt.Println("Rollback cancelled.")
+			return nil
+		}
+		number, mode = selected.Number, selectedMode
+	}
+
+	result, err := rollbackHandler.Handle(ctx, commands.RollbackCommand{
+		MountPoint: mountPoint,
+		Snapshot:   number,
+		Baseline:   baseline,
+		Mode:       mode,
+	})
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+
+	restored := fmt.Sprintf("Snapshot #%d", result.Snapshot)
+	if baseline {
+		restored = "Baseline " + snapshot.BaselineSubvolume
+	}
+	fmt.Printf("%s restored as %s (%s), previous root kept as %s.\n",
+		restored, result.NewRoot, result.Mode, result.PreviousRoot)
+	fmt.Println("Reboot to boot into the restored system.")
+	return nil
+}
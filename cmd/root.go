@@ -11,6 +11,11 @@ import (
 // Example: go build -ldflags "-X github.com/bnema/archup/cmd.version=v0.3.0"
 var version = "dev"
 
+// manifestPublicKey is the minisign public key release manifests are signed
+// with, set via ldflags at build time. Remote assets are verified against it.
+// Example: go build -ldflags "-X github.com/bnema/archup/cmd.manifestPublicKey=RWQ..."
+var manifestPublicKey = ""
+
 var rootCmd = &cobra.Command{
 	Use:          "archup",
 	Short:        "ArchUp installer",
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bnema/archup/internal/application/commands"
+	apphandlers "github.com/bnema/archup/internal/application/handlers"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/infrastructure/executor"
+	"github.com/bnema/archup/internal/infrastructure/filesystem"
+	infralogger "github.com/bnema/archup/internal/infrastructure/logger"
+	"github.com/bnema/archup/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newVerifyCmd())
+}
+
+func newVerifyCmd() *cobra.Command {
+	var jsonOutput bool
+	var username string
+	var checks []string
+	cmd := &cobra.Command{
+		Use:   "verify [mountpoint]",
+		Short: "Verify an installed system before rebooting",
+		Long:  "Checks fstab and boot UUIDs, initramfs hooks, the EFI boot entry, enabled services, the wheel group and pacman.conf of the installed system.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mountPoint := config.PathMnt
+			if len(args) == 1 {
+				mountPoint = args[0]
+			}
+			return runVerify(cmd.OutOrStdout(), mountPoint, username, checks, jsonOutput)
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the report as JSON")
+	cmd.Flags().StringVar(&username, "user", "", "User expected in the wheel group (default: first regular user)")
+	cmd.Flags().StringSliceVar(&checks, "check", nil, "Run only the named checks")
+	return cmd
+}
+
+func runVerify(out io.Writer, mountPoint, username string, checks []string, jsonOutput bool) error {
+	oldLog, err := logger.New(config.DefaultLogPath, false)
+	if err != nil {
+		return fmt.Errorf("failed to create logger: %w", err)
+	}
+	defer func() {
+		if err := oldLog.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close logger: %v\n", err)
+		}
+	}()
+
+	slogAdapter := infralogger.NewSlogAdapter(oldLog.Slog())
+	verifyHandler := apphandlers.NewVerifyHandler(
+		&filesystem.LocalFileSystem{},
+		executor.NewShellExecutor(slogAdapter),
+		executor.NewChrootExecutor(slogAdapter),
+		slogAdapter,
+	)
+
+	result, err := verifyHandler.Handle(context.Background(), commands.VerifyCommand{
+		MountPoint: mountPoint,
+		Username:   username,
+		Checks:     checks,
+	})
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result.Report); err != nil {
+			return fmt.Errorf("encode report: %w", err)
+		}
+	} else if err := writeVerifyTable(out, result.Report); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("verification failed: %d checks failed", result.Report.Count(installation.CheckFail))
+	}
+	return nil
+}
+
+func writeVerifyTable(out io.Writer, report *installation.VerificationReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAILS")
+	for _, r := range report.Results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, strings.ToUpper(r.Status.String()), r.Message)
+	}
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n",
+		report.Count(installation.CheckPass),
+		report.Count(installation.CheckWarn),
+		report.Count(installation.CheckFail))
+	return w.Flush()
+}
@@ -0,0 +1,11 @@
+// Package archup embeds the installer assets in the archup binary, so an
+// installer always installs the assets it was built with.
+package archup
+
+import "embed"
+
+// Assets holds the install/ tree and the Plymouth theme, addressed by
+// repository-relative paths such as "install/base.packages"
+//
+//go:embed install assets/plymouth
+var Assets embed.FS
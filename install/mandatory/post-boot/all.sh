@@ -30,16 +30,6 @@ fi
 
 echo "=== ArchUp First Boot Setup - $(date) ===" >> "$LOG_FILE"
 
-# Run snapper configuration
-if [ -f /usr/local/share/archup/post-boot/snapper.sh ]; then
-  echo "Configuring snapper..." >> "$LOG_FILE"
-  if bash /usr/local/share/archup/post-boot/snapper.sh >> "$LOG_FILE" 2>&1; then
-    echo "Snapper configured successfully" >> "$LOG_FILE"
-  else
-    echo "Snapper configuration failed (non-critical)" >> "$LOG_FILE"
-  fi
-fi
-
 # Configure firewalld
 if [ -f /usr/local/share/archup/post-boot/firewalld.sh ]; then
   echo "Configuring firewall..." >> "$LOG_FILE"
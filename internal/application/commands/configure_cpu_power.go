@@ -0,0 +1,12 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/system"
+
+// ConfigureCPUPowerCommand contains data for CPU power configuration
+type ConfigureCPUPowerCommand struct {
+	MountPoint string              // Root mount point
+	Driver     system.PStateDriver // P-State driver of the CPU, PStateDriverNone when neither intel_pstate nor amd_pstate
+	Mode       string              // P-State driver mode (e.g. "active", "passive"), empty for the kernel default
+	EPP        system.EPP          // Energy performance preference default
+	Daemon     system.PowerDaemon  // Power daemon, power-profiles-daemon when empty
+}
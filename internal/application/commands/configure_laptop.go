@@ -0,0 +1,11 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/system"
+
+// ConfigureLaptopCommand contains data for the laptop profile
+type ConfigureLaptopCommand struct {
+	MountPoint           string           // Root mount point
+	Enabled              bool             // Apply the laptop profile, false on desktops
+	CPUVendor            system.CPUVendor // Intel CPUs also get thermald
+	SuspendThenHibernate bool             // Hibernate after a delay in suspend, needs a disk swap in the target fstab
+}
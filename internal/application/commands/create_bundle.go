@@ -0,0 +1,9 @@
+package commands
+
+// CreateBundleCommand contains data for building an offline bundle
+type CreateBundleCommand struct {
+	OutputDir string   // Bundle directory to create, absolute
+	Version   string   // archup version recorded in the bundle marker
+	Packages  []string // Packages added on top of the installer package lists
+	Tarball   bool     // Also pack the bundle into <OutputDir>.tar
+}
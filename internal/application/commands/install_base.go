@@ -4,10 +4,14 @@ import "github.com/bnema/archup/internal/domain/packages"
 
 // InstallBaseCommand contains data for base system installation
 type InstallBaseCommand struct {
-	TargetDisk       string                 // Target disk with partitions
-	MountPoint       string                 // Root mount point (usually "/mnt")
-	Packages         []string               // Additional packages to install (in addition to base)
-	KernelVariant    packages.KernelVariant // KernelStable, KernelZen, KernelLTS, KernelHardened, KernelCachyOS
-	IncludeMicrocode bool                   // Whether to install CPU microcode
-	Encrypted        bool                   // true when disk encryption was chosen
+	TargetDisk       string                   // Target disk with partitions
+	MountPoint       string                   // Root mount point (usually "/mnt")
+	Packages         []string                 // Additional packages to install (in addition to base)
+	KernelVariant    packages.KernelVariant   // KernelStable, KernelZen, KernelLTS, KernelHardened, KernelCachyOS
+	ExtraKernels     []packages.KernelVariant // Additional kernels installed as boot fallbacks (e.g. KernelLTS)
+	IncludeMicrocode bool                     // Whether to install CPU microcode
+	Encrypted        bool                     // true when disk encryption was chosen
+	PacmanConfig     string                   // pacman.conf for pacstrap, e.g. serving an offline bundle (optional)
+	Mirrorlist       string                   // Mirrorlist of the target, replacing the copy of the host one (optional)
+	PacmanCache      *packages.PacmanCache    // LAN package cache used by the host and the target (optional)
 }
@@ -13,10 +13,13 @@ type InstallBootloaderCommand struct {
 	TimeoutSeconds    int                       // Boot menu timeout (0-600 seconds)
 	Branding          string                    // Bootloader display name
 	KernelVariant     packages.KernelVariant    // KernelStable, KernelZen, KernelLTS, KernelHardened, KernelCachyOS
+	ExtraKernels      []packages.KernelVariant  // Additional kernels listed below the primary kernel
 	RootPartition     string                    // Root partition device path
 	EncryptionType    disk.EncryptionType       // EncryptionTypeNone, EncryptionTypeLUKS, EncryptionTypeLUKSLVM
 	EFIPartition      string                    // EFI partition device path
 	TargetDisk        string                    // Target disk device path
-	KernelParamsExtra string                    // Additional kernel parameters
-	GPUVendor         string                    // "amd", "intel", "nvidia", "unknown" — used for early KMS module
+	KernelParamsExtra string                    // Additional kernel parameters, override the presets
+	CmdlinePresets    bootloader.CmdlinePresets // Boot verbosity, mitigations, IOMMU, NVIDIA modeset, resume
+	GPUVendors        []string                  // "amd", "intel", "nvidia" of the GPUs in use, display first — used for early KMS modules
+	ExtraEntries      []string                  // Extra boot menu entries: "memtest86+", "efi-shell", "netboot.xyz"
 }
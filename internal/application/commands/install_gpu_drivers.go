@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// InstallGPUDriversCommand contains data for GPU driver installation
+type InstallGPUDriversCommand struct {
+	MountPoint     string                   // Root mount point
+	GPUVendor      string                   // "amd", "intel", "nvidia", "unknown" of the GPU driving the display
+	Profile        system.HybridProfile     // Hybrid graphics profile, HybridProfileNone on single GPU systems
+	Drivers        []string                 // Driver packages selected on the GPU screen
+	EnableMultilib bool                     // Also install the lib32 variants of the drivers
+	KernelVariant  packages.KernelVariant   // Kernel the DKMS drivers are built for
+	ExtraKernels   []packages.KernelVariant // Additional kernels the DKMS drivers are built for
+}
@@ -1,13 +1,18 @@
 package commands
 
+import "github.com/bnema/archup/internal/domain/snapshot"
+
 // PostInstallCommand contains data for post-installation tasks
 type PostInstallCommand struct {
-	MountPoint         string // Root mount point
-	Username           string // Standard user username
-	UserEmail          string // User email for git config and SSH key (optional)
-	RunPostBootScripts bool   // Whether to run post-boot scripts
-	PlymouthTheme      string // Plymouth theme to install (optional)
-	InstallDankLinux   bool   // Whether to write the Dank Linux flag file for first-boot auto-install
-	TargetDisk         string // Target disk for bootloader hook (e.g. /dev/sda)
-	Encrypted          bool   // Whether disk encryption is enabled
+	MountPoint         string                    // Root mount point
+	Username           string                    // Standard user username
+	UserEmail          string                    // User email for git config and SSH key (optional)
+	RunPostBootScripts bool                      // Whether to run post-boot scripts
+	PlymouthTheme      string                    // Plymouth theme to install (optional)
+	InstallDankLinux   bool                      // Whether to write the Dank Linux flag file for first-boot auto-install
+	TargetDisk         string                    // Target disk for bootloader hook (e.g. /dev/sda)
+	Encrypted          bool                      // Whether disk encryption is enabled
+	RootDevice         string                    // Device holding the Btrfs root filesystem, for the baseline snapshot (optional)
+	SnapperConfigs     []*snapshot.SnapperConfig // Snapper configs to render into /etc/snapper/configs (optional)
+	KeepPacmanCache    bool                      // Keep the LAN package cache servers in the installed pacman.conf
 }
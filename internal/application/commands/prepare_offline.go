@@ -0,0 +1,9 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/bundle"
+
+// PrepareOfflineCommand contains data for installing from an offline bundle
+type PrepareOfflineCommand struct {
+	Bundle     *bundle.Bundle // Opened bundle, see OfflineHandler.Open
+	MountPoint string         // Root mount point of the target system
+}
@@ -0,0 +1,10 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/disk"
+
+// RescueMountCommand contains data for re-opening and mounting an existing install
+type RescueMountCommand struct {
+	MountPoint string               // Where to mount the install, e.g. /mnt
+	Devices    *disk.InstallDevices // Partitions found by RescueHandler.Detect
+	Passphrase string               // LUKS passphrase, required when Devices is encrypted
+}
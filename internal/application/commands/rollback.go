@@ -0,0 +1,11 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/snapshot"
+
+// RollbackCommand contains data for rolling the root filesystem back to a snapper snapshot
+type RollbackCommand struct {
+	MountPoint string                // Root of the installed system: / when booted, /mnt from the ISO
+	Snapshot   int                   // Number of the snapper root snapshot to restore
+	Baseline   bool                  // Restore the installer baseline snapshot instead of a snapper snapshot
+	Mode       snapshot.RollbackMode // How the snapshot replaces the current root
+}
@@ -0,0 +1,12 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/installation"
+
+// RunHooksCommand contains data for running the user hooks of a hook point
+type RunHooksCommand struct {
+	Point      installation.HookPoint
+	Hooks      installation.Hooks             // Hooks to run, those of other points are ignored
+	MountPoint string                         // Installed system root for chroot hooks, e.g. /mnt
+	Env        map[string]string              // ARCHUP_* environment passed to every hook
+	Policy     installation.HookFailurePolicy // What a failing hook does to the phase
+}
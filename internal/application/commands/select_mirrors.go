@@ -0,0 +1,9 @@
+package commands
+
+// SelectMirrorsCommand contains data for the mirror selection
+type SelectMirrorsCommand struct {
+	Timezone string   // Selected timezone, its country picks the mirrors
+	Country  string   // ISO 3166 country code overriding the timezone country (optional)
+	Servers  []string // Mirrors used as is instead of ranking, e.g. internal mirrors (optional)
+	Count    int      // Ranked mirrors to keep, packages.DefaultMirrorCount when 0
+}
@@ -4,9 +4,15 @@ import "github.com/bnema/archup/internal/domain/packages"
 
 // SetupRepositoriesCommand contains data for repository configuration
 type SetupRepositoriesCommand struct {
-	MountPoint      string                 // Root mount point
-	EnableMultilib  bool                   // Enable multilib repository
-	AURHelper       packages.AURHelper     // AURHelperParu or AURHelperYay
-	KernelVariant   packages.KernelVariant // Kernel variant for repo setup
-	AdditionalRepos []string               // Additional repository URLs
+	MountPoint      string                   // Root mount point
+	EnableMultilib  bool                     // Enable multilib repository
+	AURHelper       packages.AURHelper       // AURHelperParu or AURHelperYay
+	KernelVariant   packages.KernelVariant   // Kernel variant for repo setup
+	ExtraKernels    []packages.KernelVariant // Additional kernels for repo setup
+	AdditionalRepos []string                 // Additional repository URLs
+	Offline         bool                     // Installing from an offline bundle: only the official repositories are available
+	PacmanCache     *packages.PacmanCache    // LAN package cache for every enabled repository (optional)
+	PackageProfiles []string                 // Package profiles to install, nil for the default profiles
+	AURPackages     []string                 // AUR packages built with the AUR helper (optional)
+	Username        string                   // User the AUR packages are built as
 }
@@ -0,0 +1,8 @@
+package commands
+
+// VerifyCommand contains data for verifying an installed system before reboot
+type VerifyCommand struct {
+	MountPoint string   // Root mount point of the installed system
+	Username   string   // User expected in the wheel group (optional, detected from /etc/passwd)
+	Checks     []string // Names of the checks to run (optional, all registered checks when empty)
+}
@@ -0,0 +1,16 @@
+package commands
+
+import "github.com/bnema/archup/internal/domain/system"
+
+// WriteInventoryCommand contains the hardware detected by the installer for
+// the hardware inventory. Machine, memory and network details are read by
+// the handler.
+type WriteInventoryCommand struct {
+	MountPoint   string                // Root mount point
+	Architecture string                // Machine architecture from preflight
+	UEFI         bool                  // Booted in UEFI mode
+	SecureBoot   bool                  // Secure Boot enabled
+	CPU          system.InventoryCPU   // Processor and Zen generation
+	GPUs         []system.InventoryGPU // Detected graphics cards
+	Disks        []system.InventoryDisk
+}
@@ -5,5 +5,6 @@ type BootloaderResult struct {
 	Success        bool
 	BootloaderType string
 	Timeout        int
+	BootEntry      string // UEFI boot number of the created entry, e.g. 0003
 	ErrorDetail    string
 }
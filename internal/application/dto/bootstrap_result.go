@@ -4,6 +4,6 @@ package dto
 type BootstrapResult struct {
 	Success     bool
 	InstallDir  string
-	Method      string // "clone" or "download"
+	Source      string // Asset source the install files come from
 	ErrorDetail string
 }
@@ -0,0 +1,11 @@
+package dto
+
+// BundleResult is the result of building an offline bundle
+type BundleResult struct {
+	Success     bool
+	Path        string   // Bundle directory
+	Tarball     string   // Bundle tarball, empty unless requested
+	Packages    int      // Package files in the bundle, dependencies included
+	Repos       []string // Repositories holding packages
+	ErrorDetail string
+}
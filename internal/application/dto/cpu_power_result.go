@@ -0,0 +1,10 @@
+package dto
+
+// CPUPowerResult is the result of CPU power configuration
+type CPUPowerResult struct {
+	Success     bool
+	Daemon      string   // Power daemon installed and enabled
+	Removed     []string // Conflicting power daemon packages removed
+	ConfigFiles []string // Configuration files written to the target
+	ErrorDetail string
+}
@@ -0,0 +1,11 @@
+package dto
+
+// GPUDriversResult is the result of GPU driver installation
+type GPUDriversResult struct {
+	Success         bool
+	Packages        []string // Installed packages, lib32 variants and kernel headers included
+	EnvironmentFile string   // environment.d file written, empty when the GPU needs no variables
+	EarlyKMS        []string // Modules added to the initramfs for early KMS
+	RuntimePM       bool     // NVIDIA dGPU runtime power management set up for PRIME render offload
+	ErrorDetail     string
+}
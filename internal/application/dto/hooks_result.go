@@ -0,0 +1,10 @@
+package dto
+
+// HooksResult is the result of running the user hooks of a hook point
+type HooksResult struct {
+	Point       string
+	Success     bool
+	HooksRun    []string // Hook script names that succeeded, in run order
+	HooksFailed []string // Hook script names that failed under the warn policy
+	ErrorDetail string
+}
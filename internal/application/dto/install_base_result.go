@@ -4,5 +4,6 @@ package dto
 type InstallBaseResult struct {
 	Success           bool
 	PackagesInstalled []string
+	HostPacmanBackup  string // Copy of the host pacman.conf made before patching it, empty if untouched
 	ErrorDetail       string
 }
@@ -16,4 +16,7 @@ type InstallationStatus struct {
 	CurrentPhase       string     // Current phase name
 	LastError          string     // Last error message (if any)
 	EstimatedRemaining int        // Estimated remaining time in seconds
+	BaselineSnapshot   string     // Read-only snapshot of the freshly installed root (if taken)
+	Hardware           []string   // Hardware inventory summary, one line per component
+	AURFailed          []string   // AUR packages that failed to build
 }
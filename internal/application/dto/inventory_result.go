@@ -0,0 +1,9 @@
+package dto
+
+// InventoryResult is the result of writing the hardware inventory
+type InventoryResult struct {
+	Success     bool
+	Path        string   // Inventory file in the target
+	Summary     []string // One line per component, for display
+	ErrorDetail string
+}
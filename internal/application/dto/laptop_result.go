@@ -0,0 +1,12 @@
+package dto
+
+// LaptopResult is the result of the laptop profile configuration
+type LaptopResult struct {
+	Success      bool
+	Packages     []string // Installed packages
+	Services     []string // Enabled systemd units
+	Hibernate    bool     // Suspend-then-hibernate set up on lid close
+	ResumeDevice string   // Swap device holding the hibernation image, e.g. "UUID=..."
+	ResumeOffset int      // Swap file offset on Btrfs, 0 for a swap partition
+	ErrorDetail  string
+}
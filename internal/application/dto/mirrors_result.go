@@ -0,0 +1,12 @@
+package dto
+
+// MirrorsResult is the result of the mirror selection
+type MirrorsResult struct {
+	Success     bool
+	Country     string   // Country the mirrors were picked in, empty worldwide
+	Servers     []string // Server values of the written mirrorlist, empty when the ISO mirrorlist was kept
+	Ranked      bool     // Servers were ranked by probing, not taken from the answer file
+	Mirrorlist  string   // Mirrorlist written to the host, copied to the target by the base install
+	HostBackup  string   // Copy of the host mirrorlist restored on rollback
+	ErrorDetail string
+}
@@ -0,0 +1,13 @@
+package dto
+
+// OfflineResult holds what was set up to install from an offline bundle
+type OfflineResult struct {
+	Success          bool
+	Bundle           string // Bundle directory
+	HostDir          string // Host directory holding the offline pacman.conf and mirrorlist
+	PacmanConf       string // pacman.conf pacstrap uses, serving only the bundle repositories
+	TargetRepoMount  string // Bind mount of the bundle repositories in the target, empty until attached
+	TargetMirrorlist string // Target mirrorlist pointing at the bind mount, empty until attached
+	MirrorlistBackup string // Copy of the target mirrorlist restored by cleanup
+	ErrorDetail      string
+}
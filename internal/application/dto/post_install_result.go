@@ -6,4 +6,5 @@ type PostInstallResult struct {
 	TasksRun             []string
 	ErrorDetail          string
 	VerificationWarnings []string
+	BaselineSnapshot     string // Read-only snapshot of the installed root, empty if it could not be taken
 }
@@ -6,6 +6,8 @@ type SystemInfo struct {
 	IsUEFI            bool
 	Distribution      string
 	SecureBootEnabled bool
+	Laptop            bool
+	OnBattery         bool
 }
 
 // CPUInfo contains CPU information
@@ -5,5 +5,7 @@ type RepositoriesResult struct {
 	Success     bool
 	Multilib    bool
 	AURHelper   string
+	AURPackages []string // AUR packages built and installed
+	AURFailed   []string // AUR packages that failed to build, left to the user
 	ErrorDetail string
 }
@@ -0,0 +1,11 @@
+package dto
+
+// RescueResult is the result of re-opening and mounting an existing install
+type RescueResult struct {
+	Success      bool
+	RootDevice   string   // Device holding the Btrfs root filesystem
+	CryptDevice  string   // LUKS mapper device opened by Mount, empty when unencrypted or already open
+	EFIPartition string   // ESP partition
+	MountedAt    []string // Mount points in mount order
+	ErrorDetail  string
+}
@@ -0,0 +1,11 @@
+package dto
+
+// RollbackResult is the result of a snapshot rollback
+type RollbackResult struct {
+	Success      bool
+	Snapshot     int
+	Mode         string
+	NewRoot      string // Subvolume mounted as / on next boot
+	PreviousRoot string // Subvolume holding the replaced root, kept for recovery
+	ErrorDetail  string
+}
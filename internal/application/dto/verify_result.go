@@ -0,0 +1,10 @@
+package dto
+
+import "github.com/bnema/archup/internal/domain/installation"
+
+// VerifyResult is the result of a post-install verification run
+type VerifyResult struct {
+	Success     bool // True when no check failed
+	Report      *installation.VerificationReport
+	ErrorDetail string
+}
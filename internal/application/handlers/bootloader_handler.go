@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/bnema/archup/internal/application/commands"
@@ -58,14 +59,14 @@ func (h *BootloaderHandler) Handle(ctx context.Context, cmd commands.InstallBoot
 
 	h.logger.Info("Bootloader configuration validated", "type", bl.Type())
 
-	kernel, err := packages.NewKernel(cmd.KernelVariant)
+	kernels, err := packages.NewKernelSet(cmd.KernelVariant, cmd.ExtraKernels...)
 	if err != nil {
 		h.logger.Error("Invalid kernel variant", "error", err)
 		result.ErrorDetail = fmt.Sprintf("Invalid kernel variant: %v", err)
 		return result, err
 	}
 
-	if err := h.configureMkinitcpio(ctx, cmd.MountPoint, cmd.EncryptionType, cmd.GPUVendor, kernel.PackageName()); err != nil {
+	if err := h.configureMkinitcpio(ctx, cmd.MountPoint, cmd.EncryptionType, cmd.GPUVendors, cmd.CmdlinePresets.Resume != "", kernels.PackageNames()); err != nil {
 		result.ErrorDetail = err.Error()
 		return result, err
 	}
@@ -75,15 +76,17 @@ func (h *BootloaderHandler) Handle(ctx context.Context, cmd commands.InstallBoot
 		return result, err
 	}
 
-	if err := h.configureLimine(ctx, cmd, kernel.PackageName()); err != nil {
+	if err := h.configureLimine(ctx, cmd, kernels.PackageNames()); err != nil {
 		result.ErrorDetail = err.Error()
 		return result, err
 	}
 
-	if err := h.createBootEntry(ctx, cmd.TargetDisk, cmd.EFIPartition, cmd.MountPoint); err != nil {
+	bootEntry, err := h.createBootEntry(ctx, cmd.TargetDisk, cmd.EFIPartition, cmd.MountPoint)
+	if err != nil {
 		result.ErrorDetail = err.Error()
 		return result, err
 	}
+	result.BootEntry = bootEntry
 
 	result.Success = true
 	result.BootloaderType = bl.Type().String()
@@ -93,7 +96,7 @@ func (h *BootloaderHandler) Handle(ctx context.Context, cmd commands.InstallBoot
 	return result, nil
 }
 
-func (h *BootloaderHandler) configureMkinitcpio(ctx context.Context, mountPoint string, encType disk.EncryptionType, gpuVendor, kernelName string) error {
+func (h *BootloaderHandler) configureMkinitcpio(ctx context.Context, mountPoint string, encType disk.EncryptionType, gpuVendors []string, resume bool, kernelNames []string) error {
 	confPath := filepath.Join(mountPoint, "etc", "mkinitcpio.conf")
 	content, err := h.fs.ReadFile(confPath)
 	if err != nil {
@@ -106,11 +109,16 @@ func (h *BootloaderHandler) configureMkinitcpio(ctx context.Context, mountPoint
 		hooks = config.MkinitcpioHooksEncrypted
 	}
 
+	// Hibernation resumes from the swap once the root device is unlocked, before mounting it
+	if resume {
+		hooks = strings.Replace(hooks, " filesystems", " resume filesystems", 1)
+	}
+
 	updated := replaceHooksLine(string(content), hooks)
 
 	// Set the KMS module for early framebuffer so Plymouth loads cleanly.
 	// Without this, the kms hook has nothing to load and Plymouth appears late.
-	kmsModule := kmsModuleForGPU(gpuVendor)
+	kmsModule := kmsModuleForGPU(gpuVendors...)
 	updated = replaceModulesLine(updated, kmsModule)
 
 	if err := h.fs.WriteFile(confPath, []byte(updated), 0644); err != nil {
@@ -126,21 +134,23 @@ func (h *BootloaderHandler) configureMkinitcpio(ctx context.Context, mountPoint
 	// Warn if the fallback initramfs was not produced; limine-snapper-notify depends on it
 	// for creating snapshot boot entries. The bootloader config already tolerates a missing
 	// fallback, but an early warning here helps diagnose first-boot notification failures.
-	fallbackPath := fallbackInitramfsPath(mountPoint, kernelName)
-	if _, statErr := h.fs.Stat(fallbackPath); statErr != nil {
-		if errors.Is(statErr, os.ErrNotExist) {
-			h.logger.Warn(
-				"Fallback initramfs was not generated; snapshot boot entries may not include fallback",
-				"kernel", kernelName,
-				"path", fallbackPath,
-			)
-		} else {
-			h.logger.Warn(
-				"Could not verify fallback initramfs after mkinitcpio",
-				"kernel", kernelName,
-				"path", fallbackPath,
-				"error", statErr,
-			)
+	for _, kernelName := range kernelNames {
+		fallbackPath := fallbackInitramfsPath(mountPoint, kernelName)
+		if _, statErr := h.fs.Stat(fallbackPath); statErr != nil {
+			if errors.Is(statErr, os.ErrNotExist) {
+				h.logger.Warn(
+					"Fallback initramfs was not generated; snapshot boot entries may not include fallback",
+					"kernel", kernelName,
+					"path", fallbackPath,
+				)
+			} else {
+				h.logger.Warn(
+					"Could not verify fallback initramfs after mkinitcpio",
+					"kernel", kernelName,
+					"path", fallbackPath,
+					"error", statErr,
+				)
+			}
 		}
 	}
 
@@ -153,17 +163,25 @@ func fallbackInitramfsPath(mountPoint, kernelName string) string {
 	return filepath.Join(mountPoint, "boot", fmt.Sprintf("initramfs-%s-fallback.img", kernelName))
 }
 
-// kmsModuleForGPU returns the kernel module name required for early KMS on the given GPU vendor.
-// Returns an empty string for NVIDIA (uses proprietary driver, no early KMS) or unknown GPUs.
-func kmsModuleForGPU(vendor string) string {
-	switch vendor {
-	case "amd":
-		return "amdgpu"
-	case "intel":
-		return "i915"
-	default:
-		return ""
+// kmsModuleForGPU returns the kernel modules required for early KMS on the given
+// combination of GPU vendors, display GPU first: with PRIME render offload the
+// iGPU module is loaded early. NVIDIA is skipped, its modules only exist once the
+// GPU drivers phase installed the driver; that phase sets up NVIDIA early KMS.
+func kmsModuleForGPU(vendors ...string) string {
+	var modules []string
+	for _, vendor := range vendors {
+		module := ""
+		switch vendor {
+		case "amd":
+			module = "amdgpu"
+		case "intel":
+			module = "i915"
+		}
+		if module != "" && !slices.Contains(modules, module) {
+			modules = append(modules, module)
+		}
 	}
+	return strings.Join(modules, " ")
 }
 
 // replaceModulesLine replaces the MODULES=(...) line in mkinitcpio.conf.
@@ -205,7 +223,7 @@ func (h *BootloaderHandler) installLimine(ctx context.Context, mountPoint string
 	return nil
 }
 
-func (h *BootloaderHandler) configureLimine(ctx context.Context, cmd commands.InstallBootloaderCommand, kernelName string) error {
+func (h *BootloaderHandler) configureLimine(ctx context.Context, cmd commands.InstallBootloaderCommand, kernelNames []string) error {
 	rootUUIDBytes, err := h.cmdExec.Execute(ctx, "blkid", "-s", "UUID", "-o", "value", cmd.RootPartition)
 	if err != nil {
 		h.logger.Error("Failed to get root UUID", "error", err)
@@ -213,17 +231,12 @@ func (h *BootloaderHandler) configureLimine(ctx context.Context, cmd commands.In
 	}
 
 	rootUUID := strings.TrimSpace(string(rootUUIDBytes))
-	var kernelParams string
-	if cmd.EncryptionType != disk.EncryptionTypeNone {
-		kernelParams = fmt.Sprintf("cryptdevice=UUID=%s:cryptroot root=/dev/mapper/cryptroot rootflags=subvol=@ rw", rootUUID)
-	} else {
-		kernelParams = fmt.Sprintf("root=UUID=%s rootflags=subvol=@ rw", rootUUID)
-	}
-
-	kernelParams = strings.TrimSpace(fmt.Sprintf("%s %s", kernelParams, config.KernelParamsQuiet))
-	if extra := strings.TrimSpace(cmd.KernelParamsExtra); extra != "" {
-		kernelParams = strings.TrimSpace(kernelParams + " " + extra)
+	cmdline, err := kernelCmdline(cmd, rootUUID)
+	if err != nil {
+		h.logger.Error("Invalid kernel command line", "error", err)
+		return fmt.Errorf("invalid kernel command line: %w", err)
 	}
+	kernelParams := cmdline.Render(bootloader.CmdlineTargetLimine)
 
 	templatePath, err := h.resolveLimineTemplate()
 	if err != nil {
@@ -244,26 +257,38 @@ func (h *BootloaderHandler) configureLimine(ctx context.Context, cmd commands.In
 		machineID = "unknown"
 	}
 
-	limineConfig := string(templateBytes)
-	limineConfig = strings.ReplaceAll(limineConfig, "{{TIMEOUT}}", fmt.Sprintf("%d", cmd.TimeoutSeconds))
-	limineConfig = strings.ReplaceAll(limineConfig, "{{BRANDING}}", cmd.Branding)
-	limineConfig = strings.ReplaceAll(limineConfig, "{{COLOR}}", config.LimineColor)
-	limineConfig = strings.ReplaceAll(limineConfig, "{{KERNEL}}", kernelName)
-	limineConfig = strings.ReplaceAll(limineConfig, "{{KERNEL_PARAMS}}", kernelParams)
-	limineConfig = strings.ReplaceAll(limineConfig, "{{MACHINE_ID}}", machineID)
+	limineCfg := bootloader.LimineConfig{
+		Timeout:        cmd.TimeoutSeconds,
+		DefaultEntry:   config.LimineDefaultEntry,
+		Branding:       cmd.Branding,
+		BrandingColour: config.LimineColor,
+		MachineID:      machineID,
+	}
 
-	// Conditionally include fallback initramfs stanza only if the file exists
-	fallbackImgPath := fallbackInitramfsPath(cmd.MountPoint, kernelName)
-	fallbackEntry := ""
-	if _, err := h.fs.Stat(fallbackImgPath); err == nil {
-		fallbackEntry = fmt.Sprintf(
-			"\n    //%s-fallback\n    protocol: linux\n    path: boot():/vmlinuz-%s\n    cmdline: %s\n    module_path: boot():/initramfs-%s-fallback.img\n",
-			kernelName, kernelName, kernelParams, kernelName,
-		)
-	} else if !errors.Is(err, os.ErrNotExist) {
-		h.logger.Warn("Could not stat fallback initramfs, omitting fallback entry", "path", fallbackImgPath, "error", err)
+	// Primary kernel first: it is the default entry, extra kernels are fallbacks below it
+	for _, kernelName := range kernelNames {
+		limineCfg.Kernels = append(limineCfg.Kernels, h.limineKernel(cmd.MountPoint, kernelName, kernelParams))
+	}
+
+	for _, id := range cmd.ExtraEntries {
+		entry, err := bootloader.LookupExtraEntry(id)
+		if err != nil {
+			h.logger.Warn("Skipping unknown boot entry", "entry", id)
+			continue
+		}
+		// Optional tools must never fail the install, skip the entry instead
+		if err := h.installExtraEntry(ctx, cmd.MountPoint, entry); err != nil {
+			h.logger.Warn("Failed to install boot entry payload, omitting entry", "entry", entry.ID, "error", err)
+			continue
+		}
+		limineCfg.Entries = append(limineCfg.Entries, entry.LimineEntry())
+	}
+
+	limineConfig, err := limineCfg.Render(string(templateBytes))
+	if err != nil {
+		h.logger.Error("Failed to render Limine config", "error", err)
+		return fmt.Errorf("failed to render Limine config: %w", err)
 	}
-	limineConfig = strings.ReplaceAll(limineConfig, "{{FALLBACK_ENTRY}}", fallbackEntry)
 
 	limineConfigPath := filepath.Join(cmd.MountPoint, "boot", "limine.conf")
 	if err := h.fs.WriteFile(limineConfigPath, []byte(limineConfig), 0644); err != nil {
@@ -274,17 +299,118 @@ func (h *BootloaderHandler) configureLimine(ctx context.Context, cmd commands.In
 	return nil
 }
 
-func (h *BootloaderHandler) createBootEntry(ctx context.Context, targetDisk, efiPartition, mountPoint string) error {
+// kernelCmdline builds the kernel command line: root parameters, then presets,
+// then the extra parameters which override both
+func kernelCmdline(cmd commands.InstallBootloaderCommand, rootUUID string) (*bootloader.KernelCmdline, error) {
+	cmdline, err := bootloader.RootCmdline(rootUUID, cmd.EncryptionType != disk.EncryptionTypeNone)
+	if err != nil {
+		return nil, err
+	}
+
+	cmdline, err = cmdline.ApplyPresets(cmd.CmdlinePresets)
+	if err != nil {
+		return nil, err
+	}
+
+	extra, err := bootloader.ParseKernelCmdline(cmd.KernelParamsExtra)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmdline.Merge(extra), nil
+}
+
+// limineKernel builds the menu entry for a kernel, including the fallback
+// initramfs stanza only if the image exists
+func (h *BootloaderHandler) limineKernel(mountPoint, kernelName, kernelParams string) bootloader.LimineKernel {
+	kernel := bootloader.LimineKernel{
+		Name:    kernelName,
+		Cmdline: kernelParams,
+	}
+
+	fallbackImgPath := fallbackInitramfsPath(mountPoint, kernelName)
+	if _, err := h.fs.Stat(fallbackImgPath); err == nil {
+		kernel.Fallback = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		h.logger.Warn("Could not stat fallback initramfs, omitting fallback entry", "path", fallbackImgPath, "error", err)
+	}
+
+	return kernel
+}
+
+// installExtraEntry puts the EFI payload of an extra boot entry onto the ESP
+func (h *BootloaderHandler) installExtraEntry(ctx context.Context, mountPoint string, entry bootloader.ExtraEntry) error {
+	h.logger.Info("Installing boot entry payload", "entry", entry.ID)
+
+	if entry.Package != "" {
+		if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "pacman", "-S", "--noconfirm", "--needed", entry.Package); err != nil {
+			return fmt.Errorf("failed to install %s: %w", entry.Package, err)
+		}
+	}
+
+	dst := filepath.Join(mountPoint, "boot", filepath.FromSlash(entry.ESPPath))
+	if entry.Source == "" && entry.URL == "" {
+		// The package installs the payload straight onto the ESP
+		return nil
+	}
+
+	if err := h.fs.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+
+	if entry.Source != "" {
+		src := filepath.Join(mountPoint, filepath.FromSlash(entry.Source))
+		if _, err := h.cmdExec.Execute(ctx, "cp", src, dst); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", src, err)
+		}
+		return nil
+	}
+
+	if _, err := h.cmdExec.Execute(ctx, "curl", "-fsSL", "-o", dst, entry.URL); err != nil {
+		return fmt.Errorf("failed to download %s: %w", entry.URL, err)
+	}
+	return nil
+}
+
+// createBootEntry creates the UEFI boot entry and returns its boot number
+func (h *BootloaderHandler) createBootEntry(ctx context.Context, targetDisk, efiPartition, mountPoint string) (string, error) {
 	partNum := extractPartitionNumber(efiPartition)
 	if partNum == "" {
-		return fmt.Errorf("failed to determine EFI partition number from %s", efiPartition)
+		return "", fmt.Errorf("failed to determine EFI partition number from %s", efiPartition)
 	}
 
-	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "efibootmgr", "--create", "--disk", targetDisk, "--part", partNum, "--label", config.UEFIBootLabel, "--loader", config.UEFIBootLoader, "--unicode"); err != nil {
+	output, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "efibootmgr", "--create", "--disk", targetDisk, "--part", partNum, "--label", config.UEFIBootLabel, "--loader", config.UEFIBootLoader, "--unicode")
+	if err != nil {
 		h.logger.Error("Failed to create EFI boot entry", "error", err)
-		return fmt.Errorf("failed to create EFI boot entry: %w", err)
+		return "", fmt.Errorf("failed to create EFI boot entry: %w", err)
+	}
+
+	return firstBootOrderEntry(string(output)), nil
+}
+
+// bootOrderPattern matches the BootOrder line of efibootmgr output
+var bootOrderPattern = regexp.MustCompile(`(?m)^BootOrder:\s*([0-9A-Fa-f]{4})`)
+
+// firstBootOrderEntry returns the first boot number of the efibootmgr
+// BootOrder, where efibootmgr --create puts the new entry
+func firstBootOrderEntry(output string) string {
+	if m := bootOrderPattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Rollback deletes the UEFI boot entry created by Handle, so the firmware
+// does not keep booting into an unfinished install
+func (h *BootloaderHandler) Rollback(ctx context.Context, result *dto.BootloaderResult) error {
+	if result == nil || result.BootEntry == "" {
+		return nil
 	}
 
+	h.logger.Warn("Removing EFI boot entry", "bootnum", result.BootEntry)
+	if _, err := h.cmdExec.Execute(ctx, "efibootmgr", "--bootnum", result.BootEntry, "--delete-bootnum"); err != nil {
+		return fmt.Errorf("failed to delete EFI boot entry %s: %w", result.BootEntry, err)
+	}
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
 	"github.com/bnema/archup/internal/domain/bootloader"
 	"github.com/bnema/archup/internal/domain/disk"
 	"github.com/bnema/archup/internal/domain/packages"
@@ -158,7 +159,7 @@ func TestConfigureLimine_FallbackAbsent(t *testing.T) {
 		EncryptionType: disk.EncryptionTypeNone,
 	}
 
-	err := handler.configureLimine(context.Background(), cmd, "linux")
+	err := handler.configureLimine(context.Background(), cmd, []string{"linux"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -222,7 +223,7 @@ func TestConfigureLimine_FallbackPresent(t *testing.T) {
 		EncryptionType: disk.EncryptionTypeNone,
 	}
 
-	err := handler.configureLimine(context.Background(), cmd, "linux")
+	err := handler.configureLimine(context.Background(), cmd, []string{"linux"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -330,8 +331,233 @@ func TestConfigureMkinitcpio_WarnsWhenFallbackMissing(t *testing.T) {
 
 	handler := NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
 
-	err := handler.configureMkinitcpio(context.Background(), "/mnt", disk.EncryptionTypeNone, "", "linux")
+	err := handler.configureMkinitcpio(context.Background(), "/mnt", disk.EncryptionTypeNone, nil, false, []string{"linux"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 }
+
+func TestConfigureMkinitcpio_ResumeHook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockFS.EXPECT().ReadFile("/mnt/etc/mkinitcpio.conf").Return([]byte("MODULES=()\nHOOKS=(base udev)\n"), nil)
+	mockFS.EXPECT().WriteFile("/mnt/etc/mkinitcpio.conf", gomock.Any(), os.FileMode(0644)).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			if !strings.Contains(string(data), "encrypt resume filesystems") {
+				t.Errorf("expected the resume hook after encrypt, got:\n%s", data)
+			}
+			return nil
+		})
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "mkinitcpio", "-P").Return([]byte{}, nil)
+
+	handler := NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	if err := handler.configureMkinitcpio(context.Background(), "/mnt", disk.EncryptionTypeLUKS, nil, true, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestKMSModuleForGPU(t *testing.T) {
+	tests := []struct {
+		vendors  []string
+		expected string
+	}{
+		{[]string{"intel"}, "i915"},
+		{[]string{"intel", "nvidia"}, "i915"},
+		{[]string{"amd", "amd"}, "amdgpu"},
+		{[]string{"amd", "nvidia"}, "amdgpu"},
+		{[]string{"nvidia"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := kmsModuleForGPU(tt.vendors...); got != tt.expected {
+			t.Errorf("kmsModuleForGPU(%v) = %q, expected %q", tt.vendors, got, tt.expected)
+		}
+	}
+}
+
+// TestConfigureLimine_ExtraEntries verifies that extra boot entries get their payload
+// installed onto the ESP and are rendered as top-level menu entries.
+func TestConfigureLimine_ExtraEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "blkid", "-s", "UUID", "-o", "value", gomock.Any()).
+		Return([]byte("test-uuid"), nil)
+	mockFS.EXPECT().Exists(gomock.Any()).Return(true, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).DoAndReturn(func(path string) ([]byte, error) {
+		if strings.HasSuffix(path, "limine.conf.template") {
+			return []byte(limineTemplate), nil
+		}
+		return []byte("abc123\n"), nil
+	}).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().MkdirAll("/mnt/boot/EFI/tools", gomock.Any()).Return(nil)
+
+	// efi-shell: package installed in chroot, binary copied onto the ESP
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed", "edk2-shell").Return([]byte{}, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "cp", "/mnt/usr/share/edk2-shell/x64/Shell_Full.efi", "/mnt/boot/EFI/tools/shellx64.efi").Return([]byte{}, nil)
+
+	var writtenConfig string
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(path string, data []byte, perm os.FileMode) error {
+			writtenConfig = string(data)
+			return nil
+		},
+	)
+
+	handler := NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	cmd := commands.InstallBootloaderCommand{
+		MountPoint:     "/mnt",
+		BootloaderType: bootloader.BootloaderTypeLimine,
+		TimeoutSeconds: 5,
+		Branding:       "ArchUp",
+		KernelVariant:  packages.KernelStable,
+		RootPartition:  "/dev/sda2",
+		EncryptionType: disk.EncryptionTypeNone,
+		ExtraEntries:   []string{"efi-shell", "unknown-tool"},
+	}
+
+	if err := handler.configureLimine(context.Background(), cmd, []string{"linux"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(writtenConfig, "/UEFI Shell\n    protocol: efi\n    path: boot():/EFI/tools/shellx64.efi") {
+		t.Errorf("expected UEFI Shell entry in limine.conf, got:\n%s", writtenConfig)
+	}
+}
+
+// TestConfigureLimine_MultipleKernels verifies that every installed kernel gets a boot
+// entry, with the primary kernel first.
+func TestConfigureLimine_MultipleKernels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "blkid", "-s", "UUID", "-o", "value", gomock.Any()).
+		Return([]byte("test-uuid"), nil)
+	mockFS.EXPECT().Exists(gomock.Any()).Return(true, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).DoAndReturn(func(path string) ([]byte, error) {
+		if strings.HasSuffix(path, "limine.conf.template") {
+			return []byte(limineTemplate), nil
+		}
+		return []byte("abc123\n"), nil
+	}).AnyTimes()
+	mockFS.EXPECT().Stat("/mnt/boot/initramfs-linux-zen-fallback.img").Return(nil, nil)
+	mockFS.EXPECT().Stat("/mnt/boot/initramfs-linux-lts-fallback.img").Return(nil, nil)
+
+	var writtenConfig string
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(path string, data []byte, perm os.FileMode) error {
+			writtenConfig = string(data)
+			return nil
+		},
+	)
+
+	handler := NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	cmd := commands.InstallBootloaderCommand{
+		MountPoint:     "/mnt",
+		BootloaderType: bootloader.BootloaderTypeLimine,
+		TimeoutSeconds: 5,
+		Branding:       "ArchUp",
+		KernelVariant:  packages.KernelZen,
+		ExtraKernels:   []packages.KernelVariant{packages.KernelLTS},
+		RootPartition:  "/dev/sda2",
+		EncryptionType: disk.EncryptionTypeNone,
+	}
+
+	if err := handler.configureLimine(context.Background(), cmd, []string{"linux-zen", "linux-lts"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, want := range []string{"initramfs-linux-zen.img", "initramfs-linux-zen-fallback.img", "initramfs-linux-lts.img", "initramfs-linux-lts-fallback.img"} {
+		if !strings.Contains(writtenConfig, want) {
+			t.Errorf("expected %q in limine.conf, got:\n%s", want, writtenConfig)
+		}
+	}
+
+	if strings.Index(writtenConfig, "//linux-zen") > strings.Index(writtenConfig, "//linux-lts") {
+		t.Errorf("expected primary kernel entry first, got:\n%s", writtenConfig)
+	}
+}
+
+func TestKernelCmdline_PresetsAndExtraParams(t *testing.T) {
+	cmd := commands.InstallBootloaderCommand{
+		EncryptionType:    disk.EncryptionTypeLUKS,
+		KernelParamsExtra: "loglevel=7 amd_pstate=active",
+		CmdlinePresets: bootloader.CmdlinePresets{
+			Mitigations:   bootloader.MitigationsOff,
+			NVIDIAModeset: true,
+		},
+	}
+
+	cmdline, err := kernelCmdline(cmd, "test-uuid")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := "cryptdevice=UUID=test-uuid:cryptroot root=/dev/mapper/cryptroot rootflags=subvol=@ rw " +
+		"quiet splash loglevel=7 rd.udev.log_priority=3 systemd.show_status=auto " +
+		"mitigations=off nvidia_drm.modeset=1 amd_pstate=active"
+	if cmdline.String() != expected {
+		t.Errorf("expected %q, got %q", expected, cmdline.String())
+	}
+
+	cmd.KernelParamsExtra = `broken="quote`
+	if _, err := kernelCmdline(cmd, "test-uuid"); err == nil {
+		t.Error("expected error for invalid extra parameters")
+	}
+}
+
+func TestFirstBootOrderEntry(t *testing.T) {
+	output := "BootCurrent: 0001\nTimeout: 1 seconds\nBootOrder: 0004,0001,0000\nBoot0000* UEFI OS\nBoot0004* Arch Linux\n"
+	if got := firstBootOrderEntry(output); got != "0004" {
+		t.Errorf("expected boot entry 0004, got %q", got)
+	}
+	if got := firstBootOrderEntry("efibootmgr: no output"); got != "" {
+		t.Errorf("expected no boot entry, got %q", got)
+	}
+}
+
+func TestBootloaderHandler_Rollback(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "efibootmgr", "--bootnum", "0004", "--delete-bootnum").Return([]byte{}, nil)
+
+	handler := NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	if err := handler.Rollback(context.Background(), &dto.BootloaderResult{BootEntry: "0004"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Nothing to delete when the entry was never created
+	if err := handler.Rollback(context.Background(), &dto.BootloaderResult{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
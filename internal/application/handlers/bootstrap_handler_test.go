@@ -2,44 +2,64 @@ package handlers
 
 import (
 	"context"
-	"net/http"
-	"strings"
+	"errors"
 	"testing"
 
+	"github.com/bnema/archup/internal/config"
 	"github.com/bnema/archup/internal/domain/ports/mocks"
 	"go.uber.org/mock/gomock"
 )
 
-func TestBootstrapHandler_DownloadFiles_RejectsHTTPErrors(t *testing.T) {
+func TestBootstrapHandler_Handle_CopiesAssets(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
 
-	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
-	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockAssets.EXPECT().String().Return("embedded").AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().
-		Get("https://raw.githubusercontent.com/bnema/archup/v1.2.3/install/base.packages").
-		Return(newMockResponse(ctrl, http.StatusNotFound, []byte("not found")), nil)
-
-	handler := NewBootstrapHandler(
-		mockFS,
-		mockHTTP,
-		mockLogger,
-		"https://github.com/bnema/archup",
-		"https://raw.githubusercontent.com/bnema/archup/v1.2.3",
-		"v1.2.3",
-	)
-
-	err := handler.downloadFiles(context.Background())
-	if err == nil {
-		t.Fatal("expected downloadFiles to fail on non-2xx response")
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).DoAndReturn(func(name string) ([]byte, error) {
+		return []byte(name), nil
+	}).Times(len(config.InstallAssets()) + len(bootstrapFiles))
+	for _, f := range bootstrapFiles {
+		mockFS.EXPECT().WriteFile("/tmp/archup-install/"+f.dest, []byte(f.asset), gomock.Any()).Return(nil)
+	}
+
+	handler := NewBootstrapHandler(mockFS, mockAssets, mockLogger)
+
+	result, err := handler.Handle(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Source != "embedded" {
+		t.Errorf("unexpected result %+v", result)
 	}
+}
+
+func TestBootstrapHandler_Handle_RejectedAsset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
-	if !strings.Contains(err.Error(), "unexpected status 404") {
-		t.Fatalf("expected status error, got %v", err)
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockAssets.EXPECT().String().Return("remote https://raw.githubusercontent.com/bnema/archup/dev").AnyTimes()
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset("install/base.packages").Return(nil, errors.New("install/base.packages does not match the manifest"))
+
+	handler := NewBootstrapHandler(mockFS, mockAssets, mockLogger)
+
+	result, err := handler.Handle(context.Background())
+	if err == nil {
+		t.Fatal("expected error for rejected asset")
+	}
+	if result.Success || result.ErrorDetail == "" {
+		t.Errorf("expected failed result with detail, got %+v", result)
 	}
 }
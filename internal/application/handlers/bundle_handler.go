@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// bundlePacmanDir is the pacman work directory inside the bundle, removed once
+// the bundle is built
+const bundlePacmanDir = ".pacman"
+
+// BundleHandler builds offline bundles: the install assets plus a pacman
+// repository of every package the installer may install, dependencies included
+type BundleHandler struct {
+	fs      ports.FileSystem
+	assets  ports.AssetSource
+	cmdExec ports.CommandExecutor
+	logger  ports.Logger
+}
+
+// NewBundleHandler creates a new bundle handler
+func NewBundleHandler(fs ports.FileSystem, assets ports.AssetSource, cmdExec ports.CommandExecutor, logger ports.Logger) *BundleHandler {
+	return &BundleHandler{
+		fs:      fs,
+		assets:  assets,
+		cmdExec: cmdExec,
+		logger:  logger,
+	}
+}
+
+// Handle builds the bundle described by cmd
+func (h *BundleHandler) Handle(ctx context.Context, cmd commands.CreateBundleCommand) (*dto.BundleResult, error) {
+	result := &dto.BundleResult{
+		Success: false,
+		Repos:   []string{},
+	}
+
+	fail := func(message string, err error) (*dto.BundleResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	b, err := bundle.NewBundle(cmd.OutputDir)
+	if err != nil {
+		return fail("Invalid bundle path", err)
+	}
+	result.Path = b.Root()
+	h.logger.Info("Creating offline bundle", "path", b.Root())
+
+	if err := h.fs.MkdirAll(b.Root(), 0755); err != nil {
+		return fail("Failed to create bundle directory", err)
+	}
+	defer func() {
+		if err := h.fs.RemoveAll(filepath.Join(b.Root(), bundlePacmanDir)); err != nil {
+			h.logger.Warn("Failed to remove bundle work directory", "error", err)
+		}
+	}()
+
+	if err := h.exportAssets(b); err != nil {
+		return fail("Failed to export install assets", err)
+	}
+
+	pkgs, err := h.bundlePackages(b, cmd.Packages)
+	if err != nil {
+		return fail("Failed to collect packages", err)
+	}
+	h.logger.Info("Collected bundle packages", "count", len(pkgs))
+
+	targets, err := h.downloadPackages(ctx, b, pkgs)
+	if err != nil {
+		return fail("Failed to download packages", err)
+	}
+
+	if err := h.createRepos(ctx, b, targets); err != nil {
+		return fail("Failed to create repositories", err)
+	}
+	result.Packages = targets.Count()
+	result.Repos = targets.Repos()
+
+	if err := h.fs.WriteFile(b.MarkerPath(), []byte(bundle.Marker(cmd.Version)), 0644); err != nil {
+		return fail("Failed to write bundle marker", err)
+	}
+
+	if cmd.Tarball {
+		tarball := b.Root() + ".tar"
+		h.logger.Info("Packing bundle", "tarball", tarball)
+		if _, err := h.cmdExec.Execute(ctx, "tar", "-C", filepath.Dir(b.Root()), "-cf", tarball, filepath.Base(b.Root())); err != nil {
+			return fail("Failed to pack bundle", err)
+		}
+		result.Tarball = tarball
+	}
+
+	result.Success = true
+	h.logger.Info("Offline bundle created", "path", b.Root(), "packages", result.Packages)
+	return result, nil
+}
+
+// exportAssets writes the install assets into the bundle
+func (h *BundleHandler) exportAssets(b *bundle.Bundle) error {
+	h.logger.Info("Exporting install assets", "assets", h.assets.String())
+	if err := h.fs.RemoveAll(b.AssetsDir()); err != nil {
+		return fmt.Errorf("failed to clear assets directory: %w", err)
+	}
+	for _, asset := range config.InstallAssets() {
+		content, err := h.assets.ReadAsset(asset)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(b.AssetsDir(), filepath.FromSlash(asset))
+		if err := h.fs.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", asset, err)
+		}
+		if err := h.fs.WriteFile(dest, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", asset, err)
+		}
+	}
+	return nil
+}
+
+// bundlePackages returns every package the installer may install from the
+// official repositories, whatever the choices made in the installer
+func (h *BundleHandler) bundlePackages(b *bundle.Bundle, extra []string) ([]string, error) {
+	pkgs, err := readPackageFile(h.fs, filepath.Join(b.AssetsDir(), "install", config.BasePackagesFile))
+	if err != nil {
+		return nil, err
+	}
+	// Every profile, the profiles are chosen in the installer
+	if catalog, err := readProfileCatalog(h.fs, filepath.Join(b.AssetsDir(), "install", config.ProfilesFile)); err != nil {
+		h.logger.Warn("Could not load profiles.packages", "error", err)
+	} else {
+		pkgs = append(pkgs, catalog.AllPackages()...)
+	}
+
+	// Every official kernel with its headers for DKMS modules; linux-cachyos
+	// lives in the CachyOS repository and cannot be installed offline
+	for _, variant := range packages.AvailableKernels() {
+		if variant == packages.KernelCachyOS {
+			continue
+		}
+		kernel, err := packages.NewKernel(variant)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, kernel.PackageName(), kernel.HeadersPackage())
+	}
+
+	pkgs = append(pkgs, "intel-ucode", "amd-ucode", "cryptsetup")
+	for _, entry := range bootloader.AvailableExtraEntries() {
+		if entry.Package != "" {
+			pkgs = append(pkgs, entry.Package)
+		}
+	}
+	// Drivers of every vendor and NVIDIA branch in the official repositories,
+	// the legacy NVIDIA branches come from Chaotic-AUR and need the network
+	var drivers []string
+	for _, vendor := range []system.GPUVendor{system.GPUVendorAMD, system.GPUVendorIntel, system.GPUVendorNVIDIA} {
+		drivers = append(drivers, recommendedDrivers(vendor)...)
+	}
+	drivers = append(drivers, system.SelectNVIDIADriver(system.NVIDIAGenerationPascal).Packages...)
+	drivers = append(drivers, system.NVIDIADKMSVariants(drivers)...)
+	drivers = append(drivers, system.NVIDIAPrimePackage)
+	pkgs = append(pkgs, drivers...)
+	pkgs = append(pkgs, system.Lib32Drivers(drivers)...)
+	pkgs = append(pkgs, system.AllPowerDaemonPackages()...)
+	pkgs = append(pkgs, system.LaptopPackages(system.CPUVendorIntel)...)
+	pkgs = append(pkgs, extra...)
+
+	return uniquePackages(pkgs), nil
+}
+
+// downloadPackages resolves pkgs and their dependencies against the host
+// mirrors and downloads them, using a private pacman database so packages
+// already installed on the host are bundled too
+func (h *BundleHandler) downloadPackages(ctx context.Context, b *bundle.Bundle, pkgs []string) (bundle.Targets, error) {
+	pacmanDir := filepath.Join(b.Root(), bundlePacmanDir)
+	dbPath := filepath.Join(pacmanDir, "db")
+	confPath := filepath.Join(pacmanDir, "pacman.conf")
+	if err := h.fs.MkdirAll(dbPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pacman database directory: %w", err)
+	}
+	if err := h.fs.WriteFile(confPath, []byte(bundle.PacmanConf("/etc/pacman.d/mirrorlist")), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pacman.conf: %w", err)
+	}
+	pacman := []string{"--config", confPath, "--dbpath", dbPath}
+
+	if _, err := h.cmdExec.Execute(ctx, "pacman", append([]string{"-Sy"}, pacman...)...); err != nil {
+		return nil, fmt.Errorf("failed to sync package databases: %w", err)
+	}
+
+	args := append(append([]string{"-Sp", "--noconfirm", "--print-format", "%r %l"}, pacman...), pkgs...)
+	output, err := h.cmdExec.Execute(ctx, "pacman", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve packages: %w", err)
+	}
+	targets, err := bundle.ParseTargets(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("Downloading packages", "count", targets.Count())
+	args = append(append([]string{"-Sw", "--noconfirm", "--cachedir", filepath.Join(pacmanDir, "cache")}, pacman...), pkgs...)
+	if _, err := h.cmdExec.Execute(ctx, "pacman", args...); err != nil {
+		return nil, fmt.Errorf("failed to download packages: %w", err)
+	}
+	return targets, nil
+}
+
+// createRepos moves the downloaded packages and their signatures into one
+// repository per official repo. Repositories without packages get an empty
+// database so pacman -Sy succeeds whatever repositories the target enables.
+func (h *BundleHandler) createRepos(ctx context.Context, b *bundle.Bundle, targets bundle.Targets) error {
+	cacheDir := filepath.Join(b.Root(), bundlePacmanDir, "cache")
+
+	for _, repo := range bundle.Repos() {
+		repoPath := b.RepoPath(repo)
+		if err := h.fs.RemoveAll(repoPath); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", repo, err)
+		}
+		if err := h.fs.MkdirAll(repoPath, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", repo, err)
+		}
+		db := filepath.Join(repoPath, bundle.DatabaseName(repo))
+
+		files := targets[repo]
+		if len(files) == 0 {
+			h.logger.Info("Creating empty repository", "repo", repo)
+			if _, err := h.cmdExec.Execute(ctx, "tar", "-czf", db, "--files-from", "/dev/null"); err != nil {
+				return fmt.Errorf("failed to create empty %s database: %w", repo, err)
+			}
+			if _, err := h.cmdExec.Execute(ctx, "ln", "-sf", bundle.DatabaseName(repo), filepath.Join(repoPath, repo+".db")); err != nil {
+				return fmt.Errorf("failed to link %s database: %w", repo, err)
+			}
+			continue
+		}
+
+		moves := []string{}
+		packagePaths := make([]string, 0, len(files))
+		for _, file := range files {
+			moves = append(moves, filepath.Join(cacheDir, file))
+			if exists, _ := h.fs.Exists(filepath.Join(cacheDir, file+".sig")); exists {
+				moves = append(moves, filepath.Join(cacheDir, file+".sig"))
+			}
+			packagePaths = append(packagePaths, filepath.Join(repoPath, file))
+		}
+		if _, err := h.cmdExec.Execute(ctx, "mv", append(moves, repoPath)...); err != nil {
+			return fmt.Errorf("failed to move %s packages: %w", repo, err)
+		}
+
+		h.logger.Info("Creating repository", "repo", repo, "packages", len(files))
+		if _, err := h.cmdExec.Execute(ctx, "repo-add", append([]string{"-q", db}, packagePaths...)...); err != nil {
+			return fmt.Errorf("repo-add %s failed: %w", repo, err)
+		}
+	}
+	return nil
+}
+
+// uniquePackages drops duplicate packages, keeping the first occurrence
+func uniquePackages(pkgs []string) []string {
+	seen := make(map[string]bool, len(pkgs))
+	unique := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if !seen[pkg] {
+			seen[pkg] = true
+			unique = append(unique, pkg)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBundleHandler_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	root := "/srv/archup-bundle"
+	mockFS.EXPECT().ReadFile(root+"/assets/install/base.packages").Return([]byte("base\nlinux-firmware\n"), nil)
+	mockFS.EXPECT().ReadFile(root+"/assets/install/profiles.packages").Return([]byte("[essentials]\nalways = yes\nbase\n\n[desktop]\nlimine\n"), nil)
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().RemoveAll(gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(true, nil).AnyTimes()
+
+	mockAssets.EXPECT().String().Return("embedded").AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("asset"), nil).AnyTimes()
+	var marker string
+	mockFS.EXPECT().WriteFile(root+"/"+bundle.MarkerFile, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			marker = string(data)
+			return nil
+		})
+	mockFS.EXPECT().WriteFile(root+"/.pacman/pacman.conf", gomock.Any(), gomock.Any()).Return(nil)
+	var exported []string
+	mockFS.EXPECT().WriteFile(gomock.Any(), []byte("asset"), gomock.Any()).DoAndReturn(
+		func(name string, _ []byte, _ os.FileMode) error {
+			exported = append(exported, name)
+			return nil
+		}).AnyTimes()
+
+	var resolved []string
+	var commandsRun []string
+	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, name string, args ...string) ([]byte, error) {
+			commandsRun = append(commandsRun, name+" "+strings.Join(args, " "))
+			if name == "pacman" && args[0] == "-Sp" {
+				resolved = args
+				return []byte("core https://mirror/core/os/x86_64/base-3-2-any.pkg.tar.zst\n" +
+					"extra https://mirror/extra/os/x86_64/limine-10.1.0-1-x86_64.pkg.tar.zst\n"), nil
+			}
+			return []byte{}, nil
+		}).AnyTimes()
+
+	handler := NewBundleHandler(mockFS, mockAssets, mockExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.CreateBundleCommand{
+		OutputDir: root,
+		Version:   "v1.2.3",
+		Packages:  []string{"htop"},
+		Tarball:   true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Success || result.Packages != 2 || result.Tarball != root+".tar" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !reflect.DeepEqual(result.Repos, []string{"core", "extra"}) {
+		t.Errorf("unexpected repos %v", result.Repos)
+	}
+	if len(exported) != len(config.InstallAssets()) || exported[0] != root+"/assets/install/base.packages" {
+		t.Errorf("expected every install asset exported below assets/, got %v", exported)
+	}
+	if marker != bundle.Marker("v1.2.3") {
+		t.Errorf("unexpected marker %q", marker)
+	}
+
+	// Packages are deduplicated; kernels, drivers and every profile are bundled
+	count := map[string]int{}
+	for _, arg := range resolved {
+		count[arg]++
+	}
+	for _, pkg := range []string{"base", "limine", "linux-lts-headers", "nvidia-open", "lib32-mesa", "htop"} {
+		if count[pkg] != 1 {
+			t.Errorf("expected %s once in resolved packages, got %d", pkg, count[pkg])
+		}
+	}
+	if count["linux-cachyos"] != 0 {
+		t.Error("expected linux-cachyos not to be bundled")
+	}
+
+	expected := []string{
+		"mv " + root + "/.pacman/cache/base-3-2-any.pkg.tar.zst " + root + "/.pacman/cache/base-3-2-any.pkg.tar.zst.sig " + root + "/repo/core/os/x86_64",
+		"repo-add -q " + root + "/repo/extra/os/x86_64/extra.db.tar.gz " + root + "/repo/extra/os/x86_64/limine-10.1.0-1-x86_64.pkg.tar.zst",
+		"tar -czf " + root + "/repo/multilib/os/x86_64/multilib.db.tar.gz --files-from /dev/null",
+		"tar -C /srv -cf " + root + ".tar archup-bundle",
+	}
+	all := strings.Join(commandsRun, "\n")
+	for _, command := range expected {
+		if !strings.Contains(all, command) {
+			t.Errorf("expected command %q, ran:\n%s", command, all)
+		}
+	}
+}
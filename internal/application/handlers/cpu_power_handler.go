@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// CPUPowerHandler installs the power daemon and writes the CPU power settings
+// selected on the P-State and power screens
+type CPUPowerHandler struct {
+	fs      ports.FileSystem
+	chrExec ports.ChrootExecutor
+	logger  ports.Logger
+}
+
+// NewCPUPowerHandler creates a new CPU power handler
+func NewCPUPowerHandler(fs ports.FileSystem, chrExec ports.ChrootExecutor, logger ports.Logger) *CPUPowerHandler {
+	return &CPUPowerHandler{
+		fs:      fs,
+		chrExec: chrExec,
+		logger:  logger,
+	}
+}
+
+// Handle removes the other power daemons, installs and enables the selected
+// one and writes the P-State mode and EPP default as configuration files, so
+// they survive without the kernel parameters and can be changed later
+func (h *CPUPowerHandler) Handle(ctx context.Context, cmd commands.ConfigureCPUPowerCommand) (*dto.CPUPowerResult, error) {
+	h.logger.Info("Starting CPU power configuration", "driver", cmd.Driver, "mode", cmd.Mode, "epp", cmd.EPP, "daemon", cmd.Daemon)
+
+	result := &dto.CPUPowerResult{
+		Success:     false,
+		ErrorDetail: "",
+	}
+
+	fail := func(message string, err error) (*dto.CPUPowerResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	power, err := system.NewCPUPower(cmd.Driver, cmd.Mode, cmd.EPP, cmd.Daemon)
+	if err != nil {
+		return fail("Invalid CPU power configuration", err)
+	}
+	daemon := power.Daemon()
+
+	// Power daemons fight over the same sysfs knobs, only one may stay
+	for _, pkg := range daemon.ConflictingPackages() {
+		if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", "-Qq", pkg); err != nil {
+			continue
+		}
+		if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", "-Rns", "--noconfirm", pkg); err != nil {
+			return fail("Failed to remove conflicting power daemon", err)
+		}
+		result.Removed = append(result.Removed, pkg)
+		h.logger.Info("Removed conflicting power daemon", "package", pkg)
+	}
+
+	if pkgs := daemon.Packages(); len(pkgs) > 0 {
+		args := append([]string{"-S", "--noconfirm", "--needed"}, pkgs...)
+		if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", args...); err != nil {
+			return fail("Failed to install power daemon", err)
+		}
+	}
+
+	files := power.ConfigFiles()
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := writeTargetFile(h.fs, filepath.Join(cmd.MountPoint, path), files[path]); err != nil {
+			return fail("Failed to write CPU power configuration", err)
+		}
+		result.ConfigFiles = append(result.ConfigFiles, path)
+	}
+
+	for _, unit := range daemon.MaskedServices() {
+		if err := h.chrExec.ChrootSystemctl(ctx, "", cmd.MountPoint, "mask", unit); err != nil {
+			return fail("Failed to mask "+unit, err)
+		}
+	}
+	for _, unit := range daemon.Services() {
+		if err := h.chrExec.ChrootSystemctl(ctx, "", cmd.MountPoint, "enable", unit); err != nil {
+			return fail("Failed to enable "+unit, err)
+		}
+	}
+
+	result.Daemon = string(daemon)
+	result.Success = true
+	h.logger.Info("CPU power configuration completed successfully", "daemon", daemon, "files", result.ConfigFiles)
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCPUPowerHandler_Handle_TLP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// power-profiles-daemon is installed, tuned is not
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-Qq", "power-profiles-daemon").Return([]byte("power-profiles-daemon\n"), nil)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-Qq", "tuned").Return(nil, errors.New("package 'tuned' was not found"))
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-Rns", "--noconfirm", "power-profiles-daemon").Return([]byte{}, nil)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed", "tlp").Return([]byte{}, nil)
+
+	mockFS.EXPECT().MkdirAll("/mnt/etc/tlp.d", gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile("/mnt"+system.TLPConfigFile, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			if !strings.Contains(string(data), "CPU_ENERGY_PERF_POLICY_ON_AC=balance_performance\n") {
+				t.Errorf("unexpected TLP configuration:\n%s", data)
+			}
+			return nil
+		})
+
+	var units []string
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), "", "/mnt", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ string, args ...string) error {
+			units = append(units, strings.Join(args, " "))
+			return nil
+		}).Times(3)
+
+	handler := NewCPUPowerHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.ConfigureCPUPowerCommand{
+		MountPoint: "/mnt",
+		Driver:     system.PStateDriverIntel,
+		Mode:       "active",
+		EPP:        system.EPPBalancePerformance,
+		Daemon:     system.PowerDaemonTLP,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Daemon != "tlp" || !reflect.DeepEqual(result.Removed, []string{"power-profiles-daemon"}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	expected := []string{"mask systemd-rfkill.service", "mask systemd-rfkill.socket", "enable tlp.service"}
+	if !reflect.DeepEqual(units, expected) {
+		t.Errorf("expected units %v, got %v", expected, units)
+	}
+}
+
+func TestCPUPowerHandler_Handle_DefaultDaemon(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-Qq", gomock.Any()).Return(nil, errors.New("not found")).Times(2)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed", "power-profiles-daemon").Return([]byte{}, nil)
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), "", "/mnt", "enable", "power-profiles-daemon.service").Return(nil)
+
+	handler := NewCPUPowerHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.ConfigureCPUPowerCommand{MountPoint: "/mnt"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Daemon != "power-profiles-daemon" || len(result.ConfigFiles) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
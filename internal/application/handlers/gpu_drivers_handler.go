@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// GPUDriversHandler installs and configures the GPU drivers selected on the GPU screen
+type GPUDriversHandler struct {
+	fs      ports.FileSystem
+	chrExec ports.ChrootExecutor
+	logger  ports.Logger
+}
+
+// NewGPUDriversHandler creates a new GPU drivers handler
+func NewGPUDriversHandler(fs ports.FileSystem, chrExec ports.ChrootExecutor, logger ports.Logger) *GPUDriversHandler {
+	return &GPUDriversHandler{
+		fs:      fs,
+		chrExec: chrExec,
+		logger:  logger,
+	}
+}
+
+// Handle installs the driver packages, writes the GPU environment variables
+// and, for NVIDIA, sets up modesetting, early KMS and the initramfs hook, or
+// runtime power management when the NVIDIA dGPU is used for render offload
+func (h *GPUDriversHandler) Handle(ctx context.Context, cmd commands.InstallGPUDriversCommand) (*dto.GPUDriversResult, error) {
+	h.logger.Info("Starting GPU driver installation", "vendor", cmd.GPUVendor, "profile", cmd.Profile, "drivers", cmd.Drivers)
+
+	result := &dto.GPUDriversResult{
+		Success:     false,
+		ErrorDetail: "",
+	}
+
+	fail := func(message string, err error) (*dto.GPUDriversResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	vendor := system.GPUVendor(cmd.GPUVendor)
+	gpu := system.NewGPU(vendor, "", cmd.Drivers, recommendedEnvVars(vendor, cmd.Drivers))
+	if len(gpu.Drivers()) == 0 {
+		h.logger.Info("No GPU drivers selected, skipping")
+		result.Success = true
+		return result, nil
+	}
+
+	kernels, err := packages.NewKernelSet(cmd.KernelVariant, cmd.ExtraKernels...)
+	if err != nil {
+		return fail("Invalid kernel variant", err)
+	}
+
+	pkgs := gpu.Drivers()
+	if !kernels.OnlyStable() {
+		pkgs = system.NVIDIADKMSVariants(pkgs)
+	}
+	if cmd.EnableMultilib {
+		pkgs = append(pkgs, system.Lib32Drivers(pkgs)...)
+	}
+	// DKMS drivers (e.g. nvidia-open-dkms) are built against every installed kernel
+	pkgs = kernels.WithHeaders(pkgs)
+
+	args := append([]string{"-S", "--noconfirm", "--needed"}, pkgs...)
+	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", args...); err != nil {
+		return fail("Failed to install GPU drivers", err)
+	}
+	result.Packages = pkgs
+	h.logger.Info("GPU drivers installed", "packages", pkgs)
+
+	if env := gpu.EnvironmentFile(); env != "" {
+		envPath := filepath.Join(cmd.MountPoint, system.GPUEnvironmentFile)
+		if err := writeTargetFile(h.fs, envPath, env); err != nil {
+			return fail("Failed to write GPU environment", err)
+		}
+		result.EnvironmentFile = system.GPUEnvironmentFile
+	}
+
+	switch {
+	case len(system.NVIDIADriverPackages(pkgs)) == 0:
+		// No NVIDIA kernel module, e.g. nouveau for Tesla GPUs
+	case vendor == system.GPUVendorNVIDIA:
+		if err := h.configureNVIDIA(ctx, cmd.MountPoint, pkgs, kernels.PackageNames()); err != nil {
+			return fail("Failed to configure NVIDIA driver", err)
+		}
+		result.EarlyKMS = append([]string{}, system.NVIDIAKMSModules...)
+	case cmd.Profile == system.HybridProfileOffload:
+		if err := h.configureNVIDIAOffload(cmd.MountPoint); err != nil {
+			return fail("Failed to configure NVIDIA render offload", err)
+		}
+		result.RuntimePM = true
+	}
+
+	result.Success = true
+	h.logger.Info("GPU driver installation completed successfully")
+	return result, nil
+}
+
+// configureNVIDIA enables DRM modesetting, loads the NVIDIA modules from the
+// initramfs and installs the pacman hook keeping the initramfs in sync with
+// driver upgrades. The kms hook is dropped so nouveau stays out of the initramfs.
+func (h *GPUDriversHandler) configureNVIDIA(ctx context.Context, mountPoint string, drivers, kernelNames []string) error {
+	if err := writeTargetFile(h.fs, filepath.Join(mountPoint, system.NVIDIAModprobeFile), system.NVIDIAModprobeConf); err != nil {
+		return err
+	}
+
+	hook := system.NVIDIAPacmanHook(system.NVIDIADriverPackages(drivers), kernelNames)
+	if err := writeTargetFile(h.fs, filepath.Join(mountPoint, system.NVIDIAPacmanHookFile), hook); err != nil {
+		return err
+	}
+
+	confPath := filepath.Join(mountPoint, "etc", "mkinitcpio.conf")
+	content, err := h.fs.ReadFile(confPath)
+	if err != nil {
+		return fmt.Errorf("failed to read mkinitcpio.conf: %w", err)
+	}
+	updated := replaceModulesLine(string(content), strings.Join(system.NVIDIAKMSModules, " "))
+	updated = removeHook(updated, "kms")
+	if err := h.fs.WriteFile(confPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write mkinitcpio.conf: %w", err)
+	}
+
+	h.logger.Info("Regenerating initramfs for NVIDIA early KMS", "modules", system.NVIDIAKMSModules)
+	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "mkinitcpio", "-P"); err != nil {
+		return fmt.Errorf("failed to regenerate initramfs: %w", err)
+	}
+	return nil
+}
+
+// configureNVIDIAOffload enables DRM modesetting and runtime power management
+// of the NVIDIA dGPU for PRIME render offload. The iGPU drives the display, so
+// the NVIDIA modules stay out of the initramfs and the dGPU can power down.
+func (h *GPUDriversHandler) configureNVIDIAOffload(mountPoint string) error {
+	modprobe := system.NVIDIAModprobeConf + system.NVIDIAPowerModprobeConf
+	if err := writeTargetFile(h.fs, filepath.Join(mountPoint, system.NVIDIAModprobeFile), modprobe); err != nil {
+		return err
+	}
+	if err := writeTargetFile(h.fs, filepath.Join(mountPoint, system.NVIDIAPowerUdevFile), system.NVIDIAPowerUdevRules); err != nil {
+		return err
+	}
+	h.logger.Info("Configured NVIDIA render offload with runtime power management")
+	return nil
+}
+
+// writeTargetFile writes content to path, creating its directory
+func writeTargetFile(fs ports.FileSystem, path, content string) error {
+	if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := fs.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// removeHook removes a hook from the HOOKS=(...) line in mkinitcpio.conf
+func removeHook(content, hook string) string {
+	re := regexp.MustCompile(`(?m)^HOOKS=\((.*)\)$`)
+	return re.ReplaceAllStringFunc(content, func(line string) string {
+		inner := re.FindStringSubmatch(line)[1]
+		kept := []string{}
+		for _, field := range strings.Fields(inner) {
+			if field != hook {
+				kept = append(kept, field)
+			}
+		}
+		return "HOOKS=(" + strings.Join(kept, " ") + ")"
+	})
+}
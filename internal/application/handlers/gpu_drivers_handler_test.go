@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGPUDriversHandler_Handle_AMD(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed",
+		"mesa", "vulkan-radeon", "lib32-mesa", "lib32-vulkan-radeon").Return([]byte{}, nil)
+	mockFS.EXPECT().MkdirAll("/mnt/etc/environment.d", gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile("/mnt"+system.GPUEnvironmentFile, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			if !strings.Contains(string(data), "LIBVA_DRIVER_NAME=radeonsi\n") {
+				t.Errorf("unexpected environment file:\n%s", data)
+			}
+			return nil
+		})
+
+	handler := NewGPUDriversHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.InstallGPUDriversCommand{
+		MountPoint:     "/mnt",
+		GPUVendor:      "amd",
+		Drivers:        []string{"mesa", "vulkan-radeon"},
+		EnableMultilib: true,
+		KernelVariant:  packages.KernelStable,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.EnvironmentFile != system.GPUEnvironmentFile || len(result.EarlyKMS) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+func TestGPUDriversHandler_Handle_NVIDIA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	var installed []string
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ string, args ...string) ([]byte, error) {
+			installed = args[3:]
+			return []byte{}, nil
+		})
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "mkinitcpio", "-P").Return([]byte{}, nil)
+
+	files := map[string]string{}
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(name string, data []byte, _ os.FileMode) error {
+			files[name] = string(data)
+			return nil
+		}).AnyTimes()
+	mockFS.EXPECT().ReadFile("/mnt/etc/mkinitcpio.conf").Return(
+		[]byte("MODULES=()\nHOOKS=(base udev autodetect microcode modconf kms keyboard block plymouth filesystems fsck)\n"), nil)
+
+	handler := NewGPUDriversHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.InstallGPUDriversCommand{
+		MountPoint:     "/mnt",
+		GPUVendor:      "nvidia",
+		Drivers:        []string{"nvidia-open", "nvidia-utils"},
+		EnableMultilib: true,
+		KernelVariant:  packages.KernelZen,
+		ExtraKernels:   []packages.KernelVariant{packages.KernelLTS},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// nvidia-open is only built for the stock kernel, the DKMS variant builds for zen and lts
+	expected := []string{"nvidia-open-dkms", "nvidia-utils", "lib32-nvidia-utils", "linux-zen-headers", "linux-lts-headers"}
+	if !reflect.DeepEqual(installed, expected) {
+		t.Errorf("expected packages %v, got %v", expected, installed)
+	}
+	if !reflect.DeepEqual(result.EarlyKMS, system.NVIDIAKMSModules) {
+		t.Errorf("unexpected early KMS modules %v", result.EarlyKMS)
+	}
+
+	mkinitcpio := files["/mnt/etc/mkinitcpio.conf"]
+	if !strings.Contains(mkinitcpio, "MODULES=(nvidia nvidia_modeset nvidia_uvm nvidia_drm)") ||
+		!strings.Contains(mkinitcpio, "HOOKS=(base udev autodetect microcode modconf keyboard block plymouth filesystems fsck)") {
+		t.Errorf("unexpected mkinitcpio.conf:\n%s", mkinitcpio)
+	}
+	if files["/mnt"+system.NVIDIAModprobeFile] != system.NVIDIAModprobeConf {
+		t.Errorf("expected modesetting enabled, got %q", files["/mnt"+system.NVIDIAModprobeFile])
+	}
+	hook := files["/mnt"+system.NVIDIAPacmanHookFile]
+	for _, target := range []string{"Target=nvidia-open-dkms\n", "Target=linux-zen\n", "Target=linux-lts\n"} {
+		if !strings.Contains(hook, target) {
+			t.Errorf("expected %q in pacman hook:\n%s", target, hook)
+		}
+	}
+	if strings.Contains(hook, "Target=nvidia-utils") {
+		t.Errorf("expected no hook on nvidia-utils:\n%s", hook)
+	}
+	if !strings.Contains(files["/mnt"+system.GPUEnvironmentFile], "GBM_BACKEND=nvidia-drm\n") {
+		t.Errorf("unexpected environment file:\n%s", files["/mnt"+system.GPUEnvironmentFile])
+	}
+}
+
+func TestGPUDriversHandler_Handle_NoDrivers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	handler := NewGPUDriversHandler(mocks.NewMockFileSystem(ctrl), mocks.NewMockChrootExecutor(ctrl), mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.InstallGPUDriversCommand{MountPoint: "/mnt", GPUVendor: "unknown"})
+	if err != nil || !result.Success {
+		t.Fatalf("expected nothing to do, got %+v, %v", result, err)
+	}
+}
+
+func TestGPUDriversHandler_Handle_PRIMEOffload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", gomock.Any()).Return([]byte{}, nil)
+
+	files := map[string]string{}
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(name string, data []byte, _ os.FileMode) error {
+			files[name] = string(data)
+			return nil
+		}).AnyTimes()
+
+	handler := NewGPUDriversHandler(mockFS, mockChrExec, mockLogger)
+
+	// The iGPU drives the display: no NVIDIA early KMS, no initramfs rebuild
+	result, err := handler.Handle(context.Background(), commands.InstallGPUDriversCommand{
+		MountPoint:    "/mnt",
+		GPUVendor:     "intel",
+		Profile:       system.HybridProfileOffload,
+		Drivers:       []string{"mesa", "vulkan-intel", "nvidia-open", "nvidia-utils", "nvidia-prime"},
+		KernelVariant: packages.KernelStable,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.RuntimePM || len(result.EarlyKMS) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(files["/mnt"+system.NVIDIAModprobeFile], "NVreg_DynamicPowerManagement=0x02") ||
+		!strings.Contains(files["/mnt"+system.NVIDIAModprobeFile], "modeset=1") {
+		t.Errorf("unexpected modprobe config:\n%s", files["/mnt"+system.NVIDIAModprobeFile])
+	}
+	if files["/mnt"+system.NVIDIAPowerUdevFile] != system.NVIDIAPowerUdevRules {
+		t.Error("expected NVIDIA runtime PM udev rules")
+	}
+	if !strings.Contains(files["/mnt"+system.GPUEnvironmentFile], "LIBVA_DRIVER_NAME=iHD\n") {
+		t.Errorf("expected the iGPU environment, got:\n%s", files["/mnt"+system.GPUEnvironmentFile])
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/bnema/archup/internal/domain/ports"
@@ -10,58 +12,95 @@ import (
 
 // GPUHandler handles GPU detection.
 type GPUHandler struct {
+	fs      ports.FileSystem
 	cmdExec ports.CommandExecutor
 	logger  ports.Logger
 }
 
 // NewGPUHandler creates a new GPU handler.
-func NewGPUHandler(cmdExec ports.CommandExecutor, logger ports.Logger) *GPUHandler {
+func NewGPUHandler(fs ports.FileSystem, cmdExec ports.CommandExecutor, logger ports.Logger) *GPUHandler {
 	return &GPUHandler{
+		fs:      fs,
 		cmdExec: cmdExec,
 		logger:  logger,
 	}
 }
 
-// Detect detects the system GPU and returns recommended configuration.
-func (h *GPUHandler) Detect(ctx context.Context) (*system.GPU, error) {
-	output, err := h.cmdExec.Execute(ctx, "lspci")
+// Detect detects every GPU of the system with its recommended configuration.
+// The PCI slot and boot VGA flag tell the iGPU and dGPU of hybrid systems apart.
+func (h *GPUHandler) Detect(ctx context.Context) (*system.GPUSetup, error) {
+	output, err := h.cmdExec.Execute(ctx, "lspci", "-D", "-nn")
 	if err != nil {
 		h.logger.Warn("Failed to run lspci for GPU detection", "error", err)
-		return system.NewGPU(system.GPUVendorUnknown, "", nil, nil), err
+		return system.NewGPUSetup(), err
 	}
 
-	line := firstGPULine(string(output))
-	if line == "" {
+	lines := gpuLines(string(output))
+	if len(lines) == 0 {
 		h.logger.Warn("No GPU detected in lspci output")
-		return system.NewGPU(system.GPUVendorUnknown, "", nil, nil), nil
+		return system.NewGPUSetup(), nil
 	}
 
-	vendor := detectGPUVendor(line)
-	model := extractGPUModel(line)
-	drivers := recommendedDrivers(vendor)
+	gpus := make([]*system.GPU, 0, len(lines))
+	for _, line := range lines {
+		slot := strings.Fields(line)[0]
+		vendor := detectGPUVendor(line)
+		model := extractGPUModel(line)
+		bootVGA := h.isBootVGA(slot)
+		drivers := recommendedDrivers(vendor)
+		reason := ""
+
+		// The NVIDIA driver branch depends on the GPU generation
+		if vendorID, deviceID := extractPCIID(line); vendor == system.GPUVendorNVIDIA && vendorID == system.NVIDIAPCIVendorID {
+			generation := system.NVIDIAGenerationOf(deviceID)
+			driver := system.SelectNVIDIADriver(generation)
+			drivers, reason = driver.Packages, driver.Reason
+			h.logger.Info("Selected NVIDIA driver", "device", deviceID, "generation", generation, "packages", drivers)
+		}
 
-	h.logger.Info("Detected GPU", "vendor", vendor, "model", model)
-	return system.NewGPU(vendor, model, drivers, nil), nil
+		h.logger.Info("Detected GPU", "vendor", vendor, "model", model, "slot", slot, "bootVGA", bootVGA)
+		gpu := system.NewGPU(vendor, model, drivers, recommendedEnvVars(vendor, drivers))
+		gpus = append(gpus, gpu.WithPCI(slot, bootVGA).WithDriverReason(reason))
+	}
+
+	setup := system.NewGPUSetup(gpus...)
+	if setup.IsHybrid() {
+		h.logger.Info("Detected hybrid graphics",
+			"igpu", setup.Integrated().Vendor(),
+			"dgpu", setup.Discrete().Vendor())
+	}
+	return setup, nil
 }
 
-func firstGPULine(output string) string {
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
+// isBootVGA reads the boot_vga flag of a PCI device, false when unavailable
+func (h *GPUHandler) isBootVGA(slot string) bool {
+	content, err := h.fs.ReadFile(filepath.Join("/sys/bus/pci/devices", slot, "boot_vga"))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(content)) == "1"
+}
+
+// gpuLines returns the lspci lines of the display controllers
+func gpuLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
 		if strings.Contains(line, "VGA compatible controller") ||
 			strings.Contains(line, "3D controller") ||
 			strings.Contains(line, "Display controller") {
-			return line
+			lines = append(lines, line)
 		}
 	}
-	return ""
+	return lines
 }
 
 func detectGPUVendor(line string) system.GPUVendor {
-	lower := strings.ToLower(line)
+	// Match whole vendor names: "compatible" and "Corporation" contain "ati"
+	lower := strings.ToLower(extractGPUModel(line))
 	switch {
 	case strings.Contains(lower, "nvidia"):
 		return system.GPUVendorNVIDIA
-	case strings.Contains(lower, "advanced micro devices") || strings.Contains(lower, "amd") || strings.Contains(lower, "ati"):
+	case strings.Contains(lower, "advanced micro devices") || strings.Contains(lower, "amd") || strings.Contains(lower, "ati technologies"):
 		return system.GPUVendorAMD
 	case strings.Contains(lower, "intel"):
 		return system.GPUVendorIntel
@@ -70,12 +109,25 @@ func detectGPUVendor(line string) system.GPUVendor {
 	}
 }
 
+// pciIDPattern matches the [vendor:device] ID and revision lspci -nn appends to the model
+var pciIDPattern = regexp.MustCompile(`\s*\[([0-9a-f]{4}):([0-9a-f]{4})\](\s*\(rev [0-9a-f]+\))?\s*$`)
+
 func extractGPUModel(line string) string {
 	parts := strings.SplitN(line, ": ", 2)
 	if len(parts) < 2 {
 		return "Unknown"
 	}
-	return strings.TrimSpace(parts[1])
+	return strings.TrimSpace(pciIDPattern.ReplaceAllString(parts[1], ""))
+}
+
+// extractPCIID returns the PCI vendor and device IDs of an lspci -nn line,
+// empty when the line has none
+func extractPCIID(line string) (vendorID, deviceID string) {
+	match := pciIDPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
 }
 
 func recommendedDrivers(vendor system.GPUVendor) []string {
@@ -90,3 +142,30 @@ func recommendedDrivers(vendor system.GPUVendor) []string {
 		return []string{}
 	}
 }
+
+// recommendedEnvVars returns the VA-API and GBM environment of a vendor for
+// Wayland compositors and video decoding. The NVIDIA legacy branches predate
+// GBM and VA-API support, nouveau needs nothing.
+func recommendedEnvVars(vendor system.GPUVendor, drivers []string) map[string]string {
+	switch vendor {
+	case system.GPUVendorAMD:
+		return map[string]string{"LIBVA_DRIVER_NAME": "radeonsi"}
+	case system.GPUVendorIntel:
+		return map[string]string{"LIBVA_DRIVER_NAME": "iHD"}
+	case system.GPUVendorNVIDIA:
+		switch {
+		case len(system.NVIDIADriverPackages(drivers)) == 0:
+			return map[string]string{}
+		case system.IsLegacyNVIDIADriver(drivers):
+			return map[string]string{"__GLX_VENDOR_LIBRARY_NAME": "nvidia"}
+		}
+		return map[string]string{
+			"LIBVA_DRIVER_NAME":         "nvidia",
+			"NVD_BACKEND":               "direct",
+			"GBM_BACKEND":               "nvidia-drm",
+			"__GLX_VENDOR_LIBRARY_NAME": "nvidia",
+		}
+	default:
+		return map[string]string{}
+	}
+}
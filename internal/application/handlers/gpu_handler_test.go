@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGPUHandler_Detect_Hybrid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockExec.EXPECT().Execute(gomock.Any(), "lspci", "-D", "-nn").Return([]byte(
+		"0000:00:00.0 Host bridge [0600]: Intel Corporation Raptor Lake-P 6p+8e cores Host Bridge/DRAM Controller [8086:a706]\n"+
+			"0000:00:02.0 VGA compatible controller [0300]: Intel Corporation Raptor Lake-P [Iris Xe Graphics] [8086:a7a0] (rev 04)\n"+
+			"0000:01:00.0 3D controller [0302]: NVIDIA Corporation AD107M [GeForce RTX 4060 Max-Q / Mobile] [10de:28a0] (rev a1)\n"), nil)
+	mockFS.EXPECT().ReadFile("/sys/bus/pci/devices/0000:00:02.0/boot_vga").Return([]byte("1\n"), nil)
+	mockFS.EXPECT().ReadFile("/sys/bus/pci/devices/0000:01:00.0/boot_vga").Return(nil, errNotFound("boot_vga"))
+
+	handler := NewGPUHandler(mockFS, mockExec, mockLogger)
+
+	setup, err := handler.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(setup.GPUs()) != 2 || !setup.IsHybrid() {
+		t.Fatalf("expected hybrid setup of two GPUs, got %d GPUs", len(setup.GPUs()))
+	}
+	if igpu := setup.Integrated(); igpu.Vendor() != system.GPUVendorIntel || igpu.Slot() != "0000:00:02.0" || !igpu.BootVGA() {
+		t.Errorf("unexpected iGPU %s %s", igpu.Vendor(), igpu.Slot())
+	}
+	if dgpu := setup.Discrete(); dgpu.Vendor() != system.GPUVendorNVIDIA || dgpu.Model() != "NVIDIA Corporation AD107M [GeForce RTX 4060 Max-Q / Mobile]" {
+		t.Errorf("unexpected dGPU %s %s", dgpu.Vendor(), dgpu.Model())
+	}
+	if dgpu := setup.Discrete(); dgpu.Drivers()[0] != "nvidia-open" || !strings.Contains(dgpu.DriverReason(), "Ada Lovelace") {
+		t.Errorf("unexpected dGPU drivers %v: %s", dgpu.Drivers(), dgpu.DriverReason())
+	}
+}
+
+func TestGPUHandler_Detect_NVIDIAGeneration(t *testing.T) {
+	tests := []struct {
+		line    string
+		driver  string
+		envVars int
+	}{
+		{"0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GA104 [GeForce RTX 3070] [10de:2484] (rev a1)", "nvidia-open", 4},
+		{"0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GP104 [GeForce GTX 1080] [10de:1b80] (rev a1)", "nvidia", 4},
+		{"0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GK104 [GeForce GTX 770] [10de:1184] (rev a1)", "nvidia-470xx-dkms", 1},
+		{"0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation GF108 [GeForce GT 430] [10de:0de1] (rev a1)", "nvidia-390xx-dkms", 1},
+		{"0000:01:00.0 VGA compatible controller [0300]: NVIDIA Corporation G92 [GeForce 9800 GT] [10de:0614] (rev a2)", "mesa", 0},
+	}
+
+	for _, tt := range tests {
+		ctrl := gomock.NewController(t)
+		mockFS := mocks.NewMockFileSystem(ctrl)
+		mockExec := mocks.NewMockCommandExecutor(ctrl)
+		mockLogger := mocks.NewMockLogger(ctrl)
+
+		mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+		mockExec.EXPECT().Execute(gomock.Any(), "lspci", "-D", "-nn").Return([]byte(tt.line+"\n"), nil)
+		mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("1\n"), nil)
+
+		setup, err := NewGPUHandler(mockFS, mockExec, mockLogger).Detect(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		gpu := setup.Primary()
+		if gpu.Drivers()[0] != tt.driver || gpu.DriverReason() == "" {
+			t.Errorf("%s: expected %s, got %v (%s)", tt.line, tt.driver, gpu.Drivers(), gpu.DriverReason())
+		}
+		if len(gpu.EnvVars()) != tt.envVars {
+			t.Errorf("%s: expected %d environment variables, got %v", tt.line, tt.envVars, gpu.EnvVars())
+		}
+		if strings.Contains(gpu.Model(), "10de") {
+			t.Errorf("expected PCI ID stripped from model, got %q", gpu.Model())
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestDetectGPUVendor(t *testing.T) {
+	tests := map[string]system.GPUVendor{
+		"00:02.0 VGA compatible controller: Intel Corporation Alder Lake-P GT2":             system.GPUVendorIntel,
+		"03:00.0 VGA compatible controller: Advanced Micro Devices, Inc. [AMD/ATI] Navi 31": system.GPUVendorAMD,
+		"01:00.0 VGA compatible controller: NVIDIA Corporation GA104 [GeForce RTX 3070]":    system.GPUVendorNVIDIA,
+		"00:0f.0 VGA compatible controller: VMware SVGA II Adapter":                         system.GPUVendorUnknown,
+	}
+	for line, expected := range tests {
+		if got := detectGPUVendor(line); got != expected {
+			t.Errorf("detectGPUVendor(%q) = %s, expected %s", line, got, expected)
+		}
+	}
+}
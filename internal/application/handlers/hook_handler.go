@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// chrootHookDir is where chroot hooks are copied to inside the installed system
+const chrootHookDir = "/root/archup-hooks"
+
+// HookHandler runs user hook scripts at phase boundaries, on the host through
+// the script executor or inside the installed system through arch-chroot
+type HookHandler struct {
+	fs         ports.FileSystem
+	scriptExec ports.ScriptExecutor
+	chrExec    ports.ChrootExecutor
+	logger     ports.Logger
+}
+
+// NewHookHandler creates a new hook handler
+func NewHookHandler(fs ports.FileSystem, scriptExec ports.ScriptExecutor, chrExec ports.ChrootExecutor, logger ports.Logger) *HookHandler {
+	return &HookHandler{
+		fs:         fs,
+		scriptExec: scriptExec,
+		chrExec:    chrExec,
+		logger:     logger,
+	}
+}
+
+// Discover returns the hooks of a hook directory: one <point>/ subdirectory
+// per hook point, scripts run in file name order, see installation.NewHookFromFile
+func (h *HookHandler) Discover(dir string) (installation.Hooks, error) {
+	var hooks installation.Hooks
+	for _, point := range installation.HookPoints() {
+		pointDir := filepath.Join(dir, string(point))
+		entries, err := h.fs.ReadDir(pointDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hook directory %s: %w", pointDir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			hook, err := installation.NewHookFromFile(point, filepath.Join(pointDir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			hooks = append(hooks, hook)
+		}
+	}
+
+	h.logger.Info("Discovered hooks", "dir", dir, "count", len(hooks))
+	return hooks, nil
+}
+
+// Handle runs the hooks of cmd.Point in order
+func (h *HookHandler) Handle(ctx context.Context, cmd commands.RunHooksCommand) (*dto.HooksResult, error) {
+	result := &dto.HooksResult{
+		Point:       string(cmd.Point),
+		Success:     false,
+		HooksRun:    []string{},
+		HooksFailed: []string{},
+	}
+
+	for _, hook := range cmd.Hooks.At(cmd.Point) {
+		h.logger.Info("Running hook", "point", cmd.Point, "hook", hook.Path(), "target", hook.Target().String())
+
+		if err := h.runHook(ctx, hook, cmd); err != nil {
+			if cmd.Policy == installation.HookFailWarn {
+				h.logger.Warn("Hook failed, continuing", "hook", hook.Path(), "error", err)
+				result.HooksFailed = append(result.HooksFailed, hook.Name())
+				continue
+			}
+			h.logger.Error("Hook failed", "hook", hook.Path(), "error", err)
+			result.ErrorDetail = fmt.Sprintf("%s hook %s failed: %v", cmd.Point, hook.Name(), err)
+			return result, fmt.Errorf("%s hook %s failed: %w", cmd.Point, hook.Name(), err)
+		}
+		result.HooksRun = append(result.HooksRun, hook.Name())
+	}
+
+	result.Success = true
+	return result, nil
+}
+
+// runHook runs a single hook with its output streamed to the log
+func (h *HookHandler) runHook(ctx context.Context, hook *installation.Hook, cmd commands.RunHooksCommand) error {
+	output := &hookOutputWriter{logger: h.logger, hook: hook.Name()}
+	defer output.Flush()
+
+	env := make(map[string]string, len(cmd.Env)+1)
+	for key, value := range cmd.Env {
+		env[key] = value
+	}
+	env["ARCHUP_HOOK"] = string(cmd.Point)
+
+	if hook.Target() == installation.HookTargetHost {
+		return h.scriptExec.ExecuteScriptWithOutput(ctx, hook.Path(), env, output)
+	}
+
+	// Copy the script into the installed system, arch-chroot cannot reach host paths
+	script, err := h.fs.ReadFile(hook.Path())
+	if err != nil {
+		return fmt.Errorf("failed to read hook script: %w", err)
+	}
+	hostDir := filepath.Join(cmd.MountPoint, chrootHookDir)
+	if err := h.fs.MkdirAll(hostDir, 0700); err != nil {
+		return fmt.Errorf("failed to create hook directory: %w", err)
+	}
+	defer func() {
+		if err := h.fs.RemoveAll(hostDir); err != nil {
+			h.logger.Warn("Failed to remove hook directory", "dir", hostDir, "error", err)
+		}
+	}()
+	if err := h.fs.WriteFile(filepath.Join(hostDir, hook.Name()), script, 0700); err != nil {
+		return fmt.Errorf("failed to copy hook script: %w", err)
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	args := make([]string, 0, len(keys)+2)
+	for _, key := range keys {
+		args = append(args, key+"="+env[key])
+	}
+	args = append(args, "bash", filepath.Join(chrootHookDir, hook.Name()))
+
+	return h.chrExec.ExecuteInChrootWithOutput(ctx, cmd.MountPoint, output, "env", args...)
+}
+
+// hookOutputWriter logs hook output line by line
+type hookOutputWriter struct {
+	logger ports.Logger
+	hook   string
+	buf    []byte
+}
+
+// Write logs every complete line and buffers the rest
+func (w *hookOutputWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logger.Info("Hook output", "hook", w.hook, "line", string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs a trailing line without newline
+func (w *hookOutputWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.logger.Info("Hook output", "hook", w.hook, "line", string(w.buf))
+		w.buf = nil
+	}
+}
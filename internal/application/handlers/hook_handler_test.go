@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHookHandler_Discover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir := t.TempDir()
+	for _, name := range []string{
+		"post-base/20-agent.chroot.sh",
+		"post-base/10-ca.sh",
+		"post-base/.hidden",
+		"pre-partition/10-check.sh",
+		"unknown-point/10-ignored.sh",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create hook directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("#!/bin/bash\n"), 0755); err != nil {
+			t.Fatalf("failed to create hook: %v", err)
+		}
+	}
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).DoAndReturn(os.ReadDir).AnyTimes()
+
+	handler := NewHookHandler(mockFS, mocks.NewMockScriptExecutor(ctrl), mocks.NewMockChrootExecutor(ctrl), mockLogger)
+
+	hooks, err := handler.Discover(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var got []string
+	for _, hook := range hooks {
+		got = append(got, fmt.Sprintf("%s:%s:%s", hook.Point(), hook.Target(), hook.Name()))
+	}
+	expected := []string{
+		"pre-partition:host:10-check.sh",
+		"post-base:host:10-ca.sh",
+		"post-base:chroot:20-agent.chroot.sh",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected hooks %v, got %v", expected, got)
+	}
+}
+
+func TestHookHandler_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	var logged []string
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).DoAndReturn(func(msg string, args ...any) {
+		if msg == "Hook output" {
+			logged = append(logged, fmt.Sprint(args[1], ": ", args[3]))
+		}
+	}).AnyTimes()
+
+	hooks, err := installation.ParseHooks("post-base:host:/root/notify.sh post-base:chroot:/root/ca.sh post-install:host:/root/other.sh")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Host hook output is streamed to the log
+	mockScriptExec.EXPECT().ExecuteScriptWithOutput(gomock.Any(), "/root/notify.sh", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, path string, env map[string]string, output io.Writer) error {
+			if env["ARCHUP_HOOK"] != "post-base" || env["ARCHUP_ROOT_PARTITION"] != "/dev/sda2" {
+				t.Errorf("unexpected hook environment %v", env)
+			}
+			_, err := io.WriteString(output, "notified\nno newline")
+			return err
+		})
+
+	// Chroot hooks are copied into the target and removed afterwards
+	gomock.InOrder(
+		mockFS.EXPECT().ReadFile("/root/ca.sh").Return([]byte("trust anchor ca.pem\n"), nil),
+		mockFS.EXPECT().MkdirAll("/mnt/root/archup-hooks", gomock.Any()).Return(nil),
+		mockFS.EXPECT().WriteFile("/mnt/root/archup-hooks/ca.sh", []byte("trust anchor ca.pem\n"), gomock.Any()).Return(nil),
+		mockChrExec.EXPECT().ExecuteInChrootWithOutput(gomock.Any(), "/mnt", gomock.Any(), "env",
+			"ARCHUP_HOOK=post-base", "ARCHUP_ROOT_PARTITION=/dev/sda2", "bash", "/root/archup-hooks/ca.sh").DoAndReturn(
+			func(ctx context.Context, chrootPath string, output io.Writer, command string, args ...string) error {
+				_, err := io.WriteString(output, "anchor added\n")
+				return err
+			}),
+		mockFS.EXPECT().RemoveAll("/mnt/root/archup-hooks").Return(nil),
+	)
+
+	handler := NewHookHandler(mockFS, mockScriptExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RunHooksCommand{
+		Point:      installation.HookPostBase,
+		Hooks:      hooks,
+		MountPoint: "/mnt",
+		Env:        map[string]string{"ARCHUP_ROOT_PARTITION": "/dev/sda2"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Success || !reflect.DeepEqual(result.HooksRun, []string{"notify.sh", "ca.sh"}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	expected := []string{"notify.sh: notified", "notify.sh: no newline", "ca.sh: anchor added"}
+	if !reflect.DeepEqual(logged, expected) {
+		t.Errorf("expected logged output %v, got %v", expected, logged)
+	}
+}
+
+func TestHookHandler_Handle_FailurePolicy(t *testing.T) {
+	hooks, err := installation.ParseHooks("post-install:host:/root/fails.sh post-install:host:/root/after.sh")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		policy    installation.HookFailurePolicy
+		shouldErr bool
+		run       []string
+	}{
+		{"abort stops at the failing hook", installation.HookFailAbort, true, []string{}},
+		{"warn runs the remaining hooks", installation.HookFailWarn, false, []string{"after.sh"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+			mockLogger := mocks.NewMockLogger(ctrl)
+			mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+			mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+			mockScriptExec.EXPECT().ExecuteScriptWithOutput(gomock.Any(), "/root/fails.sh", gomock.Any(), gomock.Any()).
+				Return(errors.New("exit status 1"))
+			if !tt.shouldErr {
+				mockScriptExec.EXPECT().ExecuteScriptWithOutput(gomock.Any(), "/root/after.sh", gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			handler := NewHookHandler(mocks.NewMockFileSystem(ctrl), mockScriptExec, mocks.NewMockChrootExecutor(ctrl), mockLogger)
+
+			result, err := handler.Handle(context.Background(), commands.RunHooksCommand{
+				Point:  installation.HookPostInstall,
+				Hooks:  hooks,
+				Policy: tt.policy,
+			})
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+			if result.Success == tt.shouldErr {
+				t.Errorf("expected success=%v, got %+v", !tt.shouldErr, result)
+			}
+			if !reflect.DeepEqual(result.HooksRun, tt.run) {
+				t.Errorf("expected hooks run %v, got %v", tt.run, result.HooksRun)
+			}
+			if !tt.shouldErr && !reflect.DeepEqual(result.HooksFailed, []string{"fails.sh"}) {
+				t.Errorf("expected fails.sh to be reported, got %v", result.HooksFailed)
+			}
+		})
+	}
+}
@@ -42,8 +42,8 @@ func (h *InstallBaseHandler) Handle(ctx context.Context, cmd commands.InstallBas
 		ErrorDetail:       "",
 	}
 
-	// Validate kernel variant
-	kernel, err := packages.NewKernel(cmd.KernelVariant)
+	// Validate kernel variants
+	kernels, err := packages.NewKernelSet(cmd.KernelVariant, cmd.ExtraKernels...)
 	if err != nil {
 		h.logger.Error("Invalid kernel variant", "error", err)
 		result.ErrorDetail = fmt.Sprintf("Invalid kernel variant: %v", err)
@@ -58,9 +58,9 @@ func (h *InstallBaseHandler) Handle(ctx context.Context, cmd commands.InstallBas
 		return result, err
 	}
 
-	// Add kernel (not in base.packages, selected dynamically)
-	basePackages = append(basePackages, kernel.PackageName())
-	h.logger.Info("Adding kernel", "kernel", kernel.PackageName())
+	// Add kernels (not in base.packages, selected dynamically)
+	basePackages = append(basePackages, kernels.PackageNames()...)
+	h.logger.Info("Adding kernels", "kernels", kernels.PackageNames())
 
 	// Add microcode if requested
 	if cmd.IncludeMicrocode {
@@ -80,17 +80,47 @@ func (h *InstallBaseHandler) Handle(ctx context.Context, cmd commands.InstallBas
 		h.logger.Info("Adding additional packages", "count", len(cmd.Packages))
 	}
 
+	// DKMS modules are built against every installed kernel, which needs its headers
+	if packages.RequiresDKMS(basePackages) {
+		basePackages = kernels.WithHeaders(basePackages)
+		h.logger.Info("Adding kernel headers for DKMS modules", "headers", kernels.HeadersPackages())
+	}
+
+	// A custom pacman.conf (offline bundle) only has the official repositories
+	if kernels.Contains(packages.KernelCachyOS) && cmd.PacmanConfig != "" {
+		err := fmt.Errorf("%s is not available from the official repositories", packages.KernelCachyOS)
+		h.logger.Error("CachyOS kernel requested with a custom pacman.conf", "error", err)
+		result.ErrorDetail = err.Error()
+		return result, err
+	}
+
 	// For CachyOS kernel: add the repo to the live host before pacstrap
-	if cmd.KernelVariant == packages.KernelCachyOS {
-		if err := h.setupCachyOSOnHost(ctx); err != nil {
+	if kernels.Contains(packages.KernelCachyOS) {
+		if err := h.setupCachyOSOnHost(ctx, result); err != nil {
 			h.logger.Error("Failed to setup CachyOS repo on host", "error", err)
 			result.ErrorDetail = fmt.Sprintf("Failed to setup CachyOS repo on host: %v", err)
 			return result, err
 		}
 	}
 
+	// Route pacstrap through the LAN package cache
+	if cmd.PacmanCache != nil {
+		h.logger.Info("Using pacman cache on host", "url", cmd.PacmanCache.URL())
+		err := h.patchHostPacmanConf(result, func(conf string) string {
+			return packages.PacmanConfWithCache(conf, cmd.PacmanCache)
+		})
+		if err != nil {
+			h.logger.Error("Failed to configure pacman cache on host", "error", err)
+			result.ErrorDetail = fmt.Sprintf("Failed to configure pacman cache on host: %v", err)
+			return result, err
+		}
+	}
+
 	h.logger.Info("Installing base packages", "count", len(basePackages))
 	args := append([]string{cmd.MountPoint}, basePackages...)
+	if cmd.PacmanConfig != "" {
+		args = append([]string{"-C", cmd.PacmanConfig}, args...)
+	}
 	if _, err := h.cmdExec.Execute(ctx, "pacstrap", args...); err != nil {
 		h.logger.Error("Pacstrap failed", "error", err)
 		result.ErrorDetail = fmt.Sprintf("Pacstrap failed: %v", err)
@@ -111,6 +141,29 @@ func (h *InstallBaseHandler) Handle(ctx context.Context, cmd commands.InstallBas
 		return result, err
 	}
 
+	if cmd.Mirrorlist != "" {
+		mirrorlistPath := filepath.Join(cmd.MountPoint, packages.MirrorlistPath)
+		if err := h.fs.WriteFile(mirrorlistPath, []byte(cmd.Mirrorlist), 0644); err != nil {
+			h.logger.Error("Failed to write target mirrorlist", "error", err)
+			result.ErrorDetail = fmt.Sprintf("Failed to write target mirrorlist: %v", err)
+			return result, err
+		}
+	}
+
+	// The repositories phase adds the cache to the other repositories
+	if cmd.PacmanCache != nil {
+		targetConf := filepath.Join(cmd.MountPoint, hostPacmanConf)
+		conf, err := h.fs.ReadFile(targetConf)
+		if err == nil {
+			err = h.fs.WriteFile(targetConf, []byte(packages.PacmanConfWithCache(string(conf), cmd.PacmanCache)), 0644)
+		}
+		if err != nil {
+			h.logger.Error("Failed to configure pacman cache on target", "error", err)
+			result.ErrorDetail = fmt.Sprintf("Failed to configure pacman cache on target: %v", err)
+			return result, err
+		}
+	}
+
 	result.PackagesInstalled = basePackages
 	result.Success = true
 
@@ -118,17 +171,42 @@ func (h *InstallBaseHandler) Handle(ctx context.Context, cmd commands.InstallBas
 	return result, nil
 }
 
+// Host pacman.conf, patched for repositories pacstrap needs and restored on rollback
+const (
+	hostPacmanConf       = "/etc/pacman.conf"
+	hostPacmanConfBackup = "/etc/pacman.conf.archup"
+)
+
+// Rollback restores the host pacman.conf Handle patched
+func (h *InstallBaseHandler) Rollback(ctx context.Context, result *dto.InstallBaseResult) error {
+	if result == nil || result.HostPacmanBackup == "" {
+		return nil
+	}
+
+	h.logger.Warn("Restoring host pacman.conf", "backup", result.HostPacmanBackup)
+	original, err := h.fs.ReadFile(result.HostPacmanBackup)
+	if err != nil {
+		return fmt.Errorf("failed to read pacman.conf backup: %w", err)
+	}
+	if err := h.fs.WriteFile(hostPacmanConf, original, 0644); err != nil {
+		return fmt.Errorf("failed to restore host pacman.conf: %w", err)
+	}
+	if err := h.fs.RemoveAll(result.HostPacmanBackup); err != nil {
+		h.logger.Warn("Failed to remove pacman.conf backup", "error", err)
+	}
+	return nil
+}
+
 // setupCachyOSOnHost configures the CachyOS repo on the live host so that
 // pacstrap can resolve linux-cachyos. Mirrors the working bash approach:
 // import+sign key, write mirrorlist manually (no versioned package URLs),
 // patch pacman.conf, then sync.
-func (h *InstallBaseHandler) setupCachyOSOnHost(ctx context.Context) error {
+func (h *InstallBaseHandler) setupCachyOSOnHost(ctx context.Context, result *dto.InstallBaseResult) error {
 	h.logger.Info("Setting up CachyOS repo on host for pacstrap")
 
 	const (
 		cachyOSKeyID          = "F3B607488DB35A47"
 		keyserver             = "keyserver.ubuntu.com"
-		hostPacmanConf        = "/etc/pacman.conf"
 		cachyOSMirrorlistDir  = "/etc/pacman.d"
 		cachyOSMirrorlistPath = "/etc/pacman.d/cachyos-mirrorlist"
 		cachyOSMirrorlist     = "## CachyOS mirrorlist\nServer = https://mirror.cachyos.org/repo/$arch/$repo\n"
@@ -162,13 +240,8 @@ func (h *InstallBaseHandler) setupCachyOSOnHost(ctx context.Context) error {
 		return fmt.Errorf("failed to write host CachyOS mirrorlist: %w", err)
 	}
 
-	confBytes, err := h.fs.ReadFile(hostPacmanConf)
-	if err != nil {
-		return fmt.Errorf("failed to read host pacman.conf: %w", err)
-	}
-	conf := ensureCachyOSHostRepo(string(confBytes))
-	if err := h.fs.WriteFile(hostPacmanConf, []byte(conf), 0644); err != nil {
-		return fmt.Errorf("failed to write host pacman.conf: %w", err)
+	if err := h.patchHostPacmanConf(result, ensureCachyOSHostRepo); err != nil {
+		return err
 	}
 
 	if _, err := h.cmdExec.Execute(ctx, "pacman", "-Sy", "--noconfirm"); err != nil {
@@ -179,6 +252,29 @@ func (h *InstallBaseHandler) setupCachyOSOnHost(ctx context.Context) error {
 	return nil
 }
 
+// patchHostPacmanConf rewrites the host pacman.conf with patch. The original
+// is backed up before the first change, for Rollback.
+func (h *InstallBaseHandler) patchHostPacmanConf(result *dto.InstallBaseResult, patch func(string) string) error {
+	confBytes, err := h.fs.ReadFile(hostPacmanConf)
+	if err != nil {
+		return fmt.Errorf("failed to read host pacman.conf: %w", err)
+	}
+	conf := patch(string(confBytes))
+	if conf == string(confBytes) {
+		return nil
+	}
+	if result.HostPacmanBackup == "" {
+		if err := h.fs.WriteFile(hostPacmanConfBackup, confBytes, 0644); err != nil {
+			return fmt.Errorf("failed to back up host pacman.conf: %w", err)
+		}
+		result.HostPacmanBackup = hostPacmanConfBackup
+	}
+	if err := h.fs.WriteFile(hostPacmanConf, []byte(conf), 0644); err != nil {
+		return fmt.Errorf("failed to write host pacman.conf: %w", err)
+	}
+	return nil
+}
+
 func ensureCachyOSHostRepo(conf string) string {
 	if strings.Contains(conf, "[cachyos]") {
 		return conf
@@ -216,7 +312,18 @@ func (h *InstallBaseHandler) loadBasePackages() ([]string, error) {
 	// Read from install directory (downloaded during bootstrap)
 	packageFile := filepath.Join(config.DefaultInstallDir, config.BasePackagesFile)
 
-	content, err := h.fs.ReadFile(packageFile)
+	packages, err := readPackageFile(h.fs, packageFile)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("Loaded packages from file", "file", packageFile, "count", len(packages))
+	return packages, nil
+}
+
+// readPackageFile reads a package list file: one package per line, # comments
+func readPackageFile(fs ports.FileSystem, packageFile string) ([]string, error) {
+	content, err := fs.ReadFile(packageFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %w", packageFile, err)
 	}
@@ -239,6 +346,5 @@ func (h *InstallBaseHandler) loadBasePackages() ([]string, error) {
 		return nil, fmt.Errorf("error reading package file: %w", err)
 	}
 
-	h.logger.Info("Loaded packages from file", "file", packageFile, "count", len(packages))
 	return packages, nil
 }
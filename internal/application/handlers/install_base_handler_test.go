@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
 	"github.com/bnema/archup/internal/domain/packages"
 	"github.com/bnema/archup/internal/domain/ports/mocks"
 	"go.uber.org/mock/gomock"
@@ -194,3 +195,218 @@ func TestInstallBaseHandler_Handle_WithEncryption_IncludesCryptsetup(t *testing.
 		t.Error("expected cryptsetup to be in PackagesInstalled for encrypted install")
 	}
 }
+
+func TestInstallBaseHandler_Handle_WithExtraKernels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(basePackagesContent, nil).AnyTimes()
+	// Primary kernel first, duplicate extra kernels are ignored
+	mockExec.EXPECT().Execute(gomock.Any(), "pacstrap", "/mnt", "base", "linux-firmware", "linux-zen", "linux-lts").Return([]byte{}, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "genfstab", "-U", "/mnt").Return([]byte("# fstab"), nil)
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	cmd := commands.InstallBaseCommand{
+		TargetDisk:    "/dev/sda",
+		MountPoint:    "/mnt",
+		KernelVariant: packages.KernelZen,
+		ExtraKernels:  []packages.KernelVariant{packages.KernelLTS, packages.KernelZen},
+	}
+
+	result, err := handler.Handle(context.Background(), cmd)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Success {
+		t.Error("expected success")
+	}
+}
+
+func TestInstallBaseHandler_Handle_DKMSAddsKernelHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(basePackagesContent, nil).AnyTimes()
+	// Headers for every kernel follow the DKMS package
+	mockExec.EXPECT().Execute(gomock.Any(), "pacstrap", "/mnt", "base", "linux-firmware", "linux", "linux-lts",
+		"broadcom-wl-dkms", "linux-headers", "linux-lts-headers").Return([]byte{}, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "genfstab", "-U", "/mnt").Return([]byte("# fstab"), nil)
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	cmd := commands.InstallBaseCommand{
+		TargetDisk:    "/dev/sda",
+		MountPoint:    "/mnt",
+		KernelVariant: packages.KernelStable,
+		ExtraKernels:  []packages.KernelVariant{packages.KernelLTS},
+		Packages:      []string{"broadcom-wl-dkms"},
+	}
+
+	result, err := handler.Handle(context.Background(), cmd)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.Success {
+		t.Error("expected success")
+	}
+}
+
+func TestInstallBaseHandler_Rollback_RestoresHostPacmanConf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	original := []byte("[core]\nInclude = /etc/pacman.d/mirrorlist\n")
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadFile(hostPacmanConfBackup).Return(original, nil)
+	mockFS.EXPECT().WriteFile(hostPacmanConf, original, gomock.Any()).Return(nil)
+	mockFS.EXPECT().RemoveAll(hostPacmanConfBackup).Return(nil)
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result := &dto.InstallBaseResult{HostPacmanBackup: hostPacmanConfBackup}
+	if err := handler.Rollback(context.Background(), result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// Host pacman.conf untouched, nothing to restore
+	if err := handler.Rollback(context.Background(), &dto.InstallBaseResult{}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestInstallBaseHandler_Handle_OfflinePacmanConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(basePackagesContent, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "pacstrap", "-C", "/tmp/archup-offline/pacman.conf", "/mnt", "base", "linux-firmware", "linux").Return([]byte{}, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "genfstab", "-U", "/mnt").Return([]byte("# fstab"), nil)
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	cmd := commands.InstallBaseCommand{
+		TargetDisk:    "/dev/sda",
+		MountPoint:    "/mnt",
+		KernelVariant: packages.KernelStable,
+		PacmanConfig:  "/tmp/archup-offline/pacman.conf",
+	}
+
+	if _, err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// linux-cachyos is not in the bundle repositories
+	cmd.KernelVariant = packages.KernelCachyOS
+	if _, err := handler.Handle(context.Background(), cmd); err == nil {
+		t.Error("expected error for CachyOS kernel offline")
+	}
+}
+
+func TestInstallBaseHandler_Handle_TargetMirrorlist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(basePackagesContent, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "pacstrap", "/mnt", "base", "linux-firmware", "linux").Return([]byte{}, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "genfstab", "-U", "/mnt").Return([]byte("# fstab"), nil)
+	mockFS.EXPECT().WriteFile("/mnt/etc/fstab", gomock.Any(), gomock.Any()).Return(nil)
+
+	mirrorlist := "Server = https://a.example.fr/arch/$repo/os/$arch\n"
+	mockFS.EXPECT().WriteFile("/mnt/etc/pacman.d/mirrorlist", []byte(mirrorlist), gomock.Any()).Return(nil)
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	if _, err := handler.Handle(context.Background(), commands.InstallBaseCommand{
+		TargetDisk:    "/dev/sda",
+		MountPoint:    "/mnt",
+		KernelVariant: packages.KernelStable,
+		Mirrorlist:    mirrorlist,
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestInstallBaseHandler_Handle_PacmanCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	cache, err := packages.NewPacmanCache("http://cache.lan:9129", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	hostConf := "[options]\n\n[core]\nInclude = /etc/pacman.d/mirrorlist\n"
+	cachedConf := packages.PacmanConfWithCache(hostConf, cache)
+
+	mockFS.EXPECT().ReadFile("/etc/pacman.conf").Return([]byte(hostConf), nil)
+	mockFS.EXPECT().ReadFile("/mnt/etc/pacman.conf").Return([]byte(hostConf), nil)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(basePackagesContent, nil).AnyTimes()
+
+	gomock.InOrder(
+		mockFS.EXPECT().WriteFile("/etc/pacman.conf.archup", []byte(hostConf), gomock.Any()).Return(nil),
+		mockFS.EXPECT().WriteFile("/etc/pacman.conf", []byte(cachedConf), gomock.Any()).Return(nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "pacstrap", "/mnt", "base", "linux-firmware", "linux").Return([]byte{}, nil),
+	)
+	mockExec.EXPECT().Execute(gomock.Any(), "genfstab", "-U", "/mnt").Return([]byte("# fstab"), nil)
+	mockFS.EXPECT().WriteFile("/mnt/etc/fstab", gomock.Any(), gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile("/mnt/etc/pacman.conf", []byte(cachedConf), gomock.Any()).Return(nil)
+
+	handler := NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.InstallBaseCommand{
+		TargetDisk:    "/dev/sda",
+		MountPoint:    "/mnt",
+		KernelVariant: packages.KernelStable,
+		PacmanCache:   cache,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.HostPacmanBackup != "/etc/pacman.conf.archup" {
+		t.Errorf("expected the host pacman.conf backup for rollback, got %q", result.HostPacmanBackup)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// InventoryHandler writes the hardware inventory to the target
+type InventoryHandler struct {
+	fs     ports.FileSystem
+	logger ports.Logger
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(fs ports.FileSystem, logger ports.Logger) *InventoryHandler {
+	return &InventoryHandler{
+		fs:     fs,
+		logger: logger,
+	}
+}
+
+// Handle completes the detected hardware with the DMI, memory and network
+// details of the running system and writes the inventory as JSON.
+func (h *InventoryHandler) Handle(ctx context.Context, cmd commands.WriteInventoryCommand) (*dto.InventoryResult, error) {
+	h.logger.Info("Starting hardware inventory")
+
+	result := &dto.InventoryResult{
+		Success:     false,
+		ErrorDetail: "",
+	}
+
+	fail := func(message string, err error) (*dto.InventoryResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	machine := system.DetectMachine(ctx, h.fs)
+	machine.UEFI = cmd.UEFI
+	machine.SecureBoot = cmd.SecureBoot
+
+	inventory := system.NewHardwareInventory(
+		cmd.Architecture,
+		machine,
+		cmd.CPU,
+		system.DetectMemoryKB(ctx, h.fs),
+		cmd.GPUs,
+		cmd.Disks,
+		system.DetectNetworkInterfaces(ctx, h.fs),
+	)
+
+	content, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return fail("Failed to encode hardware inventory", err)
+	}
+
+	path := filepath.Join(cmd.MountPoint, system.HardwareInventoryFile)
+	if err := h.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fail("Failed to create inventory directory", err)
+	}
+	if err := h.fs.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fail("Failed to write hardware inventory", err)
+	}
+
+	result.Success = true
+	result.Path = system.HardwareInventoryFile
+	result.Summary = inventory.Summary()
+	h.logger.Info("Hardware inventory written", "path", path, "gpus", len(cmd.GPUs), "disks", len(cmd.Disks))
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInventoryHandler_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// A wired and a wireless card next to the loopback interface
+	sysfs := fstest.MapFS{
+		"sys/class/dmi/id/sys_vendor":           {Data: []byte("LENOVO\n")},
+		"sys/class/dmi/id/product_name":         {Data: []byte("21CB\n")},
+		"sys/class/dmi/id/bios_version":         {Data: []byte("N3AET75W (1.40 )\n")},
+		"sys/class/dmi/id/bios_date":            {Data: []byte("02/14/2024\n")},
+		"proc/meminfo":                          {Data: []byte("MemTotal:       32562428 kB\nMemFree:         1024 kB\n")},
+		"sys/class/net/lo/address":              {Data: []byte("00:00:00:00:00:00\n")},
+		"sys/class/net/enp0s31f6/address":       {Data: []byte("aa:bb:cc:dd:ee:01\n")},
+		"sys/class/net/enp0s31f6/uevent":        {Data: []byte("INTERFACE=enp0s31f6\nIFINDEX=2\n")},
+		"sys/class/net/enp0s31f6/device/uevent": {Data: []byte("DRIVER=e1000e\nPCI_SLOT_NAME=0000:00:1f.6\n")},
+		"sys/class/net/wlp0s20f3/address":       {Data: []byte("aa:bb:cc:dd:ee:02\n")},
+		"sys/class/net/wlp0s20f3/uevent":        {Data: []byte("DEVTYPE=wlan\nINTERFACE=wlp0s20f3\n")},
+		"sys/class/net/wlp0s20f3/device/uevent": {Data: []byte("DRIVER=iwlwifi\n")},
+	}
+	mockFS.EXPECT().ReadDir(gomock.Any()).DoAndReturn(func(path string) ([]os.DirEntry, error) {
+		return fs.ReadDir(sysfs, strings.TrimPrefix(path, "/"))
+	}).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).DoAndReturn(func(path string) ([]byte, error) {
+		return fs.ReadFile(sysfs, strings.TrimPrefix(path, "/"))
+	}).AnyTimes()
+
+	var written []byte
+	mockFS.EXPECT().MkdirAll("/mnt/var/lib/archup", gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile("/mnt"+system.HardwareInventoryFile, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			written = data
+			return nil
+		})
+
+	handler := NewInventoryHandler(mockFS, mockLogger)
+	result, err := handler.Handle(context.Background(), commands.WriteInventoryCommand{
+		MountPoint:   "/mnt",
+		Architecture: "x86_64",
+		UEFI:         true,
+		CPU:          system.InventoryCPU{Vendor: "AMD", Model: "AMD Ryzen 7 PRO 6850U", ZenGeneration: "Zen 3+"},
+		GPUs:         []system.InventoryGPU{{Vendor: "AMD", Model: "Radeon 680M", Slot: "0000:04:00.0", BootVGA: true}},
+		Disks:        []system.InventoryDisk{{Path: "/dev/nvme0n1", Size: "476.9G", Model: "SAMSUNG MZVL2512", Serial: "S6XYZ", Transport: "nvme"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Path != system.HardwareInventoryFile {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	var inventory struct {
+		Machine struct {
+			Vendor          string `json:"vendor"`
+			FirmwareVersion string `json:"firmware_version"`
+			UEFI            bool   `json:"uefi"`
+		} `json:"machine"`
+		CPU      system.InventoryCPU                `json:"cpu"`
+		MemoryKB uint64                             `json:"memory_kb"`
+		Disks    []system.InventoryDisk             `json:"disks"`
+		Network  []system.InventoryNetworkInterface `json:"network"`
+	}
+	if err := json.Unmarshal(written, &inventory); err != nil {
+		t.Fatalf("invalid inventory JSON: %v\n%s", err, written)
+	}
+	if inventory.Machine.Vendor != "LENOVO" || inventory.Machine.FirmwareVersion != "N3AET75W (1.40 )" || !inventory.Machine.UEFI {
+		t.Errorf("unexpected machine %+v", inventory.Machine)
+	}
+	if inventory.CPU.ZenGeneration != "Zen 3+" || inventory.MemoryKB != 32562428 {
+		t.Errorf("unexpected CPU %+v or memory %d", inventory.CPU, inventory.MemoryKB)
+	}
+	if len(inventory.Disks) != 1 || inventory.Disks[0].Transport != "nvme" || inventory.Disks[0].Serial != "S6XYZ" {
+		t.Errorf("unexpected disks %+v", inventory.Disks)
+	}
+	if len(inventory.Network) != 2 {
+		t.Fatalf("expected the loopback interface to be skipped, got %+v", inventory.Network)
+	}
+	if nic := inventory.Network[1]; nic.Name != "wlp0s20f3" || !nic.Wireless || nic.Driver != "iwlwifi" {
+		t.Errorf("unexpected wireless interface %+v", nic)
+	}
+	if !strings.Contains(strings.Join(result.Summary, "\n"), "Memory: 31.1 GiB") {
+		t.Errorf("unexpected summary %v", result.Summary)
+	}
+}
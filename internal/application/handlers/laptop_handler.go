@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// LaptopHandler detects laptops and applies the laptop profile
+type LaptopHandler struct {
+	fs      ports.FileSystem
+	chrExec ports.ChrootExecutor
+	logger  ports.Logger
+}
+
+// NewLaptopHandler creates a new laptop handler
+func NewLaptopHandler(fs ports.FileSystem, chrExec ports.ChrootExecutor, logger ports.Logger) *LaptopHandler {
+	return &LaptopHandler{
+		fs:      fs,
+		chrExec: chrExec,
+		logger:  logger,
+	}
+}
+
+// Detect reads the chassis of the running system
+func (h *LaptopHandler) Detect(ctx context.Context) (*system.Chassis, error) {
+	chassis, err := system.DetectChassis(ctx, h.fs)
+	if err != nil {
+		return nil, err
+	}
+	h.logger.Info("Detected chassis", "dmiType", chassis.DMIType(), "batteries", chassis.Batteries(), "lid", chassis.HasLid(), "laptop", chassis.IsLaptop())
+	return chassis, nil
+}
+
+// Handle installs the laptop packages, enables their services and configures
+// logind lid handling. Suspend-then-hibernate needs a disk swap in the target
+// fstab, without one the lid only suspends.
+func (h *LaptopHandler) Handle(ctx context.Context, cmd commands.ConfigureLaptopCommand) (*dto.LaptopResult, error) {
+	h.logger.Info("Starting laptop profile configuration", "enabled", cmd.Enabled, "hibernate", cmd.SuspendThenHibernate)
+
+	result := &dto.LaptopResult{
+		Success:     false,
+		ErrorDetail: "",
+	}
+
+	fail := func(message string, err error) (*dto.LaptopResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	if !cmd.Enabled {
+		h.logger.Info("Laptop profile not selected, skipping")
+		result.Success = true
+		return result, nil
+	}
+
+	pkgs := system.LaptopPackages(cmd.CPUVendor)
+	args := append([]string{"-S", "--noconfirm", "--needed"}, pkgs...)
+	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", args...); err != nil {
+		return fail("Failed to install laptop packages", err)
+	}
+	result.Packages = pkgs
+
+	for _, unit := range system.LaptopServices(cmd.CPUVendor) {
+		if err := h.chrExec.ChrootSystemctl(ctx, "", cmd.MountPoint, "enable", unit); err != nil {
+			return fail("Failed to enable "+unit, err)
+		}
+		result.Services = append(result.Services, unit)
+	}
+
+	if cmd.SuspendThenHibernate {
+		device, offset, err := h.resumeDevice(ctx, cmd.MountPoint)
+		switch {
+		case err != nil:
+			return fail("Failed to locate the hibernation swap", err)
+		case device == "":
+			h.logger.Warn("No disk swap in the target fstab, lid close will only suspend")
+		default:
+			if err := writeTargetFile(h.fs, filepath.Join(cmd.MountPoint, system.LaptopSleepFile), system.LaptopSleepConf); err != nil {
+				return fail("Failed to write sleep configuration", err)
+			}
+			result.Hibernate = true
+			result.ResumeDevice = device
+			result.ResumeOffset = offset
+		}
+	}
+
+	if err := writeTargetFile(h.fs, filepath.Join(cmd.MountPoint, system.LaptopLogindFile), system.LaptopLogindConf(result.Hibernate)); err != nil {
+		return fail("Failed to write logind configuration", err)
+	}
+
+	result.Success = true
+	h.logger.Info("Laptop profile configured", "packages", result.Packages, "hibernate", result.Hibernate)
+	return result, nil
+}
+
+// resumeDevice returns the resume device and offset of the disk swap of the
+// target fstab, empty without one. A swap file lives on the root filesystem,
+// its offset comes from btrfs.
+func (h *LaptopHandler) resumeDevice(ctx context.Context, mountPoint string) (string, int, error) {
+	fstab, err := h.fs.ReadFile(filepath.Join(mountPoint, "etc", "fstab"))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read fstab: %w", err)
+	}
+
+	swap := system.FstabSwap(string(fstab))
+	if swap == "" || !system.IsSwapFile(swap) {
+		return swap, 0, nil
+	}
+
+	root := system.FstabRoot(string(fstab))
+	if root == "" {
+		return "", 0, fmt.Errorf("no root filesystem in fstab for swap file %s", swap)
+	}
+	output, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "btrfs", "inspect-internal", "map-swapfile", "-r", swap)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to map swap file %s: %w", swap, err)
+	}
+	offset, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return "", 0, fmt.Errorf("unexpected swap file offset %q: %w", strings.TrimSpace(string(output)), err)
+	}
+	return root, offset, nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/system"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLaptopHandler_Handle_SwapFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed",
+		"fwupd", "brightnessctl", "thermald").Return([]byte{}, nil)
+	var units []string
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), "", "/mnt", "enable", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _, _ string, args ...string) error {
+			units = append(units, args[1])
+			return nil
+		}).Times(2)
+
+	mockFS.EXPECT().ReadFile("/mnt/etc/fstab").Return([]byte("UUID=aaaa / btrfs rw,subvol=/@ 0 0\n/swap/swapfile none swap defaults 0 0\n"), nil)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "btrfs", "inspect-internal", "map-swapfile", "-r", "/swap/swapfile").Return([]byte("198144\n"), nil)
+
+	files := map[string]string{}
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(name string, data []byte, _ os.FileMode) error {
+			files[name] = string(data)
+			return nil
+		}).Times(2)
+
+	handler := NewLaptopHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.ConfigureLaptopCommand{
+		MountPoint:           "/mnt",
+		Enabled:              true,
+		CPUVendor:            system.CPUVendorIntel,
+		SuspendThenHibernate: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || !result.Hibernate || result.ResumeDevice != "UUID=aaaa" || result.ResumeOffset != 198144 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !reflect.DeepEqual(units, []string{"fwupd-refresh.timer", "thermald.service"}) {
+		t.Errorf("unexpected units %v", units)
+	}
+	if !strings.Contains(files["/mnt"+system.LaptopLogindFile], "suspend-then-hibernate") {
+		t.Errorf("unexpected logind configuration:\n%s", files["/mnt"+system.LaptopLogindFile])
+	}
+	if files["/mnt"+system.LaptopSleepFile] != system.LaptopSleepConf {
+		t.Errorf("unexpected sleep configuration:\n%s", files["/mnt"+system.LaptopSleepFile])
+	}
+}
+
+func TestLaptopHandler_Handle_NoSwap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any()).Times(1)
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed",
+		"fwupd", "brightnessctl").Return([]byte{}, nil)
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), "", "/mnt", "enable", "fwupd-refresh.timer").Return(nil)
+	mockFS.EXPECT().ReadFile("/mnt/etc/fstab").Return([]byte("UUID=aaaa / btrfs rw 0 0\n/dev/zram0 none swap defaults 0 0\n"), nil)
+	mockFS.EXPECT().MkdirAll("/mnt/etc/systemd/logind.conf.d", gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile("/mnt"+system.LaptopLogindFile, []byte(system.LaptopLogindConf(false)), gomock.Any()).Return(nil)
+
+	handler := NewLaptopHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.ConfigureLaptopCommand{
+		MountPoint:           "/mnt",
+		Enabled:              true,
+		CPUVendor:            system.CPUVendorAMD,
+		SuspendThenHibernate: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Hibernate || result.ResumeDevice != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
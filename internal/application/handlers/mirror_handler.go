@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// Mirror probing limits
+const (
+	mirrorProbeCandidates  = 20 // Best scored mirrors of the country probed
+	mirrorProbeConcurrency = 8
+	mirrorArch             = "x86_64"
+)
+
+// hostMirrorlistBackup is the copy of the ISO mirrorlist restored on rollback
+const hostMirrorlistBackup = packages.MirrorlistPath + ".archup"
+
+// MirrorHandler picks and ranks the pacman mirrors used by pacstrap
+type MirrorHandler struct {
+	fs     ports.FileSystem
+	http   ports.HTTPClient
+	logger ports.Logger
+}
+
+// NewMirrorHandler creates a new mirror handler
+func NewMirrorHandler(fs ports.FileSystem, http ports.HTTPClient, logger ports.Logger) *MirrorHandler {
+	return &MirrorHandler{
+		fs:     fs,
+		http:   http,
+		logger: logger,
+	}
+}
+
+// Handle writes the host mirrorlist from the answer file mirrors, or from the
+// mirrors of the timezone country ranked by download speed. Without network
+// access to the mirror status the ISO mirrorlist is kept.
+func (h *MirrorHandler) Handle(ctx context.Context, cmd commands.SelectMirrorsCommand) (*dto.MirrorsResult, error) {
+	h.logger.Info("Starting mirror selection", "timezone", cmd.Timezone, "country", cmd.Country, "servers", len(cmd.Servers))
+
+	result := &dto.MirrorsResult{
+		Success:     false,
+		ErrorDetail: "",
+	}
+
+	fail := func(message string, err error) (*dto.MirrorsResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	origin := "Mirrors from the answer file"
+	if len(cmd.Servers) > 0 {
+		for _, server := range cmd.Servers {
+			result.Servers = append(result.Servers, packages.MirrorServer(server))
+		}
+	} else {
+		result.Country = h.country(cmd)
+		mirrors, err := h.rank(ctx, result.Country, cmd.Count)
+		if err != nil {
+			h.logger.Warn("Mirror ranking failed, keeping the ISO mirrorlist", "error", err)
+			result.Success = true
+			return result, nil
+		}
+		for _, mirror := range mirrors {
+			result.Servers = append(result.Servers, mirror.Server())
+		}
+		result.Ranked = true
+		origin = "Fastest mirrors worldwide"
+		if result.Country != "" {
+			origin = "Fastest mirrors in " + result.Country
+		}
+	}
+
+	if current, err := h.fs.ReadFile(packages.MirrorlistPath); err == nil {
+		if err := h.fs.WriteFile(hostMirrorlistBackup, current, 0644); err != nil {
+			return fail("Failed to back up host mirrorlist", err)
+		}
+		result.HostBackup = hostMirrorlistBackup
+	}
+
+	result.Mirrorlist = packages.Mirrorlist(result.Servers, origin)
+	if err := h.fs.WriteFile(packages.MirrorlistPath, []byte(result.Mirrorlist), 0644); err != nil {
+		return fail("Failed to write host mirrorlist", err)
+	}
+
+	result.Success = true
+	h.logger.Info("Mirrorlist written", "country", result.Country, "mirrors", len(result.Servers), "ranked", result.Ranked)
+	return result, nil
+}
+
+// Rollback restores the host mirrorlist Handle replaced
+func (h *MirrorHandler) Rollback(ctx context.Context, result *dto.MirrorsResult) error {
+	if result == nil || result.HostBackup == "" {
+		return nil
+	}
+
+	h.logger.Warn("Restoring host mirrorlist", "backup", result.HostBackup)
+	original, err := h.fs.ReadFile(result.HostBackup)
+	if err != nil {
+		return fmt.Errorf("failed to read mirrorlist backup: %w", err)
+	}
+	if err := h.fs.WriteFile(packages.MirrorlistPath, original, 0644); err != nil {
+		return fmt.Errorf("failed to restore host mirrorlist: %w", err)
+	}
+	if err := h.fs.RemoveAll(result.HostBackup); err != nil {
+		h.logger.Warn("Failed to remove mirrorlist backup", "error", err)
+	}
+	return nil
+}
+
+// country returns the answer file country, else the country of the timezone
+func (h *MirrorHandler) country(cmd commands.SelectMirrorsCommand) string {
+	if cmd.Country != "" {
+		return strings.ToUpper(cmd.Country)
+	}
+	zoneTab, err := h.fs.ReadFile(packages.ZoneTabPath)
+	if err != nil {
+		h.logger.Warn("Failed to read zone.tab, ranking mirrors worldwide", "error", err)
+		return ""
+	}
+	return packages.CountryForTimezone(string(zoneTab), cmd.Timezone)
+}
+
+// rank downloads the mirror status and returns the fastest mirrors of the country
+func (h *MirrorHandler) rank(ctx context.Context, country string, count int) ([]*packages.Mirror, error) {
+	resp, err := h.http.Get(packages.MirrorStatusURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch mirror status: %w", err)
+	}
+	defer func() { _ = resp.Close() }()
+	if resp.StatusCode() != 200 {
+		return nil, fmt.Errorf("mirror status returned HTTP %d", resp.StatusCode())
+	}
+
+	mirrors, err := packages.ParseMirrorStatus(resp.Body())
+	if err != nil {
+		return nil, err
+	}
+	candidates := packages.MirrorsInCountry(mirrors, country)
+	if len(candidates) > mirrorProbeCandidates {
+		candidates = candidates[:mirrorProbeCandidates]
+	}
+
+	if count <= 0 {
+		count = packages.DefaultMirrorCount
+	}
+	ranked := packages.RankMirrors(h.probe(ctx, candidates), count)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("none of the %d probed mirrors answered", len(candidates))
+	}
+	return ranked, nil
+}
+
+// probe downloads the core database of every mirror concurrently
+func (h *MirrorHandler) probe(ctx context.Context, mirrors []*packages.Mirror) []packages.MirrorProbe {
+	probes := make([]packages.MirrorProbe, len(mirrors))
+	sem := make(chan struct{}, mirrorProbeConcurrency)
+	var wg sync.WaitGroup
+
+	for i, mirror := range mirrors {
+		probes[i].Mirror = mirror
+		wg.Add(1)
+		go func(probe *packages.MirrorProbe) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := ctx.Err(); err != nil {
+				probe.Err = err
+				return
+			}
+			start := time.Now()
+			resp, err := h.http.Get(probe.Mirror.ProbeURL(mirrorArch))
+			probe.Duration = time.Since(start)
+			if err != nil {
+				probe.Err = err
+				return
+			}
+			defer func() { _ = resp.Close() }()
+			if resp.StatusCode() != 200 {
+				probe.Err = fmt.Errorf("HTTP %d", resp.StatusCode())
+				return
+			}
+			probe.Bytes = len(resp.Body())
+		}(&probes[i])
+	}
+
+	wg.Wait()
+	return probes
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const testMirrorStatus = `{"urls": [
+	{"url": "https://a.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 1.0},
+	{"url": "https://b.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 2.0},
+	{"url": "https://c.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 3.0},
+	{"url": "https://d.example.de/arch/", "protocol": "https", "country_code": "DE", "active": true, "completion_pct": 1.0, "score": 0.5}
+]}`
+
+func mockHTTPResponse(ctrl *gomock.Controller, status int, body string) *mocks.MockResponse {
+	resp := mocks.NewMockResponse(ctrl)
+	resp.EXPECT().StatusCode().Return(status).AnyTimes()
+	resp.EXPECT().Body().Return([]byte(body)).AnyTimes()
+	resp.EXPECT().Close().Return(nil).AnyTimes()
+	return resp
+}
+
+func TestMirrorHandler_Handle_RanksTimezoneCountry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockFS.EXPECT().ReadFile(packages.ZoneTabPath).Return([]byte("FR\t+4852+00220\tEurope/Paris\n"), nil)
+	mockFS.EXPECT().ReadFile(packages.MirrorlistPath).Return([]byte("Server = https://iso.example/$repo/os/$arch\n"), nil)
+
+	mockHTTP.EXPECT().Get(packages.MirrorStatusURL).Return(mockHTTPResponse(ctrl, 200, testMirrorStatus), nil)
+	mockHTTP.EXPECT().Get("https://a.example.fr/arch/core/os/x86_64/core.db").Return(mockHTTPResponse(ctrl, 200, "core.db"), nil)
+	mockHTTP.EXPECT().Get("https://b.example.fr/arch/core/os/x86_64/core.db").Return(nil, errors.New("timeout"))
+	mockHTTP.EXPECT().Get("https://c.example.fr/arch/core/os/x86_64/core.db").Return(mockHTTPResponse(ctrl, 404, ""), nil)
+
+	files := map[string]string{}
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(name string, data []byte, _ os.FileMode) error {
+			files[name] = string(data)
+			return nil
+		}).Times(2)
+
+	handler := NewMirrorHandler(mockFS, mockHTTP, mockLogger)
+	result, err := handler.Handle(context.Background(), commands.SelectMirrorsCommand{Timezone: "Europe/Paris"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || !result.Ranked || result.Country != "FR" || result.HostBackup != hostMirrorlistBackup {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(result.Servers) != 1 || result.Servers[0] != "https://a.example.fr/arch/$repo/os/$arch" {
+		t.Errorf("expected only the answering French mirror, got %v", result.Servers)
+	}
+	if files[packages.MirrorlistPath] != result.Mirrorlist || !strings.Contains(result.Mirrorlist, "Fastest mirrors in FR") {
+		t.Errorf("unexpected host mirrorlist:\n%s", files[packages.MirrorlistPath])
+	}
+	if !strings.Contains(files[hostMirrorlistBackup], "iso.example") {
+		t.Errorf("expected the ISO mirrorlist to be backed up, got %q", files[hostMirrorlistBackup])
+	}
+}
+
+func TestMirrorHandler_Handle_AnswerFileServers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockFS.EXPECT().ReadFile(packages.MirrorlistPath).Return(nil, os.ErrNotExist)
+	var written string
+	mockFS.EXPECT().WriteFile(packages.MirrorlistPath, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			written = string(data)
+			return nil
+		})
+
+	handler := NewMirrorHandler(mockFS, mockHTTP, mockLogger)
+	result, err := handler.Handle(context.Background(), commands.SelectMirrorsCommand{
+		Timezone: "Europe/Paris",
+		Servers:  []string{"http://mirror.lan/archlinux"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Ranked || result.HostBackup != "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(written, "Server = http://mirror.lan/archlinux/$repo/os/$arch\n") {
+		t.Errorf("unexpected mirrorlist:\n%s", written)
+	}
+}
+
+func TestMirrorHandler_Handle_OfflineKeepsISOMirrorlist(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+	mockHTTP.EXPECT().Get(packages.MirrorStatusURL).Return(nil, errors.New("no route to host"))
+
+	handler := NewMirrorHandler(mockFS, mockHTTP, mockLogger)
+	result, err := handler.Handle(context.Background(), commands.SelectMirrorsCommand{Country: "de"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Ranked || result.Mirrorlist != "" {
+		t.Errorf("expected the ISO mirrorlist to be kept, got %+v", result)
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// Host paths used to install from an offline bundle
+const (
+	offlineExtractDir = "/tmp/archup-bundle"
+	offlineHostDir    = "/tmp/archup-offline"
+)
+
+// OfflineHandler points pacstrap and the chroot pacman at the repositories of
+// an offline bundle instead of the network mirrors
+type OfflineHandler struct {
+	fs      ports.FileSystem
+	cmdExec ports.CommandExecutor
+	logger  ports.Logger
+}
+
+// NewOfflineHandler creates a new offline handler
+func NewOfflineHandler(fs ports.FileSystem, cmdExec ports.CommandExecutor, logger ports.Logger) *OfflineHandler {
+	return &OfflineHandler{
+		fs:      fs,
+		cmdExec: cmdExec,
+		logger:  logger,
+	}
+}
+
+// Open returns the bundle at path, a bundle directory or a tarball made by
+// `archup bundle create --tar` which is extracted first
+func (h *OfflineHandler) Open(ctx context.Context, path string) (*bundle.Bundle, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle path: %w", err)
+	}
+
+	if bundle.IsTarball(path) {
+		h.logger.Info("Extracting offline bundle", "tarball", path, "dir", offlineExtractDir)
+		if err := h.fs.RemoveAll(offlineExtractDir); err != nil {
+			return nil, fmt.Errorf("failed to clear %s: %w", offlineExtractDir, err)
+		}
+		if err := h.fs.MkdirAll(offlineExtractDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", offlineExtractDir, err)
+		}
+		if _, err := h.cmdExec.Execute(ctx, "tar", "-xf", path, "-C", offlineExtractDir, "--strip-components=1"); err != nil {
+			return nil, fmt.Errorf("failed to extract bundle: %w", err)
+		}
+		path = offlineExtractDir
+	}
+
+	b, err := bundle.NewBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	if exists, err := h.fs.Exists(b.MarkerPath()); err != nil || !exists {
+		return nil, fmt.Errorf("%s is not an archup bundle (no %s)", path, bundle.MarkerFile)
+	}
+
+	h.logger.Info("Opened offline bundle", "path", b.Root())
+	return b, nil
+}
+
+// Handle writes the host pacman.conf pacstrap installs the base system with
+func (h *OfflineHandler) Handle(ctx context.Context, cmd commands.PrepareOfflineCommand) (*dto.OfflineResult, error) {
+	result := &dto.OfflineResult{
+		Success: false,
+		Bundle:  cmd.Bundle.Root(),
+		HostDir: offlineHostDir,
+	}
+
+	if err := h.fs.MkdirAll(offlineHostDir, 0755); err != nil {
+		result.ErrorDetail = fmt.Sprintf("Failed to create %s: %v", offlineHostDir, err)
+		return result, err
+	}
+
+	mirrorlist := filepath.Join(offlineHostDir, "mirrorlist")
+	if err := h.fs.WriteFile(mirrorlist, []byte(bundle.Mirrorlist(cmd.Bundle.RepoDir())), 0644); err != nil {
+		result.ErrorDetail = fmt.Sprintf("Failed to write offline mirrorlist: %v", err)
+		return result, err
+	}
+
+	pacmanConf := filepath.Join(offlineHostDir, "pacman.conf")
+	if err := h.fs.WriteFile(pacmanConf, []byte(bundle.PacmanConf(mirrorlist)), 0644); err != nil {
+		result.ErrorDetail = fmt.Sprintf("Failed to write offline pacman.conf: %v", err)
+		return result, err
+	}
+	result.PacmanConf = pacmanConf
+
+	result.Success = true
+	h.logger.Info("Offline repositories ready on host", "bundle", cmd.Bundle.Root())
+	return result, nil
+}
+
+// Attach bind mounts the bundle repositories into the installed system and
+// points its mirrorlist at them, so pacman in the chroot installs from the bundle
+func (h *OfflineHandler) Attach(ctx context.Context, cmd commands.PrepareOfflineCommand, result *dto.OfflineResult) error {
+	repoMount := filepath.Join(cmd.MountPoint, bundle.TargetRepoDir)
+	if err := h.fs.MkdirAll(repoMount, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", repoMount, err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mount", "--bind", cmd.Bundle.RepoDir(), repoMount); err != nil {
+		return fmt.Errorf("failed to bind mount bundle repositories: %w", err)
+	}
+	result.TargetRepoMount = repoMount
+
+	mirrorlist := filepath.Join(cmd.MountPoint, "etc", "pacman.d", "mirrorlist")
+	original, err := h.fs.ReadFile(mirrorlist)
+	if err != nil {
+		return fmt.Errorf("failed to read target mirrorlist: %w", err)
+	}
+	backup := mirrorlist + ".archup-offline"
+	if err := h.fs.WriteFile(backup, original, 0644); err != nil {
+		return fmt.Errorf("failed to back up target mirrorlist: %w", err)
+	}
+	result.MirrorlistBackup = backup
+	if err := h.fs.WriteFile(mirrorlist, []byte(bundle.Mirrorlist(bundle.TargetRepoDir)), 0644); err != nil {
+		return fmt.Errorf("failed to write target mirrorlist: %w", err)
+	}
+	result.TargetMirrorlist = mirrorlist
+
+	h.logger.Info("Offline repositories attached to target", "mount", repoMount)
+	return nil
+}
+
+// Detach restores the target mirrorlist and unmounts the bundle repositories
+func (h *OfflineHandler) Detach(ctx context.Context, result *dto.OfflineResult) error {
+	if result == nil {
+		return nil
+	}
+
+	if result.MirrorlistBackup != "" {
+		original, err := h.fs.ReadFile(result.MirrorlistBackup)
+		if err != nil {
+			return fmt.Errorf("failed to read mirrorlist backup: %w", err)
+		}
+		if err := h.fs.WriteFile(result.TargetMirrorlist, original, 0644); err != nil {
+			return fmt.Errorf("failed to restore target mirrorlist: %w", err)
+		}
+		if err := h.fs.RemoveAll(result.MirrorlistBackup); err != nil {
+			h.logger.Warn("Failed to remove mirrorlist backup", "error", err)
+		}
+		result.MirrorlistBackup = ""
+	}
+
+	if result.TargetRepoMount != "" {
+		if _, err := h.cmdExec.Execute(ctx, "umount", result.TargetRepoMount); err != nil {
+			return fmt.Errorf("failed to unmount bundle repositories: %w", err)
+		}
+		// rmdir, not RemoveAll: a mount point still mounted must never be emptied
+		if _, err := h.cmdExec.Execute(ctx, "rmdir", result.TargetRepoMount); err != nil {
+			h.logger.Warn("Failed to remove repository mount point", "error", err)
+		}
+		result.TargetRepoMount = ""
+	}
+	return nil
+}
+
+// Cleanup detaches the target and removes the host pacman.conf
+func (h *OfflineHandler) Cleanup(ctx context.Context, result *dto.OfflineResult) error {
+	if result == nil {
+		return nil
+	}
+	if err := h.Detach(ctx, result); err != nil {
+		return err
+	}
+	if result.HostDir != "" {
+		if err := h.fs.RemoveAll(result.HostDir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", result.HostDir, err)
+		}
+		result.HostDir = ""
+	}
+	h.logger.Info("Offline repositories detached")
+	return nil
+}
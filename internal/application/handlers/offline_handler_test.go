@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOfflineHandler_Open(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().RemoveAll(offlineExtractDir).Return(nil)
+	mockFS.EXPECT().MkdirAll(offlineExtractDir, gomock.Any()).Return(nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "tar", "-xf", "/media/usb/archup-bundle.tar", "-C", offlineExtractDir, "--strip-components=1").Return([]byte{}, nil)
+	mockFS.EXPECT().Exists(offlineExtractDir+"/"+bundle.MarkerFile).Return(true, nil)
+	mockFS.EXPECT().Exists("/media/usb/photos/"+bundle.MarkerFile).Return(false, nil)
+
+	handler := NewOfflineHandler(mockFS, mockExec, mockLogger)
+
+	b, err := handler.Open(context.Background(), "/media/usb/archup-bundle.tar")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if b.Root() != offlineExtractDir {
+		t.Errorf("expected extracted bundle, got %s", b.Root())
+	}
+
+	if _, err := handler.Open(context.Background(), "/media/usb/photos"); err == nil {
+		t.Error("expected error for directory without marker")
+	}
+}
+
+func TestOfflineHandler_AttachDetach(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	b, _ := bundle.NewBundle("/media/usb/archup-bundle")
+	original := []byte("Server = https://geo.mirror.pkgbuild.com/$repo/os/$arch\n")
+	repoMount := "/mnt/var/cache/archup-offline"
+	mirrorlist := "/mnt/etc/pacman.d/mirrorlist"
+
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	var hostConf []byte
+	mockFS.EXPECT().MkdirAll(offlineHostDir, gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile(offlineHostDir+"/mirrorlist", gomock.Any(), gomock.Any()).Return(nil)
+	mockFS.EXPECT().WriteFile(offlineHostDir+"/pacman.conf", gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ string, data []byte, _ os.FileMode) error {
+			hostConf = data
+			return nil
+		})
+
+	gomock.InOrder(
+		mockFS.EXPECT().MkdirAll(repoMount, gomock.Any()).Return(nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "--bind", "/media/usb/archup-bundle/repo", repoMount).Return([]byte{}, nil),
+		mockFS.EXPECT().ReadFile(mirrorlist).Return(original, nil),
+		mockFS.EXPECT().WriteFile(mirrorlist+".archup-offline", original, gomock.Any()).Return(nil),
+		mockFS.EXPECT().WriteFile(mirrorlist, []byte(bundle.Mirrorlist(bundle.TargetRepoDir)), gomock.Any()).Return(nil),
+
+		// Cleanup restores the mirrorlist before unmounting
+		mockFS.EXPECT().ReadFile(mirrorlist+".archup-offline").Return(original, nil),
+		mockFS.EXPECT().WriteFile(mirrorlist, original, gomock.Any()).Return(nil),
+		mockFS.EXPECT().RemoveAll(mirrorlist+".archup-offline").Return(nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "umount", repoMount).Return([]byte{}, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "rmdir", repoMount).Return([]byte{}, nil),
+		mockFS.EXPECT().RemoveAll(offlineHostDir).Return(nil),
+	)
+
+	handler := NewOfflineHandler(mockFS, mockExec, mockLogger)
+	cmd := commands.PrepareOfflineCommand{Bundle: b, MountPoint: "/mnt"}
+
+	result, err := handler.Handle(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.PacmanConf != offlineHostDir+"/pacman.conf" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(string(hostConf), "Include = "+offlineHostDir+"/mirrorlist") {
+		t.Errorf("expected host pacman.conf to use the bundle mirrorlist, got:\n%s", hostConf)
+	}
+
+	if err := handler.Attach(context.Background(), cmd, result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := handler.Cleanup(context.Background(), result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// A second cleanup (rollback after the cleanup phase) has nothing left to undo
+	if err := handler.Cleanup(context.Background(), result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
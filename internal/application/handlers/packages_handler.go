@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// Package search on the live host
+const (
+	hostCoreSyncDB   = "/var/lib/pacman/sync/core.db"
+	maxSearchResults = 50
+)
+
+// PackagesHandler serves the package choices offered by the installer
+type PackagesHandler struct {
+	fs      ports.FileSystem
+	assets  ports.AssetSource
+	cmdExec ports.CommandExecutor
+	logger  ports.Logger
+}
+
+// NewPackagesHandler creates a new packages handler
+func NewPackagesHandler(fs ports.FileSystem, assets ports.AssetSource, cmdExec ports.CommandExecutor, logger ports.Logger) *PackagesHandler {
+	return &PackagesHandler{
+		fs:      fs,
+		assets:  assets,
+		cmdExec: cmdExec,
+		logger:  logger,
+	}
+}
+
+// Profiles returns the package profiles of the install assets, before
+// bootstrap copies them to the install directory
+func (h *PackagesHandler) Profiles() (*packages.ProfileCatalog, error) {
+	content, err := h.assets.ReadAsset("install/" + config.ProfilesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", config.ProfilesFile, err)
+	}
+	catalog, err := packages.ParseProfileCatalog(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", config.ProfilesFile, err)
+	}
+	h.logger.Info("Loaded package profiles", "count", len(catalog.Profiles()))
+	return catalog, nil
+}
+
+// Search returns the packages of the host sync databases matching query,
+// the repositories pacstrap installs from. The live ISO ships without sync
+// databases, they are downloaded on the first search.
+func (h *PackagesHandler) Search(ctx context.Context, query string) ([]packages.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	if synced, _ := h.fs.Exists(hostCoreSyncDB); !synced {
+		h.logger.Info("Downloading the host sync databases for package search")
+		if _, err := h.cmdExec.Execute(ctx, "pacman", "-Sy", "--noconfirm"); err != nil {
+			return nil, fmt.Errorf("failed to sync host pacman databases: %w", err)
+		}
+	}
+
+	// pacman exits with an error when nothing matches
+	output, err := h.cmdExec.Execute(ctx, "pacman", "-Ss", "--", query)
+	if err != nil {
+		h.logger.Debug("No package found", "query", query, "error", err)
+		return nil, nil
+	}
+
+	results := packages.ParseSearchOutput(string(output))
+	if len(results) > maxSearchResults {
+		results = results[:maxSearchResults]
+	}
+	return results, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPackagesHandler_Search_SyncsDatabasesFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+
+	mockFS.EXPECT().Exists(hostCoreSyncDB).Return(false, nil)
+	gomock.InOrder(
+		mockExec.EXPECT().Execute(gomock.Any(), "pacman", "-Sy", "--noconfirm").Return([]byte{}, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "pacman", "-Ss", "--", "neovim").Return(
+			[]byte("extra/neovim 0.11.4-1\n    Fork of Vim aiming to improve user experience\n"), nil),
+	)
+
+	handler := NewPackagesHandler(mockFS, mockAssets, mockExec, mockLogger)
+	results, err := handler.Search(context.Background(), " neovim ")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "neovim" || results[0].Repository != "extra" {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestPackagesHandler_Search_NoMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Debug(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockFS.EXPECT().Exists(hostCoreSyncDB).Return(true, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "pacman", "-Ss", "--", "nosuchpackage").Return(nil, errors.New("exit status 1"))
+
+	handler := NewPackagesHandler(mockFS, mockAssets, mockExec, mockLogger)
+	results, err := handler.Search(context.Background(), "nosuchpackage")
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no result and no error, got %v %v", results, err)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// PacmanCacheHandler discovers a LAN package cache announced over DNS-SD
+type PacmanCacheHandler struct {
+	browser ports.ServiceBrowser
+	logger  ports.Logger
+}
+
+// NewPacmanCacheHandler creates a new pacman cache handler
+func NewPacmanCacheHandler(browser ports.ServiceBrowser, logger ports.Logger) *PacmanCacheHandler {
+	return &PacmanCacheHandler{
+		browser: browser,
+		logger:  logger,
+	}
+}
+
+// Discover returns the URL of the first pacman cache announced on the local
+// network. Caches publish the _pacman-cache._tcp service with an optional
+// "path" TXT value, e.g. "path=/repo" for pacoloco.
+func (h *PacmanCacheHandler) Discover(ctx context.Context) (string, error) {
+	h.logger.Info("Looking for a pacman cache on the local network", "service", packages.PacmanCacheService)
+
+	instances, err := h.browser.Browse(ctx, packages.PacmanCacheService)
+	if err != nil {
+		return "", fmt.Errorf("failed to browse %s: %w", packages.PacmanCacheService, err)
+	}
+	if len(instances) == 0 {
+		return "", fmt.Errorf("no %s service found on the local network", packages.PacmanCacheService)
+	}
+
+	instance := instances[0]
+	url := packages.PacmanCacheURL(instance.Host, instance.Port, instance.TXT["path"])
+	h.logger.Info("Found pacman cache", "name", instance.Name, "url", url)
+	return url, nil
+}
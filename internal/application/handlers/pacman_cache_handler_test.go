@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPacmanCacheHandler_Discover(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBrowser := mocks.NewMockServiceBrowser(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockBrowser.EXPECT().Browse(gomock.Any(), packages.PacmanCacheService).Return([]ports.ServiceInstance{
+		{Name: "pacoloco", Host: "192.168.1.20", Port: 9129, TXT: map[string]string{"path": "/repo"}},
+	}, nil)
+
+	handler := NewPacmanCacheHandler(mockBrowser, mockLogger)
+	url, err := handler.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if url != "http://192.168.1.20:9129/repo" {
+		t.Errorf("unexpected cache URL %s", url)
+	}
+}
+
+func TestPacmanCacheHandler_Discover_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBrowser := mocks.NewMockServiceBrowser(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockBrowser.EXPECT().Browse(gomock.Any(), packages.PacmanCacheService).Return(nil, nil)
+
+	handler := NewPacmanCacheHandler(mockBrowser, mockLogger)
+	if _, err := handler.Discover(context.Background()); err == nil {
+		t.Error("expected an error without an announced cache")
+	}
+}
@@ -109,12 +109,12 @@ func (h *PartitionHandler) Handle(ctx context.Context, cmd commands.PartitionDis
 	// Step 7: Mount filesystems
 	h.logger.Info("Mounting filesystems")
 	mounts, err := h.mountFilesystems(ctx, efiPartition, rootDevice, layout)
+	result.MountedAt = mounts
 	if err != nil {
 		h.logger.Error("Failed to mount filesystems", "error", err)
 		result.ErrorDetail = fmt.Sprintf("Failed to mount filesystems: %v", err)
 		return result, err
 	}
-	result.MountedAt = mounts
 
 	// Build partition info for result
 	result.Partitions = []*dto.PartitionInfo{
@@ -217,7 +217,7 @@ func (h *PartitionHandler) formatEFIPartition(ctx context.Context, partitionPath
 	}
 
 	// Format as FAT32 with label "EFI"
-	if _, err := h.cmdExec.Execute(ctx, "mkfs.fat", "-F32", "-n", "EFI", partitionPath); err != nil {
+	if _, err := h.cmdExec.Execute(ctx, "mkfs.fat", "-F32", "-n", disk.LabelEFI, partitionPath); err != nil {
 		return fmt.Errorf("mkfs.fat failed: %w", err)
 	}
 
@@ -256,7 +256,7 @@ func (h *PartitionHandler) setupLUKSEncryption(ctx context.Context, partitionPat
 		"--batch-mode",
 		"--pbkdf", "argon2id",
 		"--iter-time", "2000",
-		"--label", "ARCHUP_LUKS",
+		"--label", disk.LabelLUKS,
 		"--key-file=-",
 		partitionPath,
 	); err != nil {
@@ -283,7 +283,7 @@ func (h *PartitionHandler) formatRootPartition(ctx context.Context, devicePath s
 	h.logger.Info("Formatting root partition as Btrfs", "device", devicePath)
 
 	// Format as Btrfs with label "ROOT"
-	if _, err := h.cmdExec.Execute(ctx, "mkfs.btrfs", "-f", "-L", "ROOT", devicePath); err != nil {
+	if _, err := h.cmdExec.Execute(ctx, "mkfs.btrfs", "-f", "-L", disk.LabelRoot, devicePath); err != nil {
 		return fmt.Errorf("mkfs.btrfs failed: %w", err)
 	}
 
@@ -330,7 +330,8 @@ func (h *PartitionHandler) createBtrfsSubvolumes(ctx context.Context, devicePath
 	return subvolumes, nil
 }
 
-// mountFilesystems mounts all filesystems to /mnt with proper options
+// mountFilesystems mounts all filesystems to /mnt with proper options. On
+// failure it still returns the mount points mounted so far, for Rollback.
 func (h *PartitionHandler) mountFilesystems(ctx context.Context, efiPartition, rootDevice string, layout *disk.BtrfsLayout) ([]string, error) {
 	h.logger.Info("Mounting filesystems")
 
@@ -339,17 +340,17 @@ func (h *PartitionHandler) mountFilesystems(ctx context.Context, efiPartition, r
 	// Get root subvolume
 	rootSubvolume := layout.GetRootSubvolume()
 	if rootSubvolume == nil {
-		return nil, fmt.Errorf("no root subvolume found in layout")
+		return mounts, fmt.Errorf("no root subvolume found in layout")
 	}
 
 	// Mount @ subvolume as root with Btrfs options
 	rootMountOpts, err := disk.NewBtrfsMountOptions(rootSubvolume.Name())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create mount options: %w", err)
+		return mounts, fmt.Errorf("failed to create mount options: %w", err)
 	}
 
 	if _, err := h.cmdExec.Execute(ctx, "mount", "-o", rootMountOpts.ToString(), rootDevice, "/mnt"); err != nil {
-		return nil, fmt.Errorf("failed to mount @ subvolume: %w", err)
+		return mounts, fmt.Errorf("failed to mount @ subvolume: %w", err)
 	}
 	mounts = append(mounts, "/mnt")
 
@@ -358,27 +359,27 @@ func (h *PartitionHandler) mountFilesystems(ctx context.Context, efiPartition, r
 	if homeSubvolume != nil {
 		// Create /mnt/home directory
 		if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", "/mnt/home"); err != nil {
-			return nil, fmt.Errorf("failed to create /mnt/home: %w", err)
+			return mounts, fmt.Errorf("failed to create /mnt/home: %w", err)
 		}
 
 		homeMountOpts, err := disk.NewBtrfsMountOptions(homeSubvolume.Name())
 		if err != nil {
-			return nil, fmt.Errorf("failed to create home mount options: %w", err)
+			return mounts, fmt.Errorf("failed to create home mount options: %w", err)
 		}
 
 		if _, err := h.cmdExec.Execute(ctx, "mount", "-o", homeMountOpts.ToString(), rootDevice, "/mnt/home"); err != nil {
-			return nil, fmt.Errorf("failed to mount @home subvolume: %w", err)
+			return mounts, fmt.Errorf("failed to mount @home subvolume: %w", err)
 		}
 		mounts = append(mounts, "/mnt/home")
 	}
 
 	// Mount EFI partition to /mnt/boot
 	if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", "/mnt/boot"); err != nil {
-		return nil, fmt.Errorf("failed to create /mnt/boot: %w", err)
+		return mounts, fmt.Errorf("failed to create /mnt/boot: %w", err)
 	}
 
 	if _, err := h.cmdExec.Execute(ctx, "mount", efiPartition, "/mnt/boot"); err != nil {
-		return nil, fmt.Errorf("failed to mount EFI partition: %w", err)
+		return mounts, fmt.Errorf("failed to mount EFI partition: %w", err)
 	}
 	mounts = append(mounts, "/mnt/boot")
 
@@ -388,6 +389,9 @@ func (h *PartitionHandler) mountFilesystems(ctx context.Context, efiPartition, r
 
 // Rollback unmounts filesystems and closes LUKS device
 func (h *PartitionHandler) Rollback(ctx context.Context, result *dto.PartitionResult) error {
+	if result == nil {
+		return nil
+	}
 	h.logger.Warn("Rolling back partitioning changes")
 
 	// Unmount in reverse order
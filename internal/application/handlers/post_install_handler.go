@@ -5,33 +5,36 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/bnema/archup/internal/application/commands"
 	"github.com/bnema/archup/internal/application/dto"
 	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/packages"
 	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/snapshot"
 )
 
 // PostInstallHandler handles post-installation tasks
 type PostInstallHandler struct {
 	fs         ports.FileSystem
-	httpClient ports.HTTPClient
+	assets     ports.AssetSource
+	cmdExec    ports.CommandExecutor
 	chrExec    ports.ChrootExecutor
 	scriptExec ports.ScriptExecutor
 	logger     ports.Logger
-	rawURL     string
 }
 
 // NewPostInstallHandler creates a new post-installation handler
-func NewPostInstallHandler(fs ports.FileSystem, httpClient ports.HTTPClient, chrExec ports.ChrootExecutor, scriptExec ports.ScriptExecutor, logger ports.Logger, rawURL string) *PostInstallHandler {
+func NewPostInstallHandler(fs ports.FileSystem, assets ports.AssetSource, cmdExec ports.CommandExecutor, chrExec ports.ChrootExecutor, scriptExec ports.ScriptExecutor, logger ports.Logger) *PostInstallHandler {
 	return &PostInstallHandler{
 		fs:         fs,
-		httpClient: httpClient,
+		assets:     assets,
+		cmdExec:    cmdExec,
 		chrExec:    chrExec,
 		scriptExec: scriptExec,
 		logger:     logger,
-		rawURL:     rawURL,
 	}
 }
 
@@ -69,9 +72,24 @@ func (h *PostInstallHandler) Handle(ctx context.Context, cmd commands.PostInstal
 		h.logger.Info("Dank Linux flag file written")
 	}
 
-	// Setup limine-snapper-sync for btrfs snapshot bootability
-	if err := h.setupSnapperSync(ctx, cmd.MountPoint); err != nil {
-		h.logger.Warn("Failed to setup limine-snapper-sync", "error", err)
+	// Snapper configs must exist before limine-snapper-sync reads the root config
+	if len(cmd.SnapperConfigs) > 0 {
+		if err := h.configureSnapper(ctx, cmd.MountPoint, cmd.SnapperConfigs); err != nil {
+			h.logger.Warn("Failed to configure snapper", "error", err)
+		} else {
+			result.TasksRun = append(result.TasksRun, "snapper-configs")
+		}
+	}
+
+	// Setup limine-snapper-sync for btrfs snapshot bootability, it only
+	// follows the snapshots of the root config
+	hasRootConfig := slices.ContainsFunc(cmd.SnapperConfigs, func(c *snapshot.SnapperConfig) bool {
+		return c.Name() == snapshot.RootSnapperConfig
+	})
+	if hasRootConfig {
+		if err := h.setupSnapperSync(ctx, cmd.MountPoint); err != nil {
+			h.logger.Warn("Failed to setup limine-snapper-sync", "error", err)
+		}
 	}
 
 	// Install Plymouth theme if specified
@@ -84,9 +102,9 @@ func (h *PostInstallHandler) Handle(ctx context.Context, cmd commands.PostInstal
 		}
 
 		for _, file := range config.PlymouthFiles {
-			content, err := h.downloadTemplate(filepath.Join("assets", "plymouth", file))
+			content, err := h.assets.ReadAsset("assets/plymouth/" + file)
 			if err != nil {
-				return result, fmt.Errorf("failed to download Plymouth file %s: %w", file, err)
+				return result, fmt.Errorf("failed to read Plymouth file %s: %w", file, err)
 			}
 			if err := h.fs.WriteFile(filepath.Join(themeDir, file), content, 0644); err != nil {
 				return result, fmt.Errorf("failed to write Plymouth file %s: %w", file, err)
@@ -106,7 +124,7 @@ func (h *PostInstallHandler) Handle(ctx context.Context, cmd commands.PostInstal
 		h.logger.Info("Plymouth theme installed and initramfs rebuilt")
 	}
 
-	if err := h.tunePacmanConfig(cmd.MountPoint); err != nil {
+	if err := h.tunePacmanConfig(cmd.MountPoint, cmd.KeepPacmanCache); err != nil {
 		h.logger.Warn("Failed to tune pacman.conf", "error", err)
 	}
 
@@ -120,12 +138,48 @@ func (h *PostInstallHandler) Handle(ctx context.Context, cmd commands.PostInstal
 		h.logger.Warn("Post-install verification warnings", "warnings", result.VerificationWarnings)
 	}
 
+	// Capture the pristine installed system last, once nothing else writes to it
+	if cmd.RootDevice != "" {
+		if err := h.createBaselineSnapshot(ctx, cmd.RootDevice); err != nil {
+			h.logger.Warn("Failed to create baseline snapshot", "error", err)
+		} else {
+			result.BaselineSnapshot = snapshot.BaselineSubvolume
+			result.TasksRun = append(result.TasksRun, "baseline-snapshot")
+		}
+	}
+
 	h.logger.Info("Post-installation tasks completed")
 	result.Success = true
 
 	return result, nil
 }
 
+// createBaselineSnapshot takes a read-only snapshot of @ next to it at the top
+// level of the Btrfs filesystem, so `archup rollback --baseline` can restore it
+func (h *PostInstallHandler) createBaselineSnapshot(ctx context.Context, rootDevice string) error {
+	if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", btrfsTopLevel); err != nil {
+		return fmt.Errorf("failed to create mount point: %w", err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mount", "-o", "subvolid=5", rootDevice, btrfsTopLevel); err != nil {
+		return fmt.Errorf("failed to mount top-level subvolume: %w", err)
+	}
+	defer func() {
+		if _, err := h.cmdExec.Execute(ctx, "umount", btrfsTopLevel); err != nil {
+			h.logger.Warn("Failed to unmount top-level subvolume", "error", err)
+		}
+	}()
+
+	if _, err := h.cmdExec.Execute(ctx, "btrfs", "subvolume", "snapshot", "-r",
+		filepath.Join(btrfsTopLevel, snapshot.RootSubvolume),
+		filepath.Join(btrfsTopLevel, snapshot.BaselineSubvolume),
+	); err != nil {
+		return fmt.Errorf("failed to snapshot %s: %w", snapshot.RootSubvolume, err)
+	}
+
+	h.logger.Info("Baseline snapshot created", "subvolume", snapshot.BaselineSubvolume)
+	return nil
+}
+
 func (h *PostInstallHandler) setupPostBoot(ctx context.Context, mountPoint, username, email string) error {
 	postBootPath := filepath.Join(mountPoint, "usr", "local", "share", "archup", "post-boot")
 	if err := h.fs.MkdirAll(postBootPath, 0755); err != nil {
@@ -134,14 +188,14 @@ func (h *PostInstallHandler) setupPostBoot(ctx context.Context, mountPoint, user
 
 	// Write service file with placeholder replacement for email and username
 	serviceDest := filepath.Join(mountPoint, "etc", "systemd", "system", "archup-first-boot.service")
-	if err := h.writeServiceFile("install/mandatory/post-boot/archup-first-boot.service", serviceDest, username, email); err != nil {
+	if err := h.writeServiceFile(config.PostBootServiceTemplate, serviceDest, username, email); err != nil {
 		return fmt.Errorf("failed to write service file: %w", err)
 	}
 
 	for _, script := range config.PostBootScripts {
-		src := filepath.Join("install", "mandatory", "post-boot", script)
+		src := "install/mandatory/post-boot/" + script
 		dst := filepath.Join(postBootPath, script)
-		if err := h.writeFromTemplate(src, dst); err != nil {
+		if err := h.writeFromAsset(src, dst); err != nil {
 			return fmt.Errorf("failed to write %s: %w", script, err)
 		}
 		// Only set executable bit on shell scripts
@@ -159,13 +213,10 @@ func (h *PostInstallHandler) setupPostBoot(ctx context.Context, mountPoint, user
 	return nil
 }
 
-func (h *PostInstallHandler) writeFromTemplate(src string, dst string) error {
-	content, err := h.tryReadLocal(src)
+func (h *PostInstallHandler) writeFromAsset(src string, dst string) error {
+	content, err := h.assets.ReadAsset(src)
 	if err != nil {
-		content, err = h.downloadTemplate(src)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	return h.fs.WriteFile(dst, content, 0644)
@@ -173,12 +224,9 @@ func (h *PostInstallHandler) writeFromTemplate(src string, dst string) error {
 
 // writeServiceFile writes the systemd service file with placeholder replacement
 func (h *PostInstallHandler) writeServiceFile(src, dst, username, email string) error {
-	content, err := h.tryReadLocal(src)
+	content, err := h.assets.ReadAsset(src)
 	if err != nil {
-		content, err = h.downloadTemplate(src)
-		if err != nil {
-			return err
-		}
+		return err
 	}
 
 	// Replace placeholders with actual values
@@ -189,17 +237,6 @@ func (h *PostInstallHandler) writeServiceFile(src, dst, username, email string)
 	return h.fs.WriteFile(dst, []byte(serviceContent), 0644)
 }
 
-func (h *PostInstallHandler) tryReadLocal(path string) ([]byte, error) {
-	localPath := filepath.Join(config.DefaultInstallDir, path)
-	if exists, err := h.fs.Exists(localPath); err == nil && exists {
-		return h.fs.ReadFile(localPath)
-	}
-	if exists, err := h.fs.Exists(path); err == nil && exists {
-		return h.fs.ReadFile(path)
-	}
-	return nil, fmt.Errorf("local template not found")
-}
-
 // writeDankLinuxFlag writes a flag file to the installed system so that
 // dms-opt-in.sh auto-runs without prompting on first boot.
 func (h *PostInstallHandler) writeDankLinuxFlag(mountPoint string) error {
@@ -210,6 +247,53 @@ func (h *PostInstallHandler) writeDankLinuxFlag(mountPoint string) error {
 	return h.fs.WriteFile(flagPath, []byte(""), 0644)
 }
 
+// configureSnapper renders the snapper configs and creates their .snapshots
+// subvolumes, as `snapper create-config` would on a running system
+func (h *PostInstallHandler) configureSnapper(ctx context.Context, mountPoint string, configs []*snapshot.SnapperConfig) error {
+	configsDir := filepath.Join(mountPoint, "etc", "snapper", "configs")
+	if err := h.fs.MkdirAll(configsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create snapper config directory: %w", err)
+	}
+
+	timeline := false
+	for _, c := range configs {
+		if err := h.fs.WriteFile(filepath.Join(configsDir, c.Name()), []byte(c.Render()), 0640); err != nil {
+			return fmt.Errorf("failed to write snapper config %s: %w", c.Name(), err)
+		}
+
+		if exists, _ := h.fs.Exists(filepath.Join(mountPoint, c.SnapshotsPath())); !exists {
+			if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "btrfs", "subvolume", "create", c.SnapshotsPath()); err != nil {
+				return fmt.Errorf("failed to create %s subvolume: %w", c.SnapshotsPath(), err)
+			}
+		}
+		if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "chmod", "750", c.SnapshotsPath()); err != nil {
+			return fmt.Errorf("failed to set %s permissions: %w", c.SnapshotsPath(), err)
+		}
+
+		timeline = timeline || c.Timeline()
+		h.logger.Info("Snapper config written", "config", c.String())
+	}
+
+	confD := filepath.Join(mountPoint, "etc", "conf.d")
+	if err := h.fs.MkdirAll(confD, 0755); err != nil {
+		return fmt.Errorf("failed to create conf.d directory: %w", err)
+	}
+	if err := h.fs.WriteFile(filepath.Join(confD, "snapper"), []byte(snapshot.SnapperConfigsFile(configs)), 0644); err != nil {
+		return fmt.Errorf("failed to write snapper config list: %w", err)
+	}
+
+	timers := []string{"snapper-cleanup.timer"}
+	if timeline {
+		timers = append(timers, "snapper-timeline.timer")
+	}
+	for _, timer := range timers {
+		if err := h.chrExec.ChrootSystemctl(ctx, h.logger.LogPath(), mountPoint, "enable", timer); err != nil {
+			return fmt.Errorf("failed to enable %s: %w", timer, err)
+		}
+	}
+	return nil
+}
+
 func (h *PostInstallHandler) setupSnapperSync(ctx context.Context, mountPoint string) error {
 	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "pacman", "-S", "--noconfirm", "--needed", "limine-snapper-sync"); err != nil {
 		return fmt.Errorf("failed to install limine-snapper-sync: %w", err)
@@ -262,18 +346,15 @@ func (h *PostInstallHandler) installLimineHook(mountPoint, targetDisk string) er
 	if err := h.fs.MkdirAll(hooksDir, 0755); err != nil {
 		return fmt.Errorf("failed to create hooks dir: %w", err)
 	}
-	content, err := h.tryReadLocal("install/configs/limine-update.hook")
+	content, err := h.assets.ReadAsset("install/configs/limine-update.hook")
 	if err != nil {
-		content, err = h.downloadTemplate("install/configs/limine-update.hook")
-		if err != nil {
-			return fmt.Errorf("failed to get limine hook template: %w", err)
-		}
+		return fmt.Errorf("failed to get limine hook template: %w", err)
 	}
 	hookContent := strings.ReplaceAll(string(content), limineDiskPlaceholder, targetDisk)
 	return h.fs.WriteFile(filepath.Join(hooksDir, "limine-update.hook"), []byte(hookContent), 0644)
 }
 
-func (h *PostInstallHandler) tunePacmanConfig(mountPoint string) error {
+func (h *PostInstallHandler) tunePacmanConfig(mountPoint string, keepCache bool) error {
 	confPath := filepath.Join(mountPoint, "etc", "pacman.conf")
 	content, err := h.fs.ReadFile(confPath)
 	if err != nil {
@@ -283,6 +364,10 @@ func (h *PostInstallHandler) tunePacmanConfig(mountPoint string) error {
 	conf = uncommentPacmanOption(conf, "Color")
 	conf = uncommentPacmanOption(conf, "ParallelDownloads")
 	conf = uncommentPacmanOption(conf, "ILoveCandy")
+	// The LAN package cache of the install is usually not reachable later
+	if !keepCache {
+		conf = packages.PacmanConfWithoutCache(conf)
+	}
 	return h.fs.WriteFile(confPath, []byte(conf), 0644)
 }
 
@@ -294,11 +379,8 @@ func uncommentPacmanOption(conf, option string) string {
 
 func (h *PostInstallHandler) verifyInstallation(mountPoint string, encrypted bool) []string {
 	warnings := []string{}
-	checks := []struct{ path, name string }{
-		{filepath.Join(mountPoint, "etc", "fstab"), "fstab"},
-		{filepath.Join(mountPoint, "boot", "limine.conf"), "limine.conf"},
-		{filepath.Join(mountPoint, "boot", "EFI", "BOOT", "BOOTX64.EFI"), "EFI boot file"},
-	}
+	// Quick file checks only, `archup verify` runs the full check registry
+	checks := essentialBootFiles(mountPoint)
 	if encrypted {
 		checks = append(checks, struct{ path, name string }{filepath.Join(mountPoint, "etc", "crypttab"), "crypttab"})
 	}
@@ -309,21 +391,3 @@ func (h *PostInstallHandler) verifyInstallation(mountPoint string, encrypted boo
 	}
 	return warnings
 }
-
-func (h *PostInstallHandler) downloadTemplate(path string) ([]byte, error) {
-	url := fmt.Sprintf("%s/%s", h.rawURL, path)
-	h.logger.Info("Downloading template", "url", url)
-	resp, err := h.httpClient.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if closeErr := resp.Close(); closeErr != nil {
-			h.logger.Warn("Failed to close HTTP response", "error", closeErr)
-		}
-	}()
-	if resp.StatusCode() < 200 || resp.StatusCode() > 299 {
-		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode(), url)
-	}
-	return resp.Body(), nil
-}
@@ -3,30 +3,21 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/bnema/archup/internal/application/commands"
-	"github.com/bnema/archup/internal/domain/ports"
 	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/snapshot"
 	"go.uber.org/mock/gomock"
 )
 
-func newMockResponse(ctrl *gomock.Controller, statusCode int, body []byte) ports.Response {
-	resp := mocks.NewMockResponse(ctrl)
-	resp.EXPECT().StatusCode().Return(statusCode).AnyTimes()
-	resp.EXPECT().Body().Return(body).AnyTimes()
-	resp.EXPECT().Close().Return(nil).AnyTimes()
-	return resp
-}
-
 func TestPostInstallHandler_Handle_NoScripts(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -38,12 +29,12 @@ func TestPostInstallHandler_Handle_NoScripts(t *testing.T) {
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -72,7 +63,7 @@ func TestPostInstallHandler_Handle_WithScripts(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -85,12 +76,12 @@ func TestPostInstallHandler_Handle_WithScripts(t *testing.T) {
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Chmod(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -123,7 +114,7 @@ func TestPostInstallHandler_Handle_WithPlymouth(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -135,12 +126,12 @@ func TestPostInstallHandler_Handle_WithPlymouth(t *testing.T) {
 	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -173,7 +164,7 @@ func TestPostInstallHandler_Handle_WithDankLinux(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -186,12 +177,12 @@ func TestPostInstallHandler_Handle_WithDankLinux(t *testing.T) {
 	mockFS.EXPECT().WriteFile(gomock.Eq("/mnt/var/lib/archup-install-danklinux"), gomock.Any(), gomock.Any()).Return(nil)
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -225,7 +216,7 @@ func TestPostInstallHandler_Handle_TunesPacman(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -237,7 +228,7 @@ func TestPostInstallHandler_Handle_TunesPacman(t *testing.T) {
 	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
 	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
@@ -267,7 +258,7 @@ func TestPostInstallHandler_Handle_TunesPacman(t *testing.T) {
 	).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -292,7 +283,7 @@ func TestPostInstallHandler_Handle_InstallsLimineHook(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -308,7 +299,7 @@ func TestPostInstallHandler_Handle_InstallsLimineHook(t *testing.T) {
 	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte(hookTemplate)), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte(hookTemplate), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
@@ -329,7 +320,7 @@ func TestPostInstallHandler_Handle_InstallsLimineHook(t *testing.T) {
 	).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -359,7 +350,7 @@ func TestPostInstallHandler_Handle_EnablesSnapperSync(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -374,7 +365,7 @@ func TestPostInstallHandler_Handle_EnablesSnapperSync(t *testing.T) {
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 
 	// Track pacman install of limine-snapper-sync (specific expectation first)
 	mockChrExec.EXPECT().ExecuteInChroot(
@@ -398,13 +389,14 @@ func TestPostInstallHandler_Handle_EnablesSnapperSync(t *testing.T) {
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
 		Username:           "testuser",
 		RunPostBootScripts: false,
 		PlymouthTheme:      "",
+		SnapperConfigs:     snapshot.DefaultSnapperConfigs(),
 	}
 
 	result, err := handler.Handle(context.Background(), cmd)
@@ -426,12 +418,72 @@ func TestPostInstallHandler_Handle_EnablesSnapperSync(t *testing.T) {
 	}
 }
 
+func TestPostInstallHandler_Handle_NoSnapperSyncWithoutRootConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
+
+	// Track limine-snapper-sync install and enable (specific expectations first)
+	snapperSyncSetup := false
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), "pacman", gomock.Any(), gomock.Any(), gomock.Any(), "limine-snapper-sync").DoAndReturn(
+		func(ctx context.Context, mountPoint, command string, args ...string) ([]byte, error) {
+			snapperSyncSetup = true
+			return []byte{}, nil
+		}).AnyTimes()
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), "enable", "limine-snapper-sync.service").DoAndReturn(
+		func(ctx context.Context, logPath, chrootPath string, args ...string) error {
+			snapperSyncSetup = true
+			return nil
+		}).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	configs, err := snapshot.ParseSnapperConfigs("home:/home")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.PostInstallCommand{
+		MountPoint:     "/mnt",
+		Username:       "testuser",
+		SnapperConfigs: configs,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected success, got %s", result.ErrorDetail)
+	}
+	if snapperSyncSetup {
+		t.Error("expected limine-snapper-sync not to be set up without a root snapper config")
+	}
+}
+
 func TestPostInstallHandler_Handle_Everything(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -444,12 +496,12 @@ func TestPostInstallHandler_Handle_Everything(t *testing.T) {
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Chmod(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -492,7 +544,7 @@ func TestPostInstallHandler_Handle_RunsVerification(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -504,14 +556,14 @@ func TestPostInstallHandler_Handle_RunsVerification(t *testing.T) {
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// Stat returns nil (files exist) for the checked paths
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -599,7 +651,7 @@ OTHER_OPTION="keep"
 			defer ctrl.Finish()
 
 			mockFS := mocks.NewMockFileSystem(ctrl)
-			mockHTTP := mocks.NewMockHTTPClient(ctrl)
+			mockAssets := mocks.NewMockAssetSource(ctrl)
 			mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 			mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 			mockLogger := mocks.NewMockLogger(ctrl)
@@ -629,7 +681,7 @@ OTHER_OPTION="keep"
 			}
 			// config absent: no further calls
 
-			handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+			handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 			err := handler.sanitizeLimineSnapperSyncConfig(mountPoint)
 			if err != nil {
 				t.Fatalf("expected no error, got %v", err)
@@ -643,7 +695,7 @@ func TestPostInstallHandler_Handle_VerificationWarnsOnMissingFiles(t *testing.T)
 	defer ctrl.Finish()
 
 	mockFS := mocks.NewMockFileSystem(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
@@ -655,7 +707,7 @@ func TestPostInstallHandler_Handle_VerificationWarnsOnMissingFiles(t *testing.T)
 	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
 	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
@@ -663,7 +715,7 @@ func TestPostInstallHandler_Handle_VerificationWarnsOnMissingFiles(t *testing.T)
 	mockFS.EXPECT().Stat(gomock.Eq("/mnt/boot/limine.conf")).Return(nil, fmt.Errorf("not found"))
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
 
-	handler := NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
 
 	cmd := commands.PostInstallCommand{
 		MountPoint:         "/mnt",
@@ -698,3 +750,158 @@ func TestPostInstallHandler_Handle_VerificationWarnsOnMissingFiles(t *testing.T)
 		t.Errorf("expected warning about limine.conf, got: %v", result.VerificationWarnings)
 	}
 }
+
+func TestPostInstallHandler_Handle_BaselineSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	gomock.InOrder(
+		mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", "subvolid=5", "/dev/mapper/cryptroot", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "snapshot", "-r", "/run/archup-btrfs/@", "/run/archup-btrfs/@archup-baseline").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "umount", "/run/archup-btrfs").Return(nil, nil),
+	)
+
+	handler := NewPostInstallHandler(mockFS, mockAssets, mockExec, mockChrExec, mockScriptExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.PostInstallCommand{
+		MountPoint: "/mnt",
+		Username:   "testuser",
+		RootDevice: "/dev/mapper/cryptroot",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.BaselineSnapshot != "@archup-baseline" {
+		t.Errorf("expected baseline snapshot to be recorded, got %q", result.BaselineSnapshot)
+	}
+}
+
+func TestPostInstallHandler_Handle_BaselineSnapshotFailureIsNotFatal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("wrong fs type"))
+
+	handler := NewPostInstallHandler(mockFS, mockAssets, mockExec, mockChrExec, mockScriptExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.PostInstallCommand{
+		MountPoint: "/mnt",
+		Username:   "testuser",
+		RootDevice: "/dev/sda2",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.BaselineSnapshot != "" {
+		t.Errorf("expected success without baseline, got %+v", result)
+	}
+}
+
+func TestPostInstallHandler_Handle_SnapperConfigs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(false, nil).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("graphics: yes"), nil).AnyTimes()
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
+	// .snapshots subvolumes are created for every config
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "btrfs", "subvolume", "create", "/.snapshots").Return(nil, nil)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "btrfs", "subvolume", "create", "/home/.snapshots").Return(nil, nil)
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "chmod", "750", gomock.Any()).Return(nil, nil).Times(2)
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+
+	written := map[string]string{}
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(name string, data []byte, perm interface{}) error {
+		written[name] = string(data)
+		return nil
+	}).AnyTimes()
+
+	// Only root has timeline snapshots
+	enabled := []string{}
+	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), "enable", gomock.Any()).DoAndReturn(
+		func(ctx context.Context, logPath, chrootPath string, args ...string) error {
+			enabled = append(enabled, args[1])
+			return nil
+		}).AnyTimes()
+
+	configs, err := snapshot.ParseSnapperConfigs("root:/:timeline,hourly=3 home:/home:number=9")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	handler := NewPostInstallHandler(mockFS, mockAssets, mocks.NewMockCommandExecutor(ctrl), mockChrExec, mockScriptExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.PostInstallCommand{
+		MountPoint:     "/mnt",
+		Username:       "testuser",
+		SnapperConfigs: configs,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(strings.Join(result.TasksRun, ","), "snapper-configs") {
+		t.Errorf("expected snapper-configs task, got %v", result.TasksRun)
+	}
+	if !strings.Contains(written["/mnt/etc/snapper/configs/root"], `TIMELINE_LIMIT_HOURLY="3"`) {
+		t.Errorf("unexpected root config:\n%s", written["/mnt/etc/snapper/configs/root"])
+	}
+	if !strings.Contains(written["/mnt/etc/snapper/configs/home"], `NUMBER_LIMIT="9"`) {
+		t.Errorf("unexpected home config:\n%s", written["/mnt/etc/snapper/configs/home"])
+	}
+	if written["/mnt/etc/conf.d/snapper"] != "SNAPPER_CONFIGS=\"root home\"\n" {
+		t.Errorf("unexpected conf.d/snapper: %q", written["/mnt/etc/conf.d/snapper"])
+	}
+	if got := strings.Join(enabled, ","); !strings.Contains(got, "snapper-cleanup.timer") || !strings.Contains(got, "snapper-timeline.timer") {
+		t.Errorf("expected snapper timers enabled, got %v", enabled)
+	}
+}
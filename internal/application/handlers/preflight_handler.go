@@ -113,6 +113,16 @@ func (h *PreflightHandler) Handle(ctx context.Context, cmd commands.PreflightCom
 		h.logger.Warn("Could not detect Secure Boot status", "error", err)
 	}
 
+	// Warn on battery power, pacstrap interrupted by power loss leaves a broken system
+	if chassis, err := system.DetectChassis(ctx, h.fs); err == nil {
+		result.SystemInfo.Laptop = chassis.IsLaptop()
+		result.SystemInfo.OnBattery = chassis.OnBattery()
+		if chassis.OnBattery() {
+			result.Warnings = append(result.Warnings, "Running on battery power: connect the charger, an installation interrupted by power loss leaves the disk unbootable")
+			h.logger.Warn("Running on battery power")
+		}
+	}
+
 	// Check internet connectivity (try DNS)
 	if _, err := h.cmdExec.Execute(ctx, "ping", "-c", "1", "archlinux.org"); err != nil {
 		result.Warnings = append(result.Warnings, "Could not verify internet connectivity")
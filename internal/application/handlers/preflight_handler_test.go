@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"context"
+	"io/fs"
+	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/bnema/archup/internal/application/commands"
 	"github.com/bnema/archup/internal/domain/ports/mocks"
@@ -37,6 +41,8 @@ func TestPreflightHandler_Handle(t *testing.T) {
 			return false, nil
 		}
 	}).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), "id", "-u").Return([]byte("0\n"), nil).Times(1)
 	mockExec.EXPECT().Execute(gomock.Any(), "uname", "-m").Return([]byte("x86_64\n"), nil).Times(1)
 	mockExec.EXPECT().Execute(gomock.Any(), "bootctl", "status").Return([]byte("Secure Boot: disabled"), nil).Times(2)
@@ -86,6 +92,8 @@ func TestPreflightHandler_NotRoot(t *testing.T) {
 			return false, nil
 		}
 	}).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), "id", "-u").Return([]byte("1000\n"), nil).Times(1)
 	mockExec.EXPECT().Execute(gomock.Any(), "bootctl", "status").Return([]byte("Secure Boot: disabled"), nil).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
@@ -106,3 +114,66 @@ func TestPreflightHandler_NotRoot(t *testing.T) {
 		t.Error("expected critical errors to be recorded")
 	}
 }
+
+func TestPreflightHandler_OnBattery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Info(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).Return(true, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte("0\n"), nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
+
+	// A discharging laptop battery next to a wireless mouse battery
+	sysfs := fstest.MapFS{
+		"BAT0/type":       {Data: []byte("Battery\n")},
+		"BAT0/status":     {Data: []byte("Discharging\n")},
+		"hidpp_battery_0": {Mode: fs.ModeDir},
+	}
+	entries, err := fs.ReadDir(sysfs, ".")
+	if err != nil {
+		t.Fatalf("failed to list fake sysfs: %v", err)
+	}
+	mockFS.EXPECT().ReadDir("/sys/class/power_supply").Return(entries, nil)
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).DoAndReturn(func(path string) ([]byte, error) {
+		switch path {
+		case "/sys/class/power_supply/hidpp_battery_0/type":
+			return []byte("Battery\n"), nil
+		case "/sys/class/power_supply/hidpp_battery_0/scope":
+			return []byte("Device\n"), nil
+		}
+		if name, ok := strings.CutPrefix(path, "/sys/class/power_supply/"); ok {
+			return fs.ReadFile(sysfs, name)
+		}
+		return nil, os.ErrNotExist
+	}).AnyTimes()
+
+	handler := NewPreflightHandler(mockFS, mockExec, mockLogger)
+	result, err := handler.Handle(context.Background(), commands.PreflightCommand{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !result.SystemInfo.Laptop || !result.SystemInfo.OnBattery {
+		t.Errorf("expected a laptop on battery, got %+v", result.SystemInfo)
+	}
+	found := false
+	for _, warning := range result.Warnings {
+		found = found || strings.Contains(warning, "battery")
+	}
+	if !found {
+		t.Errorf("expected a battery warning, got %v", result.Warnings)
+	}
+}
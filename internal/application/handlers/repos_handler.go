@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"path/filepath"
@@ -73,32 +72,38 @@ func (h *ReposHandler) Handle(ctx context.Context, cmd commands.SetupRepositorie
 		}
 	}
 
-	// For CachyOS kernel: setup CachyOS repo BEFORE sync
-	if cmd.KernelVariant == packages.KernelCachyOS {
-		if err := h.setupCachyOSRepo(ctx, cmd.MountPoint); err != nil {
-			return fail("Failed to setup CachyOS repository", err)
-		}
+	kernels, err := packages.NewKernelSet(cmd.KernelVariant, cmd.ExtraKernels...)
+	if err != nil {
+		return fail("Invalid kernel variant", err)
 	}
 
-	// Chaotic-AUR: always enabled — install keyring and mirrorlist BEFORE adding to pacman.conf
-	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman-key", "--recv-key", "3056513887B78AEB", "--keyserver", "keyserver.ubuntu.com"); err != nil {
-		return fail("Failed to receive Chaotic-AUR key", err)
-	}
-	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman-key", "--lsign-key", "3056513887B78AEB"); err != nil {
-		return fail("Failed to sign Chaotic-AUR key", err)
-	}
-	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", "-U", "--noconfirm",
-		"https://cdn-mirror.chaotic.cx/chaotic-aur/chaotic-keyring.pkg.tar.zst",
-		"https://cdn-mirror.chaotic.cx/chaotic-aur/chaotic-mirrorlist.pkg.tar.zst"); err != nil {
-		return fail("Failed to install Chaotic-AUR keyring", err)
-	}
+	// The CachyOS and Chaotic-AUR repositories need the network, an offline
+	// install only has the official repositories of the bundle
+	if cmd.Offline {
+		h.logger.Warn("Offline install: skipping CachyOS and Chaotic-AUR repositories")
+	} else {
+		// For CachyOS kernel: setup CachyOS repo BEFORE sync
+		if kernels.Contains(packages.KernelCachyOS) {
+			if err := h.setupCachyOSRepo(ctx, cmd.MountPoint); err != nil {
+				return fail("Failed to setup CachyOS repository", err)
+			}
+		}
 
-	confBytes, err := h.fs.ReadFile(pacmanConfPath)
-	if err != nil {
-		return fail("Failed to read pacman.conf", err)
+		if err := h.setupChaoticRepo(ctx, cmd.MountPoint, pacmanConfPath); err != nil {
+			return fail("Failed to setup Chaotic-AUR repository", err)
+		}
 	}
-	if err := h.fs.WriteFile(pacmanConfPath, []byte(ensureChaoticRepo(string(confBytes))), 0644); err != nil {
-		return fail("Failed to write pacman.conf", err)
+
+	// Serve every repository enabled above through the LAN package cache
+	if cmd.PacmanCache != nil {
+		confBytes, err := h.fs.ReadFile(pacmanConfPath)
+		if err != nil {
+			return fail("Failed to read pacman.conf", err)
+		}
+		conf := packages.PacmanConfWithCache(string(confBytes), cmd.PacmanCache)
+		if err := h.fs.WriteFile(pacmanConfPath, []byte(conf), 0644); err != nil {
+			return fail("Failed to write pacman.conf", err)
+		}
 	}
 
 	// Sync all repos
@@ -107,64 +112,148 @@ func (h *ReposHandler) Handle(ctx context.Context, cmd commands.SetupRepositorie
 	}
 
 	// Install AUR helper from Chaotic-AUR
-	if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", "-S", "--noconfirm", repo.AURHelper().String()); err != nil {
+	if cmd.Offline {
+		h.logger.Warn("Offline install: skipping AUR helper", "aurHelper", repo.AURHelper())
+	} else if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", "-S", "--noconfirm", repo.AURHelper().String()); err != nil {
 		return fail("Failed to install AUR helper", err)
 	}
 
-	// Install extra packages from extra.packages file
-	extraPkgs, err := h.loadExtraPackages()
+	// Install the packages of the selected profiles
+	profilePkgs, err := h.loadProfilePackages(cmd.PackageProfiles)
 	if err != nil {
-		h.logger.Warn("Could not load extra.packages", "error", err)
-	} else if len(extraPkgs) > 0 {
-		h.logger.Info("Installing extra packages", "count", len(extraPkgs))
-		args := append([]string{"-S", "--noconfirm", "--needed"}, extraPkgs...)
+		return fail("Invalid package profiles", err)
+	}
+	if len(profilePkgs) > 0 {
+		// DKMS modules (e.g. broadcom-wl-dkms) are built against every installed kernel
+		if packages.RequiresDKMS(profilePkgs) {
+			profilePkgs = kernels.WithHeaders(profilePkgs)
+			h.logger.Info("Adding kernel headers for DKMS modules", "headers", kernels.HeadersPackages())
+		}
+		h.logger.Info("Installing profile packages", "count", len(profilePkgs))
+		args := append([]string{"-S", "--noconfirm", "--needed"}, profilePkgs...)
 		if _, err := h.chrExec.ExecuteInChroot(ctx, cmd.MountPoint, "pacman", args...); err != nil {
-			return fail("Failed to install extra packages", err)
+			return fail("Failed to install profile packages", err)
 		}
-		h.logger.Info("Extra packages installed successfully")
+		h.logger.Info("Profile packages installed successfully")
+	}
 
-		// Enable power-profiles-daemon after it has been installed via extra.packages
-		if err := h.chrExec.ChrootSystemctl(ctx, "", cmd.MountPoint, "enable", "power-profiles-daemon.service"); err != nil {
-			h.logger.Warn("Failed to enable power-profiles-daemon", "error", err)
+	// AUR packages are built by the AUR helper, which needs the network
+	switch {
+	case len(cmd.AURPackages) == 0:
+	case cmd.Offline:
+		h.logger.Warn("Offline install: skipping AUR packages", "packages", cmd.AURPackages)
+	default:
+		if err := packages.ValidatePackageNames(cmd.AURPackages); err != nil {
+			return fail("Invalid AUR packages", err)
+		}
+		// A failed AUR build leaves a working system, the packages can be
+		// installed after the first boot
+		if err := h.installAURPackages(ctx, cmd.MountPoint, cmd.Username, repo.AURHelper(), cmd.AURPackages); err != nil {
+			h.logger.Warn("Failed to install AUR packages", "packages", cmd.AURPackages, "error", err)
+			result.AURFailed = cmd.AURPackages
+		} else {
+			result.AURPackages = cmd.AURPackages
 		}
 	}
 
 	result.Success = true
-	result.AURHelper = repo.AURHelper().String()
+	if !cmd.Offline {
+		result.AURHelper = repo.AURHelper().String()
+	}
 
 	h.logger.Info("Repository configuration completed successfully")
 	return result, nil
 }
 
-// loadExtraPackages reads the extra package list from file
-func (h *ReposHandler) loadExtraPackages() ([]string, error) {
-	packageFile := filepath.Join(config.DefaultInstallDir, config.ExtraPackagesFile)
+// setupChaoticRepo installs the Chaotic-AUR keyring and mirrorlist, then adds
+// the repository to pacman.conf
+func (h *ReposHandler) setupChaoticRepo(ctx context.Context, mountPoint, pacmanConfPath string) error {
+	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "pacman-key", "--recv-key", "3056513887B78AEB", "--keyserver", "keyserver.ubuntu.com"); err != nil {
+		return fmt.Errorf("failed to receive Chaotic-AUR key: %w", err)
+	}
+	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "pacman-key", "--lsign-key", "3056513887B78AEB"); err != nil {
+		return fmt.Errorf("failed to sign Chaotic-AUR key: %w", err)
+	}
+	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "pacman", "-U", "--noconfirm",
+		"https://cdn-mirror.chaotic.cx/chaotic-aur/chaotic-keyring.pkg.tar.zst",
+		"https://cdn-mirror.chaotic.cx/chaotic-aur/chaotic-mirrorlist.pkg.tar.zst"); err != nil {
+		return fmt.Errorf("failed to install Chaotic-AUR keyring: %w", err)
+	}
 
-	content, err := h.fs.ReadFile(packageFile)
+	confBytes, err := h.fs.ReadFile(pacmanConfPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read %s: %w", packageFile, err)
+		return fmt.Errorf("failed to read pacman.conf: %w", err)
 	}
+	if err := h.fs.WriteFile(pacmanConfPath, []byte(ensureChaoticRepo(string(confBytes))), 0644); err != nil {
+		return fmt.Errorf("failed to write pacman.conf: %w", err)
+	}
+	return nil
+}
 
-	var pkgs []string
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+// aurSudoersFile lets the user the AUR packages are built as run pacman
+// without a password while the AUR helper installs them
+const aurSudoersFile = "etc/sudoers.d/zz-archup-aur"
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+// installAURPackages builds and installs AUR packages as username with the
+// AUR helper, makepkg refuses to run as root
+func (h *ReposHandler) installAURPackages(ctx context.Context, mountPoint, username string, helper packages.AURHelper, pkgs []string) error {
+	if username == "" {
+		return fmt.Errorf("no user to build AUR packages as")
+	}
 
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	h.logger.Info("Building AUR packages", "user", username, "aurHelper", helper.String(), "packages", pkgs)
+	sudoersPath := filepath.Join(mountPoint, aurSudoersFile)
+	if err := h.fs.WriteFile(sudoersPath, []byte(username+" ALL=(ALL) NOPASSWD: ALL\n"), config.SudoersWheelPerms); err != nil {
+		return fmt.Errorf("failed to write AUR sudoers drop-in: %w", err)
+	}
+	defer func() {
+		if err := h.fs.RemoveAll(sudoersPath); err != nil {
+			h.logger.Error("Failed to remove AUR sudoers drop-in, remove it manually", "path", sudoersPath, "error", err)
 		}
+	}()
 
-		pkgs = append(pkgs, line)
+	args := append([]string{"-u", username, "-H", helper.String()}, helper.InstallArgs(pkgs)...)
+	if _, err := h.chrExec.ExecuteInChroot(ctx, mountPoint, "sudo", args...); err != nil {
+		return fmt.Errorf("%s failed: %w", helper.String(), err)
 	}
+	return nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading package file: %w", err)
+// loadProfilePackages resolves the packages of the selected profiles, the
+// default profiles when none are given. A missing profiles file installs
+// nothing, an invalid selection is an error.
+func (h *ReposHandler) loadProfilePackages(names []string) ([]string, error) {
+	profileFile := filepath.Join(config.DefaultInstallDir, config.ProfilesFile)
+
+	catalog, err := readProfileCatalog(h.fs, profileFile)
+	if err != nil {
+		h.logger.Warn("Could not load profiles.packages", "error", err)
+		return nil, nil
+	}
+	if names == nil {
+		names = catalog.DefaultSelection()
+	}
+
+	selection, err := catalog.Resolve(names)
+	if err != nil {
+		return nil, err
 	}
 
-	h.logger.Info("Loaded extra packages from file", "file", packageFile, "count", len(pkgs))
-	return pkgs, nil
+	h.logger.Info("Resolved package profiles", "profiles", selection.Profiles(), "count", len(selection.Packages()))
+	return selection.Packages(), nil
+}
+
+// readProfileCatalog reads and parses a profiles file
+func readProfileCatalog(fs ports.FileSystem, profileFile string) (*packages.ProfileCatalog, error) {
+	content, err := fs.ReadFile(profileFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", profileFile, err)
+	}
+	catalog, err := packages.ParseProfileCatalog(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", profileFile, err)
+	}
+	return catalog, nil
 }
 
 func enableMultilibSection(conf string) string {
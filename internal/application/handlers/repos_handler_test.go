@@ -39,7 +39,7 @@ func TestReposHandler_Handle_Minimal(t *testing.T) {
 	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
 
 	chaoticMocks(mockChrExec, mockFS)
-	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("extra.packages")).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
 
 	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
 
@@ -84,7 +84,7 @@ func TestReposHandler_Handle_MultilibEnabled(t *testing.T) {
 
 	chaoticMocks(mockChrExec, mockFS)
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), "pacman", "-S", "--noconfirm", "--needed", gomock.Any()).Return([]byte{}, nil).AnyTimes()
-	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("extra.packages")).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
 
 	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
 
@@ -125,7 +125,7 @@ func TestReposHandler_Handle_CachyOSKernel_AddsCachyOSRepo(t *testing.T) {
 	mockFS.EXPECT().ReadFile("/mnt/etc/pacman.conf").Return(
 		[]byte("[core]\nInclude = /etc/pacman.d/mirrorlist\n"), nil,
 	).AnyTimes()
-	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("extra.packages")).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
 
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
@@ -190,7 +190,7 @@ func TestReposHandler_Handle_WithAdditionalRepos(t *testing.T) {
 	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
 
 	chaoticMocks(mockChrExec, mockFS)
-	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("extra.packages")).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
 
 	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
 
@@ -212,3 +212,193 @@ func TestReposHandler_Handle_WithAdditionalRepos(t *testing.T) {
 		t.Error("expected success")
 	}
 }
+
+func TestReposHandler_Handle_DKMSProfilePackagesAddKernelHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	chaoticMocks(mockChrExec, mockFS)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("[wifi]\ndefault = yes\ngit\nbroadcom-wl-dkms\n"), nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-S", "--noconfirm", "--needed",
+		"git", "broadcom-wl-dkms", "linux-zen-headers").Return([]byte{}, nil)
+
+	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
+
+	cmd := commands.SetupRepositoriesCommand{
+		MountPoint:    "/mnt",
+		AURHelper:     packages.AURHelperParu,
+		KernelVariant: packages.KernelZen,
+	}
+
+	result, err := handler.Handle(context.Background(), cmd)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Error("expected success")
+	}
+}
+
+func TestReposHandler_Handle_OfflineSkipsNetworkRepos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+
+	// Only the bundle repositories are synced: no Chaotic-AUR key, no AUR helper
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "pacman", "-Sy", "--noconfirm").Return([]byte{}, nil)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
+
+	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
+
+	cmd := commands.SetupRepositoriesCommand{
+		MountPoint:    "/mnt",
+		AURHelper:     packages.AURHelperParu,
+		KernelVariant: packages.KernelCachyOS,
+		Offline:       true,
+	}
+
+	result, err := handler.Handle(context.Background(), cmd)
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Error("expected success")
+	}
+	if result.AURHelper != "" {
+		t.Errorf("expected no AUR helper offline, got %s", result.AURHelper)
+	}
+}
+
+func TestReposHandler_Handle_ConflictingProfiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	chaoticMocks(mockChrExec, mockFS)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("[server]\nconflicts = desktop\ntmux\n\n[desktop]\ngtk4\n"), nil).AnyTimes()
+
+	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.SetupRepositoriesCommand{
+		MountPoint:      "/mnt",
+		AURHelper:       packages.AURHelperParu,
+		KernelVariant:   packages.KernelStable,
+		PackageProfiles: []string{"server", "desktop"},
+	})
+
+	if err == nil {
+		t.Fatal("expected an error for conflicting profiles")
+	}
+	if result.Success {
+		t.Error("expected failure")
+	}
+}
+
+func TestReposHandler_Handle_AURPackagesBuiltAsUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	chaoticMocks(mockChrExec, mockFS)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
+
+	// The sudoers drop-in only exists while the AUR helper runs
+	gomock.InOrder(
+		mockFS.EXPECT().WriteFile("/mnt/etc/sudoers.d/zz-archup-aur", []byte("alice ALL=(ALL) NOPASSWD: ALL\n"), gomock.Any()).Return(nil),
+		mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "sudo", "-u", "alice", "-H", "paru",
+			"-S", "--noconfirm", "--needed", "--skipreview", "visual-studio-code-bin").Return([]byte{}, nil),
+		mockFS.EXPECT().RemoveAll("/mnt/etc/sudoers.d/zz-archup-aur").Return(nil),
+	)
+
+	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.SetupRepositoriesCommand{
+		MountPoint:    "/mnt",
+		AURHelper:     packages.AURHelperParu,
+		KernelVariant: packages.KernelStable,
+		AURPackages:   []string{"visual-studio-code-bin"},
+		Username:      "alice",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.AURPackages) != 1 {
+		t.Errorf("expected the AUR package in the result, got %v", result.AURPackages)
+	}
+}
+
+func TestReposHandler_Handle_AURPackagesBuildFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+
+	chaoticMocks(mockChrExec, mockFS)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, errNotFound("profiles.packages")).AnyTimes()
+
+	// The sudoers drop-in is removed even when the build fails
+	gomock.InOrder(
+		mockFS.EXPECT().WriteFile("/mnt/etc/sudoers.d/zz-archup-aur", gomock.Any(), gomock.Any()).Return(nil),
+		mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "sudo", "-u", "alice", "-H", "paru",
+			"-S", "--noconfirm", "--needed", "--skipreview", "spotify").Return(nil, fmt.Errorf("build failed")),
+		mockFS.EXPECT().RemoveAll("/mnt/etc/sudoers.d/zz-archup-aur").Return(nil),
+	)
+
+	handler := NewReposHandler(mockFS, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.SetupRepositoriesCommand{
+		MountPoint:    "/mnt",
+		AURHelper:     packages.AURHelperParu,
+		KernelVariant: packages.KernelStable,
+		AURPackages:   []string{"spotify"},
+		Username:      "alice",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected a failed AUR build not to fail the setup, got %s", result.ErrorDetail)
+	}
+	if len(result.AURPackages) != 0 {
+		t.Errorf("expected no installed AUR package, got %v", result.AURPackages)
+	}
+	if len(result.AURFailed) != 1 || result.AURFailed[0] != "spotify" {
+		t.Errorf("expected the failed AUR package in the result, got %v", result.AURFailed)
+	}
+}
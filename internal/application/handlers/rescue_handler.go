@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/disk"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// RescueHandler re-opens and mounts an existing install from the live ISO,
+// mirroring the layout PartitionHandler created
+type RescueHandler struct {
+	fs      ports.FileSystem
+	cmdExec ports.CommandExecutor
+	logger  ports.Logger
+}
+
+// NewRescueHandler creates a new rescue handler
+func NewRescueHandler(fs ports.FileSystem, cmdExec ports.CommandExecutor, logger ports.Logger) *RescueHandler {
+	return &RescueHandler{
+		fs:      fs,
+		cmdExec: cmdExec,
+		logger:  logger,
+	}
+}
+
+// Detect finds the partitions of an existing install by label, on targetDisk or on all disks
+func (h *RescueHandler) Detect(ctx context.Context, targetDisk string) (*disk.InstallDevices, error) {
+	args := []string{"-P", "-p", "-o", "NAME,FSTYPE,LABEL,PARTLABEL"}
+	if targetDisk != "" {
+		if err := disk.ValidateDiskPath(targetDisk); err != nil {
+			return nil, fmt.Errorf("invalid disk path: %w", err)
+		}
+		args = append(args, targetDisk)
+	}
+
+	output, err := h.cmdExec.Execute(ctx, "lsblk", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block devices: %w", err)
+	}
+
+	devices, err := disk.FindInstallDevices(parseLsblkPairs(string(output)))
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.Info("Found existing install", "devices", devices.String())
+	return devices, nil
+}
+
+// lsblkPairPattern matches a KEY="value" pair of `lsblk -P` output
+var lsblkPairPattern = regexp.MustCompile(`([A-Z]+)="([^"]*)"`)
+
+// parseLsblkPairs parses `lsblk -P -o NAME,FSTYPE,LABEL,PARTLABEL` output
+func parseLsblkPairs(output string) []disk.BlockDevice {
+	var devices []disk.BlockDevice
+	for _, line := range strings.Split(output, "\n") {
+		pairs := lsblkPairPattern.FindAllStringSubmatch(line, -1)
+		if len(pairs) == 0 {
+			continue
+		}
+
+		var d disk.BlockDevice
+		for _, pair := range pairs {
+			switch pair[1] {
+			case "NAME":
+				d.Path = pair[2]
+			case "FSTYPE":
+				d.FSType = pair[2]
+			case "LABEL":
+				d.Label = pair[2]
+			case "PARTLABEL":
+				d.PartLabel = pair[2]
+			}
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// Mount opens the LUKS container if needed and mounts @, @home and the ESP
+func (h *RescueHandler) Mount(ctx context.Context, cmd commands.RescueMountCommand) (*dto.RescueResult, error) {
+	result := &dto.RescueResult{
+		Success:   false,
+		MountedAt: []string{},
+	}
+
+	fail := func(message string, err error) (*dto.RescueResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		h.Unmount(ctx, result)
+		return result, err
+	}
+
+	if cmd.Devices == nil {
+		return fail("Invalid rescue command", errors.New("no install devices"))
+	}
+	result.EFIPartition = cmd.Devices.EFI
+	result.RootDevice = cmd.Devices.RootDevice()
+
+	// Refuse to stack mounts over a previous session
+	if _, err := h.cmdExec.Execute(ctx, "mountpoint", "-q", cmd.MountPoint); err == nil {
+		err := fmt.Errorf("%s is already mounted, run archup cleanup first", cmd.MountPoint)
+		h.logger.Error("Cannot mount install", "error", err)
+		result.ErrorDetail = err.Error()
+		return result, err
+	}
+
+	if cmd.Devices.Encrypted() {
+		opened, err := h.openLUKS(ctx, cmd.Devices, cmd.Passphrase)
+		if err != nil {
+			return fail("Failed to open LUKS container", err)
+		}
+		// A mapping opened by someone else is left open by Unmount
+		if opened {
+			result.CryptDevice = result.RootDevice
+		}
+	}
+
+	mounts := []struct {
+		subvolume string
+		target    string
+	}{
+		{"@", cmd.MountPoint},
+		{"@home", filepath.Join(cmd.MountPoint, "home")},
+	}
+	for _, m := range mounts {
+		opts, err := disk.NewBtrfsMountOptions(m.subvolume)
+		if err != nil {
+			return fail("Failed to create mount options", err)
+		}
+		if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", m.target); err != nil {
+			return fail("Failed to create mount point", err)
+		}
+		if _, err := h.cmdExec.Execute(ctx, "mount", "-o", opts.ToString(), result.RootDevice, m.target); err != nil {
+			return fail(fmt.Sprintf("Failed to mount %s subvolume", m.subvolume), err)
+		}
+		result.MountedAt = append(result.MountedAt, m.target)
+	}
+
+	bootTarget := filepath.Join(cmd.MountPoint, "boot")
+	if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", bootTarget); err != nil {
+		return fail("Failed to create mount point", err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mount", cmd.Devices.EFI, bootTarget); err != nil {
+		return fail("Failed to mount EFI partition", err)
+	}
+	result.MountedAt = append(result.MountedAt, bootTarget)
+
+	result.Success = true
+	h.logger.Info("Existing install mounted", "mounts", result.MountedAt)
+	return result, nil
+}
+
+// openLUKS opens the LUKS container, reusing an already opened mapping.
+// It returns true if this call opened the container.
+func (h *RescueHandler) openLUKS(ctx context.Context, devices *disk.InstallDevices, passphrase string) (bool, error) {
+	if exists, _ := h.fs.Exists(devices.RootDevice()); exists {
+		h.logger.Info("LUKS container already open", "device", devices.RootDevice())
+		return false, nil
+	}
+
+	if passphrase == "" {
+		return false, errors.New("passphrase required for encrypted install")
+	}
+
+	// Feed the passphrase over stdin so shell metacharacters are treated as data.
+	if _, err := h.cmdExec.ExecuteWithStdin(ctx, passphrase, "cryptsetup",
+		"open",
+		"--key-file=-",
+		devices.LUKS,
+		disk.CryptMapperName,
+	); err != nil {
+		return false, fmt.Errorf("cryptsetup open failed (wrong passphrase?): %w", err)
+	}
+	return true, nil
+}
+
+// Unmount unmounts what Mount mounted, in reverse order, and closes the LUKS container
+func (h *RescueHandler) Unmount(ctx context.Context, result *dto.RescueResult) {
+	for i := len(result.MountedAt) - 1; i >= 0; i-- {
+		if _, err := h.cmdExec.Execute(ctx, "umount", result.MountedAt[i]); err != nil {
+			h.logger.Warn("Failed to unmount", "mount", result.MountedAt[i], "error", err)
+		}
+	}
+	result.MountedAt = []string{}
+
+	if result.CryptDevice != "" {
+		if _, err := h.cmdExec.Execute(ctx, "cryptsetup", "close", disk.CryptMapperName); err != nil {
+			h.logger.Warn("Failed to close LUKS device", "error", err)
+		}
+		result.CryptDevice = ""
+	}
+}
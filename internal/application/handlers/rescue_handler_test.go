@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/disk"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const lsblkOutput = `NAME="/dev/nvme0n1" FSTYPE="" LABEL="" PARTLABEL=""
+NAME="/dev/nvme0n1p1" FSTYPE="vfat" LABEL="EFI" PARTLABEL="EFI"
+NAME="/dev/nvme0n1p2" FSTYPE="crypto_LUKS" LABEL="ARCHUP_LUKS" PARTLABEL="ROOT"
+`
+
+func TestRescueHandler_Detect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "lsblk", "-P", "-p", "-o", "NAME,FSTYPE,LABEL,PARTLABEL", "/dev/nvme0n1").
+		Return([]byte(lsblkOutput), nil)
+
+	handler := NewRescueHandler(mockFS, mockExec, mockLogger)
+
+	devices, err := handler.Detect(context.Background(), "/dev/nvme0n1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if devices.EFI != "/dev/nvme0n1p1" || devices.LUKS != "/dev/nvme0n1p2" {
+		t.Errorf("unexpected devices: %s", devices)
+	}
+}
+
+func TestRescueHandler_Mount_Encrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mountpoint", "-q", "/mnt").Return(nil, errors.New("not a mountpoint"))
+	mockFS.EXPECT().Exists("/dev/mapper/cryptroot").Return(false, nil)
+	mockExec.EXPECT().ExecuteWithStdin(gomock.Any(), "secret", "cryptsetup", "open", "--key-file=-", "/dev/sda2", "cryptroot").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil).Times(3)
+	gomock.InOrder(
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", gomock.Any(), "/dev/mapper/cryptroot", "/mnt").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", gomock.Any(), "/dev/mapper/cryptroot", "/mnt/home").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "/dev/sda1", "/mnt/boot").Return(nil, nil),
+	)
+
+	handler := NewRescueHandler(mockFS, mockExec, mockLogger)
+
+	result, err := handler.Mount(context.Background(), commands.RescueMountCommand{
+		MountPoint: "/mnt",
+		Devices:    &disk.InstallDevices{EFI: "/dev/sda1", LUKS: "/dev/sda2"},
+		Passphrase: "secret",
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || len(result.MountedAt) != 3 || result.CryptDevice != "/dev/mapper/cryptroot" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRescueHandler_Mount_FailureTearsDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mountpoint", "-q", "/mnt").Return(nil, errors.New("not a mountpoint"))
+	mockFS.EXPECT().Exists("/dev/mapper/cryptroot").Return(false, nil)
+	mockExec.EXPECT().ExecuteWithStdin(gomock.Any(), "secret", "cryptsetup", "open", "--key-file=-", "/dev/sda2", "cryptroot").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", gomock.Any(), "/dev/mapper/cryptroot", "/mnt").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", gomock.Any(), "/dev/mapper/cryptroot", "/mnt/home").Return(nil, errors.New("no such subvolume"))
+	// Teardown: unmount @ and close the container
+	mockExec.EXPECT().Execute(gomock.Any(), "umount", "/mnt").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "cryptsetup", "close", "cryptroot").Return(nil, nil)
+
+	handler := NewRescueHandler(mockFS, mockExec, mockLogger)
+
+	result, err := handler.Mount(context.Background(), commands.RescueMountCommand{
+		MountPoint: "/mnt",
+		Devices:    &disk.InstallDevices{EFI: "/dev/sda1", LUKS: "/dev/sda2"},
+		Passphrase: "secret",
+	})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.Success || len(result.MountedAt) != 0 {
+		t.Errorf("expected torn down result, got %+v", result)
+	}
+}
+
+func TestRescueHandler_Mount_KeepsOpenContainer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mountpoint", "-q", "/mnt").Return(nil, errors.New("not a mountpoint"))
+	// Opened by another session: no cryptsetup open, and no close on teardown
+	mockFS.EXPECT().Exists("/dev/mapper/cryptroot").Return(true, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", gomock.Any(), "/dev/mapper/cryptroot", "/mnt").Return(nil, errors.New("no such subvolume"))
+
+	handler := NewRescueHandler(mockFS, mockExec, mockLogger)
+
+	result, err := handler.Mount(context.Background(), commands.RescueMountCommand{
+		MountPoint: "/mnt",
+		Devices:    &disk.InstallDevices{EFI: "/dev/sda1", LUKS: "/dev/sda2"},
+	})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.CryptDevice != "" {
+		t.Errorf("expected the container not to be owned by the mount, got %q", result.CryptDevice)
+	}
+}
+
+func TestRescueHandler_Mount_AlreadyMounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "mountpoint", "-q", "/mnt").Return(nil, nil)
+
+	handler := NewRescueHandler(mockFS, mockExec, mockLogger)
+
+	if _, err := handler.Mount(context.Background(), commands.RescueMountCommand{
+		MountPoint: "/mnt",
+		Devices:    &disk.InstallDevices{EFI: "/dev/sda1", Root: "/dev/sda2"},
+	}); err == nil {
+		t.Error("expected error when /mnt is already mounted")
+	}
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/snapshot"
+)
+
+// btrfsTopLevel is where the top-level Btrfs subvolume (subvolid=5) is mounted
+// to create, rename or snapshot subvolumes next to @
+const btrfsTopLevel = "/run/archup-btrfs"
+
+// RollbackHandler lists snapper root snapshots and rolls the root subvolume back to one
+type RollbackHandler struct {
+	fs      ports.FileSystem
+	cmdExec ports.CommandExecutor
+	chrExec ports.ChrootExecutor
+	logger  ports.Logger
+}
+
+// NewRollbackHandler creates a new rollback handler
+func NewRollbackHandler(fs ports.FileSystem, cmdExec ports.CommandExecutor, chrExec ports.ChrootExecutor, logger ports.Logger) *RollbackHandler {
+	return &RollbackHandler{
+		fs:      fs,
+		cmdExec: cmdExec,
+		chrExec: chrExec,
+		logger:  logger,
+	}
+}
+
+// runInSystem runs a command in the installed system: directly when it is
+// the running system, through arch-chroot when it is mounted from the ISO
+func (h *RollbackHandler) runInSystem(ctx context.Context, mountPoint, name string, args ...string) ([]byte, error) {
+	if mountPoint == "/" {
+		return h.cmdExec.Execute(ctx, name, args...)
+	}
+	return h.chrExec.ExecuteInChroot(ctx, mountPoint, name, args...)
+}
+
+// ListSnapshots returns the snapshots of the snapper root config
+func (h *RollbackHandler) ListSnapshots(ctx context.Context, mountPoint string) (snapshot.List, error) {
+	output, err := h.runInSystem(ctx, mountPoint, "snapper",
+		"--no-dbus", "--csvout",
+		"-c", snapshot.RootSnapperConfig,
+		"list", "--columns", strings.Join(snapshot.SnapperListColumns, ","),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapper snapshots: %w", err)
+	}
+	return snapshot.ParseSnapperList(string(output))
+}
+
+// Handle replaces the root subvolume with a writable copy of a snapshot. The
+// change takes effect on reboot.
+func (h *RollbackHandler) Handle(ctx context.Context, cmd commands.RollbackCommand) (*dto.RollbackResult, error) {
+	result := &dto.RollbackResult{
+		Success:  false,
+		Snapshot: cmd.Snapshot,
+		Mode:     cmd.Mode.String(),
+	}
+
+	fail := func(message string, err error) (*dto.RollbackResult, error) {
+		h.logger.Error(message, "error", err)
+		result.ErrorDetail = fmt.Sprintf("%s: %v", message, err)
+		return result, err
+	}
+
+	// The baseline lives outside snapper, everything else is looked up in the root config
+	source, staging, label := snapshot.BaselineSubvolume, "@rollback-baseline", snapshot.BaselineSubvolume
+	if !cmd.Baseline {
+		snapshots, err := h.ListSnapshots(ctx, cmd.MountPoint)
+		if err != nil {
+			return fail("Failed to list snapshots", err)
+		}
+		target, ok := snapshots.Find(cmd.Snapshot)
+		if !ok {
+			return fail("Invalid snapshot", fmt.Errorf("snapshot %d not found in snapper config %s", cmd.Snapshot, snapshot.RootSnapperConfig))
+		}
+		source, staging, label = target.Path(), fmt.Sprintf("@rollback-%d", target.Number), target.String()
+	}
+
+	rootDevice, err := h.rootDevice(ctx, cmd.MountPoint)
+	if err != nil {
+		return fail("Failed to find root device", err)
+	}
+
+	// Subvolumes are renamed at the top level, outside the mounted @
+	if _, err := h.cmdExec.Execute(ctx, "mkdir", "-p", btrfsTopLevel); err != nil {
+		return fail("Failed to create mount point", err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mount", "-o", "subvolid=5", rootDevice, btrfsTopLevel); err != nil {
+		return fail("Failed to mount top-level subvolume", err)
+	}
+	defer func() {
+		if _, err := h.cmdExec.Execute(ctx, "umount", btrfsTopLevel); err != nil {
+			h.logger.Warn("Failed to unmount top-level subvolume", "error", err)
+		}
+	}()
+
+	h.logger.Info("Rolling back root", "snapshot", label, "mode", cmd.Mode.String())
+	if err := h.restoreSnapshot(ctx, source, staging); err != nil {
+		return fail("Failed to restore snapshot", err)
+	}
+
+	switch cmd.Mode {
+	case snapshot.RollbackSetDefault:
+		if err := h.setDefault(ctx, cmd.MountPoint, staging); err != nil {
+			h.undoRestore(ctx, staging)
+			return fail("Failed to set default subvolume", err)
+		}
+		result.NewRoot = staging
+		result.PreviousRoot = snapshot.RootSubvolume
+	default:
+		// genfstab pins / by subvolid as well, the ID of @ moves to previous
+		if err := h.unpinStagingFstab(staging, isSubvolIDPin); err != nil {
+			h.undoRestore(ctx, staging)
+			return fail("Failed to update restored fstab", err)
+		}
+		previous := "@.pre-rollback-" + time.Now().Format("20060102-150405")
+		if err := h.swapRoot(ctx, staging, previous); err != nil {
+			h.undoRestore(ctx, staging)
+			return fail("Failed to replace root subvolume", err)
+		}
+		result.NewRoot = snapshot.RootSubvolume
+		result.PreviousRoot = previous
+	}
+
+	// Snapshot boot entries are left alone: the old root no longer holds the
+	// snapshots, limine-snapper-sync.service updates them from the restored root
+	result.Success = true
+	h.logger.Info("Rollback prepared, reboot to apply",
+		"snapshot", label,
+		"new_root", result.NewRoot,
+		"previous_root", result.PreviousRoot)
+	return result, nil
+}
+
+// rootDevice returns the block device mounted at mountPoint, without the [/@] subvolume suffix
+func (h *RollbackHandler) rootDevice(ctx context.Context, mountPoint string) (string, error) {
+	output, err := h.cmdExec.Execute(ctx, "findmnt", "-n", "-o", "SOURCE", "--target", mountPoint)
+	if err != nil {
+		return "", fmt.Errorf("findmnt %s: %w", mountPoint, err)
+	}
+	source := strings.TrimSpace(string(output))
+	if i := strings.Index(source, "["); i >= 0 {
+		source = source[:i]
+	}
+	if source == "" {
+		return "", fmt.Errorf("nothing mounted at %s", mountPoint)
+	}
+	return source, nil
+}
+
+// restoreSnapshot creates a writable copy of the source subvolume as staging and
+// moves the snapper snapshot subvolume into it, so snapper keeps working after the rollback
+func (h *RollbackHandler) restoreSnapshot(ctx context.Context, source, staging string) error {
+	sourcePath := filepath.Join(btrfsTopLevel, source)
+	if exists, _ := h.fs.Exists(sourcePath); !exists {
+		return fmt.Errorf("snapshot subvolume %s not found", source)
+	}
+	stagingPath := filepath.Join(btrfsTopLevel, staging)
+	if exists, _ := h.fs.Exists(stagingPath); exists {
+		return fmt.Errorf("%s already exists, remove it with btrfs subvolume delete first", staging)
+	}
+
+	if _, err := h.cmdExec.Execute(ctx, "btrfs", "subvolume", "snapshot", sourcePath, stagingPath); err != nil {
+		return fmt.Errorf("failed to snapshot %s: %w", source, err)
+	}
+
+	// Nested subvolumes are not part of a snapshot, the copy only holds an empty directory
+	snapshotsDir := filepath.Join(stagingPath, snapshot.SnapshotsDir)
+	if _, err := h.cmdExec.Execute(ctx, "rmdir", snapshotsDir); err != nil {
+		h.logger.Warn("Failed to remove snapshot placeholder directory", "path", snapshotsDir, "error", err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mv",
+		filepath.Join(btrfsTopLevel, snapshot.RootSubvolume, snapshot.SnapshotsDir),
+		snapshotsDir,
+	); err != nil {
+		h.deleteStaging(ctx, stagingPath)
+		return fmt.Errorf("failed to move %s into %s: %w", snapshot.SnapshotsDir, staging, err)
+	}
+	return nil
+}
+
+// undoRestore moves the snapper snapshot subvolume back into @ and deletes
+// staging, leaving the root as it was before restoreSnapshot
+func (h *RollbackHandler) undoRestore(ctx context.Context, staging string) {
+	stagingPath := filepath.Join(btrfsTopLevel, staging)
+	snapshotsDir := filepath.Join(stagingPath, snapshot.SnapshotsDir)
+	rootSnapshotsDir := filepath.Join(btrfsTopLevel, snapshot.RootSubvolume, snapshot.SnapshotsDir)
+	if _, err := h.cmdExec.Execute(ctx, "mv", snapshotsDir, rootSnapshotsDir); err != nil {
+		// staging still holds the snapshots, it must not be deleted
+		h.logger.Error("Failed to move snapper snapshots back, move them manually",
+			"from", snapshotsDir, "to", rootSnapshotsDir, "error", err)
+		return
+	}
+	h.deleteStaging(ctx, stagingPath)
+}
+
+// deleteStaging deletes the staging copy of a snapshot
+func (h *RollbackHandler) deleteStaging(ctx context.Context, stagingPath string) {
+	if _, err := h.cmdExec.Execute(ctx, "btrfs", "subvolume", "delete", stagingPath); err != nil {
+		h.logger.Warn("Failed to delete staging subvolume", "path", stagingPath, "error", err)
+	}
+}
+
+// swapRoot renames @ to previous and staging to @, renaming previous back
+// to @ when staging cannot take its place
+func (h *RollbackHandler) swapRoot(ctx context.Context, staging, previous string) error {
+	root := filepath.Join(btrfsTopLevel, snapshot.RootSubvolume)
+	previousPath := filepath.Join(btrfsTopLevel, previous)
+	if _, err := h.cmdExec.Execute(ctx, "mv", root, previousPath); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", snapshot.RootSubvolume, previous, err)
+	}
+	if _, err := h.cmdExec.Execute(ctx, "mv", filepath.Join(btrfsTopLevel, staging), root); err != nil {
+		if _, undoErr := h.cmdExec.Execute(ctx, "mv", previousPath, root); undoErr != nil {
+			h.logger.Error("Failed to rename previous root back, rename it manually",
+				"from", previous, "to", snapshot.RootSubvolume, "error", undoErr)
+		}
+		return fmt.Errorf("failed to rename %s to %s: %w", staging, snapshot.RootSubvolume, err)
+	}
+	return nil
+}
+
+// subvolumeIDPattern matches the ID line of `btrfs subvolume show`
+var subvolumeIDPattern = regexp.MustCompile(`(?m)^\s*Subvolume ID:\s*(\d+)`)
+
+// setDefault makes staging the default subvolume and drops the subvol=@ pins
+// from the restored fstab and limine.conf, which would otherwise override it
+func (h *RollbackHandler) setDefault(ctx context.Context, mountPoint, staging string) error {
+	stagingPath := filepath.Join(btrfsTopLevel, staging)
+	output, err := h.cmdExec.Execute(ctx, "btrfs", "subvolume", "show", stagingPath)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", staging, err)
+	}
+	match := subvolumeIDPattern.FindStringSubmatch(string(output))
+	if match == nil {
+		return fmt.Errorf("no subvolume ID for %s", staging)
+	}
+
+	if err := h.unpinStagingFstab(staging, isRootSubvolPin); err != nil {
+		return err
+	}
+
+	limineConf := filepath.Join(mountPoint, "boot", "limine.conf")
+	conf, err := h.fs.ReadFile(limineConf)
+	if err != nil {
+		return fmt.Errorf("failed to read limine.conf: %w", err)
+	}
+	unpinned, err := unpinLimineRoot(string(conf))
+	if err != nil {
+		return err
+	}
+	if err := h.fs.WriteFile(limineConf, []byte(unpinned), 0o644); err != nil {
+		return fmt.Errorf("failed to write limine.conf: %w", err)
+	}
+
+	// The unpinned entries boot the default subvolume, put the pins back if it stays @
+	if _, err := h.cmdExec.Execute(ctx, "btrfs", "subvolume", "set-default", match[1], btrfsTopLevel); err != nil {
+		if restoreErr := h.fs.WriteFile(limineConf, conf, 0o644); restoreErr != nil {
+			h.logger.Error("Failed to restore limine.conf", "path", limineConf, "error", restoreErr)
+		}
+		return fmt.Errorf("btrfs set-default %s: %w", match[1], err)
+	}
+	return nil
+}
+
+// unpinStagingFstab removes the / mount options matching pinned from the fstab of staging
+func (h *RollbackHandler) unpinStagingFstab(staging string, pinned func(option string) bool) error {
+	fstabPath := filepath.Join(btrfsTopLevel, staging, "etc", "fstab")
+	fstab, err := h.fs.ReadFile(fstabPath)
+	if err != nil {
+		return fmt.Errorf("failed to read fstab: %w", err)
+	}
+	if err := h.fs.WriteFile(fstabPath, []byte(unpinFstabRoot(string(fstab), pinned)), 0o644); err != nil {
+		return fmt.Errorf("failed to write fstab: %w", err)
+	}
+	return nil
+}
+
+// isRootSubvolPin reports whether a subvol= option value selects the root subvolume
+func isRootSubvolPin(option string) bool {
+	return option == "subvol="+snapshot.RootSubvolume ||
+		option == "subvol=/"+snapshot.RootSubvolume ||
+		isSubvolIDPin(option)
+}
+
+// isSubvolIDPin reports whether an option selects a subvolume by ID
+func isSubvolIDPin(option string) bool {
+	return strings.HasPrefix(option, "subvolid=")
+}
+
+// unpinFstabRoot removes the options of the / entry matching pinned
+func unpinFstabRoot(fstab string, pinned func(option string) bool) string {
+	lines := strings.Split(fstab, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") || fields[1] != "/" {
+			continue
+		}
+		var kept []string
+		for _, option := range strings.Split(fields[3], ",") {
+			if !pinned(option) {
+				kept = append(kept, option)
+			}
+		}
+		if len(kept) == 0 {
+			kept = []string{"defaults"}
+		}
+		fields[3] = strings.Join(kept, ",")
+		lines[i] = strings.Join(fields, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// unpinLimineRoot removes rootflags=subvol=@ from the kernel entries of
+// limine.conf; snapshot entries select their subvolume explicitly and are kept
+func unpinLimineRoot(conf string) (string, error) {
+	lines := strings.Split(conf, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "cmdline:") {
+			continue
+		}
+		cmdline, err := bootloader.ParseKernelCmdline(strings.TrimSpace(strings.TrimPrefix(trimmed, "cmdline:")))
+		if err != nil {
+			return "", fmt.Errorf("invalid cmdline in limine.conf: %w", err)
+		}
+		rootflags, ok := cmdline.Get("rootflags")
+		if !ok {
+			continue
+		}
+
+		var kept []string
+		for _, option := range strings.Split(rootflags, ",") {
+			if !isRootSubvolPin(option) {
+				kept = append(kept, option)
+			}
+		}
+		if len(kept) == len(strings.Split(rootflags, ",")) {
+			continue
+		}
+		if len(kept) == 0 {
+			cmdline = cmdline.Remove("rootflags")
+		} else if cmdline, err = cmdline.Set("rootflags", strings.Join(kept, ",")); err != nil {
+			return "", fmt.Errorf("invalid rootflags: %w", err)
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		lines[i] = indent + "cmdline: " + cmdline.Render(bootloader.CmdlineTargetLimine)
+	}
+	if !strings.Contains(conf, "cmdline:") {
+		return "", errors.New("limine.conf has no kernel entries")
+	}
+	return strings.Join(lines, "\n"), nil
+}
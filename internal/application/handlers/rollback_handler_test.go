@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"go.uber.org/mock/gomock"
+)
+
+const rollbackSnapperCSV = "number,type,pre-number,date,description,userdata\n" +
+	"0,single,,,current,\n" +
+	"7,pre,,2026-10-02 09:12:00,pacman -Syu,important=yes\n" +
+	"8,post,7,2026-10-02 09:14:00,linux,important=yes\n"
+
+func setupRollbackMocks(ctrl *gomock.Controller) (*mocks.MockFileSystem, *mocks.MockCommandExecutor, *mocks.MockChrootExecutor, *mocks.MockLogger) {
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "snapper", "--no-dbus", "--csvout", "-c", "root",
+		"list", "--columns", "number,type,pre-number,date,description,userdata").
+		Return([]byte(rollbackSnapperCSV), nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "findmnt", "-n", "-o", "SOURCE", "--target", "/mnt").
+		Return([]byte("/dev/mapper/cryptroot[/@]\n"), nil).AnyTimes()
+
+	return mockFS, mockExec, mockChrExec, mockLogger
+}
+
+func TestRollbackHandler_Handle_NewRoot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupRollbackMocks(ctrl)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@/.snapshots/7/snapshot").Return(true, nil)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@rollback-7").Return(false, nil)
+
+	// The subvolid of the old @ is dropped, subvol=/@ selects the restored root
+	mockFS.EXPECT().ReadFile("/run/archup-btrfs/@rollback-7/etc/fstab").
+		Return([]byte("UUID=root-uuid / btrfs rw,noatime,compress=zstd,subvolid=256,subvol=/@ 0 0\n"), nil)
+	mockFS.EXPECT().WriteFile("/run/archup-btrfs/@rollback-7/etc/fstab",
+		[]byte("UUID=root-uuid\t/\tbtrfs\trw,noatime,compress=zstd,subvol=/@\t0\t0\n"), gomock.Any()).Return(nil)
+
+	gomock.InOrder(
+		mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", "subvolid=5", "/dev/mapper/cryptroot", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "snapshot",
+			"/run/archup-btrfs/@/.snapshots/7/snapshot", "/run/archup-btrfs/@rollback-7").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "rmdir", "/run/archup-btrfs/@rollback-7/.snapshots").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@/.snapshots", "/run/archup-btrfs/@rollback-7/.snapshots").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@", gomock.Any()).Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@rollback-7", "/run/archup-btrfs/@").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "umount", "/run/archup-btrfs").Return(nil, nil),
+	)
+
+	handler := NewRollbackHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RollbackCommand{
+		MountPoint: "/mnt",
+		Snapshot:   7,
+		Mode:       snapshot.RollbackNewRoot,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.NewRoot != "@" || !strings.HasPrefix(result.PreviousRoot, "@.pre-rollback-") {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRollbackHandler_Handle_SwapFailureRestoresSnapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupRollbackMocks(ctrl)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@/.snapshots/7/snapshot").Return(true, nil)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@rollback-7").Return(false, nil)
+
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return([]byte("UUID=root-uuid / btrfs rw,subvol=/@ 0 0\n"), nil)
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// @ is renamed back and .snapshots returns to it before staging is deleted
+	gomock.InOrder(
+		mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mount", "-o", "subvolid=5", "/dev/mapper/cryptroot", "/run/archup-btrfs").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "snapshot", gomock.Any(), gomock.Any()).Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "rmdir", gomock.Any()).Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@/.snapshots", "/run/archup-btrfs/@rollback-7/.snapshots").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@", gomock.Any()).Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@rollback-7", "/run/archup-btrfs/@").Return(nil, errors.New("device busy")),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", gomock.Any(), "/run/archup-btrfs/@").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "mv", "/run/archup-btrfs/@rollback-7/.snapshots", "/run/archup-btrfs/@/.snapshots").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "delete", "/run/archup-btrfs/@rollback-7").Return(nil, nil),
+		mockExec.EXPECT().Execute(gomock.Any(), "umount", "/run/archup-btrfs").Return(nil, nil),
+	)
+
+	handler := NewRollbackHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RollbackCommand{
+		MountPoint: "/mnt",
+		Snapshot:   7,
+		Mode:       snapshot.RollbackNewRoot,
+	})
+
+	if err == nil {
+		t.Fatal("expected error when the root cannot be replaced")
+	}
+	if result.Success {
+		t.Error("expected failure")
+	}
+}
+
+func TestRollbackHandler_Handle_SetDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupRollbackMocks(ctrl)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@/.snapshots/8/snapshot").Return(true, nil)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@rollback-8").Return(false, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "snapshot", gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "rmdir", gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mv", gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "show", "/run/archup-btrfs/@rollback-8").
+		Return([]byte("@rollback-8\n\tName: \t\t\t@rollback-8\n\tSubvolume ID: \t\t312\n"), nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "set-default", "312", "/run/archup-btrfs").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "umount", gomock.Any()).Return(nil, nil)
+
+	mockFS.EXPECT().ReadFile("/run/archup-btrfs/@rollback-8/etc/fstab").
+		Return([]byte("UUID=root-uuid / btrfs rw,noatime,compress=zstd,subvol=/@ 0 0\n"), nil)
+	mockFS.EXPECT().WriteFile("/run/archup-btrfs/@rollback-8/etc/fstab", []byte("UUID=root-uuid\t/\tbtrfs\trw,noatime,compress=zstd\t0\t0\n"), gomock.Any()).Return(nil)
+	mockFS.EXPECT().ReadFile("/mnt/boot/limine.conf").
+		Return([]byte("/+Arch Linux\n    cmdline: root=UUID=x rootflags=subvol=@ rw quiet\n"), nil)
+	mockFS.EXPECT().WriteFile("/mnt/boot/limine.conf", []byte("/+Arch Linux\n    cmdline: root=UUID=x rw quiet\n"), gomock.Any()).Return(nil)
+
+	handler := NewRollbackHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RollbackCommand{
+		MountPoint: "/mnt",
+		Snapshot:   8,
+		Mode:       snapshot.RollbackSetDefault,
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.NewRoot != "@rollback-8" || result.PreviousRoot != "@" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestRollbackHandler_Handle_UnknownSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupRollbackMocks(ctrl)
+	handler := NewRollbackHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RollbackCommand{MountPoint: "/mnt", Snapshot: 42})
+	if err == nil {
+		t.Fatal("expected error for unknown snapshot")
+	}
+	if result.Success {
+		t.Error("expected failure")
+	}
+}
+
+func TestUnpinFstabRoot(t *testing.T) {
+	fstab := "# /dev/mapper/cryptroot\n" +
+		"UUID=root-uuid / btrfs rw,noatime,subvolid=256,subvol=/@ 0 0\n" +
+		"UUID=root-uuid /home btrfs rw,noatime,subvolid=257,subvol=/@home 0 0\n"
+
+	if got := unpinFstabRoot(fstab, isSubvolIDPin); !strings.Contains(got, "UUID=root-uuid\t/\tbtrfs\trw,noatime,subvol=/@\t0\t0\n") {
+		t.Errorf("expected only subvolid removed from /, got:\n%s", got)
+	}
+	got := unpinFstabRoot(fstab, isRootSubvolPin)
+	if !strings.Contains(got, "UUID=root-uuid\t/\tbtrfs\trw,noatime\t0\t0\n") {
+		t.Errorf("expected subvolume options removed from /, got:\n%s", got)
+	}
+	if !strings.Contains(got, "subvolid=257,subvol=/@home") {
+		t.Errorf("expected /home untouched, got:\n%s", got)
+	}
+}
+
+func TestUnpinLimineRoot(t *testing.T) {
+	conf := "/+Arch Linux\n" +
+		"  //linux\n" +
+		"    cmdline: root=UUID=x rootflags=subvol=@,compress=zstd rw\n" +
+		"  //Snapshots\n" +
+		"    cmdline: root=UUID=x rootflags=subvol=@/.snapshots/3/snapshot rw\n"
+
+	got, err := unpinLimineRoot(conf)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(got, "    cmdline: root=UUID=x rootflags=compress=zstd rw\n") {
+		t.Errorf("expected subvol pin removed from kernel entry, got:\n%s", got)
+	}
+	if !strings.Contains(got, "rootflags=subvol=@/.snapshots/3/snapshot") {
+		t.Errorf("expected snapshot entry untouched, got:\n%s", got)
+	}
+
+	if _, err := unpinLimineRoot("timeout: 5\n"); err == nil {
+		t.Error("expected error for limine.conf without entries")
+	}
+}
+
+func TestRollbackHandler_Handle_Baseline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupRollbackMocks(ctrl)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@archup-baseline").Return(true, nil)
+	mockFS.EXPECT().Exists("/run/archup-btrfs/@rollback-baseline").Return(false, nil)
+	mockFS.EXPECT().ReadFile("/run/archup-btrfs/@rollback-baseline/etc/fstab").Return([]byte("UUID=root-uuid / btrfs rw,subvol=/@ 0 0\n"), nil)
+	mockFS.EXPECT().WriteFile("/run/archup-btrfs/@rollback-baseline/etc/fstab", gomock.Any(), gomock.Any()).Return(nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mkdir", "-p", gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mount", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "btrfs", "subvolume", "snapshot",
+		"/run/archup-btrfs/@archup-baseline", "/run/archup-btrfs/@rollback-baseline").Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "rmdir", gomock.Any()).Return(nil, nil)
+	mockExec.EXPECT().Execute(gomock.Any(), "mv", gomock.Any(), gomock.Any()).Return(nil, nil).Times(3)
+	mockExec.EXPECT().Execute(gomock.Any(), "umount", gomock.Any()).Return(nil, nil)
+
+	handler := NewRollbackHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.RollbackCommand{MountPoint: "/mnt", Baseline: true})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.NewRoot != "@" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
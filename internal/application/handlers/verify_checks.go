@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// VerifyCheck is a named verification check run against an installed system.
+// Checks are registered on the VerifyHandler, custom checks can be added with Register.
+type VerifyCheck struct {
+	Name string
+	Run  func(ctx context.Context, env *VerifyEnv) installation.CheckResult
+}
+
+// VerifyEnv gives checks access to the installed system
+type VerifyEnv struct {
+	MountPoint string
+	Username   string
+	FS         ports.FileSystem
+	CmdExec    ports.CommandExecutor
+	ChrExec    ports.ChrootExecutor
+
+	uuids map[string]bool
+}
+
+// Path returns the path inside the installed system
+func (e *VerifyEnv) Path(elem ...string) string {
+	return filepath.Join(append([]string{e.MountPoint}, elem...)...)
+}
+
+// ReadLines reads a file of the installed system, skipping comments and empty lines
+func (e *VerifyEnv) ReadLines(elem ...string) ([]string, error) {
+	content, err := e.FS.ReadFile(e.Path(elem...))
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// BlockUUIDs returns the filesystem UUIDs known to blkid on the host
+func (e *VerifyEnv) BlockUUIDs(ctx context.Context) (map[string]bool, error) {
+	if e.uuids != nil {
+		return e.uuids, nil
+	}
+
+	output, err := e.CmdExec.Execute(ctx, "blkid", "-s", "UUID", "-o", "value")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list block device UUIDs: %w", err)
+	}
+
+	e.uuids = map[string]bool{}
+	for _, uuid := range strings.Fields(string(output)) {
+		e.uuids[uuid] = true
+	}
+	return e.uuids, nil
+}
+
+// LimineValues returns the values of a limine.conf key across all entries
+func (e *VerifyEnv) LimineValues(key string) ([]string, error) {
+	lines, err := e.ReadLines("boot", "limine.conf")
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, line := range lines {
+		if value, ok := strings.CutPrefix(line, key+":"); ok {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values, nil
+}
+
+// DefaultVerifyChecks returns the built-in verification checks, in run order
+func DefaultVerifyChecks() []VerifyCheck {
+	return []VerifyCheck{
+		{Name: "boot-files", Run: checkBootFiles},
+		{Name: "fstab-uuids", Run: checkFstabUUIDs},
+		{Name: "boot-uuids", Run: checkBootUUIDs},
+		{Name: "initramfs-hooks", Run: checkInitramfsHooks},
+		{Name: "efi-boot-entry", Run: checkEFIBootEntry},
+		{Name: "services", Run: checkServices},
+		{Name: "wheel-group", Run: checkWheelGroup},
+		{Name: "pacman-conf", Run: checkPacmanConf},
+	}
+}
+
+// essentialBootFiles lists the files without which the installed system cannot boot
+func essentialBootFiles(mountPoint string) []struct{ path, name string } {
+	return []struct{ path, name string }{
+		{filepath.Join(mountPoint, "etc", "fstab"), "fstab"},
+		{filepath.Join(mountPoint, "boot", "limine.conf"), "limine.conf"},
+		{filepath.Join(mountPoint, "boot", "EFI", "BOOT", "BOOTX64.EFI"), "EFI boot file"},
+	}
+}
+
+func checkBootFiles(_ context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "boot-files"
+
+	var missing []string
+	for _, f := range essentialBootFiles(env.MountPoint) {
+		if _, err := env.FS.Stat(f.path); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%s)", f.name, f.path))
+		}
+	}
+	if len(missing) > 0 {
+		return installation.FailCheck(name, "missing "+strings.Join(missing, ", "))
+	}
+	return installation.PassCheck(name, "fstab, limine.conf and EFI boot file present")
+}
+
+func checkFstabUUIDs(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "fstab-uuids"
+
+	lines, err := env.ReadLines("etc", "fstab")
+	if err != nil {
+		return installation.FailCheck(name, fmt.Sprintf("cannot read fstab: %v", err))
+	}
+
+	uuids, err := env.BlockUUIDs(ctx)
+	if err != nil {
+		return installation.FailCheck(name, err.Error())
+	}
+
+	checked := 0
+	var unknown []string
+	for _, line := range lines {
+		uuid, ok := strings.CutPrefix(strings.Fields(line)[0], "UUID=")
+		if !ok {
+			continue
+		}
+		checked++
+		if !uuids[uuid] {
+			unknown = append(unknown, uuid)
+		}
+	}
+
+	switch {
+	case len(unknown) > 0:
+		return installation.FailCheck(name, "unknown UUIDs in fstab: "+strings.Join(unknown, ", "))
+	case checked == 0:
+		return installation.WarnCheck(name, "fstab has no UUID= entries")
+	default:
+		return installation.PassCheck(name, fmt.Sprintf("%d fstab UUIDs match blkid", checked))
+	}
+}
+
+func checkBootUUIDs(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "boot-uuids"
+
+	cmdlines, err := env.LimineValues("cmdline")
+	if err != nil {
+		return installation.FailCheck(name, fmt.Sprintf("cannot read limine.conf: %v", err))
+	}
+
+	var referenced []string
+	for _, line := range cmdlines {
+		cmdline, err := bootloader.ParseKernelCmdline(line)
+		if err != nil {
+			return installation.FailCheck(name, fmt.Sprintf("invalid kernel command line: %v", err))
+		}
+		for _, key := range []string{"cryptdevice", "root", "resume"} {
+			value, ok := cmdline.Get(key)
+			if !ok {
+				continue
+			}
+			if uuid, ok := strings.CutPrefix(value, "UUID="); ok {
+				uuid, _, _ = strings.Cut(uuid, ":")
+				referenced = append(referenced, uuid)
+			}
+		}
+	}
+
+	// crypttab is optional: the encrypt hook unlocks the root from the kernel command line
+	if exists, _ := env.FS.Exists(env.Path("etc", "crypttab")); exists {
+		lines, err := env.ReadLines("etc", "crypttab")
+		if err != nil {
+			return installation.FailCheck(name, fmt.Sprintf("cannot read crypttab: %v", err))
+		}
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			if uuid, ok := strings.CutPrefix(fields[1], "UUID="); ok {
+				referenced = append(referenced, uuid)
+			}
+		}
+	}
+
+	if len(referenced) == 0 {
+		return installation.WarnCheck(name, "no UUIDs referenced by the kernel command line or crypttab")
+	}
+
+	uuids, err := env.BlockUUIDs(ctx)
+	if err != nil {
+		return installation.FailCheck(name, err.Error())
+	}
+
+	var unknown []string
+	for _, uuid := range referenced {
+		if !uuids[uuid] {
+			unknown = append(unknown, uuid)
+		}
+	}
+	if len(unknown) > 0 {
+		return installation.FailCheck(name, "unknown UUIDs on the kernel command line or in crypttab: "+strings.Join(unknown, ", "))
+	}
+	return installation.PassCheck(name, fmt.Sprintf("%d boot UUIDs match blkid", len(referenced)))
+}
+
+func checkInitramfsHooks(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "initramfs-hooks"
+
+	modulePaths, err := env.LimineValues("module_path")
+	if err != nil {
+		return installation.FailCheck(name, fmt.Sprintf("cannot read limine.conf: %v", err))
+	}
+	if len(modulePaths) == 0 {
+		return installation.FailCheck(name, "limine.conf references no initramfs")
+	}
+
+	cmdlines, _ := env.LimineValues("cmdline")
+	required := []string{"hooks/udev"}
+	if strings.Contains(strings.Join(cmdlines, " "), "cryptdevice=") {
+		required = append(required, "hooks/encrypt")
+	}
+
+	var problems []string
+	seen := map[string]bool{}
+	for _, modulePath := range modulePaths {
+		image := "/boot/" + strings.TrimPrefix(modulePath, "boot():/")
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+
+		output, err := env.ChrExec.ExecuteInChroot(ctx, env.MountPoint, "lsinitcpio", "-l", image)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: cannot list contents: %v", image, err))
+			continue
+		}
+
+		contents := map[string]bool{}
+		for _, entry := range strings.Fields(string(output)) {
+			contents[strings.TrimSuffix(strings.TrimPrefix(entry, "./"), "/")] = true
+		}
+		for _, hook := range required {
+			if !contents[hook] {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", image, hook))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return installation.FailCheck(name, strings.Join(problems, "; "))
+	}
+	return installation.PassCheck(name, fmt.Sprintf("%d initramfs images contain %s", len(seen), strings.Join(required, ", ")))
+}
+
+func checkEFIBootEntry(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "efi-boot-entry"
+
+	output, err := env.CmdExec.Execute(ctx, "efibootmgr")
+	if err != nil {
+		return installation.WarnCheck(name, fmt.Sprintf("cannot read EFI boot entries: %v", err))
+	}
+	if !strings.Contains(string(output), config.UEFIBootLabel) {
+		// The firmware still finds the fallback EFI/BOOT/BOOTX64.EFI
+		return installation.WarnCheck(name, fmt.Sprintf("no %q EFI boot entry, relying on the fallback boot path", config.UEFIBootLabel))
+	}
+	return installation.PassCheck(name, fmt.Sprintf("%q EFI boot entry present", config.UEFIBootLabel))
+}
+
+// verifyServices lists the services checked by the services check and whether they are required
+var verifyServices = []struct {
+	name     string
+	required bool
+}{
+	{"NetworkManager.service", true},
+	{config.PostBootServiceName, false},
+	{"limine-snapper-sync.service", false},
+}
+
+func checkServices(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "services"
+
+	var failed, warned []string
+	for _, svc := range verifyServices {
+		if _, err := env.CmdExec.Execute(ctx, "systemctl", "--root="+env.MountPoint, "is-enabled", svc.name); err == nil {
+			continue
+		}
+		if svc.required {
+			failed = append(failed, svc.name)
+		} else {
+			warned = append(warned, svc.name)
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		return installation.FailCheck(name, "not enabled: "+strings.Join(append(failed, warned...), ", "))
+	case len(warned) > 0:
+		return installation.WarnCheck(name, "not enabled: "+strings.Join(warned, ", "))
+	default:
+		return installation.PassCheck(name, fmt.Sprintf("%d services enabled", len(verifyServices)))
+	}
+}
+
+func checkWheelGroup(_ context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "wheel-group"
+
+	if env.Username == "" {
+		return installation.WarnCheck(name, "no regular user found")
+	}
+
+	lines, err := env.ReadLines("etc", "group")
+	if err != nil {
+		return installation.FailCheck(name, fmt.Sprintf("cannot read /etc/group: %v", err))
+	}
+
+	for _, line := range lines {
+		fields := strings.Split(line, ":")
+		if len(fields) < 4 || fields[0] != "wheel" {
+			continue
+		}
+		for _, member := range strings.Split(fields[3], ",") {
+			if member == env.Username {
+				return installation.PassCheck(name, fmt.Sprintf("%s is in wheel", env.Username))
+			}
+		}
+	}
+	return installation.FailCheck(name, fmt.Sprintf("%s is not in wheel, sudo will not work", env.Username))
+}
+
+func checkPacmanConf(_ context.Context, env *VerifyEnv) installation.CheckResult {
+	const name = "pacman-conf"
+
+	content, err := env.FS.ReadFile(env.Path("etc", "pacman.conf"))
+	if err != nil {
+		return installation.FailCheck(name, fmt.Sprintf("cannot read pacman.conf: %v", err))
+	}
+
+	repos, includes, err := parsePacmanConf(string(content))
+	if err != nil {
+		return installation.FailCheck(name, err.Error())
+	}
+
+	for _, include := range includes {
+		if exists, _ := env.FS.Exists(env.Path(include)); !exists {
+			return installation.FailCheck(name, fmt.Sprintf("included file %s does not exist", include))
+		}
+	}
+	return installation.PassCheck(name, fmt.Sprintf("%d repositories: %s", len(repos), strings.Join(repos, ", ")))
+}
+
+// parsePacmanConf parses pacman.conf, returning the repositories and included files.
+// Every repository must define a Server or an Include.
+func parsePacmanConf(conf string) ([]string, []string, error) {
+	var repos, includes []string
+	section := ""
+	sources := map[string]int{}
+
+	scanner := bufio.NewScanner(strings.NewReader(conf))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return nil, nil, fmt.Errorf("line %d: malformed section %q", lineNo, line)
+			}
+			section = line[1 : len(line)-1]
+			if section != "options" {
+				repos = append(repos, section)
+				sources[section] = 0
+			}
+			continue
+		}
+
+		if section == "" {
+			return nil, nil, fmt.Errorf("line %d: directive outside of a section", lineNo)
+		}
+
+		key, value, hasValue := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, nil, fmt.Errorf("line %d: malformed directive %q", lineNo, line)
+		}
+
+		switch key {
+		case "Include":
+			if !hasValue || value == "" {
+				return nil, nil, fmt.Errorf("line %d: Include without a path", lineNo)
+			}
+			includes = append(includes, value)
+			sources[section]++
+		case "Server":
+			sources[section]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(repos) == 0 {
+		return nil, nil, fmt.Errorf("no repositories configured")
+	}
+	for _, repo := range repos {
+		if sources[repo] == 0 {
+			return nil, nil, fmt.Errorf("repository [%s] has no Server or Include", repo)
+		}
+	}
+
+	return repos, includes, nil
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// VerifyHandler verifies an installed system before reboot using a registry of checks
+type VerifyHandler struct {
+	fs      ports.FileSystem
+	cmdExec ports.CommandExecutor
+	chrExec ports.ChrootExecutor
+	logger  ports.Logger
+	checks  []VerifyCheck
+}
+
+// NewVerifyHandler creates a new verify handler with the default checks registered
+func NewVerifyHandler(fs ports.FileSystem, cmdExec ports.CommandExecutor, chrExec ports.ChrootExecutor, logger ports.Logger) *VerifyHandler {
+	h := &VerifyHandler{
+		fs:      fs,
+		cmdExec: cmdExec,
+		chrExec: chrExec,
+		logger:  logger,
+	}
+	for _, check := range DefaultVerifyChecks() {
+		h.Register(check)
+	}
+	return h
+}
+
+// Register adds a check, replacing a registered check with the same name
+func (h *VerifyHandler) Register(check VerifyCheck) {
+	for i, existing := range h.checks {
+		if existing.Name == check.Name {
+			h.checks[i] = check
+			return
+		}
+	}
+	h.checks = append(h.checks, check)
+}
+
+// CheckNames returns the names of the registered checks, in run order
+func (h *VerifyHandler) CheckNames() []string {
+	names := make([]string, 0, len(h.checks))
+	for _, check := range h.checks {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+// Handle runs the selected checks against the installed system
+func (h *VerifyHandler) Handle(ctx context.Context, cmd commands.VerifyCommand) (*dto.VerifyResult, error) {
+	h.logger.Info("Starting installation verification", "mountPoint", cmd.MountPoint)
+
+	result := &dto.VerifyResult{
+		Success: false,
+		Report:  &installation.VerificationReport{MountPoint: cmd.MountPoint},
+	}
+
+	checks, err := h.selectChecks(cmd.Checks)
+	if err != nil {
+		h.logger.Error("Invalid verification checks", "error", err)
+		result.ErrorDetail = err.Error()
+		return result, err
+	}
+
+	if exists, err := h.fs.Exists(cmd.MountPoint); err != nil || !exists {
+		err = fmt.Errorf("mount point %s does not exist", cmd.MountPoint)
+		h.logger.Error("Cannot verify installation", "error", err)
+		result.ErrorDetail = err.Error()
+		return result, err
+	}
+
+	env := &VerifyEnv{
+		MountPoint: cmd.MountPoint,
+		Username:   cmd.Username,
+		FS:         h.fs,
+		CmdExec:    h.cmdExec,
+		ChrExec:    h.chrExec,
+	}
+	if env.Username == "" {
+		env.Username = h.detectUsername(env)
+	}
+
+	for _, check := range checks {
+		checkResult := check.Run(ctx, env)
+		checkResult.Name = check.Name
+		result.Report.Add(checkResult)
+
+		switch checkResult.Status {
+		case installation.CheckFail:
+			h.logger.Error("Verification check failed", "check", check.Name, "message", checkResult.Message)
+		case installation.CheckWarn:
+			h.logger.Warn("Verification check warning", "check", check.Name, "message", checkResult.Message)
+		default:
+			h.logger.Info("Verification check passed", "check", check.Name)
+		}
+	}
+
+	result.Success = result.Report.Passed()
+	h.logger.Info("Installation verification completed",
+		"passed", result.Report.Count(installation.CheckPass),
+		"warnings", result.Report.Count(installation.CheckWarn),
+		"failures", result.Report.Count(installation.CheckFail))
+
+	return result, nil
+}
+
+// selectChecks returns the registered checks matching names, all checks when names is empty
+func (h *VerifyHandler) selectChecks(names []string) ([]VerifyCheck, error) {
+	if len(names) == 0 {
+		return h.checks, nil
+	}
+
+	selected := make([]VerifyCheck, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, check := range h.checks {
+			if check.Name == name {
+				selected = append(selected, check)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown verification check %q (available: %s)", name, strings.Join(h.CheckNames(), ", "))
+		}
+	}
+	return selected, nil
+}
+
+// detectUsername returns the first regular user (UID 1000-59999) of the installed system
+func (h *VerifyHandler) detectUsername(env *VerifyEnv) string {
+	lines, err := env.ReadLines("etc", "passwd")
+	if err != nil {
+		h.logger.Warn("Could not read /etc/passwd to detect the user", "error", err)
+		return ""
+	}
+
+	for _, line := range lines {
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err == nil && uid >= 1000 && uid < 60000 {
+			return fields[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+var verifyFiles = map[string]string{
+	"/mnt/etc/fstab": "# /dev/mapper/cryptroot\nUUID=root-uuid / btrfs rw,subvol=/@ 0 0\nUUID=efi-uuid /boot vfat rw 0 2\n",
+	"/mnt/boot/limine.conf": `timeout: 5
+/+linux
+    //linux
+    protocol: linux
+    cmdline: cryptdevice=UUID=luks-uuid:cryptroot root=/dev/mapper/cryptroot rootflags=subvol=@ rw quiet
+    module_path: boot():/initramfs-linux.img
+`,
+	"/mnt/etc/passwd":      "root:x:0:0::/root:/bin/bash\nalice:x:1000:1000::/home/alice:/bin/zsh\n",
+	"/mnt/etc/group":       "root:x:0:root\nwheel:x:998:alice\n",
+	"/mnt/etc/pacman.conf": "[options]\nHoldPkg = pacman glibc\nColor\n\n[core]\nInclude = /etc/pacman.d/mirrorlist\n\n[extra]\nInclude = /etc/pacman.d/mirrorlist\n",
+}
+
+func setupVerifyMocks(ctrl *gomock.Controller, files map[string]string, blkid string) (*mocks.MockFileSystem, *mocks.MockCommandExecutor, *mocks.MockChrootExecutor, *mocks.MockLogger) {
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockExec := mocks.NewMockCommandExecutor(ctrl)
+	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	mockFS.EXPECT().ReadFile(gomock.Any()).DoAndReturn(func(path string) ([]byte, error) {
+		if content, ok := files[path]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}).AnyTimes()
+	mockFS.EXPECT().Exists(gomock.Any()).DoAndReturn(func(path string) (bool, error) {
+		_, ok := files[path]
+		return ok || path == "/mnt" || strings.HasPrefix(path, "/mnt/etc/pacman.d/"), nil
+	}).AnyTimes()
+	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
+
+	mockExec.EXPECT().Execute(gomock.Any(), "blkid", "-s", "UUID", "-o", "value").Return([]byte(blkid), nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "efibootmgr").Return([]byte("BootCurrent: 0001\nBoot0001* Arch Linux\tHD(1,GPT)/\\EFI\\limine\\BOOTX64.EFI\n"), nil).AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "systemctl", "--root=/mnt", "is-enabled", gomock.Any()).Return([]byte("enabled\n"), nil).AnyTimes()
+	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), "/mnt", "lsinitcpio", "-l", "/boot/initramfs-linux.img").
+		Return([]byte("./\nhooks/\nhooks/udev\nhooks/encrypt\nusr/bin/cryptsetup\n"), nil).AnyTimes()
+
+	return mockFS, mockExec, mockChrExec, mockLogger
+}
+
+func TestVerifyHandler_Handle_AllChecksPass(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupVerifyMocks(ctrl, verifyFiles, "root-uuid\nefi-uuid\nluks-uuid\n")
+	handler := NewVerifyHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.VerifyCommand{MountPoint: "/mnt"})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected success, got problems: %v", result.Report.Problems())
+	}
+	if len(result.Report.Results) != len(DefaultVerifyChecks()) {
+		t.Errorf("expected %d results, got %d", len(DefaultVerifyChecks()), len(result.Report.Results))
+	}
+	if result.Report.Count(installation.CheckWarn) != 0 {
+		t.Errorf("expected no warnings, got: %v", result.Report.Problems())
+	}
+}
+
+func TestVerifyHandler_Handle_UnknownUUIDsFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// The LUKS container was reformatted: cmdline UUID no longer exists
+	mockFS, mockExec, mockChrExec, mockLogger := setupVerifyMocks(ctrl, verifyFiles, "root-uuid\nefi-uuid\n")
+	handler := NewVerifyHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.VerifyCommand{
+		MountPoint: "/mnt",
+		Checks:     []string{"fstab-uuids", "boot-uuids"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Success {
+		t.Error("expected verification failure")
+	}
+	if result.Report.Results[0].Status != installation.CheckPass {
+		t.Errorf("expected fstab-uuids to pass, got %s", result.Report.Results[0])
+	}
+	if result.Report.Results[1].Status != installation.CheckFail || !strings.Contains(result.Report.Results[1].Message, "luks-uuid") {
+		t.Errorf("expected boot-uuids to fail on luks-uuid, got %s", result.Report.Results[1])
+	}
+}
+
+func TestVerifyHandler_Handle_WheelGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupVerifyMocks(ctrl, verifyFiles, "")
+	handler := NewVerifyHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	result, err := handler.Handle(context.Background(), commands.VerifyCommand{
+		MountPoint: "/mnt",
+		Username:   "bob",
+		Checks:     []string{"wheel-group"},
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Success {
+		t.Error("expected bob not to be in wheel")
+	}
+}
+
+func TestVerifyHandler_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS, mockExec, mockChrExec, mockLogger := setupVerifyMocks(ctrl, verifyFiles, "")
+	handler := NewVerifyHandler(mockFS, mockExec, mockChrExec, mockLogger)
+
+	handler.Register(VerifyCheck{
+		Name: "custom",
+		Run: func(ctx context.Context, env *VerifyEnv) installation.CheckResult {
+			return installation.WarnCheck("", "custom warning for "+env.Username)
+		},
+	})
+
+	result, err := handler.Handle(context.Background(), commands.VerifyCommand{MountPoint: "/mnt", Checks: []string{"custom"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Success || result.Report.Results[0].Name != "custom" || result.Report.Results[0].Message != "custom warning for alice" {
+		t.Errorf("unexpected custom check result: %v", result.Report.Results)
+	}
+
+	if _, err := handler.Handle(context.Background(), commands.VerifyCommand{MountPoint: "/mnt", Checks: []string{"missing"}}); err == nil {
+		t.Error("expected error for unknown check")
+	}
+}
+
+func TestVerifyHandler_Handle_MissingMountPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+	mockFS.EXPECT().Exists("/mnt").Return(false, errors.New("not found"))
+
+	handler := NewVerifyHandler(mockFS, nil, nil, mockLogger)
+
+	if _, err := handler.Handle(context.Background(), commands.VerifyCommand{MountPoint: "/mnt"}); err == nil {
+		t.Error("expected error for missing mount point")
+	}
+}
+
+func TestParsePacmanConf(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      string
+		repos     int
+		shouldErr bool
+	}{
+		{"valid", verifyFiles["/mnt/etc/pacman.conf"], 2, false},
+		{"server only", "[options]\n[custom]\nServer = https://example.com/$arch\n", 1, false},
+		{"repo without source", "[options]\n[core]\nSigLevel = Required\n", 0, true},
+		{"malformed section", "[core\nInclude = /etc/pacman.d/mirrorlist\n", 0, true},
+		{"directive outside section", "Color\n[core]\nInclude = x\n", 0, true},
+		{"no repositories", "[options]\nColor\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, _, err := parsePacmanConf(tt.conf)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+			if len(repos) != tt.repos {
+				t.Errorf("expected %d repositories, got %v", tt.repos, repos)
+			}
+		})
+	}
+}
@@ -25,7 +25,14 @@ type InstallationService struct {
 	configHandler      *handlers.ConfigureSystemHandler
 	bootloaderHandler  *handlers.BootloaderHandler
 	reposHandler       *handlers.ReposHandler
+	gpuDriversHandler  *handlers.GPUDriversHandler
+	cpuPowerHandler    *handlers.CPUPowerHandler
+	laptopHandler      *handlers.LaptopHandler
+	mirrorHandler      *handlers.MirrorHandler
+	inventoryHandler   *handlers.InventoryHandler
 	postInstallHandler *handlers.PostInstallHandler
+	hookHandler        *handlers.HookHandler
+	offlineHandler     *handlers.OfflineHandler
 
 	// Ports
 	repo   ports.InstallationRepository
@@ -35,7 +42,10 @@ type InstallationService struct {
 	tracker *ProgressTracker
 
 	// State
-	startTime time.Time
+	startTime        time.Time
+	baselineSnapshot string
+	hardwareSummary  []string
+	aurFailed        []string
 }
 
 // NewInstallationService creates a new installation service with all handlers
@@ -49,7 +59,14 @@ func NewInstallationService(
 	configHandler *handlers.ConfigureSystemHandler,
 	bootloaderHandler *handlers.BootloaderHandler,
 	reposHandler *handlers.ReposHandler,
+	gpuDriversHandler *handlers.GPUDriversHandler,
+	cpuPowerHandler *handlers.CPUPowerHandler,
+	laptopHandler *handlers.LaptopHandler,
+	mirrorHandler *handlers.MirrorHandler,
+	inventoryHandler *handlers.InventoryHandler,
 	postInstallHandler *handlers.PostInstallHandler,
+	hookHandler *handlers.HookHandler,
+	offlineHandler *handlers.OfflineHandler,
 ) *InstallationService {
 	return &InstallationService{
 		repo:               repo,
@@ -61,7 +78,14 @@ func NewInstallationService(
 		configHandler:      configHandler,
 		bootloaderHandler:  bootloaderHandler,
 		reposHandler:       reposHandler,
+		gpuDriversHandler:  gpuDriversHandler,
+		cpuPowerHandler:    cpuPowerHandler,
+		laptopHandler:      laptopHandler,
+		mirrorHandler:      mirrorHandler,
+		inventoryHandler:   inventoryHandler,
 		postInstallHandler: postInstallHandler,
+		hookHandler:        hookHandler,
+		offlineHandler:     offlineHandler,
 		tracker:            NewProgressTracker(),
 	}
 }
@@ -74,7 +98,7 @@ func (s *InstallationService) Start(ctx context.Context, hostname string, userna
 	inst, err := installation.NewInstallation(hostname, username, targetDisk, encryptionType)
 	if err != nil {
 		s.logger.Error("Failed to create installation", "error", err)
-		s.tracker.EmitPhaseError("initialization", 0, 8, err.Error())
+		s.tracker.Emit(&dto.ProgressUpdate{Phase: "Initialization", Message: err.Error(), IsError: true})
 		return err
 	}
 
@@ -84,7 +108,7 @@ func (s *InstallationService) Start(ctx context.Context, hostname string, userna
 	// Start the installation
 	if err := s.installAgg.Start(ctx); err != nil {
 		s.logger.Error("Failed to start installation", "error", err)
-		s.tracker.EmitPhaseError("initialization", 0, 8, err.Error())
+		s.tracker.Emit(&dto.ProgressUpdate{Phase: "Initialization", Message: err.Error(), IsError: true})
 		return err
 	}
 
@@ -97,11 +121,8 @@ func (s *InstallationService) RunPreflight(ctx context.Context) (*dto.PreflightR
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Preflight Checks", 1, 8)
-
 	result, err := s.preflightHandler.Handle(ctx, commands.PreflightCommand{})
 	if err != nil {
-		s.tracker.EmitPhaseError("Preflight Checks", 1, 8, err.Error())
 		return nil, err
 	}
 
@@ -110,11 +131,9 @@ func (s *InstallationService) RunPreflight(ctx context.Context) (*dto.PreflightR
 		if len(result.CriticalErrors) > 0 {
 			errMsg = result.CriticalErrors[0]
 		}
-		s.tracker.EmitPhaseError("Preflight Checks", 1, 8, errMsg)
 		return result, errors.New(errMsg)
 	}
 
-	s.tracker.EmitPhaseCompleted("Preflight Checks", 1, 8)
 	return result, nil
 }
 
@@ -128,30 +147,31 @@ func (s *InstallationService) RunBootstrap(ctx context.Context) (*dto.BootstrapR
 		return result, err
 	}
 
-	s.logger.Info("Bootstrap completed", "method", result.Method)
+	s.logger.Info("Bootstrap completed", "assets", result.Source)
 	return result, nil
 }
 
+// RollbackBootstrap removes the install files prepared by bootstrap
+func (s *InstallationService) RollbackBootstrap(ctx context.Context, result *dto.BootstrapResult) error {
+	return s.bootstrapHandler.Rollback(ctx, result)
+}
+
 // RunPartition runs disk partitioning
 func (s *InstallationService) RunPartition(ctx context.Context, cmd commands.PartitionDiskCommand) (*dto.PartitionResult, error) {
 	if s.installAgg == nil {
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Disk Partitioning", 2, 8)
-
 	result, err := s.partitionHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("Disk Partitioning", 2, 8, err.Error())
-		return nil, err
+		// Keep the partial result, rollback needs what was done before the failure
+		return result, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("Disk Partitioning", 2, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("Disk Partitioning", 2, 8)
 	return result, nil
 }
 
@@ -161,43 +181,67 @@ func (s *InstallationService) RunBaseInstall(ctx context.Context, cmd commands.I
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Base Installation", 3, 8)
-
 	result, err := s.baseHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("Base Installation", 3, 8, err.Error())
-		return nil, err
+		// Keep the partial result, rollback needs what was done before the failure
+		return result, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("Base Installation", 3, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("Base Installation", 3, 8)
 	return result, nil
 }
 
+// RollbackPartition unmounts the target filesystems and closes the LUKS container
+func (s *InstallationService) RollbackPartition(ctx context.Context, result *dto.PartitionResult) error {
+	return s.partitionHandler.Rollback(ctx, result)
+}
+
+// RunMirrors ranks the pacman mirrors and writes the host mirrorlist
+func (s *InstallationService) RunMirrors(ctx context.Context, cmd commands.SelectMirrorsCommand) (*dto.MirrorsResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.mirrorHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
+	return result, nil
+}
+
+// RollbackMirrors restores the host mirrorlist
+func (s *InstallationService) RollbackMirrors(ctx context.Context, result *dto.MirrorsResult) error {
+	return s.mirrorHandler.Rollback(ctx, result)
+}
+
+// RollbackBaseInstall restores the host pacman.conf patched for pacstrap
+func (s *InstallationService) RollbackBaseInstall(ctx context.Context, result *dto.InstallBaseResult) error {
+	return s.baseHandler.Rollback(ctx, result)
+}
+
 // RunConfigSystem runs system configuration
 func (s *InstallationService) RunConfigSystem(ctx context.Context, cmd commands.ConfigureSystemCommand) (*dto.ConfigureSystemResult, error) {
 	if s.installAgg == nil {
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("System Configuration", 4, 8)
-
 	result, err := s.configHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("System Configuration", 4, 8, err.Error())
 		return nil, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("System Configuration", 4, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("System Configuration", 4, 8)
 	return result, nil
 }
 
@@ -207,43 +251,114 @@ func (s *InstallationService) RunBootloaderSetup(ctx context.Context, cmd comman
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Bootloader Setup", 5, 8)
-
 	result, err := s.bootloaderHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("Bootloader Setup", 5, 8, err.Error())
-		return nil, err
+		// Keep the partial result, rollback needs what was done before the failure
+		return result, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("Bootloader Setup", 5, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("Bootloader Setup", 5, 8)
 	return result, nil
 }
 
+// RollbackBootloader removes the UEFI boot entry of the installed system
+func (s *InstallationService) RollbackBootloader(ctx context.Context, result *dto.BootloaderResult) error {
+	return s.bootloaderHandler.Rollback(ctx, result)
+}
+
 // RunRepositorySetup runs repository setup
 func (s *InstallationService) RunRepositorySetup(ctx context.Context, cmd commands.SetupRepositoriesCommand) (*dto.RepositoriesResult, error) {
 	if s.installAgg == nil {
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Repository Setup", 6, 8)
-
 	result, err := s.reposHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("Repository Setup", 6, 8, err.Error())
 		return nil, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("Repository Setup", 6, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("Repository Setup", 6, 8)
+	s.aurFailed = result.AURFailed
+	return result, nil
+}
+
+// RunGPUDrivers installs and configures the selected GPU drivers
+func (s *InstallationService) RunGPUDrivers(ctx context.Context, cmd commands.InstallGPUDriversCommand) (*dto.GPUDriversResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.gpuDriversHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
+	return result, nil
+}
+
+// RunLaptop applies the laptop profile
+func (s *InstallationService) RunLaptop(ctx context.Context, cmd commands.ConfigureLaptopCommand) (*dto.LaptopResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.laptopHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
+	return result, nil
+}
+
+// RunCPUPower installs the power daemon and writes the CPU power settings
+func (s *InstallationService) RunCPUPower(ctx context.Context, cmd commands.ConfigureCPUPowerCommand) (*dto.CPUPowerResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.cpuPowerHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
+	return result, nil
+}
+
+// RunInventory writes the hardware inventory to the target. The inventory
+// is informational: a failure is logged and the installation goes on.
+func (s *InstallationService) RunInventory(ctx context.Context, cmd commands.WriteInventoryCommand) (*dto.InventoryResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.inventoryHandler.Handle(ctx, cmd)
+	if err == nil && !result.Success {
+		err = errors.New(result.ErrorDetail)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to write hardware inventory, continuing without it", "error", err)
+		return result, nil
+	}
+
+	s.hardwareSummary = result.Summary
 	return result, nil
 }
 
@@ -253,23 +368,75 @@ func (s *InstallationService) RunPostInstall(ctx context.Context, cmd commands.P
 		return nil, errors.New("installation not started")
 	}
 
-	s.tracker.EmitPhaseStarted("Post-Installation", 7, 8)
-
 	result, err := s.postInstallHandler.Handle(ctx, cmd)
 	if err != nil {
-		s.tracker.EmitPhaseError("Post-Installation", 7, 8, err.Error())
 		return nil, err
 	}
 
 	if !result.Success {
-		s.tracker.EmitPhaseError("Post-Installation", 7, 8, result.ErrorDetail)
 		return result, errors.New(result.ErrorDetail)
 	}
 
-	s.tracker.EmitPhaseCompleted("Post-Installation", 7, 8)
+	s.baselineSnapshot = result.BaselineSnapshot
+	return result, nil
+}
+
+// RunHooks runs the user hooks of a hook point
+func (s *InstallationService) RunHooks(ctx context.Context, cmd commands.RunHooksCommand) (*dto.HooksResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.hookHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
 	return result, nil
 }
 
+// RunOfflinePrepare points the host pacman at the offline bundle repositories
+func (s *InstallationService) RunOfflinePrepare(ctx context.Context, cmd commands.PrepareOfflineCommand) (*dto.OfflineResult, error) {
+	if s.installAgg == nil {
+		return nil, errors.New("installation not started")
+	}
+
+	result, err := s.offlineHandler.Handle(ctx, cmd)
+	if err != nil {
+		return result, err
+	}
+
+	if !result.Success {
+		return result, errors.New(result.ErrorDetail)
+	}
+
+	return result, nil
+}
+
+// AttachOffline points the chroot pacman at the offline bundle repositories
+func (s *InstallationService) AttachOffline(ctx context.Context, cmd commands.PrepareOfflineCommand, result *dto.OfflineResult) error {
+	return s.offlineHandler.Attach(ctx, cmd, result)
+}
+
+// DetachOffline restores the target mirrorlist and unmounts the bundle repositories
+func (s *InstallationService) DetachOffline(ctx context.Context, result *dto.OfflineResult) error {
+	return s.offlineHandler.Detach(ctx, result)
+}
+
+// CleanupOffline detaches the target and removes the host offline configuration
+func (s *InstallationService) CleanupOffline(ctx context.Context, result *dto.OfflineResult) error {
+	return s.offlineHandler.Cleanup(ctx, result)
+}
+
+// DiscoverHooks returns the hooks of a hook directory
+func (s *InstallationService) DiscoverHooks(dir string) (installation.Hooks, error) {
+	return s.hookHandler.Discover(dir)
+}
+
 // Complete marks installation as complete
 func (s *InstallationService) Complete(ctx context.Context) error {
 	if s.installAgg == nil {
@@ -279,11 +446,9 @@ func (s *InstallationService) Complete(ctx context.Context) error {
 	duration := int(time.Since(s.startTime).Seconds())
 	if err := s.installAgg.Complete(duration); err != nil {
 		s.logger.Error("Failed to complete installation", "error", err)
-		s.tracker.EmitPhaseError("Completion", 8, 8, err.Error())
 		return err
 	}
 
-	s.tracker.EmitPhaseCompleted("Completion", 8, 8)
 	s.logger.Info("Installation completed successfully", "duration", duration)
 
 	return nil
@@ -301,16 +466,19 @@ func (s *InstallationService) GetStatus() *dto.InstallationStatus {
 	startedAt := s.installAgg.StartedAt()
 
 	return &dto.InstallationStatus{
-		ID:             s.installAgg.ID(),
-		State:          s.installAgg.State().String(),
-		Hostname:       s.installAgg.Hostname(),
-		Username:       s.installAgg.Username(),
-		TargetDisk:     s.installAgg.TargetDisk(),
-		EncryptionType: s.installAgg.EncryptionType(),
-		Progress:       s.installAgg.ProgressPercentage(),
-		StartedAt:      startedAt,
-		CompletedAt:    completedAt,
-		CurrentPhase:   s.installAgg.State().String(),
+		ID:               s.installAgg.ID(),
+		State:            s.installAgg.State().String(),
+		Hostname:         s.installAgg.Hostname(),
+		Username:         s.installAgg.Username(),
+		TargetDisk:       s.installAgg.TargetDisk(),
+		EncryptionType:   s.installAgg.EncryptionType(),
+		Progress:         s.installAgg.ProgressPercentage(),
+		StartedAt:        startedAt,
+		CompletedAt:      completedAt,
+		CurrentPhase:     s.installAgg.State().String(),
+		BaselineSnapshot: s.baselineSnapshot,
+		Hardware:         s.hardwareSummary,
+		AURFailed:        s.aurFailed,
 	}
 }
 
@@ -2,7 +2,7 @@ package services
 
 import (
 	"context"
-	"net/http"
+	"os"
 	"testing"
 
 	"github.com/bnema/archup/internal/application/commands"
@@ -10,25 +10,16 @@ import (
 	"github.com/bnema/archup/internal/domain/bootloader"
 	"github.com/bnema/archup/internal/domain/disk"
 	"github.com/bnema/archup/internal/domain/packages"
-	"github.com/bnema/archup/internal/domain/ports"
 	"github.com/bnema/archup/internal/domain/ports/mocks"
 	"go.uber.org/mock/gomock"
 )
 
-func newMockResponse(ctrl *gomock.Controller, statusCode int, body []byte) ports.Response {
-	resp := mocks.NewMockResponse(ctrl)
-	resp.EXPECT().StatusCode().Return(statusCode).AnyTimes()
-	resp.EXPECT().Body().Return(body).AnyTimes()
-	resp.EXPECT().Close().Return(nil).AnyTimes()
-	return resp
-}
-
 func createTestService(ctrl *gomock.Controller) *InstallationService {
 	mockFS := mocks.NewMockFileSystem(ctrl)
 	mockExec := mocks.NewMockCommandExecutor(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockRepo := mocks.NewMockInstallationRepository(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
 
@@ -38,6 +29,7 @@ func createTestService(ctrl *gomock.Controller) *InstallationService {
 	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
 	mockLogger.EXPECT().LogPath().Return("/var/log/archup-install.log").AnyTimes()
+	mockExec.EXPECT().Execute(gomock.Any(), "blkid", "-s", "UUID", "-o", "value", gomock.Any()).Return([]byte("1234-ABCD\n"), nil).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockExec.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
@@ -51,21 +43,23 @@ func createTestService(ctrl *gomock.Controller) *InstallationService {
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Chmod(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
+	mockAssets.EXPECT().String().Return("embedded").AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChrootWithStdin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	bootstrapHandler := handlers.NewBootstrapHandler(mockFS, mockHTTP, mockLogger, "https://github.com/bnema/archup", "https://raw.githubusercontent.com/bnema/archup/dev", "dev")
+	bootstrapHandler := handlers.NewBootstrapHandler(mockFS, mockAssets, mockLogger)
 	preflightHandler := handlers.NewPreflightHandler(mockFS, mockExec, mockLogger)
 	partitionHandler := handlers.NewPartitionHandler(mockExec, mockLogger)
 	baseHandler := handlers.NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
 	configHandler := handlers.NewConfigureSystemHandler(mockFS, mockChrExec, mockLogger)
 	bootloaderHandler := handlers.NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
 	reposHandler := handlers.NewReposHandler(mockFS, mockChrExec, mockLogger)
-	postInstallHandler := handlers.NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	postInstallHandler := handlers.NewPostInstallHandler(mockFS, mockAssets, mockExec, mockChrExec, mockScriptExec, mockLogger)
 
 	return NewInstallationService(
 		mockRepo,
@@ -77,7 +71,14 @@ func createTestService(ctrl *gomock.Controller) *InstallationService {
 		configHandler,
 		bootloaderHandler,
 		reposHandler,
+		handlers.NewGPUDriversHandler(mockFS, mockChrExec, mockLogger),
+		handlers.NewCPUPowerHandler(mockFS, mockChrExec, mockLogger),
+		handlers.NewLaptopHandler(mockFS, mockChrExec, mockLogger),
+		handlers.NewMirrorHandler(mockFS, mocks.NewMockHTTPClient(ctrl), mockLogger),
+		handlers.NewInventoryHandler(mockFS, mockLogger),
 		postInstallHandler,
+		handlers.NewHookHandler(mockFS, mockScriptExec, mockChrExec, mockLogger),
+		handlers.NewOfflineHandler(mockFS, mockExec, mockLogger),
 	)
 }
 
@@ -116,7 +117,7 @@ func TestInstallationService_Start_InvalidHostname(t *testing.T) {
 	mockExec := mocks.NewMockCommandExecutor(ctrl)
 	mockChrExec := mocks.NewMockChrootExecutor(ctrl)
 	mockScriptExec := mocks.NewMockScriptExecutor(ctrl)
-	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	mockAssets := mocks.NewMockAssetSource(ctrl)
 	mockRepo := mocks.NewMockInstallationRepository(ctrl)
 	mockLogger := mocks.NewMockLogger(ctrl)
 
@@ -137,23 +138,25 @@ func TestInstallationService_Start_InvalidHostname(t *testing.T) {
 	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Chmod(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockFS.EXPECT().Stat(gomock.Any()).Return(nil, nil).AnyTimes()
-	mockHTTP.EXPECT().Get(gomock.Any()).Return(newMockResponse(ctrl, http.StatusOK, []byte("content")), nil).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockAssets.EXPECT().ReadAsset(gomock.Any()).Return([]byte("content"), nil).AnyTimes()
+	mockAssets.EXPECT().String().Return("embedded").AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChroot(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{}, nil).AnyTimes()
 	mockChrExec.EXPECT().ExecuteInChrootWithStdin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockChrExec.EXPECT().ChrootSystemctl(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	bootstrapHandler := handlers.NewBootstrapHandler(mockFS, mockHTTP, mockLogger, "https://github.com/bnema/archup", "https://raw.githubusercontent.com/bnema/archup/dev", "dev")
+	bootstrapHandler := handlers.NewBootstrapHandler(mockFS, mockAssets, mockLogger)
 	preflightHandler := handlers.NewPreflightHandler(mockFS, mockExec, mockLogger)
 	partitionHandler := handlers.NewPartitionHandler(mockExec, mockLogger)
 	baseHandler := handlers.NewInstallBaseHandler(mockFS, mockExec, mockChrExec, mockLogger)
 	configHandler := handlers.NewConfigureSystemHandler(mockFS, mockChrExec, mockLogger)
 	bootloaderHandler := handlers.NewBootloaderHandler(mockFS, mockExec, mockChrExec, mockLogger)
 	reposHandler := handlers.NewReposHandler(mockFS, mockChrExec, mockLogger)
-	postInstallHandler := handlers.NewPostInstallHandler(mockFS, mockHTTP, mockChrExec, mockScriptExec, mockLogger, "https://raw.githubusercontent.com/bnema/archup/dev")
+	postInstallHandler := handlers.NewPostInstallHandler(mockFS, mockAssets, mockExec, mockChrExec, mockScriptExec, mockLogger)
 
-	service := NewInstallationService(mockRepo, mockLogger, bootstrapHandler, preflightHandler, partitionHandler, baseHandler, configHandler, bootloaderHandler, reposHandler, postInstallHandler)
+	service := NewInstallationService(mockRepo, mockLogger, bootstrapHandler, preflightHandler, partitionHandler, baseHandler, configHandler, bootloaderHandler, reposHandler, handlers.NewGPUDriversHandler(mockFS, mockChrExec, mockLogger), handlers.NewCPUPowerHandler(mockFS, mockChrExec, mockLogger), handlers.NewLaptopHandler(mockFS, mockChrExec, mockLogger), handlers.NewMirrorHandler(mockFS, mocks.NewMockHTTPClient(ctrl), mockLogger), handlers.NewInventoryHandler(mockFS, mockLogger), postInstallHandler, handlers.NewHookHandler(mockFS, mockScriptExec, mockChrExec, mockLogger), handlers.NewOfflineHandler(mockFS, mockExec, mockLogger))
 	defer func() {
 		if err := service.Close(); err != nil {
 			t.Fatalf("expected no error, got %v", err)
@@ -429,6 +432,43 @@ func TestInstallationService_RunRepositorySetup(t *testing.T) {
 	}
 }
 
+func TestInstallationService_RunInventory_WriteFailureIsNotFatal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := createTestService(ctrl)
+	defer func() {
+		if err := service.Close(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	if err := service.Start(ctx, "myarch", "testuser", "/dev/sda", "none"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The target refuses the inventory file
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockFS.EXPECT().ReadFile(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().ReadDir(gomock.Any()).Return(nil, os.ErrNotExist).AnyTimes()
+	mockFS.EXPECT().MkdirAll(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFS.EXPECT().WriteFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(os.ErrPermission)
+	service.inventoryHandler = handlers.NewInventoryHandler(mockFS, service.logger)
+
+	result, err := service.RunInventory(ctx, commands.WriteInventoryCommand{MountPoint: "/mnt"})
+
+	if err != nil {
+		t.Fatalf("expected a failed inventory not to fail the installation, got %v", err)
+	}
+	if result == nil || result.Success || result.ErrorDetail == "" {
+		t.Errorf("expected the failed inventory result, got %+v", result)
+	}
+	if len(service.GetStatus().Hardware) != 0 {
+		t.Errorf("expected no hardware summary, got %v", service.GetStatus().Hardware)
+	}
+}
+
 func TestInstallationService_RunPostInstall(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/disk"
+	"github.com/bnema/archup/internal/domain/installation"
+)
+
+// Built-in phase names, usable as anchors for Pipeline.InsertBefore and InsertAfter
+const (
+	PhasePreflight    = "Preflight Checks"
+	PhaseBootstrap    = "Bootstrap"
+	PhasePartition    = "Disk Partitioning"
+	PhaseMirrors      = "Mirror Selection"
+	PhaseBaseInstall  = "Base Installation"
+	PhaseConfigSystem = "System Configuration"
+	PhaseLaptop       = "Laptop Profile"
+	PhaseBootloader   = "Bootloader Setup"
+	PhaseRepositories = "Repository Setup"
+	PhaseGPUDrivers   = "GPU Drivers"
+	PhaseCPUPower     = "CPU Power"
+	PhaseInventory    = "Hardware Inventory"
+	PhasePostInstall  = "Post-Installation"
+
+	PhaseOfflineHost    = "Offline Repositories"
+	PhaseOfflineTarget  = "Offline Target"
+	PhaseOfflineCleanup = "Offline Cleanup"
+)
+
+// InstallPlan holds the commands of the built-in phases. Values only known
+// once an earlier phase ran (partition devices) are filled in by the phases.
+type InstallPlan struct {
+	Partition    commands.PartitionDiskCommand
+	Mirrors      commands.SelectMirrorsCommand
+	Base         commands.InstallBaseCommand
+	Config       commands.ConfigureSystemCommand
+	Laptop       commands.ConfigureLaptopCommand
+	Bootloader   commands.InstallBootloaderCommand
+	Repositories commands.SetupRepositoriesCommand
+	GPUDrivers   commands.InstallGPUDriversCommand
+	CPUPower     commands.ConfigureCPUPowerCommand
+	Inventory    commands.WriteInventoryCommand
+	PostInstall  commands.PostInstallCommand
+
+	// User hook scripts, each hook point with hooks becomes a phase
+	Hooks      installation.Hooks
+	HookPolicy installation.HookFailurePolicy
+
+	// Offline bundle to install from instead of the network mirrors, nil online
+	Offline *bundle.Bundle
+}
+
+// hookAnchors places each hook point before or after a built-in phase
+var hookAnchors = map[installation.HookPoint]struct {
+	phase  string
+	before bool
+}{
+	installation.HookPrePartition:   {PhasePartition, true},
+	installation.HookPostPartition:  {PhasePartition, false},
+	installation.HookPostBase:       {PhaseBaseInstall, false},
+	installation.HookPostConfig:     {PhaseConfigSystem, false},
+	installation.HookPostBootloader: {PhaseBootloader, false},
+	installation.HookPostInstall:    {PhasePostInstall, false},
+}
+
+// NewPipeline returns the pipeline of built-in and hook phases for plan,
+// completing the installation once all phases ran.
+// Phases changing the host or the target disk layout have a rollback: the
+// install files are removed, the UEFI boot entry deleted, the host pacman.conf
+// and mirrorlist restored and the target filesystems unmounted. Changes inside
+// the target filesystems are not undone, the next attempt wipes the disk again.
+func (s *InstallationService) NewPipeline(plan InstallPlan) *Pipeline {
+	pipeline := NewPipeline(s.tracker, s.logger, s.Complete,
+		FuncPhase{
+			PhaseName: PhasePreflight,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Preflight, err = s.RunPreflight(ctx)
+				return err
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseBootstrap,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Bootstrap, err = s.RunBootstrap(ctx)
+				return err
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				return s.RollbackBootstrap(ctx, state.Bootstrap)
+			},
+		},
+		FuncPhase{
+			PhaseName: PhasePartition,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Partition, err = s.RunPartition(ctx, plan.Partition)
+				return err
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				return s.RollbackPartition(ctx, state.Partition)
+			},
+		},
+		// The offline bundle replaces the mirrors
+		FuncPhase{
+			PhaseName: PhaseMirrors,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Mirrors, err = s.RunMirrors(ctx, plan.Mirrors)
+				return err
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				return s.RollbackMirrors(ctx, state.Mirrors)
+			},
+			SkipFunc: func(state *PipelineState) bool {
+				return plan.Offline != nil
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseBaseInstall,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				cmd := plan.Base
+				if state.Mirrors != nil {
+					cmd.Mirrorlist = state.Mirrors.Mirrorlist
+				}
+				if state.Offline != nil {
+					cmd.PacmanConfig = state.Offline.PacmanConf
+				}
+				state.Base, err = s.RunBaseInstall(ctx, cmd)
+				return err
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				return s.RollbackBaseInstall(ctx, state.Base)
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseConfigSystem,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Config, err = s.RunConfigSystem(ctx, plan.Config)
+				return err
+			},
+		},
+		// Runs before the bootloader, which adds the hibernation resume device
+		FuncPhase{
+			PhaseName: PhaseLaptop,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.Laptop, err = s.RunLaptop(ctx, plan.Laptop)
+				return err
+			},
+			SkipFunc: func(state *PipelineState) bool {
+				return !plan.Laptop.Enabled
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseBootloader,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				cmd := plan.Bootloader
+				if state.Partition != nil {
+					cmd.RootPartition = state.Partition.RootPartition
+					cmd.EFIPartition = state.Partition.EFIPartition
+				}
+				if state.Laptop != nil && state.Laptop.Hibernate {
+					cmd.CmdlinePresets.Resume = state.Laptop.ResumeDevice
+					cmd.CmdlinePresets.ResumeOffset = state.Laptop.ResumeOffset
+				}
+				state.Bootloader, err = s.RunBootloaderSetup(ctx, cmd)
+				return err
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				return s.RollbackBootloader(ctx, state.Bootloader)
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseRepositories,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				cmd := plan.Repositories
+				cmd.Offline = state.Offline != nil
+				state.Repositories, err = s.RunRepositorySetup(ctx, cmd)
+				return err
+			},
+		},
+		// Runs once multilib is enabled, for the lib32 drivers
+		FuncPhase{
+			PhaseName: PhaseGPUDrivers,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.GPUDrivers, err = s.RunGPUDrivers(ctx, plan.GPUDrivers)
+				return err
+			},
+			SkipFunc: func(state *PipelineState) bool {
+				return len(plan.GPUDrivers.Drivers) == 0
+			},
+		},
+		FuncPhase{
+			PhaseName: PhaseCPUPower,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				state.CPUPower, err = s.RunCPUPower(ctx, plan.CPUPower)
+				return err
+			},
+		},
+		// Runs before post-install so the baseline snapshot holds the inventory
+		FuncPhase{
+			PhaseName: PhaseInventory,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				cmd := plan.Inventory
+				if state.Preflight != nil && state.Preflight.SystemInfo != nil {
+					cmd.Architecture = state.Preflight.SystemInfo.Architecture
+					cmd.UEFI = state.Preflight.SystemInfo.IsUEFI
+					cmd.SecureBoot = state.Preflight.SystemInfo.SecureBootEnabled
+				}
+				state.Inventory, err = s.RunInventory(ctx, cmd)
+				return err
+			},
+		},
+		FuncPhase{
+			PhaseName: PhasePostInstall,
+			RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+				cmd := plan.PostInstall
+				if state.Partition != nil {
+					cmd.RootDevice = rootDevice(state.Partition)
+				}
+				state.PostInstall, err = s.RunPostInstall(ctx, cmd)
+				return err
+			},
+		},
+	)
+
+	for _, point := range installation.HookPoints() {
+		if len(plan.Hooks.At(point)) == 0 {
+			continue
+		}
+		phase := s.hookPhase(point, plan)
+		anchor := hookAnchors[point]
+		insert := pipeline.InsertAfter
+		if anchor.before {
+			insert = pipeline.InsertBefore
+		}
+		if err := insert(anchor.phase, phase); err != nil {
+			s.logger.Warn("Failed to add hook phase", "point", point, "error", err)
+		}
+	}
+
+	if plan.Offline != nil {
+		s.addOfflinePhases(pipeline, plan)
+	}
+
+	return pipeline
+}
+
+// addOfflinePhases points pacstrap at the bundle before the base install and
+// the chroot pacman right after it, ahead of the post-base hooks. The cleanup
+// phase restores the target mirrorlist and unmounts the bundle before the
+// post-install baseline snapshot is taken.
+func (s *InstallationService) addOfflinePhases(pipeline *Pipeline, plan InstallPlan) {
+	cmd := commands.PrepareOfflineCommand{
+		Bundle:     plan.Offline,
+		MountPoint: plan.Base.MountPoint,
+	}
+
+	if err := pipeline.InsertBefore(PhaseBaseInstall, FuncPhase{
+		PhaseName: PhaseOfflineHost,
+		RunFunc: func(ctx context.Context, state *PipelineState) (err error) {
+			state.Offline, err = s.RunOfflinePrepare(ctx, cmd)
+			return err
+		},
+		RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+			return s.CleanupOffline(ctx, state.Offline)
+		},
+	}); err != nil {
+		s.logger.Warn("Failed to add offline phase", "phase", PhaseOfflineHost, "error", err)
+	}
+
+	if err := pipeline.InsertAfter(PhaseBaseInstall, FuncPhase{
+		PhaseName: PhaseOfflineTarget,
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			return s.AttachOffline(ctx, cmd, state.Offline)
+		},
+		RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+			return s.DetachOffline(ctx, state.Offline)
+		},
+	}); err != nil {
+		s.logger.Warn("Failed to add offline phase", "phase", PhaseOfflineTarget, "error", err)
+	}
+
+	if err := pipeline.InsertBefore(PhasePostInstall, FuncPhase{
+		PhaseName: PhaseOfflineCleanup,
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			return s.CleanupOffline(ctx, state.Offline)
+		},
+	}); err != nil {
+		s.logger.Warn("Failed to add offline phase", "phase", PhaseOfflineCleanup, "error", err)
+	}
+}
+
+// hookPhase returns the phase running the hooks of a hook point
+func (s *InstallationService) hookPhase(point installation.HookPoint, plan InstallPlan) Phase {
+	return FuncPhase{
+		PhaseName: "Hooks: " + string(point),
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			_, err := s.RunHooks(ctx, commands.RunHooksCommand{
+				Point:      point,
+				Hooks:      plan.Hooks,
+				MountPoint: plan.Base.MountPoint,
+				Env:        hookEnv(plan, state),
+				Policy:     plan.HookPolicy,
+			})
+			return err
+		},
+	}
+}
+
+// hookEnv returns the ARCHUP_* environment of hook scripts, from the plan and
+// the results of the phases that ran so far
+func hookEnv(plan InstallPlan, state *PipelineState) map[string]string {
+	env := map[string]string{
+		"ARCHUP_MOUNTPOINT":  plan.Base.MountPoint,
+		"ARCHUP_TARGET_DISK": plan.Partition.TargetDisk,
+		"ARCHUP_ENCRYPTION":  encryptionName(plan.Partition.EncryptionType),
+		"ARCHUP_KERNEL":      plan.Base.KernelVariant.String(),
+		"ARCHUP_HOSTNAME":    plan.Config.Hostname,
+		"ARCHUP_USERNAME":    plan.Config.Username,
+		"ARCHUP_TIMEZONE":    plan.Config.Timezone,
+		"ARCHUP_LOCALE":      plan.Config.Locale,
+	}
+	if state.Partition != nil {
+		env["ARCHUP_ROOT_PARTITION"] = state.Partition.RootPartition
+		env["ARCHUP_EFI_PARTITION"] = state.Partition.EFIPartition
+		env["ARCHUP_CRYPT_DEVICE"] = state.Partition.CryptDevice
+		env["ARCHUP_ROOT_DEVICE"] = rootDevice(state.Partition)
+	}
+	return env
+}
+
+// encryptionName returns the ARCHUP_ENCRYPTION answer file value of an encryption type
+func encryptionName(encryption disk.EncryptionType) string {
+	switch encryption {
+	case disk.EncryptionTypeLUKS:
+		return "luks"
+	case disk.EncryptionTypeLUKSLVM:
+		return "luks-lvm"
+	default:
+		return "none"
+	}
+}
+
+// rootDevice returns the device holding the Btrfs filesystem: the opened LUKS
+// container when encrypted, the root partition otherwise
+func rootDevice(result *dto.PartitionResult) string {
+	if result.CryptDevice != "" {
+		return result.CryptDevice
+	}
+	return result.RootPartition
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// Phase is one step of the installation pipeline.
+// Built-in phases wrap the application handlers, custom phases (corporate CA
+// certificates, agent installs, ...) are inserted with Pipeline.InsertBefore,
+// InsertAfter or Append.
+type Phase interface {
+	// Name is the phase name shown in the progress view, unique in a pipeline
+	Name() string
+
+	// Skip returns true if the phase should not run for this installation
+	Skip(state *PipelineState) bool
+
+	// Run executes the phase, storing results later phases need in state
+	Run(ctx context.Context, state *PipelineState) error
+
+	// Rollback undoes what Run changed. It runs for completed phases and for
+	// the failed one, so it must cope with the partial results of a failed Run.
+	Rollback(ctx context.Context, state *PipelineState) error
+}
+
+// Reversible is implemented by phases that know whether they have anything to
+// roll back. Phases not implementing it are always rolled back.
+type Reversible interface {
+	Reversible() bool
+}
+
+// PipelineError is returned by Pipeline.Run when a phase failed or the run was
+// cancelled, after the pipeline rolled back what already ran
+type PipelineError struct {
+	Phase          string   // Phase that failed, or the next one when cancelled
+	Err            error    // Phase error, or the context error when cancelled
+	RolledBack     []string // Phases rolled back, latest first
+	RollbackErrors []string // Phases whose rollback failed, as "phase: error"
+}
+
+// Error returns the failed phase and its error
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Phase, e.Err)
+}
+
+// Unwrap returns the phase error
+func (e *PipelineError) Unwrap() error {
+	return e.Err
+}
+
+// PipelineState carries the results of completed phases to the following ones
+type PipelineState struct {
+	Preflight    *dto.PreflightResult
+	Bootstrap    *dto.BootstrapResult
+	Partition    *dto.PartitionResult
+	Mirrors      *dto.MirrorsResult
+	Base         *dto.InstallBaseResult
+	Config       *dto.ConfigureSystemResult
+	Bootloader   *dto.BootloaderResult
+	Repositories *dto.RepositoriesResult
+	GPUDrivers   *dto.GPUDriversResult
+	CPUPower     *dto.CPUPowerResult
+	Laptop       *dto.LaptopResult
+	Inventory    *dto.InventoryResult
+	PostInstall  *dto.PostInstallResult
+	Offline      *dto.OfflineResult
+}
+
+// FuncPhase adapts functions to the Phase interface. Nil SkipFunc never
+// skips, nil RollbackFunc has nothing to undo.
+type FuncPhase struct {
+	PhaseName    string
+	RunFunc      func(ctx context.Context, state *PipelineState) error
+	RollbackFunc func(ctx context.Context, state *PipelineState) error
+	SkipFunc     func(state *PipelineState) bool
+}
+
+// Name returns the phase name
+func (p FuncPhase) Name() string {
+	return p.PhaseName
+}
+
+// Skip calls SkipFunc if set
+func (p FuncPhase) Skip(state *PipelineState) bool {
+	return p.SkipFunc != nil && p.SkipFunc(state)
+}
+
+// Run calls RunFunc
+func (p FuncPhase) Run(ctx context.Context, state *PipelineState) error {
+	return p.RunFunc(ctx, state)
+}
+
+// Rollback calls RollbackFunc if set
+func (p FuncPhase) Rollback(ctx context.Context, state *PipelineState) error {
+	if p.RollbackFunc == nil {
+		return nil
+	}
+	return p.RollbackFunc(ctx, state)
+}
+
+// Reversible returns true if RollbackFunc is set
+func (p FuncPhase) Reversible() bool {
+	return p.RollbackFunc != nil
+}
+
+// Pipeline runs phases in order and numbers their progress updates.
+// The final step completes the installation and is numbered after the last phase.
+type Pipeline struct {
+	phases    []Phase
+	completed []Phase
+	state     *PipelineState
+	complete  func(ctx context.Context) error
+
+	tracker *ProgressTracker
+	logger  ports.Logger
+}
+
+// NewPipeline creates a pipeline running phases then complete
+func NewPipeline(tracker *ProgressTracker, logger ports.Logger, complete func(ctx context.Context) error, phases ...Phase) *Pipeline {
+	return &Pipeline{
+		phases:   phases,
+		state:    &PipelineState{},
+		complete: complete,
+		tracker:  tracker,
+		logger:   logger,
+	}
+}
+
+// Phases returns the phases in run order
+func (p *Pipeline) Phases() []Phase {
+	return p.phases
+}
+
+// PhaseNames returns the phase names in run order
+func (p *Pipeline) PhaseNames() []string {
+	names := make([]string, 0, len(p.phases))
+	for _, phase := range p.phases {
+		names = append(names, phase.Name())
+	}
+	return names
+}
+
+// State returns the results collected by the phases
+func (p *Pipeline) State() *PipelineState {
+	return p.state
+}
+
+// Completed returns the phases that ran successfully, in run order
+func (p *Pipeline) Completed() []Phase {
+	return p.completed
+}
+
+// Append adds a phase after the last one
+func (p *Pipeline) Append(phase Phase) error {
+	return p.insert(len(p.phases), phase)
+}
+
+// InsertBefore adds a phase before the named phase
+func (p *Pipeline) InsertBefore(name string, phase Phase) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	return p.insert(i, phase)
+}
+
+// InsertAfter adds a phase after the named phase
+func (p *Pipeline) InsertAfter(name string, phase Phase) error {
+	i, err := p.index(name)
+	if err != nil {
+		return err
+	}
+	return p.insert(i+1, phase)
+}
+
+// Run executes the phases in order, stopping at the first failure or when ctx
+// is cancelled. It then rolls back the failed phase and the completed ones in
+// reverse order and returns a *PipelineError.
+func (p *Pipeline) Run(ctx context.Context) error {
+	total := len(p.phases) + 1
+
+	for i, phase := range p.phases {
+		number := i + 1
+		name := phase.Name()
+
+		if err := ctx.Err(); err != nil {
+			p.tracker.EmitPhaseError(name, number, total, "Installation cancelled")
+			return p.rollback(ctx, name, err, nil)
+		}
+
+		if phase.Skip(p.state) {
+			p.logger.Info("Skipping phase", "phase", name)
+			p.tracker.EmitPhaseCompleted(name, number, total)
+			continue
+		}
+
+		p.logger.Info("Running phase", "phase", name, "number", number, "total", total)
+		p.tracker.EmitPhaseStarted(name, number, total)
+		if err := phase.Run(ctx, p.state); err != nil {
+			p.logger.Error("Phase failed", "phase", name, "error", err)
+			p.tracker.EmitPhaseError(name, number, total, err.Error())
+			return p.rollback(ctx, name, err, phase)
+		}
+		p.completed = append(p.completed, phase)
+		p.tracker.EmitPhaseCompleted(name, number, total)
+	}
+
+	if p.complete != nil {
+		if err := p.complete(ctx); err != nil {
+			p.tracker.EmitPhaseError("Completion", total, total, err.Error())
+			return err
+		}
+	}
+	p.tracker.EmitPhaseCompleted("Completion", total, total)
+	return nil
+}
+
+// rollback undoes the failed phase, if any, then the completed phases latest first
+func (p *Pipeline) rollback(ctx context.Context, name string, err error, failed Phase) error {
+	pipelineErr := &PipelineError{Phase: name, Err: err}
+
+	// Rollback runs after a cancel too, only the caller's deadline would stop it
+	ctx = context.WithoutCancel(ctx)
+
+	phases := p.completed
+	if failed != nil {
+		phases = append(append([]Phase{}, p.completed...), failed)
+	}
+	for i := len(phases) - 1; i >= 0; i-- {
+		phase := phases[i]
+		if r, ok := phase.(Reversible); ok && !r.Reversible() {
+			continue
+		}
+
+		p.logger.Warn("Rolling back phase", "phase", phase.Name())
+		p.tracker.Emit(&dto.ProgressUpdate{
+			Phase:   phase.Name(),
+			Message: "Rolling back " + phase.Name(),
+		})
+		if err := phase.Rollback(ctx, p.state); err != nil {
+			p.logger.Error("Phase rollback failed", "phase", phase.Name(), "error", err)
+			pipelineErr.RollbackErrors = append(pipelineErr.RollbackErrors, fmt.Sprintf("%s: %v", phase.Name(), err))
+			continue
+		}
+		pipelineErr.RolledBack = append(pipelineErr.RolledBack, phase.Name())
+	}
+
+	return pipelineErr
+}
+
+func (p *Pipeline) index(name string) (int, error) {
+	for i, phase := range p.phases {
+		if phase.Name() == name {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown phase %q", name)
+}
+
+func (p *Pipeline) insert(i int, phase Phase) error {
+	if _, err := p.index(phase.Name()); err == nil {
+		return fmt.Errorf("duplicate phase %q", phase.Name())
+	}
+	p.phases = append(p.phases[:i], append([]Phase{phase}, p.phases[i:]...)...)
+	return nil
+}
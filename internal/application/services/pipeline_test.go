@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bnema/archup/internal/application/commands"
+	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/domain/bundle"
+	"github.com/bnema/archup/internal/domain/disk"
+	"github.com/bnema/archup/internal/domain/installation"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestPipeline(t *testing.T, ran *[]string, phases ...string) (*Pipeline, *ProgressTracker) {
+	ctrl := gomock.NewController(t)
+	mockLogger := mocks.NewMockLogger(ctrl)
+	mockLogger.EXPECT().Info(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Warn(gomock.Any(), gomock.Any()).AnyTimes()
+	mockLogger.EXPECT().Error(gomock.Any(), gomock.Any()).AnyTimes()
+
+	tracker := NewProgressTracker()
+	t.Cleanup(tracker.Close)
+
+	pipeline := NewPipeline(tracker, mockLogger, nil)
+	for _, name := range phases {
+		if err := pipeline.Append(recordingPhase(name, ran)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	return pipeline, tracker
+}
+
+func recordingPhase(name string, ran *[]string) FuncPhase {
+	return FuncPhase{
+		PhaseName: name,
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			*ran = append(*ran, name)
+			return nil
+		},
+	}
+}
+
+func drain(ch <-chan *dto.ProgressUpdate) []*dto.ProgressUpdate {
+	var updates []*dto.ProgressUpdate
+	for {
+		select {
+		case update := <-ch:
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
+
+func TestPipeline_Insert(t *testing.T) {
+	var ran []string
+	pipeline, _ := newTestPipeline(t, &ran, "partition", "base", "post")
+
+	if err := pipeline.InsertAfter("base", recordingPhase("ca-certificates", &ran)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := pipeline.InsertBefore("partition", recordingPhase("inventory", &ran)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{"inventory", "partition", "base", "ca-certificates", "post"}
+	if got := pipeline.PhaseNames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected phases %v, got %v", expected, got)
+	}
+
+	if err := pipeline.InsertAfter("missing", recordingPhase("agent", &ran)); err == nil {
+		t.Error("expected error for unknown anchor phase")
+	}
+	if err := pipeline.Append(recordingPhase("base", &ran)); err == nil {
+		t.Error("expected error for duplicate phase")
+	}
+}
+
+func TestPipeline_Run(t *testing.T) {
+	var ran []string
+	pipeline, tracker := newTestPipeline(t, &ran, "partition", "base")
+	if err := pipeline.Append(FuncPhase{
+		PhaseName: "agent",
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			t.Error("skipped phase must not run")
+			return nil
+		},
+		SkipFunc: func(state *PipelineState) bool { return true },
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ch := tracker.Subscribe()
+
+	if err := pipeline.Run(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(ran, []string{"partition", "base"}) {
+		t.Errorf("unexpected run order %v", ran)
+	}
+	if len(pipeline.Completed()) != 2 {
+		t.Errorf("expected 2 completed phases, got %d", len(pipeline.Completed()))
+	}
+
+	updates := drain(ch)
+	last := updates[len(updates)-1]
+	if last.Phase != "Completion" || last.PhaseNumber != 4 || last.TotalPhases != 4 || last.ProgressPercent != 100 {
+		t.Errorf("unexpected final update %+v", last)
+	}
+	for _, update := range updates {
+		if update.Phase == "base" && update.PhaseNumber != 2 {
+			t.Errorf("expected base to be phase 2, got %d", update.PhaseNumber)
+		}
+	}
+}
+
+func TestPipeline_Run_StopsOnFailure(t *testing.T) {
+	var ran []string
+	pipeline, tracker := newTestPipeline(t, &ran, "partition")
+	if err := pipeline.Append(FuncPhase{
+		PhaseName: "base",
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			return errors.New("pacstrap failed")
+		},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := pipeline.Append(recordingPhase("post", &ran)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ch := tracker.Subscribe()
+
+	err := pipeline.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if !reflect.DeepEqual(ran, []string{"partition"}) {
+		t.Errorf("expected only partition to run, got %v", ran)
+	}
+	if len(pipeline.Completed()) != 1 || pipeline.Completed()[0].Name() != "partition" {
+		t.Errorf("unexpected completed phases %v", pipeline.Completed())
+	}
+
+	updates := drain(ch)
+	last := updates[len(updates)-1]
+	if !last.IsError || last.Phase != "base" || last.PhaseNumber != 2 || last.Message != "pacstrap failed" {
+		t.Errorf("unexpected error update %+v", last)
+	}
+}
+
+func TestPipeline_Run_RollsBack(t *testing.T) {
+	var ran, rolledBack []string
+	pipeline, _ := newTestPipeline(t, &ran)
+	reversible := func(name string, runErr error) FuncPhase {
+		return FuncPhase{
+			PhaseName: name,
+			RunFunc: func(ctx context.Context, state *PipelineState) error {
+				ran = append(ran, name)
+				return runErr
+			},
+			RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+				if ctx.Err() != nil {
+					t.Errorf("rollback of %s got a cancelled context", name)
+				}
+				rolledBack = append(rolledBack, name)
+				if name == "bootstrap" {
+					return errors.New("busy")
+				}
+				return nil
+			},
+		}
+	}
+	for _, phase := range []Phase{
+		reversible("bootstrap", nil),
+		reversible("partition", nil),
+		recordingPhase("config", &ran),
+		reversible("bootloader", errors.New("efibootmgr failed")),
+		reversible("post", nil),
+	} {
+		if err := pipeline.Append(phase); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	err := pipeline.Run(context.Background())
+	var pipelineErr *PipelineError
+	if !errors.As(err, &pipelineErr) {
+		t.Fatalf("expected PipelineError, got %v", err)
+	}
+	if pipelineErr.Phase != "bootloader" || err.Error() != "bootloader: efibootmgr failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// The failed phase is rolled back first, config has nothing to roll back
+	if expected := []string{"bootloader", "partition", "bootstrap"}; !reflect.DeepEqual(rolledBack, expected) {
+		t.Errorf("expected rollback order %v, got %v", expected, rolledBack)
+	}
+	if expected := []string{"bootloader", "partition"}; !reflect.DeepEqual(pipelineErr.RolledBack, expected) {
+		t.Errorf("expected rolled back %v, got %v", expected, pipelineErr.RolledBack)
+	}
+	if expected := []string{"bootstrap: busy"}; !reflect.DeepEqual(pipelineErr.RollbackErrors, expected) {
+		t.Errorf("expected rollback errors %v, got %v", expected, pipelineErr.RollbackErrors)
+	}
+}
+
+func TestPipeline_Run_Cancelled(t *testing.T) {
+	var ran, rolledBack []string
+	ctx, cancel := context.WithCancel(context.Background())
+	pipeline, _ := newTestPipeline(t, &ran)
+	if err := pipeline.Append(FuncPhase{
+		PhaseName: "partition",
+		RunFunc: func(ctx context.Context, state *PipelineState) error {
+			cancel()
+			return nil
+		},
+		RollbackFunc: func(ctx context.Context, state *PipelineState) error {
+			rolledBack = append(rolledBack, "partition")
+			return nil
+		},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := pipeline.Append(recordingPhase("base", &ran)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err := pipeline.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("expected base not to run, got %v", ran)
+	}
+	if !reflect.DeepEqual(rolledBack, []string{"partition"}) {
+		t.Errorf("expected partition to be rolled back, got %v", rolledBack)
+	}
+}
+
+func TestInstallationService_NewPipeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := createTestService(ctrl)
+	defer func() {
+		if err := service.Close(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}()
+
+	expected := []string{
+		PhasePreflight, PhaseBootstrap, PhasePartition, PhaseMirrors, PhaseBaseInstall,
+		PhaseConfigSystem, PhaseLaptop, PhaseBootloader, PhaseRepositories, PhaseGPUDrivers, PhaseCPUPower, PhaseInventory, PhasePostInstall,
+	}
+	if got := service.NewPipeline(InstallPlan{}).PhaseNames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected phases %v, got %v", expected, got)
+	}
+}
+
+func TestInstallationService_NewPipeline_Hooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := createTestService(ctrl)
+	defer func() {
+		if err := service.Close(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}()
+
+	hooks, err := installation.ParseHooks("pre-partition:host:/root/a.sh post-base:chroot:/root/b.sh post-install:host:/root/c.sh")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{
+		PhasePreflight, PhaseBootstrap, "Hooks: pre-partition", PhasePartition, PhaseMirrors, PhaseBaseInstall, "Hooks: post-base",
+		PhaseConfigSystem, PhaseLaptop, PhaseBootloader, PhaseRepositories, PhaseGPUDrivers, PhaseCPUPower, PhaseInventory, PhasePostInstall, "Hooks: post-install",
+	}
+	if got := service.NewPipeline(InstallPlan{Hooks: hooks}).PhaseNames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected phases %v, got %v", expected, got)
+	}
+}
+
+func TestInstallationService_NewPipeline_Offline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := createTestService(ctrl)
+	defer func() {
+		if err := service.Close(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}()
+
+	hooks, err := installation.ParseHooks("post-base:chroot:/root/b.sh post-install:host:/root/c.sh")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	b, err := bundle.NewBundle("/media/usb/archup-bundle")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Post-base hooks already see the bundle, the baseline snapshot of
+	// post-install does not
+	expected := []string{
+		PhasePreflight, PhaseBootstrap, PhasePartition, PhaseMirrors, PhaseOfflineHost, PhaseBaseInstall, PhaseOfflineTarget,
+		"Hooks: post-base", PhaseConfigSystem, PhaseLaptop, PhaseBootloader, PhaseRepositories, PhaseGPUDrivers, PhaseCPUPower, PhaseInventory,
+		PhaseOfflineCleanup, PhasePostInstall, "Hooks: post-install",
+	}
+	if got := service.NewPipeline(InstallPlan{Hooks: hooks, Offline: b}).PhaseNames(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected phases %v, got %v", expected, got)
+	}
+}
+
+func TestHookEnv(t *testing.T) {
+	plan := InstallPlan{
+		Partition: commands.PartitionDiskCommand{TargetDisk: "/dev/nvme0n1", EncryptionType: disk.EncryptionTypeLUKS},
+		Base:      commands.InstallBaseCommand{MountPoint: "/mnt", KernelVariant: packages.KernelZen},
+		Config:    commands.ConfigureSystemCommand{Hostname: "myarch", Username: "testuser"},
+	}
+
+	env := hookEnv(plan, &PipelineState{})
+	if env["ARCHUP_ENCRYPTION"] != "luks" || env["ARCHUP_KERNEL"] != "linux-zen" || env["ARCHUP_MOUNTPOINT"] != "/mnt" {
+		t.Errorf("unexpected environment %v", env)
+	}
+	if _, ok := env["ARCHUP_ROOT_PARTITION"]; ok {
+		t.Error("expected no partition variables before partitioning")
+	}
+
+	env = hookEnv(plan, &PipelineState{Partition: &dto.PartitionResult{
+		RootPartition: "/dev/nvme0n1p2",
+		EFIPartition:  "/dev/nvme0n1p1",
+		CryptDevice:   "/dev/mapper/cryptroot",
+	}})
+	if env["ARCHUP_ROOT_PARTITION"] != "/dev/nvme0n1p2" || env["ARCHUP_CRYPT_DEVICE"] != "/dev/mapper/cryptroot" || env["ARCHUP_ROOT_DEVICE"] != "/dev/mapper/cryptroot" {
+		t.Errorf("unexpected partition environment %v", env)
+	}
+}
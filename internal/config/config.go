@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -12,12 +13,14 @@ const (
 	DefaultLogPath    = "/var/log/archup-install.log"
 
 	// Install paths
-	DefaultInstallDir     = "/tmp/archup-install"
-	DefaultInstallRepoDir = "/tmp/archup-install/repo"
-	DefaultInstallPath    = ".local/share/archup/install"
-	BasePackagesFile      = "base.packages"
-	ExtraPackagesFile     = "extra.packages"
-	LimineConfigTemplate  = "configs/limine.conf.template"
+	DefaultInstallDir    = "/tmp/archup-install"
+	DefaultInstallPath   = ".local/share/archup/install"
+	BasePackagesFile     = "base.packages"
+	ProfilesFile         = "profiles.packages"
+	LimineConfigTemplate = "configs/limine.conf.template"
+
+	// DefaultHooksDir holds user hook scripts, one subdirectory per hook point
+	DefaultHooksDir = "/etc/archup/hooks"
 )
 
 // Encryption types
@@ -52,14 +55,11 @@ const (
 	MkinitcpioHooksEncrypted = "HOOKS=(base udev autodetect microcode modconf kms keyboard keymap consolefont block plymouth encrypt filesystems fsck)"
 )
 
-// Kernel parameters
-const (
-	KernelParamsQuiet = "quiet splash loglevel=3 rd.udev.log_priority=3 systemd.show_status=auto"
-)
-
 // Limine configuration
 const (
-	LimineColor = "6"
+	LimineColor        = "6"
+	LimineDefaultEntry = 2 // First kernel entry inside the expanded OS directory
+	LimineTimeout      = 5
 )
 
 // UEFI boot entry
@@ -120,10 +120,9 @@ const (
 	PostBootServiceName  = "archup-first-boot.service"
 )
 
-// Post-boot script files to download
+// Post-boot script files
 var PostBootScripts = []string{
 	"all.sh",
-	"snapper.sh",
 	"firewalld.sh",
 	"ssh-keygen.sh",
 	"blesh.sh",
@@ -131,9 +130,29 @@ var PostBootScripts = []string{
 	"dms-opt-in.sh",
 }
 
-// Post-boot service template URL path
+// Post-boot service template asset path
 const PostBootServiceTemplate = "install/mandatory/post-boot/archup-first-boot.service"
 
+// InstallAssets returns the repository-relative path of every asset the
+// installer reads, the files an offline bundle carries
+func InstallAssets() []string {
+	assets := []string{
+		"install/" + BasePackagesFile,
+		"install/" + ProfilesFile,
+		"install/configs/limine.conf.template",
+		"install/configs/limine-update.hook",
+		"install/configs/chaotic-aur.conf",
+		PostBootServiceTemplate,
+	}
+	for _, script := range PostBootScripts {
+		assets = append(assets, "install/mandatory/post-boot/"+script)
+	}
+	for _, file := range PlymouthFiles {
+		assets = append(assets, "assets/plymouth/"+file)
+	}
+	return assets
+}
+
 // Config holds the installation configuration
 // This mirrors the shell script's config format for compatibility
 type Config struct {
@@ -146,9 +165,11 @@ type Config struct {
 	Keymap          string
 	Timezone        string
 	Locale          string
-	Bootloader      string // "limine" (only supported bootloader)
-	EncryptionType  string // "none", "luks", or "luks-lvm"
-	EncryptPassword string // Separate encryption password if different from user password
+	Bootloader      string   // "limine" (only supported bootloader)
+	BootTimeout     int      // Boot menu timeout in seconds
+	BootEntries     []string // Extra boot menu entries: "memtest86+", "efi-shell", "netboot.xyz"
+	EncryptionType  string   // "none", "luks", or "luks-lvm"
+	EncryptPassword string   // Separate encryption password if different from user password
 
 	// Form-only fields (not persisted)
 	ConfirmPassword              string // Temporary field for password confirmation
@@ -163,10 +184,14 @@ type Config struct {
 	CryptDevice   string // /dev/mapper/cryptroot if encrypted
 
 	// Base
-	KernelChoice string // "linux", "linux-zen", "linux-lts", "linux-hardened"
-	Microcode    string // "intel-ucode" or "amd-ucode"
-	CPUVendor    string // "Intel", "AMD", or "Unknown"
-	AMDPState    string // "active", "passive", "guided", or empty
+	KernelChoice  string // "linux", "linux-zen", "linux-lts", "linux-hardened"
+	Microcode     string // "intel-ucode" or "amd-ucode"
+	CPUVendor     string // "Intel", "AMD", or "Unknown"
+	AMDPState     string // "active", "passive", "guided", or empty
+	IntelPState   string // "active", "passive", or empty
+	PowerDaemon   string // "power-profiles-daemon", "tuned", "tlp" or "none"
+	CPUEPP        string // "performance", "balance_performance", "balance_power", "power", or empty
+	LaptopProfile string // "on", "hibernate" or "off", preselected when a laptop is detected
 
 	// Config
 	NetworkManager string // "NetworkManager" by default
@@ -175,16 +200,40 @@ type Config struct {
 	AURHelper      string // "paru" or "yay"
 	EnableMultilib bool
 
+	// Packages
+	PackageProfiles []string // Package profiles of profiles.packages, e.g. "server dev-tools"; empty for the defaults
+	Packages        []string // Extra packages from the official repositories, installed by pacstrap
+	AURPackages     []string // AUR packages built as the user with the AUR helper
+
+	// Mirrors
+	MirrorCountry string   // ISO 3166 country code ranked instead of the timezone country, e.g. "FR"
+	Mirrors       []string // Mirrors used as is instead of ranking, e.g. "http://mirror.lan/archlinux"
+
+	// Pacman cache
+	PacmanCache     string // LAN caching proxy URL or custom Server for every repository, "auto" to discover it over mDNS
+	KeepPacmanCache bool   // Keep the cache configured in the installed system
+
+	// Snapshots
+	SnapperConfigs string // "name:subvolume[:options] ...", e.g. "root:/:timeline,hourly=5 home:/home"
+
+	// Hooks
+	Hooks      string // "point:target:path ...", e.g. "post-base:chroot:/root/ca.sh"
+	HooksDir   string // Directory with <point>/ subdirectories of hook scripts
+	HookPolicy string // "abort" or "warn" when a hook fails
+
+	// Offline
+	OfflineBundle string // Bundle directory built by `archup bundle create`, empty online
+
 	// Paths
 	ConfigPath  string
 	LogPath     string
 	InstallPath string // ~/.local/share/archup/install
 	RepoURL     string
-	RawURL      string
+	RawURL      string // Remote assets of `archup install --remote-assets`
 }
 
 // NewConfig creates a new Config with sensible defaults.
-// version determines which git ref to use for downloads and bootstrap assets.
+// version determines which git ref remote assets are downloaded from.
 // ENV=dev forces the dev ref regardless of the version string.
 func NewConfig(version string) *Config {
 	ref := bootstrapRef(version)
@@ -195,12 +244,15 @@ func NewConfig(version string) *Config {
 		Timezone:                     "UTC",
 		Keymap:                       "us",
 		Bootloader:                   "limine",
+		BootTimeout:                  LimineTimeout,
 		EncryptionType:               "none",
 		KernelChoice:                 "linux",
 		NetworkManager:               "NetworkManager",
 		AURHelper:                    "paru",
 		EnableMultilib:               true,
 		UseSamePasswordForEncryption: true, // Default to using same password for encryption
+		HooksDir:                     DefaultHooksDir,
+		HookPolicy:                   "abort",
 		ConfigPath:                   DefaultConfigPath,
 		LogPath:                      DefaultLogPath,
 		RepoURL:                      "https://github.com/bnema/archup",
@@ -226,7 +278,7 @@ func bootstrapRef(version string) string {
 	}
 }
 
-// Branch returns the git ref to use for asset downloads and repo cloning.
+// Branch returns the git ref remote assets are downloaded from.
 func (c *Config) Branch() string {
 	rawURL := strings.TrimSpace(c.RawURL)
 	if rawURL == "" {
@@ -272,7 +324,10 @@ func Load(path string, version string) (*Config, error) {
 		key := strings.TrimSpace(parts[0])
 		value := strings.Trim(strings.TrimSpace(parts[1]), `"'`) // Remove quotes
 
-		cfg.setValue(key, value)
+		if err := cfg.setValue(key, value); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -323,6 +378,8 @@ func (c *Config) Save() error {
 		{"ARCHUP_TIMEZONE", c.Timezone},
 		{"ARCHUP_LOCALE", c.Locale},
 		{"ARCHUP_BOOTLOADER", c.Bootloader},
+		{"ARCHUP_BOOT_TIMEOUT", strconv.Itoa(c.BootTimeout)},
+		{"ARCHUP_BOOT_ENTRIES", strings.Join(c.BootEntries, " ")},
 		{"ARCHUP_ENCRYPTION", c.EncryptionType},
 		{"ARCHUP_ENCRYPTION_PASSWORD", c.EncryptPassword},
 		{"ARCHUP_TARGET_DISK", c.TargetDisk},
@@ -332,9 +389,25 @@ func (c *Config) Save() error {
 		{"ARCHUP_EFI_PARTITION", c.EFIPartition},
 		{"ARCHUP_KERNEL", c.KernelChoice},
 		{"ARCHUP_AMD_PSTATE", c.AMDPState},
+		{"ARCHUP_INTEL_PSTATE", c.IntelPState},
+		{"ARCHUP_POWER_DAEMON", c.PowerDaemon},
+		{"ARCHUP_CPU_EPP", c.CPUEPP},
+		{"ARCHUP_LAPTOP_PROFILE", c.LaptopProfile},
 		{"ARCHUP_NETWORK_MANAGER", c.NetworkManager},
 		{"ARCHUP_AUR_HELPER", c.AURHelper},
 		{"ARCHUP_ENABLE_MULTILIB", boolToString(c.EnableMultilib)},
+		{"ARCHUP_PACKAGE_PROFILES", strings.Join(c.PackageProfiles, " ")},
+		{"ARCHUP_PACKAGES", strings.Join(c.Packages, " ")},
+		{"ARCHUP_AUR_PACKAGES", strings.Join(c.AURPackages, " ")},
+		{"ARCHUP_MIRROR_COUNTRY", c.MirrorCountry},
+		{"ARCHUP_MIRRORS", strings.Join(c.Mirrors, " ")},
+		{"ARCHUP_PACMAN_CACHE", c.PacmanCache},
+		{"ARCHUP_PACMAN_CACHE_KEEP", boolToString(c.KeepPacmanCache)},
+		{"ARCHUP_SNAPPER_CONFIGS", c.SnapperConfigs},
+		{"ARCHUP_HOOKS", c.Hooks},
+		{"ARCHUP_HOOKS_DIR", c.HooksDir},
+		{"ARCHUP_HOOK_POLICY", c.HookPolicy},
+		{"ARCHUP_OFFLINE_BUNDLE", c.OfflineBundle},
 	}
 
 	for _, entry := range entries {
@@ -357,7 +430,7 @@ func (c *Config) Save() error {
 }
 
 // setValue sets a config value based on key name
-func (c *Config) setValue(key, value string) {
+func (c *Config) setValue(key, value string) error {
 	switch key {
 	case "ARCHUP_HOSTNAME":
 		c.Hostname = value
@@ -375,6 +448,14 @@ func (c *Config) setValue(key, value string) {
 		c.Locale = value
 	case "ARCHUP_BOOTLOADER":
 		c.Bootloader = value
+	case "ARCHUP_BOOT_TIMEOUT":
+		timeout, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid ARCHUP_BOOT_TIMEOUT %q: expected seconds", value)
+		}
+		c.BootTimeout = timeout
+	case "ARCHUP_BOOT_ENTRIES":
+		c.BootEntries = strings.Fields(value)
 	case "ARCHUP_ENCRYPTION":
 		c.EncryptionType = value
 	case "ARCHUP_ENCRYPTION_PASSWORD":
@@ -393,13 +474,46 @@ func (c *Config) setValue(key, value string) {
 		c.KernelChoice = value
 	case "ARCHUP_AMD_PSTATE":
 		c.AMDPState = value
+	case "ARCHUP_INTEL_PSTATE":
+		c.IntelPState = value
+	case "ARCHUP_POWER_DAEMON":
+		c.PowerDaemon = value
+	case "ARCHUP_CPU_EPP":
+		c.CPUEPP = value
+	case "ARCHUP_LAPTOP_PROFILE":
+		c.LaptopProfile = value
 	case "ARCHUP_NETWORK_MANAGER":
 		c.NetworkManager = value
 	case "ARCHUP_AUR_HELPER":
 		c.AURHelper = value
 	case "ARCHUP_ENABLE_MULTILIB":
 		c.EnableMultilib = stringToBool(value)
+	case "ARCHUP_PACKAGE_PROFILES":
+		c.PackageProfiles = strings.Fields(value)
+	case "ARCHUP_PACKAGES":
+		c.Packages = strings.Fields(value)
+	case "ARCHUP_AUR_PACKAGES":
+		c.AURPackages = strings.Fields(value)
+	case "ARCHUP_MIRROR_COUNTRY":
+		c.MirrorCountry = value
+	case "ARCHUP_MIRRORS":
+		c.Mirrors = strings.Fields(value)
+	case "ARCHUP_PACMAN_CACHE":
+		c.PacmanCache = value
+	case "ARCHUP_PACMAN_CACHE_KEEP":
+		c.KeepPacmanCache = stringToBool(value)
+	case "ARCHUP_SNAPPER_CONFIGS":
+		c.SnapperConfigs = value
+	case "ARCHUP_HOOKS":
+		c.Hooks = value
+	case "ARCHUP_HOOKS_DIR":
+		c.HooksDir = value
+	case "ARCHUP_HOOK_POLICY":
+		c.HookPolicy = value
+	case "ARCHUP_OFFLINE_BUNDLE":
+		c.OfflineBundle = value
 	}
+	return nil
 }
 
 func boolToString(b bool) string {
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestNewConfig_UsesReleaseVersionAsBootstrapRef(t *testing.T) {
 	cfg := NewConfig("v1.2.3")
@@ -39,3 +43,175 @@ func TestNewConfig_ENVDevOverridesReleaseVersion(t *testing.T) {
 		t.Fatalf("expected dev ref override, got %q", cfg.Branch())
 	}
 }
+
+func TestLoad_BootOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_BOOT_TIMEOUT=\"10\"\nARCHUP_BOOT_ENTRIES=\"memtest86+ efi-shell\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.BootTimeout != 10 {
+		t.Errorf("expected boot timeout 10, got %d", cfg.BootTimeout)
+	}
+
+	if len(cfg.BootEntries) != 2 || cfg.BootEntries[0] != "memtest86+" || cfg.BootEntries[1] != "efi-shell" {
+		t.Errorf("unexpected boot entries: %v", cfg.BootEntries)
+	}
+}
+
+func TestLoad_InvalidBootTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	if err := os.WriteFile(path, []byte("ARCHUP_BOOT_TIMEOUT=\"5s\"\n"), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	if _, err := Load(path, "v1.2.3"); err == nil {
+		t.Error("expected error for a non-numeric boot timeout")
+	}
+}
+
+func TestLoad_SnapperConfigs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_SNAPPER_CONFIGS=\"root:/:timeline,hourly=5 home:/home\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.SnapperConfigs != "root:/:timeline,hourly=5 home:/home" {
+		t.Errorf("unexpected snapper configs: %q", cfg.SnapperConfigs)
+	}
+}
+
+func TestLoad_Hooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_HOOKS=\"post-base:chroot:/root/ca.sh\"\nARCHUP_HOOKS_DIR=\"/root/hooks\"\nARCHUP_HOOK_POLICY=\"warn\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.Hooks != "post-base:chroot:/root/ca.sh" || cfg.HooksDir != "/root/hooks" || cfg.HookPolicy != "warn" {
+		t.Errorf("unexpected hooks config: %q %q %q", cfg.Hooks, cfg.HooksDir, cfg.HookPolicy)
+	}
+	if NewConfig("v1.2.3").HooksDir != DefaultHooksDir {
+		t.Errorf("expected default hooks dir %s", DefaultHooksDir)
+	}
+}
+
+func TestLoad_CPUPower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_INTEL_PSTATE=\"passive\"\nARCHUP_POWER_DAEMON=\"tlp\"\nARCHUP_CPU_EPP=\"balance_power\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.IntelPState != "passive" || cfg.PowerDaemon != "tlp" || cfg.CPUEPP != "balance_power" {
+		t.Errorf("unexpected CPU power config: %q %q %q", cfg.IntelPState, cfg.PowerDaemon, cfg.CPUEPP)
+	}
+}
+
+func TestLoad_PackageProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	if err := os.WriteFile(path, []byte("ARCHUP_PACKAGE_PROFILES=\"server  dev-tools\"\n"), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(cfg.PackageProfiles) != 2 || cfg.PackageProfiles[0] != "server" || cfg.PackageProfiles[1] != "dev-tools" {
+		t.Errorf("unexpected package profiles: %v", cfg.PackageProfiles)
+	}
+}
+
+func TestLoad_ExtraPackages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_PACKAGES=\"htop\"\nARCHUP_AUR_PACKAGES=\"visual-studio-code-bin spotify\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(cfg.Packages) != 1 || cfg.Packages[0] != "htop" {
+		t.Errorf("unexpected packages: %v", cfg.Packages)
+	}
+	if len(cfg.AURPackages) != 2 || cfg.AURPackages[0] != "visual-studio-code-bin" || cfg.AURPackages[1] != "spotify" {
+		t.Errorf("unexpected AUR packages: %v", cfg.AURPackages)
+	}
+}
+
+func TestLoad_Mirrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_MIRROR_COUNTRY=\"DE\"\nARCHUP_MIRRORS=\"http://mirror.lan/archlinux https://backup.lan/$repo/os/$arch\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.MirrorCountry != "DE" || len(cfg.Mirrors) != 2 || cfg.Mirrors[1] != "https://backup.lan/$repo/os/$arch" {
+		t.Errorf("unexpected mirror config: %q %v", cfg.MirrorCountry, cfg.Mirrors)
+	}
+}
+
+func TestLoad_PacmanCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	content := "ARCHUP_PACMAN_CACHE=\"http://cache.lan:9129/repo\"\nARCHUP_PACMAN_CACHE_KEEP=\"true\"\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.PacmanCache != "http://cache.lan:9129/repo" || !cfg.KeepPacmanCache {
+		t.Errorf("unexpected pacman cache config: %q %v", cfg.PacmanCache, cfg.KeepPacmanCache)
+	}
+}
+
+func TestLoad_OfflineBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.conf")
+	if err := os.WriteFile(path, []byte("ARCHUP_OFFLINE_BUNDLE=\"/run/media/archup-bundle\"\n"), 0600); err != nil {
+		t.Fatalf("failed to write answer file: %v", err)
+	}
+
+	cfg, err := Load(path, "v1.2.3")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.OfflineBundle != "/run/media/archup-bundle" {
+		t.Errorf("unexpected offline bundle: %q", cfg.OfflineBundle)
+	}
+}
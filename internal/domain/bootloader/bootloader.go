@@ -26,15 +26,22 @@ type Bootloader struct {
 	efiPath  string
 }
 
-// NewBootloader creates a new Bootloader value object with validation
-func NewBootloader(bootType BootloaderType, timeoutSeconds int, branding string) (*Bootloader, error) {
-	// Validate timeout
+// ValidateTimeout checks a boot menu timeout in seconds
+func ValidateTimeout(timeoutSeconds int) error {
 	if timeoutSeconds < 0 {
-		return nil, errors.New("bootloader timeout cannot be negative")
+		return errors.New("bootloader timeout cannot be negative")
 	}
 
 	if timeoutSeconds > 600 {
-		return nil, errors.New("bootloader timeout cannot exceed 600 seconds")
+		return errors.New("bootloader timeout cannot exceed 600 seconds")
+	}
+	return nil
+}
+
+// NewBootloader creates a new Bootloader value object with validation
+func NewBootloader(bootType BootloaderType, timeoutSeconds int, branding string) (*Bootloader, error) {
+	if err := ValidateTimeout(timeoutSeconds); err != nil {
+		return nil, err
 	}
 
 	// Validate branding
@@ -0,0 +1,383 @@
+package bootloader
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cmdlineKeyPattern matches a kernel parameter name, e.g. "quiet" or "nvidia_drm.modeset"
+var cmdlineKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// repeatableCmdlineKeys are parameters the kernel honours several times, e.g. one console per device
+var repeatableCmdlineKeys = map[string]bool{
+	"console": true,
+}
+
+// CmdlineParam is a single kernel parameter, either a flag ("quiet") or a key=value pair
+type CmdlineParam struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// String renders the parameter, quoting values containing spaces
+func (p CmdlineParam) String() string {
+	if !p.HasValue {
+		return p.Key
+	}
+	if strings.ContainsAny(p.Value, " \t") {
+		return p.Key + `="` + p.Value + `"`
+	}
+	return p.Key + "=" + p.Value
+}
+
+// normalizedKey returns the key as compared by the kernel, which treats - and _ alike
+func (p CmdlineParam) normalizedKey() string {
+	return strings.ReplaceAll(p.Key, "-", "_")
+}
+
+func (p CmdlineParam) validate() error {
+	if !cmdlineKeyPattern.MatchString(p.Key) {
+		return fmt.Errorf("invalid kernel parameter name: %q", p.Key)
+	}
+	if strings.ContainsAny(p.Value, "\"\r\n") {
+		return fmt.Errorf("invalid value for kernel parameter %s: %q", p.Key, p.Value)
+	}
+	return nil
+}
+
+// Verbosity is the boot message preset
+type Verbosity int
+
+const (
+	// VerbosityQuiet hides kernel and systemd messages behind the splash screen
+	VerbosityQuiet Verbosity = iota
+
+	// VerbosityVerbose shows all boot messages
+	VerbosityVerbose
+)
+
+// String returns the preset name
+func (v Verbosity) String() string {
+	if v == VerbosityVerbose {
+		return "verbose"
+	}
+	return "quiet"
+}
+
+// quietParams are the parameters of the quiet preset
+var quietParams = []CmdlineParam{
+	{Key: "quiet"},
+	{Key: "splash"},
+	{Key: "loglevel", Value: "3", HasValue: true},
+	{Key: "rd.udev.log_priority", Value: "3", HasValue: true},
+	{Key: "systemd.show_status", Value: "auto", HasValue: true},
+}
+
+// MitigationsPolicy is the CPU vulnerability mitigations preset
+type MitigationsPolicy int
+
+const (
+	// MitigationsAuto keeps the kernel defaults
+	MitigationsAuto MitigationsPolicy = iota
+
+	// MitigationsAutoNoSMT enables mitigations and disables SMT where required
+	MitigationsAutoNoSMT
+
+	// MitigationsOff disables all mitigations for performance
+	MitigationsOff
+)
+
+// String returns the mitigations= value
+func (m MitigationsPolicy) String() string {
+	switch m {
+	case MitigationsAutoNoSMT:
+		return "auto,nosmt"
+	case MitigationsOff:
+		return "off"
+	default:
+		return "auto"
+	}
+}
+
+// CmdlinePresets selects the parameters added on top of the root parameters
+type CmdlinePresets struct {
+	Verbosity     Verbosity
+	Mitigations   MitigationsPolicy
+	IOMMU         bool   // Enable the IOMMU in passthrough mode for VFIO
+	CPUVendor     string // "intel" or "amd", selects the IOMMU parameter
+	NVIDIAModeset bool   // Enable NVIDIA DRM kernel mode setting
+	Resume        string // Hibernation swap device, e.g. "UUID=..." (optional)
+	ResumeOffset  int    // Swap file offset on Btrfs, 0 for a swap partition
+}
+
+// CmdlineTarget is the boot method the command line is rendered for
+type CmdlineTarget int
+
+const (
+	// CmdlineTargetLimine renders the value of a limine.conf "cmdline:" key
+	CmdlineTargetLimine CmdlineTarget = iota
+
+	// CmdlineTargetSystemdBoot renders a systemd-boot entry "options" line
+	CmdlineTargetSystemdBoot
+
+	// CmdlineTargetUKI renders the content of /etc/kernel/cmdline embedded in a UKI
+	CmdlineTargetUKI
+)
+
+// KernelCmdline is an immutable value object for the kernel command line.
+// Parameters keep their insertion order; setting an existing key replaces its value.
+type KernelCmdline struct {
+	params []CmdlineParam
+}
+
+// NewKernelCmdline creates a new KernelCmdline from validated parameters
+func NewKernelCmdline(params ...CmdlineParam) (*KernelCmdline, error) {
+	c := &KernelCmdline{}
+	for _, p := range params {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		c = c.with(p)
+	}
+	return c, nil
+}
+
+// ParseKernelCmdline parses a space-separated command line such as
+// `root=UUID=abc rw quiet foo="a b"`
+func ParseKernelCmdline(s string) (*KernelCmdline, error) {
+	var params []CmdlineParam
+	var token strings.Builder
+	inQuotes := false
+
+	flush := func() error {
+		if token.Len() == 0 {
+			return nil
+		}
+		p, err := parseCmdlineParam(token.String())
+		if err != nil {
+			return err
+		}
+		params = append(params, p)
+		token.Reset()
+		return nil
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case r == '\n' || r == '\r':
+			return nil, errors.New("kernel command line cannot contain line breaks")
+		default:
+			token.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, errors.New("kernel command line has an unterminated quote")
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return NewKernelCmdline(params...)
+}
+
+func parseCmdlineParam(token string) (CmdlineParam, error) {
+	if token == "--" {
+		return CmdlineParam{}, errors.New("init arguments after -- are not supported")
+	}
+	key, value, hasValue := strings.Cut(token, "=")
+	p := CmdlineParam{Key: key, Value: value, HasValue: hasValue}
+	return p, p.validate()
+}
+
+// RootCmdline returns the parameters mounting the Btrfs @ subvolume as root,
+// unlocking it through the encrypt hook when encrypted
+func RootCmdline(rootUUID string, encrypted bool) (*KernelCmdline, error) {
+	if strings.TrimSpace(rootUUID) == "" {
+		return nil, errors.New("root UUID cannot be empty")
+	}
+
+	if encrypted {
+		return NewKernelCmdline(
+			CmdlineParam{Key: "cryptdevice", Value: "UUID=" + rootUUID + ":cryptroot", HasValue: true},
+			CmdlineParam{Key: "root", Value: "/dev/mapper/cryptroot", HasValue: true},
+			CmdlineParam{Key: "rootflags", Value: "subvol=@", HasValue: true},
+			CmdlineParam{Key: "rw"},
+		)
+	}
+
+	return NewKernelCmdline(
+		CmdlineParam{Key: "root", Value: "UUID=" + rootUUID, HasValue: true},
+		CmdlineParam{Key: "rootflags", Value: "subvol=@", HasValue: true},
+		CmdlineParam{Key: "rw"},
+	)
+}
+
+// with returns a copy with the parameter added or replacing the existing key
+func (c *KernelCmdline) with(p CmdlineParam) *KernelCmdline {
+	params := append([]CmdlineParam{}, c.params...)
+	for i, existing := range params {
+		if existing.normalizedKey() != p.normalizedKey() {
+			continue
+		}
+		if repeatableCmdlineKeys[p.normalizedKey()] && existing != p {
+			continue
+		}
+		params[i] = p
+		return &KernelCmdline{params: params}
+	}
+	return &KernelCmdline{params: append(params, p)}
+}
+
+// Set returns a copy with key=value set, replacing any previous value
+func (c *KernelCmdline) Set(key, value string) (*KernelCmdline, error) {
+	p := CmdlineParam{Key: key, Value: value, HasValue: true}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return c.with(p), nil
+}
+
+// Flag returns a copy with the flag parameter set
+func (c *KernelCmdline) Flag(key string) (*KernelCmdline, error) {
+	p := CmdlineParam{Key: key}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return c.with(p), nil
+}
+
+// Remove returns a copy without the given keys
+func (c *KernelCmdline) Remove(keys ...string) *KernelCmdline {
+	removed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		removed[CmdlineParam{Key: key}.normalizedKey()] = true
+	}
+
+	params := make([]CmdlineParam, 0, len(c.params))
+	for _, p := range c.params {
+		if !removed[p.normalizedKey()] {
+			params = append(params, p)
+		}
+	}
+	return &KernelCmdline{params: params}
+}
+
+// Merge returns a copy with the parameters of other added, other winning on duplicate keys
+func (c *KernelCmdline) Merge(other *KernelCmdline) *KernelCmdline {
+	merged := c
+	if other == nil {
+		return merged
+	}
+	for _, p := range other.params {
+		merged = merged.with(p)
+	}
+	return merged
+}
+
+// ApplyPresets returns a copy with the preset parameters added
+func (c *KernelCmdline) ApplyPresets(presets CmdlinePresets) (*KernelCmdline, error) {
+	result := c
+
+	if presets.Verbosity == VerbosityQuiet {
+		for _, p := range quietParams {
+			result = result.with(p)
+		}
+	} else {
+		keys := make([]string, 0, len(quietParams))
+		for _, p := range quietParams {
+			keys = append(keys, p.Key)
+		}
+		result = result.Remove(keys...)
+	}
+
+	if presets.Mitigations != MitigationsAuto {
+		result = result.with(CmdlineParam{Key: "mitigations", Value: presets.Mitigations.String(), HasValue: true})
+	}
+
+	if presets.IOMMU {
+		switch strings.ToLower(presets.CPUVendor) {
+		case "intel":
+			result = result.with(CmdlineParam{Key: "intel_iommu", Value: "on", HasValue: true})
+		case "amd":
+			// The AMD IOMMU is enabled by default when present
+		default:
+			return nil, fmt.Errorf("IOMMU preset requires an Intel or AMD CPU, got %q", presets.CPUVendor)
+		}
+		result = result.with(CmdlineParam{Key: "iommu", Value: "pt", HasValue: true})
+	}
+
+	if presets.NVIDIAModeset {
+		result = result.with(CmdlineParam{Key: "nvidia_drm.modeset", Value: "1", HasValue: true})
+	}
+
+	if presets.Resume != "" {
+		p := CmdlineParam{Key: "resume", Value: presets.Resume, HasValue: true}
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		result = result.with(p)
+		if presets.ResumeOffset > 0 {
+			result = result.with(CmdlineParam{Key: "resume_offset", Value: strconv.Itoa(presets.ResumeOffset), HasValue: true})
+		}
+	} else if presets.ResumeOffset > 0 {
+		return nil, errors.New("resume offset requires a resume device")
+	}
+
+	return result, nil
+}
+
+// Params returns the parameters in order
+func (c *KernelCmdline) Params() []CmdlineParam {
+	return append([]CmdlineParam{}, c.params...)
+}
+
+// Get returns the value of the last parameter with the given key
+func (c *KernelCmdline) Get(key string) (string, bool) {
+	wanted := CmdlineParam{Key: key}.normalizedKey()
+	for i := len(c.params) - 1; i >= 0; i-- {
+		if c.params[i].normalizedKey() == wanted {
+			return c.params[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Render returns the command line formatted for the given boot method
+func (c *KernelCmdline) Render(target CmdlineTarget) string {
+	switch target {
+	case CmdlineTargetSystemdBoot:
+		return "options " + c.String()
+	case CmdlineTargetUKI:
+		return c.String() + "\n"
+	default:
+		return c.String()
+	}
+}
+
+// String returns the space-separated command line
+func (c *KernelCmdline) String() string {
+	parts := make([]string, 0, len(c.params))
+	for _, p := range c.params {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Equals checks if two KernelCmdline objects are equal
+func (c *KernelCmdline) Equals(other *KernelCmdline) bool {
+	if other == nil {
+		return false
+	}
+	return c.String() == other.String()
+}
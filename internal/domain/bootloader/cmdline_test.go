@@ -0,0 +1,151 @@
+package bootloader
+
+import (
+	"testing"
+)
+
+func TestParseKernelCmdline(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		shouldErr bool
+	}{
+		{"flags and values", "quiet root=UUID=abc rw", "quiet root=UUID=abc rw", false},
+		{"extra whitespace", "  quiet \t splash  ", "quiet splash", false},
+		{"duplicate key last wins", "loglevel=3 quiet loglevel=7", "loglevel=7 quiet", false},
+		{"dash and underscore alike", "nvidia-drm.modeset=0 nvidia_drm.modeset=1", "nvidia_drm.modeset=1", false},
+		{"repeatable console", "console=tty0 console=ttyS0,115200", "console=tty0 console=ttyS0,115200", false},
+		{"quoted value", `acpi_osi="Windows 2020"`, `acpi_osi="Windows 2020"`, false},
+		{"empty", "", "", false},
+		{"unterminated quote", `foo="bar`, "", true},
+		{"empty key", "=foo", "", true},
+		{"invalid key", "foo$bar=1", "", true},
+		{"line break", "quiet\nsplash", "", true},
+		{"init arguments", "quiet -- single", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmdline, err := ParseKernelCmdline(tt.input)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+			if err == nil && cmdline.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, cmdline.String())
+			}
+		})
+	}
+}
+
+func TestRootCmdline(t *testing.T) {
+	plain, err := RootCmdline("abc", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if plain.String() != "root=UUID=abc rootflags=subvol=@ rw" {
+		t.Errorf("unexpected root cmdline: %s", plain)
+	}
+
+	encrypted, err := RootCmdline("abc", true)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if encrypted.String() != "cryptdevice=UUID=abc:cryptroot root=/dev/mapper/cryptroot rootflags=subvol=@ rw" {
+		t.Errorf("unexpected encrypted root cmdline: %s", encrypted)
+	}
+
+	if _, err := RootCmdline(" ", false); err == nil {
+		t.Error("expected error for empty root UUID")
+	}
+}
+
+func TestKernelCmdline_ApplyPresets(t *testing.T) {
+	root, _ := RootCmdline("abc", false)
+
+	tests := []struct {
+		name      string
+		presets   CmdlinePresets
+		expected  string
+		shouldErr bool
+	}{
+		{
+			"defaults are quiet",
+			CmdlinePresets{},
+			"root=UUID=abc rootflags=subvol=@ rw quiet splash loglevel=3 rd.udev.log_priority=3 systemd.show_status=auto",
+			false,
+		},
+		{
+			"verbose with mitigations off",
+			CmdlinePresets{Verbosity: VerbosityVerbose, Mitigations: MitigationsOff},
+			"root=UUID=abc rootflags=subvol=@ rw mitigations=off",
+			false,
+		},
+		{
+			"intel iommu and nvidia",
+			CmdlinePresets{Verbosity: VerbosityVerbose, IOMMU: true, CPUVendor: "Intel", NVIDIAModeset: true},
+			"root=UUID=abc rootflags=subvol=@ rw intel_iommu=on iommu=pt nvidia_drm.modeset=1",
+			false,
+		},
+		{
+			"amd iommu",
+			CmdlinePresets{Verbosity: VerbosityVerbose, IOMMU: true, CPUVendor: "amd"},
+			"root=UUID=abc rootflags=subvol=@ rw iommu=pt",
+			false,
+		},
+		{
+			"resume from swap file",
+			CmdlinePresets{Verbosity: VerbosityVerbose, Resume: "UUID=abc", ResumeOffset: 533760},
+			"root=UUID=abc rootflags=subvol=@ rw resume=UUID=abc resume_offset=533760",
+			false,
+		},
+		{"iommu without vendor", CmdlinePresets{IOMMU: true}, "", true},
+		{"resume offset without device", CmdlinePresets{ResumeOffset: 10}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmdline, err := root.ApplyPresets(tt.presets)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+			if err == nil && cmdline.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, cmdline.String())
+			}
+		})
+	}
+}
+
+func TestKernelCmdline_MergeOverridesPresets(t *testing.T) {
+	root, _ := RootCmdline("abc", false)
+	cmdline, _ := root.ApplyPresets(CmdlinePresets{})
+	extra, _ := ParseKernelCmdline("loglevel=7 amd_pstate=active")
+
+	merged := cmdline.Merge(extra)
+
+	if value, _ := merged.Get("loglevel"); value != "7" {
+		t.Errorf("expected loglevel=7, got %s", value)
+	}
+	if _, ok := merged.Get("amd_pstate"); !ok {
+		t.Error("expected amd_pstate to be merged")
+	}
+	if !cmdline.Equals(cmdline.Merge(nil)) {
+		t.Error("expected merging nil to keep the command line")
+	}
+}
+
+func TestKernelCmdline_Render(t *testing.T) {
+	cmdline, _ := ParseKernelCmdline("root=UUID=abc rw quiet")
+
+	tests := map[CmdlineTarget]string{
+		CmdlineTargetLimine:      "root=UUID=abc rw quiet",
+		CmdlineTargetSystemdBoot: "options root=UUID=abc rw quiet",
+		CmdlineTargetUKI:         "root=UUID=abc rw quiet\n",
+	}
+
+	for target, expected := range tests {
+		if got := cmdline.Render(target); got != expected {
+			t.Errorf("target %d: expected %q, got %q", target, expected, got)
+		}
+	}
+}
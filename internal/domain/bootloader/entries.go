@@ -0,0 +1,63 @@
+package bootloader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtraEntry is an optional boot menu tool and the way its EFI payload is provided.
+// A payload either comes from a package (Source inside the target root, or installed
+// straight onto the ESP when Source is empty) or is downloaded from URL.
+type ExtraEntry struct {
+	ID      string // Answer-file identifier, e.g. "memtest86+"
+	Title   string // Menu title
+	Package string // Package providing the payload (optional)
+	Source  string // Payload path inside the target root, copied to ESPPath (optional)
+	URL     string // Download URL when no package provides the payload (optional)
+	ESPPath string // Payload path relative to the ESP
+}
+
+// LimineEntry returns the Limine menu entry chainloading the payload
+func (e ExtraEntry) LimineEntry() LimineEntry {
+	return LimineEntry{
+		Name:     e.Title,
+		Protocol: "efi",
+		Path:     "boot():/" + e.ESPPath,
+	}
+}
+
+// AvailableExtraEntries returns the supported extra boot menu entries
+func AvailableExtraEntries() []ExtraEntry {
+	return []ExtraEntry{
+		{
+			ID:      "memtest86+",
+			Title:   "Memtest86+",
+			Package: "memtest86+-efi",
+			ESPPath: "memtest86+/memtest.efi",
+		},
+		{
+			ID:      "efi-shell",
+			Title:   "UEFI Shell",
+			Package: "edk2-shell",
+			Source:  "usr/share/edk2-shell/x64/Shell_Full.efi",
+			ESPPath: "EFI/tools/shellx64.efi",
+		},
+		{
+			ID:      "netboot.xyz",
+			Title:   "netboot.xyz",
+			URL:     "https://boot.netboot.xyz/ipxe/netboot.xyz.efi",
+			ESPPath: "EFI/tools/netboot.xyz.efi",
+		},
+	}
+}
+
+// LookupExtraEntry returns the extra entry with the given identifier
+func LookupExtraEntry(id string) (ExtraEntry, error) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	for _, e := range AvailableExtraEntries() {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return ExtraEntry{}, fmt.Errorf("unknown boot entry: %s", id)
+}
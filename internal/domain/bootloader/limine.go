@@ -0,0 +1,171 @@
+package bootloader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// LimineKernel is a kernel menu entry rendered into limine.conf
+type LimineKernel struct {
+	Name     string // Kernel package name, e.g. "linux" or "linux-lts"
+	Cmdline  string // Kernel command line
+	Fallback bool   // Whether the fallback initramfs exists and gets its own entry
+}
+
+// LimineEntry is an additional top-level menu entry (memtest, EFI tools, ...)
+type LimineEntry struct {
+	Name     string // Menu title
+	Protocol string // Limine boot protocol, e.g. "efi" or "linux"
+	Path     string // Limine path, e.g. "boot():/EFI/tools/shellx64.efi"
+	Cmdline  string // Optional command line
+}
+
+// LimineConfig is the typed model rendered into limine.conf.
+// The first kernel is the primary (default) kernel; further kernels are
+// listed below it in the same menu directory, e.g. linux-lts as a rescue kernel.
+type LimineConfig struct {
+	Timeout        int
+	DefaultEntry   int
+	Branding       string
+	BrandingColour string
+	MachineID      string
+	Kernels        []LimineKernel
+	Entries        []LimineEntry
+}
+
+// Primary returns the primary kernel entry
+func (c LimineConfig) Primary() LimineKernel {
+	if len(c.Kernels) == 0 {
+		return LimineKernel{}
+	}
+	return c.Kernels[0]
+}
+
+// Secondary returns the kernels installed next to the primary one
+func (c LimineConfig) Secondary() []LimineKernel {
+	if len(c.Kernels) < 2 {
+		return nil
+	}
+	return c.Kernels[1:]
+}
+
+// Validate checks that the model can be rendered into a valid limine.conf
+func (c LimineConfig) Validate() error {
+	if c.Timeout < 0 || c.Timeout > 600 {
+		return errors.New("limine timeout must be between 0 and 600 seconds")
+	}
+
+	if c.DefaultEntry < 1 {
+		return errors.New("limine default entry must be at least 1")
+	}
+
+	if len(c.Kernels) == 0 {
+		return errors.New("limine config requires at least one kernel")
+	}
+
+	seen := make(map[string]bool, len(c.Kernels))
+	for _, k := range c.Kernels {
+		if strings.TrimSpace(k.Name) == "" {
+			return errors.New("limine kernel name cannot be empty")
+		}
+		if seen[k.Name] {
+			return fmt.Errorf("duplicate limine kernel entry: %s", k.Name)
+		}
+		seen[k.Name] = true
+		if err := validateLimineValue(k.Name, k.Cmdline); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range c.Entries {
+		if strings.TrimSpace(e.Name) == "" || strings.TrimSpace(e.Path) == "" {
+			return errors.New("limine entry requires a name and a path")
+		}
+		if e.Protocol == "" {
+			return fmt.Errorf("limine entry %s has no protocol", e.Name)
+		}
+		if err := validateLimineValue(e.Name, e.Protocol, e.Path, e.Cmdline); err != nil {
+			return err
+		}
+	}
+
+	return validateLimineValue(c.Branding, c.BrandingColour, c.MachineID)
+}
+
+// validateLimineValue rejects values that would break the line-based config format
+func validateLimineValue(values ...string) error {
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return fmt.Errorf("limine config value contains a line break: %q", v)
+		}
+	}
+	return nil
+}
+
+// limineBaseTemplates holds the named templates available to every limine.conf template
+const limineBaseTemplates = `{{define "kernel"}}
+    //{{.Name}}
+    protocol: linux
+    path: boot():/vmlinuz-{{.Name}}
+    cmdline: {{.Cmdline}}
+    module_path: boot():/initramfs-{{.Name}}.img
+{{template "fallback" .}}{{end}}{{define "fallback"}}{{if .Fallback}}
+    //{{.Name}}-fallback
+    protocol: linux
+    path: boot():/vmlinuz-{{.Name}}
+    cmdline: {{.Cmdline}}
+    module_path: boot():/initramfs-{{.Name}}-fallback.img
+{{end}}{{end}}{{define "entry"}}
+/{{.Name}}
+    protocol: {{.Protocol}}
+    path: {{.Path}}
+{{- if .Cmdline}}
+    cmdline: {{.Cmdline}}
+{{- end}}
+{{end}}`
+
+// legacyLiminePlaceholders maps the placeholders of pre-template limine.conf.template
+// files to template actions, so templates shipped with older releases keep working
+var legacyLiminePlaceholders = strings.NewReplacer(
+	"{{TIMEOUT}}", "{{.Timeout}}",
+	"{{BRANDING}}", "{{.Branding}}",
+	"{{COLOR}}", "{{.BrandingColour}}",
+	"{{KERNEL}}", "{{.Primary.Name}}",
+	"{{KERNEL_PARAMS}}", "{{.Primary.Cmdline}}",
+	"{{MACHINE_ID}}", "{{.MachineID}}",
+	"{{FALLBACK_ENTRY}}", `{{template "fallback" .Primary}}{{range .Secondary}}{{template "kernel" .}}{{end}}`,
+)
+
+// IsLegacyLimineTemplate reports whether the template uses the old placeholder syntax
+func IsLegacyLimineTemplate(tmpl string) bool {
+	return strings.Contains(tmpl, "{{KERNEL}}") || strings.Contains(tmpl, "{{TIMEOUT}}")
+}
+
+// Render renders the config through the given limine.conf template
+func (c LimineConfig) Render(tmpl string) (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	if IsLegacyLimineTemplate(tmpl) {
+		// Legacy templates have no slot for top-level entries, append them at the end
+		tmpl = legacyLiminePlaceholders.Replace(tmpl) + `{{range .Entries}}{{template "entry" .}}{{end}}`
+	}
+
+	t, err := template.New("limine.conf").Option("missingkey=error").Parse(limineBaseTemplates)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base limine templates: %w", err)
+	}
+	if _, err := t.Parse(tmpl); err != nil {
+		return "", fmt.Errorf("failed to parse limine template: %w", err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, c); err != nil {
+		return "", fmt.Errorf("failed to render limine template: %w", err)
+	}
+
+	return b.String(), nil
+}
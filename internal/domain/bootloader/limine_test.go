@@ -0,0 +1,137 @@
+package bootloader
+
+import (
+	"strings"
+	"testing"
+)
+
+const limineTestTemplate = `timeout: {{.Timeout}}
+default_entry: {{.DefaultEntry}}
+interface_branding: {{.Branding}}
+
+/+{{.Primary.Name}}
+comment: machine-id={{.MachineID}}
+{{range .Kernels}}{{template "kernel" .}}{{end}}
+    //Snapshots
+{{range .Entries}}{{template "entry" .}}{{end}}`
+
+const limineLegacyTestTemplate = `timeout: {{TIMEOUT}}
+interface_branding: {{BRANDING}}
+
+/+{{KERNEL}}
+comment: machine-id={{MACHINE_ID}}
+
+    //{{KERNEL}}
+    protocol: linux
+    path: boot():/vmlinuz-{{KERNEL}}
+    cmdline: {{KERNEL_PARAMS}}
+    module_path: boot():/initramfs-{{KERNEL}}.img
+{{FALLBACK_ENTRY}}
+    //Snapshots
+`
+
+func testLimineConfig() LimineConfig {
+	return LimineConfig{
+		Timeout:        5,
+		DefaultEntry:   2,
+		Branding:       "Arch Linux",
+		BrandingColour: "6",
+		MachineID:      "abc123",
+		Kernels: []LimineKernel{
+			{Name: "linux-zen", Cmdline: "root=UUID=x rw", Fallback: true},
+			{Name: "linux-lts", Cmdline: "root=UUID=x rw"},
+		},
+		Entries: []LimineEntry{
+			{Name: "Memtest86+", Protocol: "efi", Path: "boot():/memtest86+/memtest.efi"},
+		},
+	}
+}
+
+func TestLimineConfig_Render(t *testing.T) {
+	templates := map[string]string{
+		"template": limineTestTemplate,
+		"legacy":   limineLegacyTestTemplate,
+	}
+
+	for name, tmpl := range templates {
+		t.Run(name, func(t *testing.T) {
+			out, err := testLimineConfig().Render(tmpl)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			expected := []string{
+				"timeout: 5",
+				"interface_branding: Arch Linux",
+				"/+linux-zen",
+				"comment: machine-id=abc123",
+				"module_path: boot():/initramfs-linux-zen.img",
+				"module_path: boot():/initramfs-linux-zen-fallback.img",
+				"//linux-lts",
+				"module_path: boot():/initramfs-linux-lts.img",
+				"/Memtest86+\n    protocol: efi\n    path: boot():/memtest86+/memtest.efi",
+			}
+			for _, want := range expected {
+				if !strings.Contains(out, want) {
+					t.Errorf("expected %q in output, got:\n%s", want, out)
+				}
+			}
+
+			if strings.Contains(out, "linux-lts-fallback") {
+				t.Errorf("expected no fallback entry for linux-lts, got:\n%s", out)
+			}
+
+			if strings.Index(out, "//linux-lts") > strings.Index(out, "//Snapshots") {
+				t.Errorf("expected secondary kernel before Snapshots entry, got:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestLimineConfig_RenderInvalidTemplate(t *testing.T) {
+	if _, err := testLimineConfig().Render("timeout: {{.Unknown}}"); err == nil {
+		t.Error("expected error for unknown template field")
+	}
+}
+
+func TestLimineConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(c *LimineConfig)
+		shouldErr bool
+	}{
+		{"valid", func(c *LimineConfig) {}, false},
+		{"no kernels", func(c *LimineConfig) { c.Kernels = nil }, true},
+		{"duplicate kernel", func(c *LimineConfig) { c.Kernels[1].Name = "linux-zen" }, true},
+		{"negative timeout", func(c *LimineConfig) { c.Timeout = -1 }, true},
+		{"zero default entry", func(c *LimineConfig) { c.DefaultEntry = 0 }, true},
+		{"newline in cmdline", func(c *LimineConfig) { c.Kernels[0].Cmdline = "quiet\nprotocol: efi" }, true},
+		{"entry without path", func(c *LimineConfig) { c.Entries[0].Path = "" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testLimineConfig()
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); (err != nil) != tt.shouldErr {
+				t.Errorf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+		})
+	}
+}
+
+func TestLookupExtraEntry(t *testing.T) {
+	entry, err := LookupExtraEntry("EFI-Shell")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	limineEntry := entry.LimineEntry()
+	if limineEntry.Protocol != "efi" || limineEntry.Path != "boot():/EFI/tools/shellx64.efi" {
+		t.Errorf("unexpected limine entry: %+v", limineEntry)
+	}
+
+	if _, err := LookupExtraEntry("grub"); err == nil {
+		t.Error("expected error for unknown entry")
+	}
+}
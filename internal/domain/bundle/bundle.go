@@ -0,0 +1,185 @@
+// Package bundle models the archup offline bundle: the install assets plus a
+// pacman repository of every package the installer may install, laid out like
+// an Arch mirror so pacman reaches it through a file:// Server line.
+//
+//	<bundle>/archup-bundle                     marker, archup version that built it
+//	<bundle>/assets/install/...                archup install/ tree
+//	<bundle>/assets/assets/plymouth/...        archup assets/ tree
+//	<bundle>/repo/<repo>/os/x86_64/<repo>.db   one repo-add repository per official repo
+package bundle
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// MarkerFile identifies a bundle directory
+	MarkerFile = "archup-bundle"
+
+	// AssetsDir holds the archup source tree subset the installer reads
+	AssetsDir = "assets"
+
+	// RepoDir holds the pacman repositories
+	RepoDir = "repo"
+
+	// Arch is the only architecture bundles are built for
+	Arch = "x86_64"
+
+	// TargetRepoDir is where the repositories are bind mounted in the installed
+	// system while the installer runs pacman in the chroot
+	TargetRepoDir = "/var/cache/archup-offline"
+)
+
+// Repos returns the official repositories a bundle mirrors
+func Repos() []string {
+	return []string{"core", "extra", "multilib"}
+}
+
+// Bundle is an offline bundle directory
+type Bundle struct {
+	root string
+}
+
+// NewBundle creates a bundle rooted at an absolute directory
+func NewBundle(root string) (*Bundle, error) {
+	if !path.IsAbs(root) {
+		return nil, fmt.Errorf("bundle path must be absolute, got %q", root)
+	}
+	return &Bundle{root: path.Clean(root)}, nil
+}
+
+// Root returns the bundle directory
+func (b *Bundle) Root() string {
+	return b.root
+}
+
+// MarkerPath returns the path of the marker file
+func (b *Bundle) MarkerPath() string {
+	return path.Join(b.root, MarkerFile)
+}
+
+// AssetsDir returns the directory holding the install assets
+func (b *Bundle) AssetsDir() string {
+	return path.Join(b.root, AssetsDir)
+}
+
+// RepoDir returns the directory holding the pacman repositories
+func (b *Bundle) RepoDir() string {
+	return path.Join(b.root, RepoDir)
+}
+
+// RepoPath returns the package directory of a repository
+func (b *Bundle) RepoPath(repo string) string {
+	return RepoPath(b.RepoDir(), repo)
+}
+
+// String returns human-readable representation
+func (b *Bundle) String() string {
+	return "Bundle(" + b.root + ")"
+}
+
+// RepoPath returns the package directory of a repository below a repo root,
+// matching the $repo/os/$arch layout of Mirrorlist
+func RepoPath(repoRoot, repo string) string {
+	return path.Join(repoRoot, repo, "os", Arch)
+}
+
+// DatabaseName returns the repo-add database file name of a repository
+func DatabaseName(repo string) string {
+	return repo + ".db.tar.gz"
+}
+
+// Mirrorlist returns a pacman mirrorlist serving the repositories below repoRoot
+func Mirrorlist(repoRoot string) string {
+	return "## archup offline bundle\nServer = file://" + repoRoot + "/$repo/os/$arch\n"
+}
+
+// PacmanConf returns a pacman.conf with only the official repositories, each
+// including mirrorlist. Packages keep their Arch signatures, checked against
+// the archlinux-keyring of the live ISO.
+func PacmanConf(mirrorlist string) string {
+	var b strings.Builder
+	b.WriteString("[options]\n")
+	b.WriteString("Architecture = auto\n")
+	b.WriteString("SigLevel = Required DatabaseOptional\n")
+	b.WriteString("LocalFileSigLevel = Optional\n")
+	for _, repo := range Repos() {
+		fmt.Fprintf(&b, "\n[%s]\nInclude = %s\n", repo, mirrorlist)
+	}
+	return b.String()
+}
+
+// Marker returns the marker file content
+func Marker(version string) string {
+	return "version=" + version + "\n"
+}
+
+// IsTarball returns true if path names a bundle tarball rather than a directory
+func IsTarball(name string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz", ".tar.zst"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Targets maps each repository to the package files resolved from it
+type Targets map[string][]string
+
+// Repos returns the repositories with packages, sorted
+func (t Targets) Repos() []string {
+	repos := make([]string, 0, len(t))
+	for repo := range t {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// Count returns the number of package files
+func (t Targets) Count() int {
+	count := 0
+	for _, files := range t {
+		count += len(files)
+	}
+	return count
+}
+
+// ParseTargets parses `pacman -Sp --print-format "%r %l"` output: one
+// "repository location" line per package, dependencies included
+func ParseTargets(output string) (Targets, error) {
+	targets := Targets{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "::") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid pacman target %q", line)
+		}
+		repo, file := fields[0], path.Base(fields[1])
+		if !isOfficialRepo(repo) {
+			return nil, fmt.Errorf("package %s comes from %s, bundles only hold %s packages",
+				file, repo, strings.Join(Repos(), ", "))
+		}
+		targets[repo] = append(targets[repo], file)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no packages resolved")
+	}
+	return targets, nil
+}
+
+func isOfficialRepo(repo string) bool {
+	for _, r := range Repos() {
+		if r == repo {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package bundle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewBundle(t *testing.T) {
+	b, err := NewBundle("/srv/archup-bundle/")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if b.Root() != "/srv/archup-bundle" {
+		t.Errorf("expected cleaned root, got %q", b.Root())
+	}
+	if got := b.RepoPath("extra"); got != "/srv/archup-bundle/repo/extra/os/x86_64" {
+		t.Errorf("unexpected repo path %q", got)
+	}
+	if got := b.AssetsDir(); got != "/srv/archup-bundle/assets" {
+		t.Errorf("unexpected assets dir %q", got)
+	}
+
+	if _, err := NewBundle("archup-bundle"); err == nil {
+		t.Error("expected error for relative path")
+	}
+}
+
+func TestPacmanConf(t *testing.T) {
+	conf := PacmanConf("/tmp/archup-offline/mirrorlist")
+	for _, repo := range Repos() {
+		if !strings.Contains(conf, "["+repo+"]\nInclude = /tmp/archup-offline/mirrorlist\n") {
+			t.Errorf("expected %s to include the offline mirrorlist, got:\n%s", repo, conf)
+		}
+	}
+	if strings.Contains(conf, "chaotic") || strings.Contains(conf, "cachyos") {
+		t.Error("expected only official repositories")
+	}
+
+	mirrorlist := Mirrorlist(TargetRepoDir)
+	if !strings.Contains(mirrorlist, "Server = file:///var/cache/archup-offline/$repo/os/$arch") {
+		t.Errorf("unexpected mirrorlist %q", mirrorlist)
+	}
+}
+
+func TestIsTarball(t *testing.T) {
+	tests := map[string]bool{
+		"/srv/bundle.tar":     true,
+		"/srv/bundle.tar.zst": true,
+		"/srv/bundle.tgz":     true,
+		"/srv/bundle":         false,
+		"/srv/bundle.d":       false,
+	}
+	for name, expected := range tests {
+		if got := IsTarball(name); got != expected {
+			t.Errorf("IsTarball(%q) = %v, expected %v", name, got, expected)
+		}
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	output := `core https://geo.mirror.pkgbuild.com/core/os/x86_64/base-3-2-any.pkg.tar.zst
+core https://geo.mirror.pkgbuild.com/core/os/x86_64/linux-6.17.1.arch1-1-x86_64.pkg.tar.zst
+extra https://geo.mirror.pkgbuild.com/extra/os/x86_64/limine-10.1.0-1-x86_64.pkg.tar.zst
+`
+	targets, err := ParseTargets(output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(targets.Repos(), []string{"core", "extra"}) {
+		t.Errorf("unexpected repos %v", targets.Repos())
+	}
+	if targets.Count() != 3 {
+		t.Errorf("expected 3 packages, got %d", targets.Count())
+	}
+	if targets["extra"][0] != "limine-10.1.0-1-x86_64.pkg.tar.zst" {
+		t.Errorf("expected file names, got %v", targets["extra"])
+	}
+
+	if _, err := ParseTargets("chaotic-aur https://cdn-mirror.chaotic.cx/paru-2.0-1-x86_64.pkg.tar.zst\n"); err == nil {
+		t.Error("expected error for unofficial repository")
+	}
+	if _, err := ParseTargets(""); err == nil {
+		t.Error("expected error for empty output")
+	}
+}
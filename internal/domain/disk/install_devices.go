@@ -0,0 +1,104 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Labels written by the installer, used to find an existing install again
+const (
+	// LabelEFI is the FAT label and GPT partition name of the ESP
+	LabelEFI = "EFI"
+
+	// LabelRoot is the Btrfs label and GPT partition name of the root partition
+	LabelRoot = "ROOT"
+
+	// LabelLUKS is the LUKS2 header label of the encrypted root partition
+	LabelLUKS = "ARCHUP_LUKS"
+
+	// CryptMapperName is the device mapper name of the opened LUKS container
+	CryptMapperName = "cryptroot"
+)
+
+// BlockDevice is a block device as reported by lsblk
+type BlockDevice struct {
+	Path      string
+	FSType    string
+	Label     string
+	PartLabel string
+}
+
+// InstallDevices are the partitions of an existing archup install
+type InstallDevices struct {
+	EFI  string // ESP partition
+	Root string // Btrfs root partition, empty when encrypted and still locked
+	LUKS string // LUKS partition holding the root filesystem, empty when unencrypted
+}
+
+// Encrypted returns true if the root filesystem is inside a LUKS container
+func (d *InstallDevices) Encrypted() bool {
+	return d.LUKS != ""
+}
+
+// RootDevice returns the device holding the Btrfs root filesystem once opened
+func (d *InstallDevices) RootDevice() string {
+	if d.Encrypted() {
+		return "/dev/mapper/" + CryptMapperName
+	}
+	return d.Root
+}
+
+// String returns human-readable representation
+func (d *InstallDevices) String() string {
+	if d.Encrypted() {
+		return fmt.Sprintf("InstallDevices(efi=%s, luks=%s)", d.EFI, d.LUKS)
+	}
+	return fmt.Sprintf("InstallDevices(efi=%s, root=%s)", d.EFI, d.Root)
+}
+
+// FindInstallDevices finds the ESP and root partitions of an archup install by
+// their labels. An install is expected exactly once among the devices.
+func FindInstallDevices(devices []BlockDevice) (*InstallDevices, error) {
+	var efi, root, luks []string
+	for _, d := range devices {
+		switch {
+		case d.FSType == "vfat" && (d.Label == LabelEFI || d.PartLabel == LabelEFI):
+			efi = append(efi, d.Path)
+		case d.FSType == "crypto_LUKS" && d.Label == LabelLUKS:
+			luks = append(luks, d.Path)
+		case d.FSType == "btrfs" && (d.Label == LabelRoot || d.PartLabel == LabelRoot):
+			root = append(root, d.Path)
+		}
+	}
+
+	// An already opened LUKS container exposes the root filesystem a second time
+	if len(luks) > 0 {
+		unlocked := root[:0]
+		for _, path := range root {
+			if !strings.HasPrefix(path, "/dev/mapper/") {
+				unlocked = append(unlocked, path)
+			}
+		}
+		root = unlocked
+	}
+
+	if len(efi) == 0 {
+		return nil, errors.New("no EFI partition found")
+	}
+	if len(luks) == 0 && len(root) == 0 {
+		return nil, fmt.Errorf("no %s or %s partition found", LabelLUKS, LabelRoot)
+	}
+	if len(efi) > 1 || len(luks)+len(root) > 1 {
+		return nil, fmt.Errorf("several installs found (%s), select a disk",
+			strings.Join(append(append(efi, luks...), root...), ", "))
+	}
+
+	result := &InstallDevices{EFI: efi[0]}
+	if len(luks) == 1 {
+		result.LUKS = luks[0]
+	} else {
+		result.Root = root[0]
+	}
+	return result, nil
+}
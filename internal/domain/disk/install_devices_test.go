@@ -0,0 +1,90 @@
+package disk
+
+import (
+	"testing"
+)
+
+func TestFindInstallDevices(t *testing.T) {
+	tests := []struct {
+		name      string
+		devices   []BlockDevice
+		expected  InstallDevices
+		shouldErr bool
+	}{
+		{
+			name: "unencrypted",
+			devices: []BlockDevice{
+				{Path: "/dev/sda"},
+				{Path: "/dev/sda1", FSType: "vfat", Label: "EFI", PartLabel: "EFI"},
+				{Path: "/dev/sda2", FSType: "btrfs", Label: "ROOT", PartLabel: "ROOT"},
+			},
+			expected: InstallDevices{EFI: "/dev/sda1", Root: "/dev/sda2"},
+		},
+		{
+			name: "encrypted",
+			devices: []BlockDevice{
+				{Path: "/dev/nvme0n1p1", FSType: "vfat", Label: "EFI", PartLabel: "EFI"},
+				{Path: "/dev/nvme0n1p2", FSType: "crypto_LUKS", Label: "ARCHUP_LUKS", PartLabel: "ROOT"},
+			},
+			expected: InstallDevices{EFI: "/dev/nvme0n1p1", LUKS: "/dev/nvme0n1p2"},
+		},
+		{
+			name: "encrypted and already open",
+			devices: []BlockDevice{
+				{Path: "/dev/nvme0n1p1", FSType: "vfat", Label: "EFI"},
+				{Path: "/dev/nvme0n1p2", FSType: "crypto_LUKS", Label: "ARCHUP_LUKS"},
+				{Path: "/dev/mapper/cryptroot", FSType: "btrfs", Label: "ROOT"},
+			},
+			expected: InstallDevices{EFI: "/dev/nvme0n1p1", LUKS: "/dev/nvme0n1p2"},
+		},
+		{
+			name: "no EFI partition",
+			devices: []BlockDevice{
+				{Path: "/dev/sda2", FSType: "btrfs", Label: "ROOT"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no root partition",
+			devices: []BlockDevice{
+				{Path: "/dev/sda1", FSType: "vfat", Label: "EFI"},
+				{Path: "/dev/sda2", FSType: "ext4", Label: "ROOT"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "two installs",
+			devices: []BlockDevice{
+				{Path: "/dev/sda1", FSType: "vfat", Label: "EFI"},
+				{Path: "/dev/sda2", FSType: "btrfs", Label: "ROOT"},
+				{Path: "/dev/sdb1", FSType: "vfat", Label: "EFI"},
+				{Path: "/dev/sdb2", FSType: "btrfs", Label: "ROOT"},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devices, err := FindInstallDevices(tt.devices)
+			if (err != nil) != tt.shouldErr {
+				t.Fatalf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+			if err == nil && *devices != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *devices)
+			}
+		})
+	}
+}
+
+func TestInstallDevices_RootDevice(t *testing.T) {
+	plain := &InstallDevices{EFI: "/dev/sda1", Root: "/dev/sda2"}
+	if plain.Encrypted() || plain.RootDevice() != "/dev/sda2" {
+		t.Errorf("unexpected root device for unencrypted install: %s", plain.RootDevice())
+	}
+
+	encrypted := &InstallDevices{EFI: "/dev/sda1", LUKS: "/dev/sda2"}
+	if !encrypted.Encrypted() || encrypted.RootDevice() != "/dev/mapper/cryptroot" {
+		t.Errorf("unexpected root device for encrypted install: %s", encrypted.RootDevice())
+	}
+}
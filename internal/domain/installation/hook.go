@@ -0,0 +1,228 @@
+package installation
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// HookPoint is a phase boundary user hook scripts run at
+type HookPoint string
+
+const (
+	// HookPrePartition runs before the target disk is wiped
+	HookPrePartition HookPoint = "pre-partition"
+
+	// HookPostPartition runs once the filesystems are mounted at the mount point
+	HookPostPartition HookPoint = "post-partition"
+
+	// HookPostBase runs after pacstrap installed the base system
+	HookPostBase HookPoint = "post-base"
+
+	// HookPostConfig runs after hostname, locale and users are configured
+	HookPostConfig HookPoint = "post-config"
+
+	// HookPostBootloader runs after the bootloader is installed
+	HookPostBootloader HookPoint = "post-bootloader"
+
+	// HookPostInstall runs after the post-installation tasks
+	HookPostInstall HookPoint = "post-install"
+)
+
+// HookPoints returns the hook points in installation order
+func HookPoints() []HookPoint {
+	return []HookPoint{
+		HookPrePartition,
+		HookPostPartition,
+		HookPostBase,
+		HookPostConfig,
+		HookPostBootloader,
+		HookPostInstall,
+	}
+}
+
+// ParseHookPoint parses a hook point name
+func ParseHookPoint(name string) (HookPoint, error) {
+	for _, p := range HookPoints() {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown hook point %q", name)
+}
+
+// HasTarget returns true if the target system is installed at this point,
+// so hooks can run inside it
+func (p HookPoint) HasTarget() bool {
+	return p != HookPrePartition && p != HookPostPartition
+}
+
+// HookTarget is where a hook script runs
+type HookTarget int
+
+const (
+	// HookTargetHost runs the script on the live ISO
+	HookTargetHost HookTarget = iota
+
+	// HookTargetChroot runs the script inside the installed system
+	HookTargetChroot
+)
+
+// String returns the target name
+func (t HookTarget) String() string {
+	if t == HookTargetChroot {
+		return "chroot"
+	}
+	return "host"
+}
+
+// ParseHookTarget parses a target name
+func ParseHookTarget(name string) (HookTarget, error) {
+	switch name {
+	case "host":
+		return HookTargetHost, nil
+	case "chroot":
+		return HookTargetChroot, nil
+	default:
+		return HookTargetHost, fmt.Errorf("unknown hook target %q (expected host or chroot)", name)
+	}
+}
+
+// HookFailurePolicy is what a failing hook does to its phase
+type HookFailurePolicy int
+
+const (
+	// HookFailAbort fails the phase and stops the installation
+	HookFailAbort HookFailurePolicy = iota
+
+	// HookFailWarn logs the failure and runs the remaining hooks
+	HookFailWarn
+)
+
+// String returns the policy name
+func (p HookFailurePolicy) String() string {
+	if p == HookFailWarn {
+		return "warn"
+	}
+	return "abort"
+}
+
+// ParseHookFailurePolicy parses a policy name, empty meaning abort
+func ParseHookFailurePolicy(name string) (HookFailurePolicy, error) {
+	switch name {
+	case "", "abort":
+		return HookFailAbort, nil
+	case "warn":
+		return HookFailWarn, nil
+	default:
+		return HookFailAbort, fmt.Errorf("unknown hook failure policy %q (expected abort or warn)", name)
+	}
+}
+
+// chrootHookSuffixes mark hook directory scripts run inside the installed system
+var chrootHookSuffixes = []string{".chroot", ".chroot.sh"}
+
+// Hook is a user script run at a hook point
+type Hook struct {
+	point  HookPoint
+	target HookTarget
+	path   string
+}
+
+// NewHook creates a validated hook. path is the script path on the host.
+func NewHook(point HookPoint, target HookTarget, scriptPath string) (*Hook, error) {
+	if _, err := ParseHookPoint(string(point)); err != nil {
+		return nil, err
+	}
+	if !path.IsAbs(scriptPath) {
+		return nil, fmt.Errorf("hook script path must be absolute, got %q", scriptPath)
+	}
+	if target == HookTargetChroot && !point.HasTarget() {
+		return nil, fmt.Errorf("%s hooks cannot run in chroot, the target system is not installed yet", point)
+	}
+
+	return &Hook{
+		point:  point,
+		target: target,
+		path:   path.Clean(scriptPath),
+	}, nil
+}
+
+// NewHookFromFile creates a hook for a script found in a hook directory.
+// Scripts named *.chroot or *.chroot.sh run inside the installed system.
+func NewHookFromFile(point HookPoint, scriptPath string) (*Hook, error) {
+	target := HookTargetHost
+	for _, suffix := range chrootHookSuffixes {
+		if strings.HasSuffix(scriptPath, suffix) {
+			target = HookTargetChroot
+		}
+	}
+	return NewHook(point, target, scriptPath)
+}
+
+// Point returns the hook point
+func (h *Hook) Point() HookPoint {
+	return h.point
+}
+
+// Target returns where the script runs
+func (h *Hook) Target() HookTarget {
+	return h.target
+}
+
+// Path returns the script path on the host
+func (h *Hook) Path() string {
+	return h.path
+}
+
+// Name returns the script file name
+func (h *Hook) Name() string {
+	return path.Base(h.path)
+}
+
+// String returns human-readable representation
+func (h *Hook) String() string {
+	return fmt.Sprintf("Hook(%s, %s, %s)", h.point, h.target, h.path)
+}
+
+// Hooks is a list of hooks in run order
+type Hooks []*Hook
+
+// At returns the hooks of a hook point, keeping their order
+func (hs Hooks) At(point HookPoint) Hooks {
+	var at Hooks
+	for _, h := range hs {
+		if h.point == point {
+			at = append(at, h)
+		}
+	}
+	return at
+}
+
+// ParseHooks parses the ARCHUP_HOOKS answer file value: whitespace separated
+// point:target:path entries. An empty spec has no hooks.
+//
+//	post-base:chroot:/root/corporate-ca.sh post-install:host:/root/notify.sh
+func ParseHooks(spec string) (Hooks, error) {
+	var hooks Hooks
+	for _, entry := range strings.Fields(spec) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid hook %q, expected point:target:path", entry)
+		}
+		point, err := ParseHookPoint(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		target, err := ParseHookTarget(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		hook, err := NewHook(point, target, parts[2])
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
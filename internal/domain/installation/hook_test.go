@@ -0,0 +1,100 @@
+package installation
+
+import "testing"
+
+func TestParseHooks(t *testing.T) {
+	hooks, err := ParseHooks("post-base:chroot:/root/ca.sh pre-partition:host:/root/wipe-check.sh post-base:host:/root/notify.sh")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(hooks) != 3 {
+		t.Fatalf("expected 3 hooks, got %d", len(hooks))
+	}
+
+	postBase := hooks.At(HookPostBase)
+	if len(postBase) != 2 {
+		t.Fatalf("expected 2 post-base hooks, got %d", len(postBase))
+	}
+	if postBase[0].Target() != HookTargetChroot || postBase[0].Name() != "ca.sh" {
+		t.Errorf("unexpected first post-base hook %s", postBase[0])
+	}
+	if postBase[1].Target() != HookTargetHost || postBase[1].Path() != "/root/notify.sh" {
+		t.Errorf("unexpected second post-base hook %s", postBase[1])
+	}
+	if len(hooks.At(HookPostInstall)) != 0 {
+		t.Error("expected no post-install hooks")
+	}
+
+	if hooks, err := ParseHooks("  "); err != nil || len(hooks) != 0 {
+		t.Errorf("expected empty spec to have no hooks, got %v, %v", hooks, err)
+	}
+}
+
+func TestParseHooks_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"missing target", "post-base:/root/ca.sh"},
+		{"unknown point", "pre-reboot:host:/root/ca.sh"},
+		{"unknown target", "post-base:container:/root/ca.sh"},
+		{"relative path", "post-base:host:ca.sh"},
+		{"chroot before base install", "post-partition:chroot:/root/ca.sh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseHooks(tt.spec); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNewHookFromFile(t *testing.T) {
+	tests := []struct {
+		path   string
+		target HookTarget
+	}{
+		{"/etc/archup/hooks/post-base/10-ca.sh", HookTargetHost},
+		{"/etc/archup/hooks/post-base/20-agent.chroot.sh", HookTargetChroot},
+		{"/etc/archup/hooks/post-base/30-agent.chroot", HookTargetChroot},
+	}
+
+	for _, tt := range tests {
+		hook, err := NewHookFromFile(HookPostBase, tt.path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if hook.Target() != tt.target {
+			t.Errorf("%s: expected target %s, got %s", tt.path, tt.target, hook.Target())
+		}
+	}
+
+	if _, err := NewHookFromFile(HookPrePartition, "/etc/archup/hooks/pre-partition/10-x.chroot.sh"); err == nil {
+		t.Error("expected error for pre-partition chroot hook")
+	}
+}
+
+func TestParseHookFailurePolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  HookFailurePolicy
+		shouldErr bool
+	}{
+		{"", HookFailAbort, false},
+		{"abort", HookFailAbort, false},
+		{"warn", HookFailWarn, false},
+		{"ignore", HookFailAbort, true},
+	}
+
+	for _, tt := range tests {
+		policy, err := ParseHookFailurePolicy(tt.name)
+		if (err != nil) != tt.shouldErr {
+			t.Errorf("%q: expected error=%v, got %v", tt.name, tt.shouldErr, err)
+		}
+		if policy != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.name, tt.expected, policy)
+		}
+	}
+}
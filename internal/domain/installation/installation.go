@@ -293,14 +293,11 @@ func (i *Installation) recordEvent(event DomainEvent) {
 	i.events = append(i.events, event)
 }
 
-// getNextPhase returns the next phase in the sequence
+// getNextPhase returns the next phase in the lifecycle, or -1 if there is none
 func (i *Installation) getNextPhase() State {
-	sequence := PhaseSequence()
-	currentIndex := PhaseIndex(i.state)
-
-	if currentIndex == -1 || currentIndex >= len(sequence)-1 {
+	next, ok := i.state.NextState()
+	if !ok {
 		return -1
 	}
-
-	return sequence[currentIndex+1]
+	return next
 }
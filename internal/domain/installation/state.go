@@ -73,6 +73,21 @@ func (s State) IsValid() bool {
 	return s >= StateNotStarted && s <= StateFailed
 }
 
+// transitions lists the states each state may progress to (strict progression).
+// Failed is reachable from any non-terminal state and not listed.
+var transitions = map[State][]State{
+	StateNotStarted:          {StatePreflightChecks},
+	StatePreflightChecks:     {StateDiskPartitioning},
+	StateDiskPartitioning:    {StateBaseInstallation},
+	StateBaseInstallation:    {StateSystemConfiguration},
+	StateSystemConfiguration: {StateBootloaderSetup},
+	StateBootloaderSetup:     {StateRepositorySetup},
+	StateRepositorySetup:     {StatePostInstallation},
+	StatePostInstallation:    {StateCompleted},
+	StateCompleted:           {},
+	StateFailed:              {},
+}
+
 // CanTransitionTo returns true if a transition from the current state to the target state is allowed
 // This enforces the business rule that states must progress in strict order
 func (s State) CanTransitionTo(target State) bool {
@@ -91,21 +106,7 @@ func (s State) CanTransitionTo(target State) bool {
 		return false
 	}
 
-	// Define valid transitions (strict progression)
-	validTransitions := map[State][]State{
-		StateNotStarted:          {StatePreflightChecks},
-		StatePreflightChecks:     {StateDiskPartitioning},
-		StateDiskPartitioning:    {StateBaseInstallation},
-		StateBaseInstallation:    {StateSystemConfiguration},
-		StateSystemConfiguration: {StateBootloaderSetup},
-		StateBootloaderSetup:     {StateRepositorySetup},
-		StateRepositorySetup:     {StatePostInstallation},
-		StatePostInstallation:    {StateCompleted},
-		StateCompleted:           {},
-		StateFailed:              {},
-	}
-
-	allowed, exists := validTransitions[s]
+	allowed, exists := transitions[s]
 	if !exists {
 		return false
 	}
@@ -128,29 +129,14 @@ func (s *State) TransitionTo(target State) error {
 	return nil
 }
 
-// PhaseSequence returns the sequence of installation phases in order
-func PhaseSequence() []State {
-	return []State{
-		StatePreflightChecks,
-		StateDiskPartitioning,
-		StateBaseInstallation,
-		StateSystemConfiguration,
-		StateBootloaderSetup,
-		StateRepositorySetup,
-		StatePostInstallation,
-		StateCompleted,
-	}
-}
-
-// PhaseIndex returns the index of a phase in the sequence, or -1 if not found
-func PhaseIndex(state State) int {
-	sequence := PhaseSequence()
-	for i, s := range sequence {
-		if s == state {
-			return i
-		}
+// NextState returns the state following s in the installation lifecycle, or
+// false for terminal states
+func (s State) NextState() (State, bool) {
+	next := transitions[s]
+	if len(next) == 0 {
+		return -1, false
 	}
-	return -1
+	return next[0], true
 }
 
 // ProgressPercentage returns estimated progress as percentage (0-100) based on current state
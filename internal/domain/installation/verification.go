@@ -0,0 +1,99 @@
+package installation
+
+import "fmt"
+
+// CheckStatus is the outcome of a verification check
+type CheckStatus int
+
+const (
+	// CheckPass means the check found no problem
+	CheckPass CheckStatus = iota
+
+	// CheckWarn means the system boots but something is degraded
+	CheckWarn
+
+	// CheckFail means the system is likely unbootable or unusable
+	CheckFail
+)
+
+// String returns the status name
+func (s CheckStatus) String() string {
+	switch s {
+	case CheckWarn:
+		return "warn"
+	case CheckFail:
+		return "fail"
+	default:
+		return "pass"
+	}
+}
+
+// MarshalText encodes the status as its name in JSON reports
+func (s CheckStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// CheckResult is the outcome of a single verification check
+type CheckResult struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// PassCheck creates a passing check result
+func PassCheck(name, message string) CheckResult {
+	return CheckResult{Name: name, Status: CheckPass, Message: message}
+}
+
+// WarnCheck creates a warning check result
+func WarnCheck(name, message string) CheckResult {
+	return CheckResult{Name: name, Status: CheckWarn, Message: message}
+}
+
+// FailCheck creates a failing check result
+func FailCheck(name, message string) CheckResult {
+	return CheckResult{Name: name, Status: CheckFail, Message: message}
+}
+
+// String returns human-readable representation
+func (r CheckResult) String() string {
+	return fmt.Sprintf("%s [%s]: %s", r.Name, r.Status, r.Message)
+}
+
+// VerificationReport collects the results of a verification run
+type VerificationReport struct {
+	MountPoint string        `json:"mount_point"`
+	Results    []CheckResult `json:"results"`
+}
+
+// Add appends a check result
+func (r *VerificationReport) Add(result CheckResult) {
+	r.Results = append(r.Results, result)
+}
+
+// Count returns the number of results with the given status
+func (r *VerificationReport) Count(status CheckStatus) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Passed returns true if no check failed
+func (r *VerificationReport) Passed() bool {
+	return r.Count(CheckFail) == 0
+}
+
+// Problems returns the warnings and failures as strings
+func (r *VerificationReport) Problems() []string {
+	problems := []string{}
+	for _, result := range r.Results {
+		if result.Status != CheckPass {
+			problems = append(problems, result.String())
+		}
+	}
+	return problems
+}
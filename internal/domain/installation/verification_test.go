@@ -0,0 +1,38 @@
+package installation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVerificationReport(t *testing.T) {
+	report := &VerificationReport{MountPoint: "/mnt"}
+	report.Add(PassCheck("boot-files", "present"))
+	report.Add(WarnCheck("efi-boot-entry", "fallback path only"))
+
+	if !report.Passed() {
+		t.Error("expected warnings not to fail the report")
+	}
+	if len(report.Problems()) != 1 {
+		t.Errorf("expected 1 problem, got %v", report.Problems())
+	}
+
+	report.Add(FailCheck("fstab-uuids", "unknown UUID"))
+	if report.Passed() || report.Count(CheckFail) != 1 {
+		t.Error("expected the report to fail")
+	}
+}
+
+func TestVerificationReport_JSON(t *testing.T) {
+	report := &VerificationReport{MountPoint: "/mnt"}
+	report.Add(FailCheck("services", "not enabled: NetworkManager.service"))
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(string(data), `"status":"fail"`) {
+		t.Errorf("expected status name in JSON, got %s", data)
+	}
+}
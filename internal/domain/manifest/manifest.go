@@ -0,0 +1,104 @@
+// Package manifest models the signed list of SHA-256 hashes published with
+// each release, against which remote install assets are verified.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// File is the manifest path in the archup repository
+	File = "manifest.sha256"
+
+	// SignatureFile is the minisign signature of File
+	SignatureFile = File + ".minisig"
+)
+
+// Manifest maps repository-relative asset paths to their SHA-256 hash, in
+// the `sha256sum` output format: one "<hex>  <path>" line per asset
+type Manifest struct {
+	hashes map[string]string
+}
+
+// New returns the manifest of assets, path to content
+func New(assets map[string][]byte) *Manifest {
+	m := &Manifest{hashes: make(map[string]string, len(assets))}
+	for name, content := range assets {
+		m.hashes[cleanPath(name)] = Hash(content)
+	}
+	return m
+}
+
+// Parse parses a manifest in the `sha256sum` output format
+func Parse(data []byte) (*Manifest, error) {
+	m := &Manifest{hashes: map[string]string{}}
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("manifest line %d: expected \"<sha256>  <path>\", got %q", i+1, line)
+		}
+		hash, name := strings.ToLower(fields[0]), cleanPath(strings.TrimPrefix(fields[1], "*"))
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("manifest line %d: invalid SHA-256 %q", i+1, fields[0])
+		}
+		if _, ok := m.hashes[name]; ok {
+			return nil, fmt.Errorf("manifest line %d: duplicate entry for %s", i+1, name)
+		}
+		m.hashes[name] = hash
+	}
+	if len(m.hashes) == 0 {
+		return nil, fmt.Errorf("manifest is empty")
+	}
+	return m, nil
+}
+
+// Verify returns an error unless content is the asset listed under name
+func (m *Manifest) Verify(name string, content []byte) error {
+	name = cleanPath(name)
+	expected, ok := m.hashes[name]
+	if !ok {
+		return fmt.Errorf("%s is not listed in the manifest", name)
+	}
+	if got := Hash(content); got != expected {
+		return fmt.Errorf("%s does not match the manifest: SHA-256 %s, expected %s", name, got, expected)
+	}
+	return nil
+}
+
+// Paths returns the listed asset paths, sorted
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.hashes))
+	for name := range m.hashes {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// String renders the manifest in the `sha256sum` output format, sorted by path
+func (m *Manifest) String() string {
+	var b strings.Builder
+	for _, name := range m.Paths() {
+		fmt.Fprintf(&b, "%s  %s\n", m.hashes[name], name)
+	}
+	return b.String()
+}
+
+// Hash returns the hex SHA-256 of content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func cleanPath(name string) string {
+	return path.Clean(strings.TrimPrefix(strings.TrimPrefix(name, "./"), "/"))
+}
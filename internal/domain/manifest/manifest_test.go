@@ -0,0 +1,56 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	base := Hash([]byte("base\n"))
+	data := "# archup v1.2.3\n" + base + "  install/base.packages\n" + Hash([]byte("#!/bin/bash\n")) + " *./install/mandatory/post-boot/all.sh\n"
+
+	m, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := m.Paths(); len(got) != 2 || got[0] != "install/base.packages" || got[1] != "install/mandatory/post-boot/all.sh" {
+		t.Errorf("unexpected paths %v", got)
+	}
+	if !strings.Contains(m.String(), base+"  install/base.packages\n") {
+		t.Errorf("unexpected rendering %q", m.String())
+	}
+
+	invalid := []string{
+		"",
+		"not-a-hash  install/base.packages\n",
+		base + "\n",
+		base + "  install/base.packages\n" + base + "  install/base.packages\n",
+	}
+	for _, data := range invalid {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("expected error for %q", data)
+		}
+	}
+}
+
+func TestManifest_Verify(t *testing.T) {
+	m := New(map[string][]byte{"install/base.packages": []byte("base\n")})
+
+	if err := m.Verify("/install/base.packages", []byte("base\n")); err != nil {
+		t.Errorf("expected match, got %v", err)
+	}
+	if err := m.Verify("install/base.packages", []byte("base\nbackdoor\n")); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+	if err := m.Verify("install/extra.packages", []byte("")); err == nil || !strings.Contains(err.Error(), "not listed") {
+		t.Errorf("expected unlisted error, got %v", err)
+	}
+
+	parsed, err := Parse([]byte(m.String()))
+	if err != nil {
+		t.Fatalf("expected rendered manifest to parse, got %v", err)
+	}
+	if err := parsed.Verify("install/base.packages", []byte("base\n")); err != nil {
+		t.Errorf("expected round trip, got %v", err)
+	}
+}
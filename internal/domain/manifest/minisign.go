@@ -0,0 +1,101 @@
+package manifest
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// minisign key and signature algorithm identifiers
+const (
+	algorithmEd25519   = "Ed" // signature of the message itself (minisign -l)
+	algorithmPrehashed = "ED" // signature of the BLAKE2b hash of the message
+	keyIDSize          = 8
+)
+
+// PublicKey is a minisign Ed25519 public key
+type PublicKey struct {
+	keyID [keyIDSize]byte
+	key   ed25519.PublicKey
+}
+
+// ParsePublicKey parses a minisign public key: the base64 line of a
+// minisign.pub file, as printed by `minisign -R`
+func ParsePublicKey(encoded string) (*PublicKey, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, errors.New("no manifest public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	if len(raw) != 2+keyIDSize+ed25519.PublicKeySize || string(raw[:2]) != algorithmEd25519 {
+		return nil, errors.New("not a minisign Ed25519 public key")
+	}
+	pk := &PublicKey{key: ed25519.PublicKey(raw[2+keyIDSize:])}
+	copy(pk.keyID[:], raw[2:2+keyIDSize])
+	return pk, nil
+}
+
+// KeyID returns the key identifier minisign prints, in hex
+func (pk *PublicKey) KeyID() string {
+	// minisign stores the key ID little-endian and prints it as a number
+	var b strings.Builder
+	for i := keyIDSize - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "%02X", pk.keyID[i])
+	}
+	return b.String()
+}
+
+// Verify checks a minisign signature of message: the signature itself and
+// the global signature covering its trusted comment.
+// Only legacy signatures (`minisign -S -l`) are supported, prehashed ones need
+// BLAKE2b which the standard library does not provide.
+func (pk *PublicKey) Verify(message, signature []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) < 4 {
+		return errors.New("invalid minisign signature: expected 4 lines")
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	if !strings.HasPrefix(lines[0], "untrusted comment:") {
+		return errors.New("invalid minisign signature: missing untrusted comment")
+	}
+	trustedComment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return errors.New("invalid minisign signature: missing trusted comment")
+	}
+
+	sigBlock, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sigBlock) != 2+keyIDSize+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature block")
+	}
+	switch string(sigBlock[:2]) {
+	case algorithmEd25519:
+	case algorithmPrehashed:
+		return errors.New("prehashed minisign signatures are not supported, sign with `minisign -S -l`")
+	default:
+		return fmt.Errorf("unknown minisign signature algorithm %q", sigBlock[:2])
+	}
+	if !bytes.Equal(sigBlock[2:2+keyIDSize], pk.keyID[:]) {
+		return fmt.Errorf("manifest signed with another key than %s", pk.KeyID())
+	}
+	sig := sigBlock[2+keyIDSize:]
+	if !ed25519.Verify(pk.key, message, sig) {
+		return errors.New("manifest signature verification failed")
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign global signature")
+	}
+	if !ed25519.Verify(pk.key, append(append([]byte{}, sig...), trustedComment...), globalSig) {
+		return errors.New("manifest trusted comment verification failed")
+	}
+	return nil
+}
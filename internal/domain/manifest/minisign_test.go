@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// testSigner produces minisign keys and legacy signatures, as `minisign -S -l`
+type testSigner struct {
+	keyID [keyIDSize]byte
+	priv  ed25519.PrivateKey
+	pub   ed25519.PublicKey
+}
+
+func newTestSigner(t *testing.T) *testSigner {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &testSigner{keyID: [keyIDSize]byte{1, 2, 3, 4, 5, 6, 7, 8}, priv: priv, pub: pub}
+}
+
+func (s *testSigner) publicKey() string {
+	raw := append(append([]byte(algorithmEd25519), s.keyID[:]...), s.pub...)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func (s *testSigner) sign(message []byte, algorithm, trustedComment string) []byte {
+	sig := ed25519.Sign(s.priv, message)
+	block := append(append([]byte(algorithm), s.keyID[:]...), sig...)
+	global := ed25519.Sign(s.priv, append(append([]byte{}, sig...), trustedComment...))
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(block) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func TestParsePublicKey(t *testing.T) {
+	signer := newTestSigner(t)
+	pk, err := ParsePublicKey(signer.publicKey() + "\n")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pk.KeyID() != "0807060504030201" {
+		t.Errorf("unexpected key id %s", pk.KeyID())
+	}
+
+	for _, invalid := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("Ed-too-short"))} {
+		if _, err := ParsePublicKey(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestPublicKey_Verify(t *testing.T) {
+	signer := newTestSigner(t)
+	pk, err := ParsePublicKey(signer.publicKey())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	message := []byte(Hash([]byte("base\n")) + "  install/base.packages\n")
+	signature := signer.sign(message, algorithmEd25519, "timestamp:1760000000\tfile:manifest.sha256")
+
+	if err := pk.Verify(message, signature); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	if err := pk.Verify(append(message, "x"...), signature); err == nil {
+		t.Error("expected error for tampered manifest")
+	}
+
+	tampered := strings.Replace(string(signature), "file:manifest.sha256", "file:other", 1)
+	if err := pk.Verify(message, []byte(tampered)); err == nil {
+		t.Error("expected error for tampered trusted comment")
+	}
+
+	other := newTestSigner(t)
+	other.keyID = [keyIDSize]byte{9, 9, 9, 9, 9, 9, 9, 9}
+	if err := pk.Verify(message, other.sign(message, algorithmEd25519, "x")); err == nil {
+		t.Error("expected error for signature of another key")
+	}
+
+	if err := pk.Verify(message, signer.sign(message, algorithmPrehashed, "x")); err == nil || !strings.Contains(err.Error(), "-l") {
+		t.Errorf("expected prehashed signatures to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package packages
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PacmanCacheService is the DNS-SD service type LAN package caches are
+// discovered with, e.g. published by avahi next to pacoloco or nginx
+const PacmanCacheService = "_pacman-cache._tcp"
+
+// PacmanCacheAuto asks for a pacman cache discovered on the local network
+const PacmanCacheAuto = "auto"
+
+// pacmanCacheMarker precedes the Server lines the pacman cache adds to
+// pacman.conf, so they can be removed again
+const pacmanCacheMarker = "# archup pacman cache"
+
+// cachedRepositories are the pacman.conf sections served through the cache
+var cachedRepositories = map[string]bool{
+	"core":        true,
+	"extra":       true,
+	"multilib":    true,
+	"chaotic-aur": true,
+	"cachyos":     true,
+}
+
+// PacmanCache is an immutable value object for a LAN package cache queried
+// before the mirrors of every repository.
+type PacmanCache struct {
+	url  string
+	keep bool
+}
+
+// NewPacmanCache creates a pacman cache. The URL is either the base of a
+// caching proxy serving archlinux/$repo/os/$arch, chaotic-aur/$repo/$arch and
+// cachyos/$arch/$repo (the pacoloco layout), or a Server value containing
+// $repo used for every repository. keep leaves the cache configured in the
+// installed system.
+func NewPacmanCache(url string, keep bool) (*PacmanCache, error) {
+	url = strings.TrimSpace(url)
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, fmt.Errorf("invalid pacman cache URL %q", url)
+	}
+	return &PacmanCache{url: url, keep: keep}, nil
+}
+
+// URL returns the cache URL.
+func (c *PacmanCache) URL() string { return c.url }
+
+// Keep returns true if the cache stays configured in the installed system.
+func (c *PacmanCache) Keep() bool { return c.keep }
+
+// Server returns the pacman Server value of a repository through the cache.
+func (c *PacmanCache) Server(repo string) string {
+	if strings.Contains(c.url, "$repo") {
+		return c.url
+	}
+	base := strings.TrimSuffix(c.url, "/")
+	switch repo {
+	case "chaotic-aur":
+		return base + "/chaotic-aur/$repo/$arch"
+	case "cachyos":
+		return base + "/cachyos/$arch/$repo"
+	default:
+		return base + "/archlinux/$repo/os/$arch"
+	}
+}
+
+// PacmanConfWithCache returns conf with the cache as the first server of
+// every cached repository section. Disabled sections are left alone and
+// running it again updates the cache servers.
+func PacmanConfWithCache(conf string, cache *PacmanCache) string {
+	lines := strings.Split(PacmanConfWithoutCache(conf), "\n")
+	out := make([]string, 0, len(lines)+2*len(cachedRepositories))
+	for _, line := range lines {
+		out = append(out, line)
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, "]") {
+			continue
+		}
+		repo := strings.Trim(trimmed, "[]")
+		if cachedRepositories[repo] {
+			out = append(out, pacmanCacheMarker, "Server = "+cache.Server(repo))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// PacmanConfWithoutCache returns conf without the cache servers added by
+// PacmanConfWithCache.
+func PacmanConfWithoutCache(conf string) string {
+	lines := strings.Split(conf, "\n")
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == pacmanCacheMarker {
+			i++ // Skip the Server line following the marker
+			continue
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(out, "\n")
+}
+
+// PacmanCacheURL returns the URL of a discovered cache from its address,
+// port and the DNS-SD "path" TXT value.
+func PacmanCacheURL(host string, port int, path string) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("http://%s:%d%s", host, port, strings.TrimSuffix(path, "/"))
+}
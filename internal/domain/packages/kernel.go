@@ -1,6 +1,9 @@
 package packages
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 // KernelVariant represents available kernel choices
 type KernelVariant int
@@ -80,6 +83,12 @@ func (k *Kernel) PackageName() string {
 	return k.variant.String()
 }
 
+// HeadersPackage returns the pacman package providing the kernel headers,
+// required to build DKMS modules against this kernel
+func (k *Kernel) HeadersPackage() string {
+	return k.variant.String() + "-headers"
+}
+
 // String returns human-readable representation
 func (k *Kernel) String() string {
 	return "Kernel(" + k.variant.String() + ")"
@@ -103,3 +112,129 @@ func AvailableKernels() []KernelVariant {
 		KernelCachyOS,
 	}
 }
+
+// KernelSet is an immutable value object for the installed kernels: a primary
+// kernel booted by default plus extra kernels kept as fallbacks (e.g. linux-lts),
+// so a broken update of one kernel never leaves the machine unbootable.
+type KernelSet struct {
+	kernels []*Kernel
+}
+
+// NewKernelSet creates a new KernelSet. Extra kernels duplicating the primary
+// kernel or each other are ignored.
+func NewKernelSet(primary KernelVariant, extras ...KernelVariant) (*KernelSet, error) {
+	primaryKernel, err := NewKernel(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	kernels := []*Kernel{primaryKernel}
+	seen := map[KernelVariant]bool{primary: true}
+	for _, variant := range extras {
+		if seen[variant] {
+			continue
+		}
+		kernel, err := NewKernel(variant)
+		if err != nil {
+			return nil, err
+		}
+		seen[variant] = true
+		kernels = append(kernels, kernel)
+	}
+
+	return &KernelSet{
+		kernels: kernels,
+	}, nil
+}
+
+// Primary returns the default boot kernel
+func (s *KernelSet) Primary() *Kernel {
+	return s.kernels[0]
+}
+
+// Extras returns the kernels installed next to the primary kernel
+func (s *KernelSet) Extras() []*Kernel {
+	return append([]*Kernel{}, s.kernels[1:]...)
+}
+
+// Kernels returns all kernels, primary first
+func (s *KernelSet) Kernels() []*Kernel {
+	return append([]*Kernel{}, s.kernels...)
+}
+
+// PackageNames returns the pacman package names of all kernels, primary first
+func (s *KernelSet) PackageNames() []string {
+	names := make([]string, 0, len(s.kernels))
+	for _, k := range s.kernels {
+		names = append(names, k.PackageName())
+	}
+	return names
+}
+
+// Contains returns true if the variant is part of the set
+func (s *KernelSet) Contains(variant KernelVariant) bool {
+	for _, k := range s.kernels {
+		if k.Variant() == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// OnlyStable returns true if the stock linux kernel is the only kernel, the
+// one prebuilt out-of-tree modules such as nvidia-open are built for
+func (s *KernelSet) OnlyStable() bool {
+	return len(s.kernels) == 1 && s.kernels[0].Variant().IsStable()
+}
+
+// HeadersPackages returns the headers packages of all kernels, primary first
+func (s *KernelSet) HeadersPackages() []string {
+	names := make([]string, 0, len(s.kernels))
+	for _, k := range s.kernels {
+		names = append(names, k.HeadersPackage())
+	}
+	return names
+}
+
+// WithHeaders returns pkgs with the headers of every kernel appended when any
+// package is built through DKMS. Headers already in the list are not repeated.
+func (s *KernelSet) WithHeaders(pkgs []string) []string {
+	if !RequiresDKMS(pkgs) {
+		return pkgs
+	}
+
+	present := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		present[pkg] = true
+	}
+
+	result := append([]string{}, pkgs...)
+	for _, headers := range s.HeadersPackages() {
+		if !present[headers] {
+			result = append(result, headers)
+		}
+	}
+	return result
+}
+
+// HasFallback returns true if at least one extra kernel is installed
+func (s *KernelSet) HasFallback() bool {
+	return len(s.kernels) > 1
+}
+
+// String returns human-readable representation
+func (s *KernelSet) String() string {
+	return "KernelSet(" + strings.Join(s.PackageNames(), ", ") + ")"
+}
+
+// RequiresDKMS returns true if any package depends on dkms. DKMS packages
+// follow the Arch naming convention of a -dkms suffix (e.g. broadcom-wl-dkms,
+// nvidia-dkms).
+func RequiresDKMS(pkgs []string) bool {
+	for _, pkg := range pkgs {
+		if pkg == "dkms" || strings.HasSuffix(pkg, "-dkms") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package packages
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mirror selection sources and destinations
+const (
+	MirrorStatusURL = "https://archlinux.org/mirrors/status/json/"
+	MirrorlistPath  = "/etc/pacman.d/mirrorlist"
+	ZoneTabPath     = "/usr/share/zoneinfo/zone.tab"
+)
+
+// DefaultMirrorCount is the number of ranked mirrors written to the mirrorlist
+const DefaultMirrorCount = 10
+
+// mirrorServerSuffix turns a mirror base URL into a pacman Server value
+const mirrorServerSuffix = "$repo/os/$arch"
+
+// Mirror is an immutable value object for an Arch Linux mirror from the
+// mirror status.
+type Mirror struct {
+	url     string  // Base URL ending with a slash
+	country string  // ISO 3166 country code, empty for worldwide mirrors
+	score   float64 // Mirror status score, lower is better
+}
+
+// NewMirror creates a mirror from its base URL, country code and status score.
+func NewMirror(url, country string, score float64) (*Mirror, error) {
+	url = strings.TrimSpace(url)
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("invalid mirror URL %q", url)
+	}
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return &Mirror{url: url, country: strings.ToUpper(country), score: score}, nil
+}
+
+// URL returns the mirror base URL.
+func (m *Mirror) URL() string { return m.url }
+
+// Country returns the ISO 3166 country code of the mirror.
+func (m *Mirror) Country() string { return m.country }
+
+// Score returns the mirror status score, lower is better.
+func (m *Mirror) Score() float64 { return m.score }
+
+// Server returns the pacman Server value of the mirror.
+func (m *Mirror) Server() string { return m.url + mirrorServerSuffix }
+
+// ProbeURL returns the URL of the core database, downloaded to measure the
+// mirror throughput.
+func (m *Mirror) ProbeURL(arch string) string {
+	return m.url + "core/os/" + arch + "/core.db"
+}
+
+// mirrorStatus is the layout of the mirror status JSON
+type mirrorStatus struct {
+	URLs []struct {
+		URL           string   `json:"url"`
+		Protocol      string   `json:"protocol"`
+		CountryCode   string   `json:"country_code"`
+		Active        bool     `json:"active"`
+		CompletionPct float64  `json:"completion_pct"`
+		Score         *float64 `json:"score"`
+	} `json:"urls"`
+}
+
+// ParseMirrorStatus returns the active, fully synced HTTPS mirrors of the
+// mirror status JSON, best status score first.
+func ParseMirrorStatus(data []byte) ([]*Mirror, error) {
+	var status mirrorStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror status: %w", err)
+	}
+
+	var mirrors []*Mirror
+	for _, entry := range status.URLs {
+		if !entry.Active || entry.Protocol != "https" || entry.CompletionPct < 1 || entry.Score == nil {
+			continue
+		}
+		mirror, err := NewMirror(entry.URL, entry.CountryCode, *entry.Score)
+		if err != nil {
+			continue
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	if len(mirrors) == 0 {
+		return nil, errors.New("no usable mirror in the mirror status")
+	}
+
+	sort.SliceStable(mirrors, func(i, j int) bool { return mirrors[i].score < mirrors[j].score })
+	return mirrors, nil
+}
+
+// MirrorsInCountry returns the mirrors of a country, every mirror when the
+// country is unknown or has none.
+func MirrorsInCountry(mirrors []*Mirror, country string) []*Mirror {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return mirrors
+	}
+	var result []*Mirror
+	for _, mirror := range mirrors {
+		if mirror.country == country {
+			result = append(result, mirror)
+		}
+	}
+	if len(result) == 0 {
+		return mirrors
+	}
+	return result
+}
+
+// MirrorProbe is the outcome of downloading the probe file of a mirror.
+type MirrorProbe struct {
+	Mirror   *Mirror
+	Bytes    int
+	Duration time.Duration
+	Err      error
+}
+
+// Throughput returns the measured download speed in bytes per second, 0 for
+// a failed probe.
+func (p MirrorProbe) Throughput() float64 {
+	if p.Err != nil || p.Bytes == 0 || p.Duration <= 0 {
+		return 0
+	}
+	return float64(p.Bytes) / p.Duration.Seconds()
+}
+
+// RankMirrors returns the mirrors of the successful probes, fastest first,
+// at most count of them.
+func RankMirrors(probes []MirrorProbe, count int) []*Mirror {
+	ranked := make([]MirrorProbe, 0, len(probes))
+	for _, probe := range probes {
+		if probe.Throughput() > 0 {
+			ranked = append(ranked, probe)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Throughput() > ranked[j].Throughput() })
+
+	var mirrors []*Mirror
+	for _, probe := range ranked {
+		if count > 0 && len(mirrors) == count {
+			break
+		}
+		mirrors = append(mirrors, probe.Mirror)
+	}
+	return mirrors
+}
+
+// MirrorServer returns the pacman Server value of a mirror given as a base
+// URL or as a full Server value containing $repo.
+func MirrorServer(url string) string {
+	url = strings.TrimSpace(url)
+	if strings.Contains(url, "$repo") {
+		return url
+	}
+	return strings.TrimSuffix(url, "/") + "/" + mirrorServerSuffix
+}
+
+// Mirrorlist returns a pacman mirrorlist of servers, in order.
+func Mirrorlist(servers []string, origin string) string {
+	var b strings.Builder
+	b.WriteString("## Arch Linux mirrorlist, written by archup\n")
+	if origin != "" {
+		b.WriteString("## " + origin + "\n")
+	}
+	for _, server := range servers {
+		b.WriteString("Server = " + server + "\n")
+	}
+	return b.String()
+}
+
+// CountryForTimezone returns the ISO 3166 country code of a timezone from the
+// tzdata zone.tab, empty when not found (e.g. "UTC").
+func CountryForTimezone(zoneTab, timezone string) string {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" {
+		return ""
+	}
+	for _, line := range strings.Split(zoneTab, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[2] == timezone {
+			return fields[0]
+		}
+	}
+	return ""
+}
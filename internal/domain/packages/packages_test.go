@@ -1,7 +1,11 @@
 package packages
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Kernel Tests
@@ -216,3 +220,334 @@ func TestRepository_Equals(t *testing.T) {
 		t.Error("expected nil to not be equal")
 	}
 }
+
+// KernelSet Tests
+
+func TestNewKernelSet(t *testing.T) {
+	set, err := NewKernelSet(KernelZen, KernelLTS, KernelZen, KernelLTS)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	names := set.PackageNames()
+	if len(names) != 2 || names[0] != "linux-zen" || names[1] != "linux-lts" {
+		t.Errorf("expected [linux-zen linux-lts], got %v", names)
+	}
+
+	if set.Primary().Variant() != KernelZen {
+		t.Errorf("expected primary linux-zen, got %s", set.Primary().PackageName())
+	}
+
+	if !set.HasFallback() {
+		t.Error("expected fallback kernel")
+	}
+
+	if !set.Contains(KernelLTS) || set.Contains(KernelCachyOS) {
+		t.Error("unexpected Contains result")
+	}
+}
+
+func TestNewKernelSet_PrimaryOnly(t *testing.T) {
+	set, err := NewKernelSet(KernelStable)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if set.HasFallback() || len(set.Extras()) != 0 {
+		t.Error("expected no extra kernels")
+	}
+}
+
+func TestNewKernelSet_Invalid(t *testing.T) {
+	if _, err := NewKernelSet(KernelVariant(99)); err == nil {
+		t.Error("expected error for invalid primary kernel")
+	}
+
+	if _, err := NewKernelSet(KernelStable, KernelVariant(99)); err == nil {
+		t.Error("expected error for invalid extra kernel")
+	}
+}
+
+func TestKernel_HeadersPackage(t *testing.T) {
+	tests := map[KernelVariant]string{
+		KernelStable:   "linux-headers",
+		KernelZen:      "linux-zen-headers",
+		KernelLTS:      "linux-lts-headers",
+		KernelHardened: "linux-hardened-headers",
+		KernelCachyOS:  "linux-cachyos-headers",
+	}
+
+	for variant, expected := range tests {
+		kernel, _ := NewKernel(variant)
+		if kernel.HeadersPackage() != expected {
+			t.Errorf("expected %s, got %s", expected, kernel.HeadersPackage())
+		}
+	}
+}
+
+func TestRequiresDKMS(t *testing.T) {
+	tests := []struct {
+		pkgs     []string
+		expected bool
+	}{
+		{[]string{"git", "broadcom-wl-dkms"}, true},
+		{[]string{"dkms"}, true},
+		{[]string{"git", "nvidia-open", "dkms-tools"}, false},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := RequiresDKMS(tt.pkgs); got != tt.expected {
+			t.Errorf("RequiresDKMS(%v): expected %v, got %v", tt.pkgs, tt.expected, got)
+		}
+	}
+}
+
+func TestKernelSet_WithHeaders(t *testing.T) {
+	set, err := NewKernelSet(KernelCachyOS, KernelLTS)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := set.WithHeaders([]string{"git"}); len(got) != 1 {
+		t.Errorf("expected no headers without DKMS packages, got %v", got)
+	}
+
+	got := set.WithHeaders([]string{"broadcom-wl-dkms", "linux-lts-headers"})
+	expected := []string{"broadcom-wl-dkms", "linux-lts-headers", "linux-cachyos-headers"}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// Mirror Tests
+
+func TestParseMirrorStatus(t *testing.T) {
+	status := `{"urls": [
+		{"url": "https://slow.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 3.2},
+		{"url": "https://fast.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 0.8},
+		{"url": "https://stale.example.fr/arch/", "protocol": "https", "country_code": "FR", "active": true, "completion_pct": 0.6, "score": 9.0},
+		{"url": "rsync://rsync.example.fr/arch/", "protocol": "rsync", "country_code": "FR", "active": true, "completion_pct": 1.0, "score": 1.0},
+		{"url": "https://mirror.example.de/arch", "protocol": "https", "country_code": "DE", "active": true, "completion_pct": 1.0, "score": 1.5},
+		{"url": "https://new.example.de/arch/", "protocol": "https", "country_code": "DE", "active": true, "completion_pct": 1.0, "score": null}
+	]}`
+
+	mirrors, err := ParseMirrorStatus([]byte(status))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	var urls []string
+	for _, mirror := range mirrors {
+		urls = append(urls, mirror.URL())
+	}
+	expected := "https://fast.example.fr/arch/ https://mirror.example.de/arch/ https://slow.example.fr/arch/"
+	if strings.Join(urls, " ") != expected {
+		t.Errorf("expected %s, got %v", expected, urls)
+	}
+
+	if fr := MirrorsInCountry(mirrors, "fr"); len(fr) != 2 {
+		t.Errorf("expected the 2 French mirrors, got %d", len(fr))
+	}
+	if all := MirrorsInCountry(mirrors, "NZ"); len(all) != 3 {
+		t.Errorf("expected every mirror for a country without mirrors, got %d", len(all))
+	}
+}
+
+func TestRankMirrors(t *testing.T) {
+	a, _ := NewMirror("https://a.example/", "FR", 1)
+	b, _ := NewMirror("https://b.example/", "FR", 2)
+	c, _ := NewMirror("https://c.example/", "FR", 3)
+
+	ranked := RankMirrors([]MirrorProbe{
+		{Mirror: a, Bytes: 100000, Duration: 2 * time.Second},
+		{Mirror: b, Bytes: 100000, Duration: 500 * time.Millisecond},
+		{Mirror: c, Err: errors.New("timeout")},
+	}, 5)
+	if len(ranked) != 2 || ranked[0] != b || ranked[1] != a {
+		t.Errorf("expected b then a, got %v", ranked)
+	}
+	if got := RankMirrors([]MirrorProbe{{Mirror: a, Bytes: 1, Duration: time.Second}, {Mirror: b, Bytes: 2, Duration: time.Second}}, 1); len(got) != 1 {
+		t.Errorf("expected the count to be applied, got %d mirrors", len(got))
+	}
+	if probe := a.ProbeURL("x86_64"); probe != "https://a.example/core/os/x86_64/core.db" {
+		t.Errorf("unexpected probe URL %s", probe)
+	}
+}
+
+func TestMirrorlist(t *testing.T) {
+	servers := []string{MirrorServer("http://mirror.lan/archlinux/"), MirrorServer("https://cache.lan/$repo/os/$arch")}
+	list := Mirrorlist(servers, "Internal mirrors")
+	for _, want := range []string{
+		"Server = http://mirror.lan/archlinux/$repo/os/$arch\n",
+		"Server = https://cache.lan/$repo/os/$arch\n",
+		"## Internal mirrors\n",
+	} {
+		if !strings.Contains(list, want) {
+			t.Errorf("expected %q in mirrorlist:\n%s", want, list)
+		}
+	}
+}
+
+func TestCountryForTimezone(t *testing.T) {
+	zoneTab := "# tzdata\nFR\t+4852+00220\tEurope/Paris\nDE\t+5230+01322\tEurope/Berlin\tmost of Germany\n"
+	tests := map[string]string{"Europe/Paris": "FR", "Europe/Berlin": "DE", "UTC": "", "": ""}
+	for timezone, expected := range tests {
+		if got := CountryForTimezone(zoneTab, timezone); got != expected {
+			t.Errorf("CountryForTimezone(%q): expected %q, got %q", timezone, expected, got)
+		}
+	}
+}
+
+// Pacman cache Tests
+
+func TestPacmanConfWithCache(t *testing.T) {
+	cache, err := NewPacmanCache("http://cache.lan:9129/repo/", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	conf := "[options]\nParallelDownloads = 5\n\n[core]\nInclude = /etc/pacman.d/mirrorlist\n\n#[multilib]\n#Include = /etc/pacman.d/mirrorlist\n\n[chaotic-aur]\nInclude = /etc/pacman.d/chaotic-mirrorlist\n"
+	cached := PacmanConfWithCache(conf, cache)
+
+	for _, want := range []string{
+		"[core]\n# archup pacman cache\nServer = http://cache.lan:9129/repo/archlinux/$repo/os/$arch\nInclude",
+		"[chaotic-aur]\n# archup pacman cache\nServer = http://cache.lan:9129/repo/chaotic-aur/$repo/$arch\nInclude",
+	} {
+		if !strings.Contains(cached, want) {
+			t.Errorf("expected %q in:\n%s", want, cached)
+		}
+	}
+	if strings.Count(cached, "Server =") != 2 {
+		t.Errorf("expected disabled and non-repository sections to be skipped:\n%s", cached)
+	}
+	if again := PacmanConfWithCache(cached, cache); again != cached {
+		t.Errorf("expected applying the cache twice to be stable:\n%s", again)
+	}
+	if restored := PacmanConfWithoutCache(cached); restored != conf {
+		t.Errorf("expected the original configuration back, got:\n%s", restored)
+	}
+}
+
+func TestPacmanCache_Server(t *testing.T) {
+	custom, _ := NewPacmanCache("http://cache.lan/$repo/$arch", true)
+	if got := custom.Server("cachyos"); got != "http://cache.lan/$repo/$arch" {
+		t.Errorf("expected the custom server for every repository, got %s", got)
+	}
+	proxy, _ := NewPacmanCache("http://cache.lan", true)
+	if got := proxy.Server("cachyos"); got != "http://cache.lan/cachyos/$arch/$repo" {
+		t.Errorf("unexpected CachyOS server %s", got)
+	}
+	if _, err := NewPacmanCache("cache.lan", false); err == nil {
+		t.Error("expected an error without a scheme")
+	}
+	if got := PacmanCacheURL("192.168.1.20", 9129, "repo/"); got != "http://192.168.1.20:9129/repo" {
+		t.Errorf("unexpected discovered URL %s", got)
+	}
+}
+
+// Package profile Tests
+
+const testProfiles = `# profiles
+[essentials]
+always = yes
+git
+
+[server]
+description = Headless
+conflicts = desktop
+git
+tmux
+
+[desktop]
+description = Desktop
+default = yes
+requires = fonts
+gtk4
+
+[fonts]
+noto-fonts
+`
+
+func TestProfileCatalog_Resolve(t *testing.T) {
+	catalog, err := ParseProfileCatalog(testProfiles)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if names := catalog.DefaultSelection(); !reflect.DeepEqual(names, []string{"desktop"}) {
+		t.Errorf("unexpected default selection %v", names)
+	}
+	if len(catalog.Selectable()) != 3 {
+		t.Errorf("expected the always installed profile to be hidden, got %d profiles", len(catalog.Selectable()))
+	}
+
+	selection, err := catalog.Resolve([]string{"desktop"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(selection.Profiles(), []string{"essentials", "desktop", "fonts"}) {
+		t.Errorf("expected the required and always installed profiles, got %v", selection.Profiles())
+	}
+
+	server, err := catalog.Resolve([]string{"server", "server"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(server.Packages(), []string{"git", "tmux"}) {
+		t.Errorf("expected duplicate packages once, got %v", server.Packages())
+	}
+
+	if _, err := catalog.Resolve([]string{"server", "desktop"}); err == nil {
+		t.Error("expected an error for conflicting profiles")
+	}
+	if _, err := catalog.Resolve([]string{"gaming"}); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+func TestParseProfileCatalog_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"package outside a profile": "git\n[base]\n",
+		"duplicate profile":         "[base]\n[base]\n",
+		"unknown option":            "[base]\npriority = 1\n",
+		"unknown reference":         "[base]\nrequires = fonts\n",
+	} {
+		if _, err := ParseProfileCatalog(content); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// Package search Tests
+
+func TestParseSearchOutput(t *testing.T) {
+	output := "extra/neovim 0.11.4-1 [installed]\n" +
+		"    Fork of Vim aiming to improve user experience\n" +
+		"extra/qt6-base 6.9.2-1 (qt6)\n" +
+		"    A cross-platform application and UI framework\n" +
+		"    (core)\n"
+
+	results := ParseSearchOutput(output)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	want := SearchResult{Repository: "extra", Name: "neovim", Version: "0.11.4-1",
+		Description: "Fork of Vim aiming to improve user experience", Installed: true}
+	if results[0] != want {
+		t.Errorf("unexpected first result %+v", results[0])
+	}
+	if results[1].Installed || results[1].Description != "A cross-platform application and UI framework (core)" {
+		t.Errorf("unexpected second result %+v", results[1])
+	}
+}
+
+func TestValidatePackageNames(t *testing.T) {
+	if err := ValidatePackageNames([]string{"neovim", "ttf-jetbrains-mono-nerd", "libc++", "python3.12"}); err != nil {
+		t.Errorf("expected valid names, got %v", err)
+	}
+	for _, name := range []string{"--overwrite", "../pkg", "Vim", ""} {
+		if err := ValidatePackageNames([]string{name}); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+	}
+}
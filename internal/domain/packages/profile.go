@@ -0,0 +1,249 @@
+package packages
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PackageProfile is an immutable value object for a named set of packages
+// installed after the AUR helper, e.g. "desktop-wayland" or "server".
+type PackageProfile struct {
+	name        string
+	description string
+	packages    []string
+	requires    []string // Profiles installed along with this one
+	conflicts   []string // Profiles that cannot be installed with this one
+	isDefault   bool     // Selected when no selection is given
+	always      bool     // Installed whatever the selection
+}
+
+// Name returns the profile name.
+func (p *PackageProfile) Name() string { return p.name }
+
+// Description returns the profile description.
+func (p *PackageProfile) Description() string { return p.description }
+
+// Packages returns the packages of the profile, in file order.
+func (p *PackageProfile) Packages() []string { return append([]string{}, p.packages...) }
+
+// Requires returns the profiles installed along with this one.
+func (p *PackageProfile) Requires() []string { return append([]string{}, p.requires...) }
+
+// Conflicts returns the profiles that cannot be installed with this one.
+func (p *PackageProfile) Conflicts() []string { return append([]string{}, p.conflicts...) }
+
+// Default returns true if the profile is selected when no selection is given.
+func (p *PackageProfile) Default() bool { return p.isDefault }
+
+// Always returns true if the profile is installed whatever the selection.
+func (p *PackageProfile) Always() bool { return p.always }
+
+// ProfileCatalog is the set of package profiles of profiles.packages.
+type ProfileCatalog struct {
+	profiles []*PackageProfile
+}
+
+// ParseProfileCatalog parses a profiles file: each profile starts with a
+// [name] header followed by "key = value" options (description, default,
+// always, requires, conflicts) and one package per line. Comments start
+// with #.
+func ParseProfileCatalog(content string) (*ProfileCatalog, error) {
+	catalog := &ProfileCatalog{}
+	var current *PackageProfile
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(strings.Trim(line, "[]"))
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty profile name", i+1)
+			}
+			if _, ok := catalog.Profile(name); ok {
+				return nil, fmt.Errorf("line %d: duplicate profile %q", i+1, name)
+			}
+			current = &PackageProfile{name: name}
+			catalog.profiles = append(catalog.profiles, current)
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: %q is outside of a profile", i+1, line)
+		}
+
+		key, value, isOption := strings.Cut(line, "=")
+		if !isOption {
+			current.packages = append(current.packages, line)
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "description":
+			current.description = value
+		case "default":
+			current.isDefault = isYes(value)
+		case "always":
+			current.always = isYes(value)
+		case "requires":
+			current.requires = append(current.requires, strings.Fields(value)...)
+		case "conflicts":
+			current.conflicts = append(current.conflicts, strings.Fields(value)...)
+		default:
+			return nil, fmt.Errorf("line %d: unknown profile option %q", i+1, strings.TrimSpace(key))
+		}
+	}
+
+	// References must name profiles of the catalog
+	for _, profile := range catalog.profiles {
+		for _, name := range append(profile.Requires(), profile.conflicts...) {
+			if _, ok := catalog.Profile(name); !ok {
+				return nil, fmt.Errorf("profile %q references unknown profile %q", profile.name, name)
+			}
+		}
+	}
+	return catalog, nil
+}
+
+func isYes(value string) bool {
+	switch strings.ToLower(value) {
+	case "yes", "true", "1":
+		return true
+	}
+	return false
+}
+
+// Profiles returns every profile, in file order.
+func (c *ProfileCatalog) Profiles() []*PackageProfile {
+	return append([]*PackageProfile{}, c.profiles...)
+}
+
+// Selectable returns the profiles offered for selection, those not installed
+// whatever the selection.
+func (c *ProfileCatalog) Selectable() []*PackageProfile {
+	var profiles []*PackageProfile
+	for _, profile := range c.profiles {
+		if !profile.always {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// Profile returns the profile with the given name.
+func (c *ProfileCatalog) Profile(name string) (*PackageProfile, bool) {
+	for _, profile := range c.profiles {
+		if profile.name == name {
+			return profile, true
+		}
+	}
+	return nil, false
+}
+
+// DefaultSelection returns the names of the default profiles.
+func (c *ProfileCatalog) DefaultSelection() []string {
+	var names []string
+	for _, profile := range c.profiles {
+		if profile.isDefault && !profile.always {
+			names = append(names, profile.name)
+		}
+	}
+	return names
+}
+
+// AllPackages returns the packages of every profile without duplicates.
+func (c *ProfileCatalog) AllPackages() []string {
+	return uniquePackages(c.profiles)
+}
+
+// ProfileSelection is the outcome of resolving selected profiles.
+type ProfileSelection struct {
+	profiles []string
+	packages []string
+}
+
+// Profiles returns the installed profiles, required ones included, in file order.
+func (s *ProfileSelection) Profiles() []string { return append([]string{}, s.profiles...) }
+
+// Packages returns the packages to install without duplicates.
+func (s *ProfileSelection) Packages() []string { return append([]string{}, s.packages...) }
+
+// Resolve returns the profiles and packages installed for the selected
+// profile names: the always installed profiles and the required profiles
+// are added, conflicting profiles are rejected and packages shared by
+// several profiles are installed once.
+func (c *ProfileCatalog) Resolve(names []string) (*ProfileSelection, error) {
+	selected := map[string]bool{}
+	var add func(name, by string) error
+	add = func(name, by string) error {
+		if selected[name] {
+			return nil
+		}
+		profile, ok := c.Profile(name)
+		if !ok {
+			if by != "" {
+				return fmt.Errorf("profile %q requires unknown profile %q", by, name)
+			}
+			return fmt.Errorf("unknown package profile %q", name)
+		}
+		selected[name] = true
+		for _, required := range profile.requires {
+			if err := add(required, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, profile := range c.profiles {
+		if profile.always {
+			if err := add(profile.name, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range names {
+		if err := add(strings.TrimSpace(name), ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var profiles []*PackageProfile
+	for _, profile := range c.profiles {
+		if selected[profile.name] {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	for _, profile := range profiles {
+		for _, other := range profiles {
+			if slices.Contains(profile.conflicts, other.name) {
+				return nil, fmt.Errorf("package profiles %q and %q conflict", profile.name, other.name)
+			}
+		}
+	}
+
+	selection := &ProfileSelection{packages: uniquePackages(profiles)}
+	for _, profile := range profiles {
+		selection.profiles = append(selection.profiles, profile.name)
+	}
+	return selection, nil
+}
+
+// uniquePackages returns the packages of profiles in order, each once
+func uniquePackages(profiles []*PackageProfile) []string {
+	seen := map[string]bool{}
+	var pkgs []string
+	for _, profile := range profiles {
+		for _, pkg := range profile.packages {
+			if !seen[pkg] {
+				seen[pkg] = true
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+	return pkgs
+}
@@ -23,6 +23,19 @@ func (a AURHelper) String() string {
 	}
 }
 
+// InstallArgs returns the arguments installing AUR packages without any
+// prompt, PKGBUILD review and diff menus included.
+func (a AURHelper) InstallArgs(pkgs []string) []string {
+	args := []string{"-S", "--noconfirm", "--needed"}
+	switch a {
+	case AURHelperYay:
+		args = append(args, "--answerclean", "None", "--answerdiff", "None")
+	default:
+		args = append(args, "--skipreview")
+	}
+	return append(args, pkgs...)
+}
+
 // Repository is an immutable value object for repository configuration.
 // Chaotic-AUR is always enabled.
 type Repository struct {
@@ -0,0 +1,61 @@
+package packages
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SearchResult is a package of the sync databases matching a search.
+type SearchResult struct {
+	Repository  string
+	Name        string
+	Version     string
+	Description string
+	Installed   bool
+}
+
+// ParseSearchOutput parses the output of `pacman -Ss`: a "repo/name version
+// [groups] [installed]" line per package followed by its indented description.
+func ParseSearchOutput(output string) []SearchResult {
+	var results []SearchResult
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if len(results) > 0 {
+				last := &results[len(results)-1]
+				last.Description = strings.TrimSpace(last.Description + " " + strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		repo, name, ok := strings.Cut(fields[0], "/")
+		if !ok || name == "" {
+			continue
+		}
+		result := SearchResult{Repository: repo, Name: name}
+		if len(fields) > 1 {
+			result.Version = fields[1]
+		}
+		result.Installed = strings.Contains(line, "[installed")
+		results = append(results, result)
+	}
+	return results
+}
+
+// packageNamePattern is the set of names makepkg accepts
+var packageNamePattern = regexp.MustCompile(`^[a-z0-9@_+][a-z0-9@._+-]*$`)
+
+// ValidatePackageNames returns an error for the first name that is not a
+// valid package name, e.g. an option or a path.
+func ValidatePackageNames(names []string) error {
+	for _, name := range names {
+		if !packageNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid package name %q", name)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/bnema/archup/internal/domain/ports (interfaces: FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,Logger,InstallationRepository)
+// Source: github.com/bnema/archup/internal/domain/ports (interfaces: FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,ServiceBrowser,AssetSource,Logger,InstallationRepository)
 //
 // Generated by this command:
 //
-//	mockgen -destination=mocks/mock_ports.go -package=mocks . FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,Logger,InstallationRepository
+//	mockgen -destination=mocks/mock_ports.go -package=mocks . FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,ServiceBrowser,AssetSource,Logger,InstallationRepository
 //
 
 // Package mocks is a generated GoMock package.
@@ -11,6 +11,7 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	os "os"
 	reflect "reflect"
 
@@ -100,6 +101,21 @@ func (mr *MockFileSystemMockRecorder) MkdirAll(path, perm any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MkdirAll", reflect.TypeOf((*MockFileSystem)(nil).MkdirAll), path, perm)
 }
 
+// ReadDir mocks base method.
+func (m *MockFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDir", name)
+	ret0, _ := ret[0].([]os.DirEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDir indicates an expected call of ReadDir.
+func (mr *MockFileSystemMockRecorder) ReadDir(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDir", reflect.TypeOf((*MockFileSystem)(nil).ReadDir), name)
+}
+
 // ReadFile mocks base method.
 func (m *MockFileSystem) ReadFile(name string) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -270,44 +286,44 @@ func (mr *MockCommandExecutorMockRecorder) Execute(ctx, command any, args ...any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockCommandExecutor)(nil).Execute), varargs...)
 }
 
-// ExecuteWithStdin mocks base method.
-func (m *MockCommandExecutor) ExecuteWithStdin(ctx context.Context, stdin, command string, args ...string) ([]byte, error) {
+// ExecuteWithEnv mocks base method.
+func (m *MockCommandExecutor) ExecuteWithEnv(ctx context.Context, env map[string]string, command string, args ...string) ([]byte, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, stdin, command}
+	varargs := []any{ctx, env, command}
 	for _, a := range args {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "ExecuteWithStdin", varargs...)
+	ret := m.ctrl.Call(m, "ExecuteWithEnv", varargs...)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ExecuteWithStdin indicates an expected call of ExecuteWithStdin.
-func (mr *MockCommandExecutorMockRecorder) ExecuteWithStdin(ctx, stdin, command any, args ...any) *gomock.Call {
+// ExecuteWithEnv indicates an expected call of ExecuteWithEnv.
+func (mr *MockCommandExecutorMockRecorder) ExecuteWithEnv(ctx, env, command any, args ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, stdin, command}, args...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteWithStdin", reflect.TypeOf((*MockCommandExecutor)(nil).ExecuteWithStdin), varargs...)
+	varargs := append([]any{ctx, env, command}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteWithEnv", reflect.TypeOf((*MockCommandExecutor)(nil).ExecuteWithEnv), varargs...)
 }
 
-// ExecuteWithEnv mocks base method.
-func (m *MockCommandExecutor) ExecuteWithEnv(ctx context.Context, env map[string]string, command string, args ...string) ([]byte, error) {
+// ExecuteWithStdin mocks base method.
+func (m *MockCommandExecutor) ExecuteWithStdin(ctx context.Context, stdin, command string, args ...string) ([]byte, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{ctx, env, command}
+	varargs := []any{ctx, stdin, command}
 	for _, a := range args {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "ExecuteWithEnv", varargs...)
+	ret := m.ctrl.Call(m, "ExecuteWithStdin", varargs...)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ExecuteWithEnv indicates an expected call of ExecuteWithEnv.
-func (mr *MockCommandExecutorMockRecorder) ExecuteWithEnv(ctx, env, command any, args ...any) *gomock.Call {
+// ExecuteWithStdin indicates an expected call of ExecuteWithStdin.
+func (mr *MockCommandExecutorMockRecorder) ExecuteWithStdin(ctx, stdin, command any, args ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{ctx, env, command}, args...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteWithEnv", reflect.TypeOf((*MockCommandExecutor)(nil).ExecuteWithEnv), varargs...)
+	varargs := append([]any{ctx, stdin, command}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteWithStdin", reflect.TypeOf((*MockCommandExecutor)(nil).ExecuteWithStdin), varargs...)
 }
 
 // MockChrootExecutor is a mock of ChrootExecutor interface.
@@ -373,6 +389,25 @@ func (mr *MockChrootExecutorMockRecorder) ExecuteInChroot(ctx, chrootPath, comma
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteInChroot", reflect.TypeOf((*MockChrootExecutor)(nil).ExecuteInChroot), varargs...)
 }
 
+// ExecuteInChrootWithOutput mocks base method.
+func (m *MockChrootExecutor) ExecuteInChrootWithOutput(ctx context.Context, chrootPath string, output io.Writer, command string, args ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, chrootPath, output, command}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteInChrootWithOutput", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteInChrootWithOutput indicates an expected call of ExecuteInChrootWithOutput.
+func (mr *MockChrootExecutorMockRecorder) ExecuteInChrootWithOutput(ctx, chrootPath, output, command any, args ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, chrootPath, output, command}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteInChrootWithOutput", reflect.TypeOf((*MockChrootExecutor)(nil).ExecuteInChrootWithOutput), varargs...)
+}
+
 // ExecuteInChrootWithStdin mocks base method.
 func (m *MockChrootExecutor) ExecuteInChrootWithStdin(ctx context.Context, chrootPath, stdin, command string, args ...string) error {
 	m.ctrl.T.Helper()
@@ -430,6 +465,20 @@ func (mr *MockScriptExecutorMockRecorder) ExecuteScript(ctx, scriptPath, env any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScript", reflect.TypeOf((*MockScriptExecutor)(nil).ExecuteScript), ctx, scriptPath, env)
 }
 
+// ExecuteScriptWithOutput mocks base method.
+func (m *MockScriptExecutor) ExecuteScriptWithOutput(ctx context.Context, scriptPath string, env map[string]string, output io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteScriptWithOutput", ctx, scriptPath, env, output)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteScriptWithOutput indicates an expected call of ExecuteScriptWithOutput.
+func (mr *MockScriptExecutorMockRecorder) ExecuteScriptWithOutput(ctx, scriptPath, env, output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScriptWithOutput", reflect.TypeOf((*MockScriptExecutor)(nil).ExecuteScriptWithOutput), ctx, scriptPath, env, output)
+}
+
 // MockHTTPClient is a mock of HTTPClient interface.
 type MockHTTPClient struct {
 	ctrl     *gomock.Controller
@@ -550,6 +599,98 @@ func (mr *MockResponseMockRecorder) StatusCode() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusCode", reflect.TypeOf((*MockResponse)(nil).StatusCode))
 }
 
+// MockServiceBrowser is a mock of ServiceBrowser interface.
+type MockServiceBrowser struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceBrowserMockRecorder
+	isgomock struct{}
+}
+
+// MockServiceBrowserMockRecorder is the mock recorder for MockServiceBrowser.
+type MockServiceBrowserMockRecorder struct {
+	mock *MockServiceBrowser
+}
+
+// NewMockServiceBrowser creates a new mock instance.
+func NewMockServiceBrowser(ctrl *gomock.Controller) *MockServiceBrowser {
+	mock := &MockServiceBrowser{ctrl: ctrl}
+	mock.recorder = &MockServiceBrowserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceBrowser) EXPECT() *MockServiceBrowserMockRecorder {
+	return m.recorder
+}
+
+// Browse mocks base method.
+func (m *MockServiceBrowser) Browse(ctx context.Context, service string) ([]ports.ServiceInstance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Browse", ctx, service)
+	ret0, _ := ret[0].([]ports.ServiceInstance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Browse indicates an expected call of Browse.
+func (mr *MockServiceBrowserMockRecorder) Browse(ctx, service any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Browse", reflect.TypeOf((*MockServiceBrowser)(nil).Browse), ctx, service)
+}
+
+// MockAssetSource is a mock of AssetSource interface.
+type MockAssetSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssetSourceMockRecorder
+	isgomock struct{}
+}
+
+// MockAssetSourceMockRecorder is the mock recorder for MockAssetSource.
+type MockAssetSourceMockRecorder struct {
+	mock *MockAssetSource
+}
+
+// NewMockAssetSource creates a new mock instance.
+func NewMockAssetSource(ctrl *gomock.Controller) *MockAssetSource {
+	mock := &MockAssetSource{ctrl: ctrl}
+	mock.recorder = &MockAssetSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssetSource) EXPECT() *MockAssetSourceMockRecorder {
+	return m.recorder
+}
+
+// ReadAsset mocks base method.
+func (m *MockAssetSource) ReadAsset(path string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAsset", path)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAsset indicates an expected call of ReadAsset.
+func (mr *MockAssetSourceMockRecorder) ReadAsset(path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAsset", reflect.TypeOf((*MockAssetSource)(nil).ReadAsset), path)
+}
+
+// String mocks base method.
+func (m *MockAssetSource) String() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "String")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// String indicates an expected call of String.
+func (mr *MockAssetSourceMockRecorder) String() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "String", reflect.TypeOf((*MockAssetSource)(nil).String))
+}
+
 // MockLogger is a mock of Logger interface.
 type MockLogger struct {
 	ctrl     *gomock.Controller
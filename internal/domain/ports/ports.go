@@ -1,9 +1,10 @@
-//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -destination=mocks/mock_ports.go -package=mocks . FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,Logger,InstallationRepository
+//go:generate go run go.uber.org/mock/mockgen@v0.6.0 -destination=mocks/mock_ports.go -package=mocks . FileSystem,File,CommandExecutor,ChrootExecutor,ScriptExecutor,HTTPClient,Response,ServiceBrowser,AssetSource,Logger,InstallationRepository
 
 package ports
 
 import (
 	"context"
+	"io"
 	"os"
 )
 
@@ -33,6 +34,9 @@ type FileSystem interface {
 
 	// Exists checks if a file/directory exists
 	Exists(path string) (bool, error)
+
+	// ReadDir lists a directory sorted by file name
+	ReadDir(name string) ([]os.DirEntry, error)
 }
 
 // File is a file handle for reading/writing
@@ -67,6 +71,10 @@ type ChrootExecutor interface {
 	// ExecuteInChrootWithStdin runs a command inside a chroot with stdin
 	ExecuteInChrootWithStdin(ctx context.Context, chrootPath string, stdin string, command string, args ...string) error
 
+	// ExecuteInChrootWithOutput runs a command inside a chroot, streaming its
+	// stdout and stderr to output while it runs
+	ExecuteInChrootWithOutput(ctx context.Context, chrootPath string, output io.Writer, command string, args ...string) error
+
 	// ChrootSystemctl runs systemctl commands in chroot
 	ChrootSystemctl(ctx context.Context, logPath string, chrootPath string, args ...string) error
 }
@@ -75,6 +83,10 @@ type ChrootExecutor interface {
 type ScriptExecutor interface {
 	// ExecuteScript runs a shell script with environment variables
 	ExecuteScript(ctx context.Context, scriptPath string, env map[string]string) error
+
+	// ExecuteScriptWithOutput runs a shell script with environment variables,
+	// streaming its stdout and stderr to output while it runs
+	ExecuteScriptWithOutput(ctx context.Context, scriptPath string, env map[string]string, output io.Writer) error
 }
 
 // HTTPClient is the port for HTTP operations
@@ -98,6 +110,32 @@ type Response interface {
 	Close() error
 }
 
+// ServiceBrowser is the port for DNS-SD service discovery on the local network
+type ServiceBrowser interface {
+	// Browse returns the instances of a service type, e.g. "_http._tcp",
+	// answering before ctx is done
+	Browse(ctx context.Context, service string) ([]ServiceInstance, error)
+}
+
+// ServiceInstance is a service instance found by a ServiceBrowser
+type ServiceInstance struct {
+	Name string            // Instance name, e.g. "pacoloco on cache"
+	Host string            // Address, or host name when no address was announced
+	Port int               // Service port
+	TXT  map[string]string // TXT record key/value pairs, e.g. "path"
+}
+
+// AssetSource is the port for the installer assets: the install/ and
+// assets/plymouth trees of the archup repository
+type AssetSource interface {
+	// ReadAsset returns an asset by its repository-relative slash path,
+	// e.g. "install/base.packages"
+	ReadAsset(path string) ([]byte, error)
+
+	// String describes where the assets come from, for logs
+	String() string
+}
+
 // Logger is the port for logging
 type Logger interface {
 	// Info logs an informational message
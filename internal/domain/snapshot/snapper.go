@@ -0,0 +1,288 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Retention is how many snapshots snapper's cleanup keeps for a config
+type Retention struct {
+	Hourly          int
+	Daily           int
+	Weekly          int
+	Monthly         int
+	Yearly          int
+	Number          int // pre/post pacman snapshots
+	NumberImportant int // pre/post snapshots marked important (kernel updates)
+}
+
+// DefaultRetention keeps one snapshot per timeline period and five pacman transactions
+var DefaultRetention = Retention{
+	Hourly:          1,
+	Daily:           1,
+	Weekly:          1,
+	Monthly:         1,
+	Yearly:          0,
+	Number:          5,
+	NumberImportant: 5,
+}
+
+// retentionKeys maps the answer file option names to retention fields
+var retentionKeys = []string{"hourly", "daily", "weekly", "monthly", "yearly", "number", "important"}
+
+func (r *Retention) field(key string) *int {
+	switch key {
+	case "hourly":
+		return &r.Hourly
+	case "daily":
+		return &r.Daily
+	case "weekly":
+		return &r.Weekly
+	case "monthly":
+		return &r.Monthly
+	case "yearly":
+		return &r.Yearly
+	case "number":
+		return &r.Number
+	case "important":
+		return &r.NumberImportant
+	default:
+		return nil
+	}
+}
+
+// Validate checks that no limit is negative
+func (r Retention) Validate() error {
+	for _, key := range retentionKeys {
+		if *r.field(key) < 0 {
+			return fmt.Errorf("%s limit cannot be negative", key)
+		}
+	}
+	return nil
+}
+
+// snapperConfigNamePattern matches names accepted by snapper -c
+var snapperConfigNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// SnapperConfig is a snapper config covering one subvolume
+type SnapperConfig struct {
+	name      string
+	subvolume string
+	timeline  bool
+	retention Retention
+}
+
+// NewSnapperConfig creates a validated snapper config
+func NewSnapperConfig(name, subvolume string, timeline bool, retention Retention) (*SnapperConfig, error) {
+	if !snapperConfigNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid snapper config name %q", name)
+	}
+	if !path.IsAbs(subvolume) || path.Clean(subvolume) != subvolume {
+		return nil, fmt.Errorf("snapper config %s: subvolume must be a clean absolute path, got %q", name, subvolume)
+	}
+	if err := retention.Validate(); err != nil {
+		return nil, fmt.Errorf("snapper config %s: %w", name, err)
+	}
+
+	return &SnapperConfig{
+		name:      name,
+		subvolume: subvolume,
+		timeline:  timeline,
+		retention: retention,
+	}, nil
+}
+
+// Name returns the config name, e.g. root
+func (c *SnapperConfig) Name() string {
+	return c.name
+}
+
+// Subvolume returns the mount point of the snapshotted subvolume, e.g. /home
+func (c *SnapperConfig) Subvolume() string {
+	return c.subvolume
+}
+
+// Timeline returns true if hourly timeline snapshots are created
+func (c *SnapperConfig) Timeline() bool {
+	return c.timeline
+}
+
+// Retention returns the cleanup limits
+func (c *SnapperConfig) Retention() Retention {
+	return c.retention
+}
+
+// SnapshotsPath returns the path of the .snapshots subvolume snapper stores snapshots in
+func (c *SnapperConfig) SnapshotsPath() string {
+	return path.Join(c.subvolume, SnapshotsDir)
+}
+
+// WithTimeline returns a copy with timeline snapshots enabled or disabled
+func (c *SnapperConfig) WithTimeline(timeline bool) *SnapperConfig {
+	copied := *c
+	copied.timeline = timeline
+	return &copied
+}
+
+// WithRetention returns a copy with the given cleanup limits
+func (c *SnapperConfig) WithRetention(retention Retention) (*SnapperConfig, error) {
+	return NewSnapperConfig(c.name, c.subvolume, c.timeline, retention)
+}
+
+// Render returns the /etc/snapper/configs/<name> file content
+func (c *SnapperConfig) Render() string {
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	values := []struct {
+		key   string
+		value string
+	}{
+		{"SUBVOLUME", c.subvolume},
+		{"FSTYPE", "btrfs"},
+		{"QGROUP", ""},
+		{"SPACE_LIMIT", "0.5"},
+		{"FREE_LIMIT", "0.2"},
+		{"ALLOW_USERS", ""},
+		{"ALLOW_GROUPS", ""},
+		{"SYNC_ACL", "no"},
+		{"BACKGROUND_COMPARISON", "yes"},
+		{"NUMBER_CLEANUP", "yes"},
+		{"NUMBER_MIN_AGE", "1800"},
+		{"NUMBER_LIMIT", strconv.Itoa(c.retention.Number)},
+		{"NUMBER_LIMIT_IMPORTANT", strconv.Itoa(c.retention.NumberImportant)},
+		{"TIMELINE_CREATE", yesNo(c.timeline)},
+		{"TIMELINE_CLEANUP", "yes"},
+		{"TIMELINE_MIN_AGE", "1800"},
+		{"TIMELINE_LIMIT_HOURLY", strconv.Itoa(c.retention.Hourly)},
+		{"TIMELINE_LIMIT_DAILY", strconv.Itoa(c.retention.Daily)},
+		{"TIMELINE_LIMIT_WEEKLY", strconv.Itoa(c.retention.Weekly)},
+		{"TIMELINE_LIMIT_MONTHLY", strconv.Itoa(c.retention.Monthly)},
+		{"TIMELINE_LIMIT_YEARLY", strconv.Itoa(c.retention.Yearly)},
+		{"EMPTY_PRE_POST_CLEANUP", "yes"},
+		{"EMPTY_PRE_POST_MIN_AGE", "1800"},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# snapper config %q, generated by archup\n", c.name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "%s=%q\n", v.key, v.value)
+	}
+	return b.String()
+}
+
+// Spec returns the answer file representation, see ParseSnapperConfigs
+func (c *SnapperConfig) Spec() string {
+	options := []string{"no-timeline"}
+	if c.timeline {
+		options[0] = "timeline"
+	}
+	for _, key := range retentionKeys {
+		options = append(options, fmt.Sprintf("%s=%d", key, *c.retention.field(key)))
+	}
+	return c.name + ":" + c.subvolume + ":" + strings.Join(options, ",")
+}
+
+// String returns human-readable representation
+func (c *SnapperConfig) String() string {
+	return fmt.Sprintf("SnapperConfig(%s=%s, timeline=%v)", c.name, c.subvolume, c.timeline)
+}
+
+// DefaultSnapperConfigs returns the root config with timeline snapshots and
+// the home config without
+func DefaultSnapperConfigs() []*SnapperConfig {
+	root, _ := NewSnapperConfig(RootSnapperConfig, "/", true, DefaultRetention)
+	home, _ := NewSnapperConfig("home", "/home", false, DefaultRetention)
+	return []*SnapperConfig{root, home}
+}
+
+// ParseSnapperConfigs parses the ARCHUP_SNAPPER_CONFIGS answer file value:
+// whitespace separated name:subvolume[:options] entries, options being a
+// comma separated list of timeline, no-timeline and hourly, daily, weekly,
+// monthly, yearly, number or important limits. Unset limits use
+// DefaultRetention, timeline is off unless requested.
+//
+//	root:/:timeline,hourly=5,daily=7 home:/home:number=10
+func ParseSnapperConfigs(spec string) ([]*SnapperConfig, error) {
+	entries := strings.Fields(spec)
+	if len(entries) == 0 {
+		return nil, errors.New("no snapper configs")
+	}
+
+	var configs []*SnapperConfig
+	names := make(map[string]bool)
+	subvolumes := make(map[string]bool)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid snapper config %q, expected name:subvolume[:options]", entry)
+		}
+
+		timeline := false
+		retention := DefaultRetention
+		if len(parts) == 3 && parts[2] != "" {
+			for _, option := range strings.Split(parts[2], ",") {
+				switch option {
+				case "timeline":
+					timeline = true
+					continue
+				case "no-timeline":
+					timeline = false
+					continue
+				}
+
+				key, value, ok := strings.Cut(option, "=")
+				field := retention.field(key)
+				if !ok || field == nil {
+					return nil, fmt.Errorf("snapper config %s: unknown option %q", parts[0], option)
+				}
+				limit, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("snapper config %s: invalid %s limit %q", parts[0], key, value)
+				}
+				*field = limit
+			}
+		}
+
+		config, err := NewSnapperConfig(parts[0], parts[1], timeline, retention)
+		if err != nil {
+			return nil, err
+		}
+		if names[config.name] {
+			return nil, fmt.Errorf("duplicate snapper config %s", config.name)
+		}
+		if subvolumes[config.subvolume] {
+			return nil, fmt.Errorf("subvolume %s is covered by several snapper configs", config.subvolume)
+		}
+		names[config.name] = true
+		subvolumes[config.subvolume] = true
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// SnapperConfigsSpec returns the answer file representation of configs
+func SnapperConfigsSpec(configs []*SnapperConfig) string {
+	specs := make([]string, 0, len(configs))
+	for _, c := range configs {
+		specs = append(specs, c.Spec())
+	}
+	return strings.Join(specs, " ")
+}
+
+// SnapperConfigsFile returns the /etc/conf.d/snapper content listing the configs
+func SnapperConfigsFile(configs []*SnapperConfig) string {
+	names := make([]string, 0, len(configs))
+	for _, c := range configs {
+		names = append(names, c.name)
+	}
+	return fmt.Sprintf("SNAPPER_CONFIGS=%q\n", strings.Join(names, " "))
+}
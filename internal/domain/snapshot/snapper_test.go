@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewSnapperConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		subvolume string
+		retention Retention
+		shouldErr bool
+	}{
+		{"valid root", "root", "/", DefaultRetention, false},
+		{"valid nested", "var-lib", "/var/lib/libvirt", DefaultRetention, false},
+		{"invalid name", "my config", "/", DefaultRetention, true},
+		{"relative subvolume", "home", "home", DefaultRetention, true},
+		{"unclean subvolume", "home", "/home/", DefaultRetention, true},
+		{"negative limit", "root", "/", Retention{Hourly: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSnapperConfig(tt.config, tt.subvolume, false, tt.retention)
+			if (err != nil) != tt.shouldErr {
+				t.Errorf("expected error=%v, got %v", tt.shouldErr, err)
+			}
+		})
+	}
+}
+
+func TestSnapperConfig_Render(t *testing.T) {
+	config, err := NewSnapperConfig("root", "/", true, Retention{Hourly: 5, Daily: 7, Number: 10, NumberImportant: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	rendered := config.Render()
+	for _, line := range []string{
+		`SUBVOLUME="/"`,
+		`FSTYPE="btrfs"`,
+		`TIMELINE_CREATE="yes"`,
+		`TIMELINE_LIMIT_HOURLY="5"`,
+		`TIMELINE_LIMIT_DAILY="7"`,
+		`TIMELINE_LIMIT_YEARLY="0"`,
+		`NUMBER_LIMIT="10"`,
+		`NUMBER_LIMIT_IMPORTANT="3"`,
+	} {
+		if !strings.Contains(rendered, line+"\n") {
+			t.Errorf("expected rendered config to contain %s, got:\n%s", line, rendered)
+		}
+	}
+	if config.SnapshotsPath() != "/.snapshots" {
+		t.Errorf("unexpected snapshots path %s", config.SnapshotsPath())
+	}
+}
+
+func TestParseSnapperConfigs(t *testing.T) {
+	configs, err := ParseSnapperConfigs("root:/:timeline,hourly=5,daily=7 home:/home:number=10")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(configs) != 2 {
+		t.Fatalf("expected 2 configs, got %d", len(configs))
+	}
+
+	root, home := configs[0], configs[1]
+	if root.Name() != "root" || !root.Timeline() || root.Retention().Hourly != 5 || root.Retention().Daily != 7 {
+		t.Errorf("unexpected root config: %s %+v", root, root.Retention())
+	}
+	if root.Retention().Weekly != DefaultRetention.Weekly {
+		t.Errorf("expected unset limits to use defaults, got %+v", root.Retention())
+	}
+	if home.Subvolume() != "/home" || home.Timeline() || home.Retention().Number != 10 {
+		t.Errorf("unexpected home config: %s %+v", home, home.Retention())
+	}
+
+	// Spec round trip
+	reparsed, err := ParseSnapperConfigs(SnapperConfigsSpec(configs))
+	if err != nil {
+		t.Fatalf("expected spec to parse, got %v", err)
+	}
+	for i := range configs {
+		if reparsed[i].Spec() != configs[i].Spec() {
+			t.Errorf("spec round trip mismatch: %s != %s", reparsed[i].Spec(), configs[i].Spec())
+		}
+	}
+}
+
+func TestParseSnapperConfigs_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"empty", "  "},
+		{"missing subvolume", "root"},
+		{"unknown option", "root:/:hourly=1,forever"},
+		{"invalid limit", "root:/:daily=many"},
+		{"negative limit", "root:/:daily=-1"},
+		{"duplicate name", "root:/ root:/home"},
+		{"duplicate subvolume", "root:/ other:/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSnapperConfigs(tt.spec); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSnapperConfigsFile(t *testing.T) {
+	if got := SnapperConfigsFile(DefaultSnapperConfigs()); got != "SNAPPER_CONFIGS=\"root home\"\n" {
+		t.Errorf("unexpected conf.d content %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+package snapshot
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Layout of the snapper root config on an archup install: snapper creates
+// /.snapshots as a subvolume nested in @, so every snapshot lives below @
+const (
+	// RootSubvolume is the subvolume mounted as /
+	RootSubvolume = "@"
+
+	// SnapshotsDir is the snapper snapshot directory inside the root subvolume
+	SnapshotsDir = ".snapshots"
+
+	// RootSnapperConfig is the snapper config covering the root filesystem
+	RootSnapperConfig = "root"
+
+	// BaselineSubvolume is the read-only snapshot of @ taken by the installer
+	// at the end of the installation, outside snapper so cleanup never removes it
+	BaselineSubvolume = "@archup-baseline"
+)
+
+// SnapperListColumns are the columns requested from `snapper --csvout list`
+var SnapperListColumns = []string{"number", "type", "pre-number", "date", "description", "userdata"}
+
+// Type is the snapper snapshot type
+type Type string
+
+const (
+	// TypeSingle is a standalone snapshot (timeline, manual, baseline)
+	TypeSingle Type = "single"
+
+	// TypePre is taken before a pacman transaction
+	TypePre Type = "pre"
+
+	// TypePost is taken after a pacman transaction and references its pre snapshot
+	TypePost Type = "post"
+)
+
+// Snapshot is a snapper snapshot of the root config
+type Snapshot struct {
+	Number      int
+	Type        Type
+	PreNumber   int // pre snapshot of a post snapshot, 0 otherwise
+	Date        string
+	Description string
+	Userdata    string
+}
+
+// Path returns the snapshot path relative to the top-level Btrfs subvolume
+func (s Snapshot) Path() string {
+	return path.Join(RootSubvolume, SnapshotsDir, strconv.Itoa(s.Number), "snapshot")
+}
+
+// Important returns true if snapper marked the snapshot important (kernel updates)
+func (s Snapshot) Important() bool {
+	return strings.Contains(s.Userdata, "important=yes")
+}
+
+// String returns human-readable representation
+func (s Snapshot) String() string {
+	return fmt.Sprintf("#%d %s %s %s", s.Number, s.Type, s.Date, s.Description)
+}
+
+// List is the snapshot list of a snapper config, oldest first
+type List []Snapshot
+
+// Find returns the snapshot with the given number
+func (l List) Find(number int) (Snapshot, bool) {
+	for _, s := range l {
+		if s.Number == number {
+			return s, true
+		}
+	}
+	return Snapshot{}, false
+}
+
+// Counterpart returns the other half of a pre/post pair: the post snapshot of
+// a pre snapshot or the pre snapshot of a post snapshot
+func (l List) Counterpart(s Snapshot) (Snapshot, bool) {
+	switch s.Type {
+	case TypePre:
+		for _, other := range l {
+			if other.Type == TypePost && other.PreNumber == s.Number {
+				return other, true
+			}
+		}
+	case TypePost:
+		return l.Find(s.PreNumber)
+	}
+	return Snapshot{}, false
+}
+
+// TransactionDescription returns the pacman transaction of a pre/post
+// snapshot as "pre description → post description", as written by snap-pac
+func (l List) TransactionDescription(s Snapshot) string {
+	other, ok := l.Counterpart(s)
+	if !ok {
+		return s.Description
+	}
+	pre, post := s, other
+	if s.Type == TypePost {
+		pre, post = other, s
+	}
+	if post.Description == "" || post.Description == pre.Description {
+		return pre.Description
+	}
+	return pre.Description + " → " + post.Description
+}
+
+// ParseSnapperList parses `snapper --csvout list --columns` output with the
+// SnapperListColumns columns. Snapshot 0 (the live system) is skipped.
+func ParseSnapperList(output string) (List, error) {
+	reader := csv.NewReader(strings.NewReader(output))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("empty snapper output")
+		}
+		return nil, fmt.Errorf("invalid snapper output: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range SnapperListColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("snapper output lacks column %q", name)
+		}
+	}
+
+	var list List
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapper output: %w", err)
+		}
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		number, err := strconv.Atoi(field("number"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot number %q", field("number"))
+		}
+		if number == 0 {
+			continue
+		}
+		preNumber := 0
+		if pre := field("pre-number"); pre != "" {
+			if preNumber, err = strconv.Atoi(pre); err != nil {
+				return nil, fmt.Errorf("invalid pre-number %q of snapshot %d", pre, number)
+			}
+		}
+
+		list = append(list, Snapshot{
+			Number:      number,
+			Type:        Type(field("type")),
+			PreNumber:   preNumber,
+			Date:        field("date"),
+			Description: field("description"),
+			Userdata:    field("userdata"),
+		})
+	}
+	return list, nil
+}
+
+// RollbackMode is how a snapshot replaces the current root
+type RollbackMode int
+
+const (
+	// RollbackNewRoot renames @ away and makes a writable copy of the snapshot
+	// the new @, so the existing subvol=@ pins in fstab and limine.conf keep working
+	RollbackNewRoot RollbackMode = iota
+
+	// RollbackSetDefault makes a writable copy of the snapshot the default
+	// subvolume and drops the subvol=@ pins so the default is mounted as /
+	RollbackSetDefault
+)
+
+// String returns the mode name as used on the command line
+func (m RollbackMode) String() string {
+	if m == RollbackSetDefault {
+		return "set-default"
+	}
+	return "new-root"
+}
+
+// ParseRollbackMode parses a mode name
+func ParseRollbackMode(name string) (RollbackMode, error) {
+	switch name {
+	case "", "new-root":
+		return RollbackNewRoot, nil
+	case "set-default":
+		return RollbackSetDefault, nil
+	default:
+		return RollbackNewRoot, fmt.Errorf("unknown rollback mode %q (expected new-root or set-default)", name)
+	}
+}
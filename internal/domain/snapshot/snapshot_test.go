@@ -0,0 +1,97 @@
+package snapshot
+
+import "testing"
+
+const snapperCSV = `number,type,pre-number,date,description,userdata
+0,single,,,current,
+1,single,,2026-10-01 10:00:00,archup baseline,
+2,pre,,2026-10-02 09:12:00,pacman -Syu,important=yes
+3,post,2,2026-10-02 09:14:00,linux mesa,important=yes
+4,single,,2026-10-02 10:00:00,timeline,
+5,pre,,2026-10-03 18:00:00,"pacman -S foo, bar",
+`
+
+func TestParseSnapperList(t *testing.T) {
+	list, err := ParseSnapperList(snapperCSV)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(list) != 5 {
+		t.Fatalf("expected 5 snapshots without the current system, got %d", len(list))
+	}
+	post, ok := list.Find(3)
+	if !ok || post.Type != TypePost || post.PreNumber != 2 || !post.Important() {
+		t.Errorf("unexpected post snapshot: %+v", post)
+	}
+	if list[4].Description != "pacman -S foo, bar" {
+		t.Errorf("expected quoted description, got %q", list[4].Description)
+	}
+	if post.Path() != "@/.snapshots/3/snapshot" {
+		t.Errorf("unexpected snapshot path %s", post.Path())
+	}
+}
+
+func TestParseSnapperList_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"empty", ""},
+		{"missing column", "number,type,date\n1,single,2026-10-01\n"},
+		{"invalid number", "number,type,pre-number,date,description,userdata\nx,single,,,,\n"},
+		{"invalid pre-number", "number,type,pre-number,date,description,userdata\n3,post,y,,,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSnapperList(tt.output); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestList_TransactionDescription(t *testing.T) {
+	list, err := ParseSnapperList(snapperCSV)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := []struct {
+		number   int
+		expected string
+	}{
+		{2, "pacman -Syu → linux mesa"},
+		{3, "pacman -Syu → linux mesa"},
+		{4, "timeline"},
+		{5, "pacman -S foo, bar"}, // no post snapshot yet
+	}
+	for _, tt := range tests {
+		s, _ := list.Find(tt.number)
+		if got := list.TransactionDescription(s); got != tt.expected {
+			t.Errorf("snapshot %d: expected %q, got %q", tt.number, tt.expected, got)
+		}
+	}
+}
+
+func TestParseRollbackMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  RollbackMode
+		shouldErr bool
+	}{
+		{"", RollbackNewRoot, false},
+		{"new-root", RollbackNewRoot, false},
+		{"set-default", RollbackSetDefault, false},
+		{"subvolume", RollbackNewRoot, true},
+	}
+	for _, tt := range tests {
+		mode, err := ParseRollbackMode(tt.name)
+		if (err != nil) != tt.shouldErr {
+			t.Errorf("%q: expected error=%v, got %v", tt.name, tt.shouldErr, err)
+		}
+		if err == nil && (mode != tt.expected || (tt.name != "" && mode.String() != tt.name)) {
+			t.Errorf("%q: unexpected mode %s", tt.name, mode)
+		}
+	}
+}
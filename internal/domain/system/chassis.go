@@ -0,0 +1,193 @@
+package system
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// Laptop configuration files written to the target
+const (
+	LaptopLogindFile = "/etc/systemd/logind.conf.d/10-archup-laptop.conf"
+	LaptopSleepFile  = "/etc/systemd/sleep.conf.d/10-archup-laptop.conf"
+)
+
+// LaptopSleepConf delays hibernation after suspend, so a closed laptop
+// survives an empty battery
+const LaptopSleepConf = "[Sleep]\nHibernateDelaySec=90min\n"
+
+// Sysfs and procfs paths chassis detection reads
+const (
+	dmiChassisTypePath = "/sys/class/dmi/id/chassis_type"
+	powerSupplyDir     = "/sys/class/power_supply"
+	acpiLidDir         = "/proc/acpi/button/lid"
+)
+
+// laptopChassisTypes are the SMBIOS chassis types of portable machines:
+// portable, laptop, notebook, hand held, sub notebook, tablet, convertible, detachable
+var laptopChassisTypes = map[int]bool{8: true, 9: true, 10: true, 11: true, 14: true, 30: true, 31: true, 32: true}
+
+// Chassis is an immutable value object describing the machine form factor
+// and power source.
+type Chassis struct {
+	dmiType   int      // SMBIOS chassis type, 0 if unknown
+	batteries []string // power_supply names of system batteries
+	lid       bool
+	onBattery bool
+}
+
+// NewChassis creates a chassis from the DMI chassis type, the system
+// batteries, ACPI lid presence and whether a battery is discharging.
+func NewChassis(dmiType int, batteries []string, lid, onBattery bool) *Chassis {
+	return &Chassis{
+		dmiType:   dmiType,
+		batteries: append([]string{}, batteries...),
+		lid:       lid,
+		onBattery: onBattery,
+	}
+}
+
+// DMIType returns the SMBIOS chassis type, 0 if unknown.
+func (c *Chassis) DMIType() int { return c.dmiType }
+
+// Batteries returns the power_supply names of the system batteries.
+func (c *Chassis) Batteries() []string { return append([]string{}, c.batteries...) }
+
+// HasLid returns true if ACPI reports a lid switch.
+func (c *Chassis) HasLid() bool { return c.lid }
+
+// OnBattery returns true if the machine currently runs on battery power.
+func (c *Chassis) OnBattery() bool { return c.onBattery }
+
+// IsLaptop returns true if any of the DMI chassis type, a system battery or
+// a lid switch says the machine is portable.
+func (c *Chassis) IsLaptop() bool {
+	return c.Reason() != ""
+}
+
+// Reason returns why the machine is considered a laptop, empty otherwise.
+func (c *Chassis) Reason() string {
+	switch {
+	case laptopChassisTypes[c.dmiType]:
+		return "DMI chassis type " + strconv.Itoa(c.dmiType)
+	case c.lid:
+		return "ACPI lid switch"
+	case len(c.batteries) > 0:
+		return "battery " + strings.Join(c.batteries, ", ")
+	default:
+		return ""
+	}
+}
+
+// DetectChassis reads the DMI chassis type, the power supplies and the ACPI
+// lid of the running system. Missing files are not errors, virtual machines
+// and containers lack most of them.
+func DetectChassis(ctx context.Context, fs ports.FileSystem) (*Chassis, error) {
+	dmiType := 0
+	if content, err := fs.ReadFile(dmiChassisTypePath); err == nil {
+		dmiType, _ = strconv.Atoi(strings.TrimSpace(string(content)))
+	}
+
+	var batteries []string
+	onBattery := false
+	if entries, err := fs.ReadDir(powerSupplyDir); err == nil {
+		for _, entry := range entries {
+			dir := filepath.Join(powerSupplyDir, entry.Name())
+			if readSysfsValue(fs, filepath.Join(dir, "type")) != "Battery" {
+				continue
+			}
+			// Mice, keyboards and headsets report scope Device
+			if readSysfsValue(fs, filepath.Join(dir, "scope")) == "Device" {
+				continue
+			}
+			batteries = append(batteries, entry.Name())
+			if readSysfsValue(fs, filepath.Join(dir, "status")) == "Discharging" {
+				onBattery = true
+			}
+		}
+	}
+
+	lid := false
+	if entries, err := fs.ReadDir(acpiLidDir); err == nil && len(entries) > 0 {
+		lid = true
+	}
+
+	return NewChassis(dmiType, batteries, lid, onBattery), nil
+}
+
+func readSysfsValue(fs ports.FileSystem, path string) string {
+	content, err := fs.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// LaptopPackages returns the packages of the laptop profile: firmware
+// updates, backlight control and, on Intel, the thermal daemon.
+func LaptopPackages(vendor CPUVendor) []string {
+	pkgs := []string{"fwupd", "brightnessctl"}
+	if vendor == CPUVendorIntel {
+		pkgs = append(pkgs, "thermald")
+	}
+	return pkgs
+}
+
+// LaptopServices returns the systemd units the laptop profile enables.
+func LaptopServices(vendor CPUVendor) []string {
+	units := []string{"fwupd-refresh.timer"}
+	if vendor == CPUVendorIntel {
+		units = append(units, "thermald.service")
+	}
+	return units
+}
+
+// LaptopLogindConf returns the logind drop-in suspending on lid close, or
+// suspending then hibernating on battery when hibernation is set up. Lid
+// close is ignored while docked to an external display.
+func LaptopLogindConf(suspendThenHibernate bool) string {
+	action := "suspend"
+	if suspendThenHibernate {
+		action = "suspend-then-hibernate"
+	}
+	return "[Login]\n" +
+		"HandleLidSwitch=" + action + "\n" +
+		"HandleLidSwitchExternalPower=suspend\n" +
+		"HandleLidSwitchDocked=ignore\n"
+}
+
+// FstabSwap returns the disk swap of an fstab, a device spec (e.g.
+// "UUID=...") or a swap file path, empty when there is none. zram devices
+// cannot hold a hibernation image and are skipped.
+func FstabSwap(fstab string) string {
+	for _, line := range strings.Split(fstab, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") || fields[2] != "swap" {
+			continue
+		}
+		if strings.HasPrefix(fields[0], "/dev/zram") {
+			continue
+		}
+		return fields[0]
+	}
+	return ""
+}
+
+// IsSwapFile returns true if a swap spec from FstabSwap is a file rather than a device.
+func IsSwapFile(spec string) bool {
+	return strings.HasPrefix(spec, "/") && !strings.HasPrefix(spec, "/dev/")
+}
+
+// FstabRoot returns the device spec mounted on / in an fstab, empty when missing.
+func FstabRoot(fstab string) string {
+	for _, line := range strings.Split(fstab, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") && fields[1] == "/" {
+			return fields[0]
+		}
+	}
+	return ""
+}
@@ -32,6 +32,9 @@ type GPU struct {
 	model   string
 	drivers []string
 	envVars map[string]string
+	slot    string
+	bootVGA bool
+	reason  string
 }
 
 // NewGPU creates a new GPU value object.
@@ -79,3 +82,34 @@ func (g *GPU) EnvVars() map[string]string {
 	}
 	return copyVars
 }
+
+// WithPCI returns a copy of the GPU located at a PCI slot (e.g. "0000:01:00.0").
+// bootVGA marks the GPU the firmware initialized the display on.
+func (g *GPU) WithPCI(slot string, bootVGA bool) *GPU {
+	copyGPU := *g
+	copyGPU.slot = slot
+	copyGPU.bootVGA = bootVGA
+	return &copyGPU
+}
+
+// Slot returns the PCI slot of the GPU, empty when unknown.
+func (g *GPU) Slot() string {
+	return g.slot
+}
+
+// BootVGA returns true if the firmware initialized the display on this GPU.
+func (g *GPU) BootVGA() bool {
+	return g.bootVGA
+}
+
+// WithDriverReason returns a copy of the GPU explaining its driver choice.
+func (g *GPU) WithDriverReason(reason string) *GPU {
+	copyGPU := *g
+	copyGPU.reason = reason
+	return &copyGPU
+}
+
+// DriverReason returns why the drivers were chosen, empty for the vendor defaults.
+func (g *GPU) DriverReason() string {
+	return g.reason
+}
@@ -0,0 +1,96 @@
+package system
+
+import (
+	"sort"
+	"strings"
+)
+
+// GPUEnvironmentFile is the environment.d file holding the GPU environment variables
+const GPUEnvironmentFile = "/etc/environment.d/10-archup-gpu.conf"
+
+// NVIDIA early KMS and modesetting configuration
+const (
+	NVIDIAModprobeFile   = "/etc/modprobe.d/nvidia.conf"
+	NVIDIAModprobeConf   = "options nvidia_drm modeset=1 fbdev=1\n"
+	NVIDIAPacmanHookFile = "/etc/pacman.d/hooks/nvidia.hook"
+)
+
+// NVIDIAKMSModules are loaded from the initramfs for NVIDIA early KMS, in load order
+var NVIDIAKMSModules = []string{"nvidia", "nvidia_modeset", "nvidia_uvm", "nvidia_drm"}
+
+// lib32Drivers maps driver packages to their multilib counterparts, for Steam and Wine
+var lib32Drivers = map[string]string{
+	"mesa":          "lib32-mesa",
+	"vulkan-radeon": "lib32-vulkan-radeon",
+	"vulkan-intel":  "lib32-vulkan-intel",
+	"nvidia-utils":  "lib32-nvidia-utils",
+
+	"nvidia-470xx-utils": "lib32-nvidia-470xx-utils",
+	"nvidia-390xx-utils": "lib32-nvidia-390xx-utils",
+}
+
+// Lib32Drivers returns the multilib counterparts of the driver packages that have one
+func Lib32Drivers(drivers []string) []string {
+	result := []string{}
+	for _, driver := range drivers {
+		if lib32, ok := lib32Drivers[driver]; ok {
+			result = append(result, lib32)
+		}
+	}
+	return result
+}
+
+// EnvironmentFile returns the environment.d content of the GPU environment
+// variables, sorted by name. Empty when the GPU needs none.
+func (g *GPU) EnvironmentFile() string {
+	if len(g.envVars) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(g.envVars))
+	for key := range g.envVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# GPU environment, written by archup\n")
+	for _, key := range keys {
+		b.WriteString(key + "=" + g.envVars[key] + "\n")
+	}
+	return b.String()
+}
+
+// NVIDIADriverPackages returns the kernel module packages among the drivers,
+// the ones the initramfs has to be rebuilt for when they change
+func NVIDIADriverPackages(drivers []string) []string {
+	result := []string{}
+	for _, driver := range drivers {
+		switch {
+		case driver == "nvidia" || driver == "nvidia-open" || driver == "nvidia-lts" || driver == "nvidia-open-lts":
+			result = append(result, driver)
+		case strings.HasPrefix(driver, "nvidia") && strings.HasSuffix(driver, "-dkms"):
+			result = append(result, driver)
+		}
+	}
+	return result
+}
+
+// NVIDIAPacmanHook returns the pacman hook regenerating the initramfs when the
+// NVIDIA kernel module or a kernel is upgraded, so the early KMS modules
+// always match the driver. Kernel upgrades already run mkinitcpio, the hook
+// exits early when a kernel is among the targets.
+func NVIDIAPacmanHook(drivers, kernels []string) string {
+	var b strings.Builder
+	b.WriteString("[Trigger]\nOperation=Install\nOperation=Upgrade\nOperation=Remove\nType=Package\n")
+	for _, target := range append(append([]string{}, drivers...), kernels...) {
+		b.WriteString("Target=" + target + "\n")
+	}
+	b.WriteString("\n[Action]\n")
+	b.WriteString("Description=Updating NVIDIA module in initcpio\n")
+	b.WriteString("Depends=mkinitcpio\n")
+	b.WriteString("When=PostTransaction\n")
+	b.WriteString("NeedsTargets\n")
+	b.WriteString("Exec=/bin/sh -c 'while read -r trg; do case $trg in linux*) exit 0; esac; done; /usr/bin/mkinitcpio -P'\n")
+	return b.String()
+}
@@ -0,0 +1,177 @@
+package system
+
+// NVIDIAPrimePackage provides prime-run, launching an application on the NVIDIA dGPU
+const NVIDIAPrimePackage = "nvidia-prime"
+
+// NVIDIA runtime power management for PRIME render offload: the dGPU powers
+// down when no application renders on it
+const (
+	NVIDIAPowerUdevFile     = "/etc/udev/rules.d/80-nvidia-pm.rules"
+	NVIDIAPowerModprobeConf = "options nvidia NVreg_DynamicPowerManagement=0x02\n"
+	NVIDIAPowerUdevRules    = `# Enable runtime PM for NVIDIA VGA/3D controller devices on driver bind
+ACTION=="bind", SUBSYSTEM=="pci", ATTR{vendor}=="0x10de", ATTR{class}=="0x030000", TEST=="power/control", ATTR{power/control}="auto"
+ACTION=="bind", SUBSYSTEM=="pci", ATTR{vendor}=="0x10de", ATTR{class}=="0x030200", TEST=="power/control", ATTR{power/control}="auto"
+
+# Disable runtime PM for NVIDIA VGA/3D controller devices on driver unbind
+ACTION=="unbind", SUBSYSTEM=="pci", ATTR{vendor}=="0x10de", ATTR{class}=="0x030000", TEST=="power/control", ATTR{power/control}="on"
+ACTION=="unbind", SUBSYSTEM=="pci", ATTR{vendor}=="0x10de", ATTR{class}=="0x030200", TEST=="power/control", ATTR{power/control}="on"
+`
+)
+
+// HybridProfile selects which GPUs of a hybrid (iGPU + dGPU) system are used.
+type HybridProfile string
+
+const (
+	// HybridProfileNone is a single GPU system
+	HybridProfileNone HybridProfile = ""
+
+	// HybridProfileIntegrated only installs the iGPU drivers
+	HybridProfileIntegrated HybridProfile = "igpu"
+
+	// HybridProfileOffload renders on the iGPU and offloads applications to
+	// the dGPU on demand (PRIME render offload)
+	HybridProfileOffload HybridProfile = "offload"
+
+	// HybridProfileDiscrete only installs the dGPU drivers
+	HybridProfileDiscrete HybridProfile = "dgpu"
+)
+
+// HybridProfiles returns the hybrid profiles in display order.
+func HybridProfiles() []HybridProfile {
+	return []HybridProfile{HybridProfileOffload, HybridProfileIntegrated, HybridProfileDiscrete}
+}
+
+// String returns human-readable profile name.
+func (p HybridProfile) String() string {
+	switch p {
+	case HybridProfileIntegrated:
+		return "iGPU only"
+	case HybridProfileOffload:
+		return "PRIME render offload"
+	case HybridProfileDiscrete:
+		return "dGPU only"
+	default:
+		return "Single GPU"
+	}
+}
+
+// GPUSetup holds every GPU of the system.
+type GPUSetup struct {
+	gpus []*GPU
+}
+
+// NewGPUSetup creates a GPU setup from the detected GPUs, in PCI order.
+func NewGPUSetup(gpus ...*GPU) *GPUSetup {
+	setup := &GPUSetup{gpus: []*GPU{}}
+	for _, gpu := range gpus {
+		if gpu != nil {
+			setup.gpus = append(setup.gpus, gpu)
+		}
+	}
+	return setup
+}
+
+// GPUs returns the detected GPUs.
+func (s *GPUSetup) GPUs() []*GPU {
+	return append([]*GPU{}, s.gpus...)
+}
+
+// Primary returns the GPU the firmware initialized the display on, the first
+// GPU when none is flagged, or an unknown GPU when none was detected.
+func (s *GPUSetup) Primary() *GPU {
+	for _, gpu := range s.gpus {
+		if gpu.BootVGA() {
+			return gpu
+		}
+	}
+	if len(s.gpus) > 0 {
+		return s.gpus[0]
+	}
+	return NewGPU(GPUVendorUnknown, "", nil, nil)
+}
+
+// Integrated returns the iGPU of a hybrid system, nil otherwise. The iGPU is
+// the Intel or AMD boot VGA device, or the first Intel GPU when no device is
+// flagged.
+func (s *GPUSetup) Integrated() *GPU {
+	if !s.IsHybrid() {
+		return nil
+	}
+	return s.integratedCandidate()
+}
+
+// Discrete returns the dGPU of a hybrid system, nil otherwise.
+func (s *GPUSetup) Discrete() *GPU {
+	return s.discreteCandidate(s.integratedCandidate())
+}
+
+// IsHybrid returns true if the system has an iGPU and a dGPU.
+func (s *GPUSetup) IsHybrid() bool {
+	return s.Discrete() != nil
+}
+
+// ProfileGPUs returns the GPUs a hybrid profile uses, the one driving the
+// display first. A single GPU system always uses its primary GPU.
+func (s *GPUSetup) ProfileGPUs(profile HybridProfile) []*GPU {
+	if !s.IsHybrid() {
+		return []*GPU{s.Primary()}
+	}
+	switch profile {
+	case HybridProfileIntegrated:
+		return []*GPU{s.Integrated()}
+	case HybridProfileDiscrete:
+		return []*GPU{s.Discrete()}
+	default:
+		return []*GPU{s.Integrated(), s.Discrete()}
+	}
+}
+
+// ProfileDrivers returns the driver packages of a profile, without duplicates.
+// PRIME render offload to an NVIDIA dGPU adds nvidia-prime.
+func (s *GPUSetup) ProfileDrivers(profile HybridProfile) []string {
+	seen := map[string]bool{}
+	drivers := []string{}
+	add := func(pkg string) {
+		if !seen[pkg] {
+			seen[pkg] = true
+			drivers = append(drivers, pkg)
+		}
+	}
+
+	gpus := s.ProfileGPUs(profile)
+	for _, gpu := range gpus {
+		for _, pkg := range gpu.Drivers() {
+			add(pkg)
+		}
+	}
+	if len(gpus) > 1 && gpus[1].Vendor() == GPUVendorNVIDIA {
+		add(NVIDIAPrimePackage)
+	}
+	return drivers
+}
+
+func (s *GPUSetup) integratedCandidate() *GPU {
+	for _, gpu := range s.gpus {
+		if gpu.BootVGA() && (gpu.Vendor() == GPUVendorIntel || gpu.Vendor() == GPUVendorAMD) {
+			return gpu
+		}
+	}
+	for _, gpu := range s.gpus {
+		if gpu.Vendor() == GPUVendorIntel {
+			return gpu
+		}
+	}
+	return nil
+}
+
+func (s *GPUSetup) discreteCandidate(integrated *GPU) *GPU {
+	if integrated == nil {
+		return nil
+	}
+	for _, gpu := range s.gpus {
+		if gpu != integrated && (gpu.Vendor() == GPUVendorNVIDIA || gpu.Vendor() == GPUVendorAMD) {
+			return gpu
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// HardwareInventoryFile is where the hardware inventory is written in the target
+const HardwareInventoryFile = "/var/lib/archup/hardware.json"
+
+// Sysfs and procfs paths the inventory reads
+const (
+	dmiIDDir    = "/sys/class/dmi/id"
+	netClassDir = "/sys/class/net"
+	memInfoPath = "/proc/meminfo"
+)
+
+// InventoryCPU describes the processor of the inventory.
+type InventoryCPU struct {
+	Vendor        string `json:"vendor"`
+	Model         string `json:"model"`
+	ZenGeneration string `json:"zen_generation,omitempty"`
+}
+
+// InventoryGPU describes a graphics card of the inventory.
+type InventoryGPU struct {
+	Vendor  string   `json:"vendor"`
+	Model   string   `json:"model"`
+	Slot    string   `json:"slot,omitempty"`
+	BootVGA bool     `json:"boot_vga"`
+	Drivers []string `json:"drivers,omitempty"`
+}
+
+// InventoryDisk describes a block device of the inventory.
+type InventoryDisk struct {
+	Path      string `json:"path"`
+	Size      string `json:"size,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	Transport string `json:"transport,omitempty"` // nvme, sata, usb...
+}
+
+// InventoryNetworkInterface describes a physical network interface of the inventory.
+type InventoryNetworkInterface struct {
+	Name     string `json:"name"`
+	MAC      string `json:"mac,omitempty"`
+	Driver   string `json:"driver,omitempty"`
+	Wireless bool   `json:"wireless"`
+}
+
+// InventoryMachine describes the machine and its firmware from DMI.
+type InventoryMachine struct {
+	Vendor          string `json:"vendor,omitempty"`
+	Product         string `json:"product,omitempty"`
+	Version         string `json:"version,omitempty"`
+	FirmwareVendor  string `json:"firmware_vendor,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	FirmwareDate    string `json:"firmware_date,omitempty"`
+	UEFI            bool   `json:"uefi"`
+	SecureBoot      bool   `json:"secure_boot"`
+}
+
+// InventoryGPUOf converts a detected GPU to its inventory record.
+func InventoryGPUOf(gpu *GPU) InventoryGPU {
+	return InventoryGPU{
+		Vendor:  gpu.Vendor().String(),
+		Model:   gpu.Model(),
+		Slot:    gpu.Slot(),
+		BootVGA: gpu.BootVGA(),
+		Drivers: gpu.Drivers(),
+	}
+}
+
+// HardwareInventory is an aggregate describing the hardware archup installed
+// on, kept in the target to help triage bug reports.
+type HardwareInventory struct {
+	architecture string
+	machine      InventoryMachine
+	cpu          InventoryCPU
+	memoryKB     uint64
+	gpus         []InventoryGPU
+	disks        []InventoryDisk
+	network      []InventoryNetworkInterface
+}
+
+// NewHardwareInventory creates an inventory from the machine, the processor,
+// the memory size in KiB and the GPUs, disks and network interfaces.
+func NewHardwareInventory(architecture string, machine InventoryMachine, cpu InventoryCPU, memoryKB uint64,
+	gpus []InventoryGPU, disks []InventoryDisk, network []InventoryNetworkInterface) *HardwareInventory {
+	return &HardwareInventory{
+		architecture: architecture,
+		machine:      machine,
+		cpu:          cpu,
+		memoryKB:     memoryKB,
+		gpus:         append([]InventoryGPU{}, gpus...),
+		disks:        append([]InventoryDisk{}, disks...),
+		network:      append([]InventoryNetworkInterface{}, network...),
+	}
+}
+
+// Architecture returns the machine architecture (e.g. "x86_64").
+func (h *HardwareInventory) Architecture() string { return h.architecture }
+
+// Machine returns the DMI machine and firmware information.
+func (h *HardwareInventory) Machine() InventoryMachine { return h.machine }
+
+// CPU returns the processor.
+func (h *HardwareInventory) CPU() InventoryCPU { return h.cpu }
+
+// MemoryKB returns the memory size in KiB, 0 if unknown.
+func (h *HardwareInventory) MemoryKB() uint64 { return h.memoryKB }
+
+// GPUs returns the graphics cards.
+func (h *HardwareInventory) GPUs() []InventoryGPU { return append([]InventoryGPU{}, h.gpus...) }
+
+// Disks returns the block devices.
+func (h *HardwareInventory) Disks() []InventoryDisk { return append([]InventoryDisk{}, h.disks...) }
+
+// NetworkInterfaces returns the physical network interfaces.
+func (h *HardwareInventory) NetworkInterfaces() []InventoryNetworkInterface {
+	return append([]InventoryNetworkInterface{}, h.network...)
+}
+
+// inventoryJSON is the on-disk layout of the inventory
+type inventoryJSON struct {
+	Architecture string                      `json:"architecture,omitempty"`
+	Machine      InventoryMachine            `json:"machine"`
+	CPU          InventoryCPU                `json:"cpu"`
+	MemoryKB     uint64                      `json:"memory_kb"`
+	GPUs         []InventoryGPU              `json:"gpus"`
+	Disks        []InventoryDisk             `json:"disks"`
+	Network      []InventoryNetworkInterface `json:"network"`
+}
+
+// MarshalJSON encodes the inventory.
+func (h *HardwareInventory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(inventoryJSON{
+		Architecture: h.architecture,
+		Machine:      h.machine,
+		CPU:          h.cpu,
+		MemoryKB:     h.memoryKB,
+		GPUs:         h.gpus,
+		Disks:        h.disks,
+		Network:      h.network,
+	})
+}
+
+// Summary returns one human-readable line per component, for display.
+func (h *HardwareInventory) Summary() []string {
+	var lines []string
+
+	if machine := strings.TrimSpace(h.machine.Vendor + " " + h.machine.Product); machine != "" {
+		lines = append(lines, "Machine: "+machine)
+	}
+	if h.machine.FirmwareVersion != "" {
+		firmware := h.machine.FirmwareVersion
+		if h.machine.FirmwareDate != "" {
+			firmware += " (" + h.machine.FirmwareDate + ")"
+		}
+		lines = append(lines, "Firmware: "+firmware)
+	}
+
+	cpu := h.cpu.Model
+	if cpu == "" {
+		cpu = h.cpu.Vendor
+	}
+	if h.cpu.ZenGeneration != "" {
+		cpu += " (" + h.cpu.ZenGeneration + ")"
+	}
+	if cpu != "" {
+		lines = append(lines, "CPU: "+cpu)
+	}
+
+	if h.memoryKB > 0 {
+		lines = append(lines, fmt.Sprintf("Memory: %.1f GiB", float64(h.memoryKB)/(1024*1024)))
+	}
+	for _, gpu := range h.gpus {
+		lines = append(lines, "GPU: "+gpu.Model)
+	}
+	for _, disk := range h.disks {
+		details := strings.TrimSpace(strings.Join([]string{disk.Size, disk.Transport, disk.Model}, " "))
+		lines = append(lines, fmt.Sprintf("Disk: %s %s", disk.Path, details))
+	}
+	for _, nic := range h.network {
+		kind := "ethernet"
+		if nic.Wireless {
+			kind = "wireless"
+		}
+		lines = append(lines, fmt.Sprintf("Network: %s (%s, %s)", nic.Name, kind, nic.Driver))
+	}
+	return lines
+}
+
+// DetectMachine reads the DMI machine and firmware identification of the
+// running system. Missing files are left empty, virtual machines and
+// containers lack some of them.
+func DetectMachine(ctx context.Context, fs ports.FileSystem) InventoryMachine {
+	read := func(name string) string {
+		return readSysfsValue(fs, filepath.Join(dmiIDDir, name))
+	}
+	return InventoryMachine{
+		Vendor:          read("sys_vendor"),
+		Product:         read("product_name"),
+		Version:         read("product_version"),
+		FirmwareVendor:  read("bios_vendor"),
+		FirmwareVersion: read("bios_version"),
+		FirmwareDate:    read("bios_date"),
+	}
+}
+
+// DetectMemoryKB returns the memory size in KiB from /proc/meminfo, 0 if unknown.
+func DetectMemoryKB(ctx context.Context, fs ports.FileSystem) uint64 {
+	content, err := fs.ReadFile(memInfoPath)
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseUint(fields[1], 10, 64)
+			return kb
+		}
+	}
+	return 0
+}
+
+// DetectNetworkInterfaces returns the physical network interfaces of the
+// running system. Interfaces without a backing device (loopback, bridges,
+// tunnels) are skipped.
+func DetectNetworkInterfaces(ctx context.Context, fs ports.FileSystem) []InventoryNetworkInterface {
+	entries, err := fs.ReadDir(netClassDir)
+	if err != nil {
+		return nil
+	}
+
+	var nics []InventoryNetworkInterface
+	for _, entry := range entries {
+		dir := filepath.Join(netClassDir, entry.Name())
+		device, err := fs.ReadFile(filepath.Join(dir, "device", "uevent"))
+		if err != nil {
+			continue
+		}
+		uevent, _ := fs.ReadFile(filepath.Join(dir, "uevent"))
+		nics = append(nics, InventoryNetworkInterface{
+			Name:     entry.Name(),
+			MAC:      readSysfsValue(fs, filepath.Join(dir, "address")),
+			Driver:   ueventValue(string(device), "DRIVER"),
+			Wireless: ueventValue(string(uevent), "DEVTYPE") == "wlan",
+		})
+	}
+	return nics
+}
+
+// ueventValue returns the value of a KEY=value line of a sysfs uevent file
+func ueventValue(uevent, key string) string {
+	for _, line := range strings.Split(uevent, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package system
+
+import (
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+)
+
+// NVIDIAPCIVendorID is the PCI vendor ID of NVIDIA
+const NVIDIAPCIVendorID = "10de"
+
+// NVIDIAGeneration is the architecture of an NVIDIA GPU, deciding its driver branch.
+type NVIDIAGeneration string
+
+const (
+	NVIDIAGenerationUnknown   NVIDIAGeneration = "unknown"
+	NVIDIAGenerationTesla     NVIDIAGeneration = "tesla"
+	NVIDIAGenerationFermi     NVIDIAGeneration = "fermi"
+	NVIDIAGenerationKepler    NVIDIAGeneration = "kepler"
+	NVIDIAGenerationMaxwell   NVIDIAGeneration = "maxwell"
+	NVIDIAGenerationPascal    NVIDIAGeneration = "pascal"
+	NVIDIAGenerationVolta     NVIDIAGeneration = "volta"
+	NVIDIAGenerationTuring    NVIDIAGeneration = "turing"
+	NVIDIAGenerationAmpere    NVIDIAGeneration = "ampere"
+	NVIDIAGenerationAda       NVIDIAGeneration = "ada"
+	NVIDIAGenerationBlackwell NVIDIAGeneration = "blackwell"
+)
+
+// String returns human-readable generation name.
+func (g NVIDIAGeneration) String() string {
+	switch g {
+	case NVIDIAGenerationAda:
+		return "Ada Lovelace"
+	case NVIDIAGenerationUnknown:
+		return "Unknown"
+	default:
+		return strings.ToUpper(string(g[:1])) + string(g[1:])
+	}
+}
+
+//go:embed nvidia_generations.txt
+var nvidiaGenerationsTable string
+
+// nvidiaGenerationRange maps an inclusive range of PCI device IDs to a generation
+type nvidiaGenerationRange struct {
+	first, last uint64
+	generation  NVIDIAGeneration
+}
+
+// nvidiaGenerations is parsed once from the embedded table
+var nvidiaGenerations = parseNVIDIAGenerations(nvidiaGenerationsTable)
+
+func parseNVIDIAGenerations(table string) []nvidiaGenerationRange {
+	var ranges []nvidiaGenerationRange
+	scanner := bufio.NewScanner(strings.NewReader(table))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		first, errFirst := strconv.ParseUint(fields[0], 16, 16)
+		last, errLast := strconv.ParseUint(fields[1], 16, 16)
+		if errFirst != nil || errLast != nil {
+			continue
+		}
+		ranges = append(ranges, nvidiaGenerationRange{first: first, last: last, generation: NVIDIAGeneration(fields[2])})
+	}
+	return ranges
+}
+
+// NVIDIAGenerationOf returns the generation of an NVIDIA PCI device ID
+// (e.g. "2484"), NVIDIAGenerationUnknown when the ID is not in the table.
+func NVIDIAGenerationOf(deviceID string) NVIDIAGeneration {
+	id, err := strconv.ParseUint(strings.TrimSpace(deviceID), 16, 16)
+	if err != nil {
+		return NVIDIAGenerationUnknown
+	}
+	for _, r := range nvidiaGenerations {
+		if id >= r.first && id <= r.last {
+			return r.generation
+		}
+	}
+	return NVIDIAGenerationUnknown
+}
+
+// NVIDIADriver is the driver branch chosen for an NVIDIA GPU and why.
+type NVIDIADriver struct {
+	Packages []string
+	Legacy   bool // 470xx/390xx branch from Chaotic-AUR, DKMS only and without GBM or VA-API support
+	Reason   string
+}
+
+// SelectNVIDIADriver returns the driver branch supporting a generation: the
+// open kernel modules from Turing on, the proprietary modules for Maxwell to
+// Volta, the legacy branches for Kepler and Fermi and nouveau for Tesla.
+// Unknown devices are assumed recent, the table only misses new IDs.
+func SelectNVIDIADriver(generation NVIDIAGeneration) NVIDIADriver {
+	switch generation {
+	case NVIDIAGenerationTuring, NVIDIAGenerationAmpere, NVIDIAGenerationAda, NVIDIAGenerationBlackwell:
+		return NVIDIADriver{
+			Packages: []string{"nvidia-open", "nvidia-utils", "libva-nvidia-driver"},
+			Reason:   generation.String() + " GPU: open kernel modules (nvidia-open)",
+		}
+	case NVIDIAGenerationMaxwell, NVIDIAGenerationPascal, NVIDIAGenerationVolta:
+		return NVIDIADriver{
+			Packages: []string{"nvidia", "nvidia-utils", "libva-nvidia-driver"},
+			Reason:   generation.String() + " GPU: not supported by the open kernel modules, proprietary driver (nvidia)",
+		}
+	case NVIDIAGenerationKepler:
+		return NVIDIADriver{
+			Packages: []string{"nvidia-470xx-dkms", "nvidia-470xx-utils"},
+			Legacy:   true,
+			Reason:   "Kepler GPU: legacy 470xx driver from Chaotic-AUR",
+		}
+	case NVIDIAGenerationFermi:
+		return NVIDIADriver{
+			Packages: []string{"nvidia-390xx-dkms", "nvidia-390xx-utils"},
+			Legacy:   true,
+			Reason:   "Fermi GPU: legacy 390xx driver from Chaotic-AUR",
+		}
+	case NVIDIAGenerationTesla:
+		return NVIDIADriver{
+			Packages: []string{"mesa"},
+			Reason:   "Tesla GPU: no NVIDIA driver supports it anymore, using nouveau (mesa)",
+		}
+	default:
+		return NVIDIADriver{
+			Packages: []string{"nvidia-open", "nvidia-utils", "libva-nvidia-driver"},
+			Reason:   "Unknown device ID, assuming a recent GPU: open kernel modules (nvidia-open)",
+		}
+	}
+}
+
+// IsLegacyNVIDIADriver returns true if the drivers hold a 470xx or 390xx branch
+func IsLegacyNVIDIADriver(drivers []string) bool {
+	for _, driver := range drivers {
+		if strings.HasPrefix(driver, "nvidia-470xx") || strings.HasPrefix(driver, "nvidia-390xx") {
+			return true
+		}
+	}
+	return false
+}
+
+// NVIDIADKMSVariants returns the drivers with the prebuilt NVIDIA kernel
+// modules replaced by their -dkms variant, which builds for any kernel. The
+// prebuilt modules only exist for the stock linux kernel.
+func NVIDIADKMSVariants(drivers []string) []string {
+	result := make([]string, 0, len(drivers))
+	for _, driver := range drivers {
+		switch driver {
+		case "nvidia", "nvidia-open":
+			result = append(result, driver+"-dkms")
+		default:
+			result = append(result, driver)
+		}
+	}
+	return result
+}
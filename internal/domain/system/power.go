@@ -0,0 +1,301 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CPU power configuration files written to the target
+const (
+	CPUPowerTmpfilesFile = "/etc/tmpfiles.d/archup-cpu-power.conf"
+	TLPConfigFile        = "/etc/tlp.d/10-archup-cpu.conf"
+	TunedProfileFile     = "/etc/tuned/active_profile"
+	TunedProfileModeFile = "/etc/tuned/profile_mode"
+)
+
+// PStateDriver is the CPU frequency scaling driver a P-State mode applies to.
+type PStateDriver string
+
+const (
+	PStateDriverNone  PStateDriver = ""
+	PStateDriverIntel PStateDriver = "intel_pstate"
+	PStateDriverAMD   PStateDriver = "amd_pstate"
+)
+
+// PowerDaemon is the userspace power management daemon of the target.
+type PowerDaemon string
+
+const (
+	PowerDaemonPPD   PowerDaemon = "power-profiles-daemon"
+	PowerDaemonTuned PowerDaemon = "tuned"
+	PowerDaemonTLP   PowerDaemon = "tlp"
+	PowerDaemonNone  PowerDaemon = "none"
+)
+
+// PowerDaemons returns the power daemons in display order.
+func PowerDaemons() []PowerDaemon {
+	return []PowerDaemon{PowerDaemonPPD, PowerDaemonTuned, PowerDaemonTLP, PowerDaemonNone}
+}
+
+// ParsePowerDaemon parses a power daemon name, power-profiles-daemon when empty.
+func ParsePowerDaemon(name string) (PowerDaemon, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "ppd", string(PowerDaemonPPD):
+		return PowerDaemonPPD, nil
+	case string(PowerDaemonTuned):
+		return PowerDaemonTuned, nil
+	case string(PowerDaemonTLP):
+		return PowerDaemonTLP, nil
+	case string(PowerDaemonNone):
+		return PowerDaemonNone, nil
+	default:
+		return "", fmt.Errorf("unknown power daemon %q", name)
+	}
+}
+
+// String returns human-readable daemon name.
+func (d PowerDaemon) String() string {
+	switch d {
+	case PowerDaemonPPD:
+		return "power-profiles-daemon"
+	case PowerDaemonTuned:
+		return "TuneD"
+	case PowerDaemonTLP:
+		return "TLP"
+	default:
+		return "None"
+	}
+}
+
+// Description returns a short description of the daemon.
+func (d PowerDaemon) Description() string {
+	switch d {
+	case PowerDaemonPPD:
+		return "Desktop power profiles switchable from GNOME/KDE (default)"
+	case PowerDaemonTuned:
+		return "Profile based tuning, also exposes the power profiles API"
+	case PowerDaemonTLP:
+		return "Battery focused laptop tuning with AC/battery settings"
+	default:
+		return "Only the kernel defaults and the EPP below"
+	}
+}
+
+// Packages returns the packages providing the daemon.
+func (d PowerDaemon) Packages() []string {
+	switch d {
+	case PowerDaemonPPD:
+		return []string{"power-profiles-daemon"}
+	case PowerDaemonTuned:
+		return []string{"tuned"}
+	case PowerDaemonTLP:
+		return []string{"tlp"}
+	default:
+		return nil
+	}
+}
+
+// Services returns the systemd units to enable for the daemon.
+func (d PowerDaemon) Services() []string {
+	switch d {
+	case PowerDaemonPPD:
+		return []string{"power-profiles-daemon.service"}
+	case PowerDaemonTuned:
+		return []string{"tuned.service"}
+	case PowerDaemonTLP:
+		return []string{"tlp.service"}
+	default:
+		return nil
+	}
+}
+
+// MaskedServices returns the systemd units to mask for the daemon. TLP
+// handles the radio device state itself and conflicts with systemd-rfkill.
+func (d PowerDaemon) MaskedServices() []string {
+	if d == PowerDaemonTLP {
+		return []string{"systemd-rfkill.service", "systemd-rfkill.socket"}
+	}
+	return nil
+}
+
+// ConflictingPackages returns the packages of the other daemons, which fight
+// over the same knobs and must be removed.
+func (d PowerDaemon) ConflictingPackages() []string {
+	result := []string{}
+	for _, other := range PowerDaemons() {
+		if other != d {
+			result = append(result, other.Packages()...)
+		}
+	}
+	return result
+}
+
+// AllPowerDaemonPackages returns the packages of every power daemon, for offline bundles.
+func AllPowerDaemonPackages() []string {
+	result := []string{}
+	for _, daemon := range PowerDaemons() {
+		result = append(result, daemon.Packages()...)
+	}
+	return result
+}
+
+// EPP is an energy performance preference hint of HWP (Intel) or CPPC (AMD
+// active mode) CPUs.
+type EPP string
+
+const (
+	EPPDefault            EPP = ""
+	EPPPerformance        EPP = "performance"
+	EPPBalancePerformance EPP = "balance_performance"
+	EPPBalancePower       EPP = "balance_power"
+	EPPPower              EPP = "power"
+)
+
+// EPPs returns the EPP values in display order, the firmware default first.
+func EPPs() []EPP {
+	return []EPP{EPPDefault, EPPPerformance, EPPBalancePerformance, EPPBalancePower, EPPPower}
+}
+
+// ParseEPP parses an EPP value, EPPDefault when empty.
+func ParseEPP(value string) (EPP, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "default" {
+		return EPPDefault, nil
+	}
+	for _, epp := range EPPs() {
+		if string(epp) == value {
+			return epp, nil
+		}
+	}
+	return "", fmt.Errorf("unknown energy performance preference %q", value)
+}
+
+// String returns the sysfs value, "default" for the firmware default.
+func (e EPP) String() string {
+	if e == EPPDefault {
+		return "default"
+	}
+	return string(e)
+}
+
+// onBattery returns the EPP used on battery by TLP, one step towards power saving
+func (e EPP) onBattery() EPP {
+	switch e {
+	case EPPPerformance:
+		return EPPBalancePerformance
+	case EPPBalancePerformance:
+		return EPPBalancePower
+	default:
+		return e
+	}
+}
+
+// tunedProfile returns the TuneD profile closest to the EPP
+func (e EPP) tunedProfile() string {
+	switch e {
+	case EPPPerformance:
+		return "throughput-performance"
+	case EPPBalancePower:
+		return "balanced-battery"
+	case EPPPower:
+		return "powersave"
+	default:
+		return "balanced"
+	}
+}
+
+// CPUPower is an immutable value object holding the CPU power settings of the
+// target: the P-State driver mode, the EPP default and the power daemon.
+type CPUPower struct {
+	driver PStateDriver
+	mode   string
+	epp    EPP
+	daemon PowerDaemon
+}
+
+// NewCPUPower creates the CPU power settings. The mode is a P-State driver
+// mode (e.g. "active", "passive"), empty to keep the kernel default.
+func NewCPUPower(driver PStateDriver, mode string, epp EPP, daemon PowerDaemon) (*CPUPower, error) {
+	if mode != "" && driver == PStateDriverNone {
+		return nil, fmt.Errorf("P-State mode %q without a P-State driver", mode)
+	}
+	if daemon == "" {
+		daemon = PowerDaemonPPD
+	}
+	// Passive and guided modes hand frequency selection to the governor, EPP
+	// hints only exist in active mode
+	if mode != "" && mode != "active" {
+		epp = EPPDefault
+	}
+	return &CPUPower{driver: driver, mode: mode, epp: epp, daemon: daemon}, nil
+}
+
+// Driver returns the P-State driver.
+func (p *CPUPower) Driver() PStateDriver { return p.driver }
+
+// Mode returns the P-State driver mode, empty for the kernel default.
+func (p *CPUPower) Mode() string { return p.mode }
+
+// EPP returns the EPP default.
+func (p *CPUPower) EPP() EPP { return p.epp }
+
+// Daemon returns the power daemon.
+func (p *CPUPower) Daemon() PowerDaemon { return p.daemon }
+
+// ConfigFiles returns the configuration files to write to the target, by path.
+// TLP sets the driver mode and EPP from its own configuration; otherwise a
+// tmpfiles.d entry applies them at boot. power-profiles-daemon and TuneD set
+// the EPP from their active profile, which TuneD selects from the EPP.
+func (p *CPUPower) ConfigFiles() map[string]string {
+	files := map[string]string{}
+
+	switch p.daemon {
+	case PowerDaemonTLP:
+		if conf := p.tlpConfig(); conf != "" {
+			files[TLPConfigFile] = conf
+		}
+		return files
+	case PowerDaemonTuned:
+		files[TunedProfileFile] = p.epp.tunedProfile() + "\n"
+		files[TunedProfileModeFile] = "manual\n"
+	}
+
+	if conf := p.tmpfilesConfig(); conf != "" {
+		files[CPUPowerTmpfilesFile] = conf
+	}
+	return files
+}
+
+// tmpfilesConfig returns the tmpfiles.d entries writing the driver mode and,
+// without a daemon managing it, the EPP to sysfs
+func (p *CPUPower) tmpfilesConfig() string {
+	var lines []string
+	if p.mode != "" {
+		lines = append(lines, fmt.Sprintf("w /sys/devices/system/cpu/%s/status - - - - %s", p.driver, p.mode))
+	}
+	if p.epp != EPPDefault && p.daemon == PowerDaemonNone {
+		lines = append(lines, "w /sys/devices/system/cpu/cpufreq/policy*/energy_performance_preference - - - - "+string(p.epp))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "# CPU power settings, written by archup\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// tlpConfig returns the TLP drop-in setting the driver mode and EPP
+func (p *CPUPower) tlpConfig() string {
+	var lines []string
+	if p.mode != "" {
+		lines = append(lines, "CPU_DRIVER_OPMODE_ON_AC="+p.mode, "CPU_DRIVER_OPMODE_ON_BAT="+p.mode)
+	}
+	if p.epp != EPPDefault {
+		lines = append(lines,
+			"CPU_ENERGY_PERF_POLICY_ON_AC="+string(p.epp),
+			"CPU_ENERGY_PERF_POLICY_ON_BAT="+string(p.epp.onBattery()),
+		)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "# CPU power settings, written by archup\n" + strings.Join(lines, "\n") + "\n"
+}
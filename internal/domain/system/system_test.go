@@ -814,3 +814,296 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// GPU driver Tests
+
+func TestLib32Drivers(t *testing.T) {
+	got := Lib32Drivers([]string{"mesa", "vulkan-intel", "intel-media-driver"})
+	if strings.Join(got, " ") != "lib32-mesa lib32-vulkan-intel" {
+		t.Errorf("unexpected lib32 drivers %v", got)
+	}
+}
+
+func TestGPU_EnvironmentFile(t *testing.T) {
+	gpu := NewGPU(GPUVendorNVIDIA, "RTX", nil, map[string]string{"LIBVA_DRIVER_NAME": "nvidia", "GBM_BACKEND": "nvidia-drm"})
+	expected := "# GPU environment, written by archup\nGBM_BACKEND=nvidia-drm\nLIBVA_DRIVER_NAME=nvidia\n"
+	if got := gpu.EnvironmentFile(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	if NewGPU(GPUVendorUnknown, "", nil, nil).EnvironmentFile() != "" {
+		t.Error("expected no environment file without variables")
+	}
+}
+
+func TestNVIDIADriverPackages(t *testing.T) {
+	got := NVIDIADriverPackages([]string{"nvidia-open", "nvidia-utils", "libva-nvidia-driver", "nvidia-settings", "nvidia-prime", "nvidia-470xx-dkms"})
+	if strings.Join(got, " ") != "nvidia-open nvidia-470xx-dkms" {
+		t.Errorf("unexpected driver packages %v", got)
+	}
+}
+
+func TestGPUSetup_Hybrid(t *testing.T) {
+	igpu := NewGPU(GPUVendorIntel, "Iris Xe", []string{"mesa", "vulkan-intel"}, nil).WithPCI("0000:00:02.0", true)
+	dgpu := NewGPU(GPUVendorNVIDIA, "RTX 4060", []string{"nvidia-open", "nvidia-utils"}, nil).WithPCI("0000:01:00.0", false)
+	setup := NewGPUSetup(dgpu, igpu)
+
+	if !setup.IsHybrid() || setup.Integrated() != igpu || setup.Discrete() != dgpu || setup.Primary() != igpu {
+		t.Fatal("expected Intel iGPU and NVIDIA dGPU")
+	}
+
+	tests := map[HybridProfile]string{
+		HybridProfileIntegrated: "mesa vulkan-intel",
+		HybridProfileOffload:    "mesa vulkan-intel nvidia-open nvidia-utils nvidia-prime",
+		HybridProfileDiscrete:   "nvidia-open nvidia-utils",
+	}
+	for profile, expected := range tests {
+		if got := strings.Join(setup.ProfileDrivers(profile), " "); got != expected {
+			t.Errorf("%s: expected drivers %q, got %q", profile, expected, got)
+		}
+	}
+	if gpus := setup.ProfileGPUs(HybridProfileOffload); gpus[0] != igpu || gpus[1] != dgpu {
+		t.Error("expected the iGPU to drive the display with render offload")
+	}
+}
+
+func TestGPUSetup_SingleGPU(t *testing.T) {
+	amd := NewGPU(GPUVendorAMD, "Navi 31", []string{"mesa"}, nil).WithPCI("0000:03:00.0", true)
+	setup := NewGPUSetup(amd)
+
+	if setup.IsHybrid() || setup.Integrated() != nil || setup.Discrete() != nil {
+		t.Error("expected a single GPU setup")
+	}
+	if gpus := setup.ProfileGPUs(HybridProfileOffload); len(gpus) != 1 || gpus[0] != amd {
+		t.Error("expected the only GPU whatever the profile")
+	}
+	if NewGPUSetup().Primary().Vendor() != GPUVendorUnknown {
+		t.Error("expected an unknown primary GPU without GPUs")
+	}
+}
+
+func TestNVIDIAGenerationOf(t *testing.T) {
+	tests := map[string]NVIDIAGeneration{
+		"10c3": NVIDIAGenerationTesla,
+		"0de1": NVIDIAGenerationFermi,
+		"1140": NVIDIAGenerationFermi,
+		"1184": NVIDIAGenerationKepler,
+		"13c2": NVIDIAGenerationMaxwell,
+		"1b80": NVIDIAGenerationPascal,
+		"1db4": NVIDIAGenerationVolta,
+		"1e84": NVIDIAGenerationTuring,
+		"2484": NVIDIAGenerationAmpere,
+		"2684": NVIDIAGenerationAda,
+		"2b85": NVIDIAGenerationBlackwell,
+		"ffff": NVIDIAGenerationUnknown,
+		"zz":   NVIDIAGenerationUnknown,
+	}
+	for id, expected := range tests {
+		if got := NVIDIAGenerationOf(id); got != expected {
+			t.Errorf("NVIDIAGenerationOf(%s) = %s, expected %s", id, got, expected)
+		}
+	}
+}
+
+func TestNVIDIAGenerationsTable(t *testing.T) {
+	if len(nvidiaGenerations) == 0 {
+		t.Fatal("expected the embedded table to parse")
+	}
+	for i, a := range nvidiaGenerations {
+		if a.first > a.last {
+			t.Errorf("range %04x-%04x is reversed", a.first, a.last)
+		}
+		for _, b := range nvidiaGenerations[i+1:] {
+			if a.first <= b.last && b.first <= a.last {
+				t.Errorf("ranges %04x-%04x and %04x-%04x overlap", a.first, a.last, b.first, b.last)
+			}
+		}
+	}
+}
+
+func TestSelectNVIDIADriver(t *testing.T) {
+	tests := map[NVIDIAGeneration]string{
+		NVIDIAGenerationAda:     "nvidia-open",
+		NVIDIAGenerationPascal:  "nvidia",
+		NVIDIAGenerationKepler:  "nvidia-470xx-dkms",
+		NVIDIAGenerationFermi:   "nvidia-390xx-dkms",
+		NVIDIAGenerationTesla:   "mesa",
+		NVIDIAGenerationUnknown: "nvidia-open",
+	}
+	for generation, expected := range tests {
+		driver := SelectNVIDIADriver(generation)
+		if driver.Packages[0] != expected || driver.Reason == "" {
+			t.Errorf("%s: expected %s, got %+v", generation, expected, driver)
+		}
+		if driver.Legacy != IsLegacyNVIDIADriver(driver.Packages) {
+			t.Errorf("%s: legacy flag does not match the packages", generation)
+		}
+	}
+}
+
+func TestNVIDIADKMSVariants(t *testing.T) {
+	got := NVIDIADKMSVariants([]string{"nvidia-open", "nvidia-utils", "nvidia", "nvidia-470xx-dkms"})
+	if strings.Join(got, " ") != "nvidia-open-dkms nvidia-utils nvidia-dkms nvidia-470xx-dkms" {
+		t.Errorf("unexpected DKMS variants %v", got)
+	}
+}
+
+func TestCPUPower_ConfigFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   PStateDriver
+		mode     string
+		epp      EPP
+		daemon   PowerDaemon
+		expected map[string][]string
+	}{
+		{
+			name: "ppd keeps EPP", driver: PStateDriverIntel, mode: "active", epp: EPPPower, daemon: PowerDaemonPPD,
+			expected: map[string][]string{CPUPowerTmpfilesFile: {"w /sys/devices/system/cpu/intel_pstate/status - - - - active"}},
+		},
+		{
+			name: "no daemon writes EPP", driver: PStateDriverAMD, mode: "active", epp: EPPBalancePower, daemon: PowerDaemonNone,
+			expected: map[string][]string{CPUPowerTmpfilesFile: {
+				"w /sys/devices/system/cpu/amd_pstate/status - - - - active",
+				"policy*/energy_performance_preference - - - - balance_power",
+			}},
+		},
+		{
+			name: "tuned profile from EPP", driver: PStateDriverNone, epp: EPPPerformance, daemon: PowerDaemonTuned,
+			expected: map[string][]string{TunedProfileFile: {"throughput-performance"}, TunedProfileModeFile: {"manual"}},
+		},
+		{
+			name: "tlp", driver: PStateDriverIntel, mode: "active", epp: EPPBalancePerformance, daemon: PowerDaemonTLP,
+			expected: map[string][]string{TLPConfigFile: {
+				"CPU_DRIVER_OPMODE_ON_AC=active",
+				"CPU_ENERGY_PERF_POLICY_ON_AC=balance_performance",
+				"CPU_ENERGY_PERF_POLICY_ON_BAT=balance_power",
+			}},
+		},
+		{
+			name: "passive drops EPP", driver: PStateDriverIntel, mode: "passive", epp: EPPPower, daemon: PowerDaemonTLP,
+			expected: map[string][]string{TLPConfigFile: {"CPU_DRIVER_OPMODE_ON_BAT=passive"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			power, err := NewCPUPower(tt.driver, tt.mode, tt.epp, tt.daemon)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			files := power.ConfigFiles()
+			if len(files) != len(tt.expected) {
+				t.Fatalf("expected %d files, got %v", len(tt.expected), files)
+			}
+			for path, lines := range tt.expected {
+				for _, line := range lines {
+					if !strings.Contains(files[path], line) {
+						t.Errorf("%s: expected %q in:\n%s", path, line, files[path])
+					}
+				}
+			}
+			if tt.mode == "passive" && strings.Contains(files[TLPConfigFile], "ENERGY_PERF") {
+				t.Errorf("expected no EPP in passive mode:\n%s", files[TLPConfigFile])
+			}
+		})
+	}
+
+	if _, err := NewCPUPower(PStateDriverNone, "active", EPPDefault, PowerDaemonPPD); err == nil {
+		t.Error("expected an error for a mode without a driver")
+	}
+}
+
+func TestPowerDaemon_ConflictingPackages(t *testing.T) {
+	if got := strings.Join(PowerDaemonTLP.ConflictingPackages(), " "); got != "power-profiles-daemon tuned" {
+		t.Errorf("unexpected conflicts %q", got)
+	}
+	if daemon, err := ParsePowerDaemon(""); err != nil || daemon != PowerDaemonPPD {
+		t.Errorf("expected power-profiles-daemon by default, got %q %v", daemon, err)
+	}
+	if _, err := ParseEPP("turbo"); err == nil {
+		t.Error("expected an error for an unknown EPP")
+	}
+}
+
+func TestChassis_IsLaptop(t *testing.T) {
+	tests := []struct {
+		name    string
+		chassis *Chassis
+		laptop  bool
+	}{
+		{"notebook", NewChassis(10, nil, false, false), true},
+		{"desktop with lid", NewChassis(3, nil, true, false), true},
+		{"unknown type with battery", NewChassis(0, []string{"BAT0"}, false, true), true},
+		{"desktop", NewChassis(3, nil, false, false), false},
+	}
+	for _, tt := range tests {
+		if got := tt.chassis.IsLaptop(); got != tt.laptop {
+			t.Errorf("%s: expected laptop %v, got %v (%q)", tt.name, tt.laptop, got, tt.chassis.Reason())
+		}
+	}
+}
+
+func TestFstabSwap(t *testing.T) {
+	fstab := `# /dev/nvme0n1p2
+UUID=aaaa	/	btrfs	rw,subvol=/@	0 0
+/dev/zram0	none	swap	defaults	0 0
+/swap/swapfile	none	swap	defaults	0 0
+`
+	if swap := FstabSwap(fstab); swap != "/swap/swapfile" || !IsSwapFile(swap) {
+		t.Errorf("expected the swap file, got %q", swap)
+	}
+	if root := FstabRoot(fstab); root != "UUID=aaaa" {
+		t.Errorf("expected the root UUID, got %q", root)
+	}
+	if swap := FstabSwap("UUID=bbbb none swap defaults 0 0\n"); swap != "UUID=bbbb" || IsSwapFile(swap) {
+		t.Errorf("expected the swap partition, got %q", swap)
+	}
+	if swap := FstabSwap("/dev/zram0 none swap defaults 0 0\n"); swap != "" {
+		t.Errorf("expected zram to be skipped, got %q", swap)
+	}
+}
+
+func TestLaptopLogindConf(t *testing.T) {
+	if !strings.Contains(LaptopLogindConf(true), "HandleLidSwitch=suspend-then-hibernate\n") {
+		t.Error("expected suspend-then-hibernate on lid close")
+	}
+	if !strings.Contains(LaptopLogindConf(false), "HandleLidSwitch=suspend\n") {
+		t.Error("expected suspend on lid close")
+	}
+	if pkgs := strings.Join(LaptopPackages(CPUVendorIntel), " "); pkgs != "fwupd brightnessctl thermald" {
+		t.Errorf("unexpected Intel laptop packages %q", pkgs)
+	}
+	if pkgs := strings.Join(LaptopPackages(CPUVendorAMD), " "); strings.Contains(pkgs, "thermald") {
+		t.Errorf("expected no thermald on AMD, got %q", pkgs)
+	}
+}
+
+func TestHardwareInventory_Summary(t *testing.T) {
+	inventory := NewHardwareInventory("x86_64",
+		InventoryMachine{Vendor: "Framework", Product: "Laptop 13", FirmwareVersion: "03.05", FirmwareDate: "11/01/2024"},
+		InventoryCPU{Vendor: "AMD", Model: "AMD Ryzen 7 7840U", ZenGeneration: "Zen 4"},
+		16*1024*1024,
+		[]InventoryGPU{InventoryGPUOf(NewGPU(GPUVendorAMD, "Radeon 780M", []string{"mesa"}, nil).WithPCI("0000:c1:00.0", true))},
+		[]InventoryDisk{{Path: "/dev/nvme0n1", Size: "931.5G", Transport: "nvme", Model: "WD_BLACK SN850X"}},
+		[]InventoryNetworkInterface{{Name: "wlp1s0", Driver: "mt7921e", Wireless: true}},
+	)
+
+	summary := strings.Join(inventory.Summary(), "\n")
+	for _, want := range []string{
+		"Machine: Framework Laptop 13",
+		"Firmware: 03.05 (11/01/2024)",
+		"CPU: AMD Ryzen 7 7840U (Zen 4)",
+		"Memory: 16.0 GiB",
+		"GPU: Radeon 780M",
+		"Disk: /dev/nvme0n1 931.5G nvme WD_BLACK SN850X",
+		"Network: wlp1s0 (wireless, mt7921e)",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("expected %q in summary:\n%s", want, summary)
+		}
+	}
+	if gpus := inventory.GPUs(); len(gpus) != 1 || gpus[0].Slot != "0000:c1:00.0" || !gpus[0].BootVGA {
+		t.Errorf("unexpected GPUs %+v", gpus)
+	}
+}
@@ -0,0 +1,79 @@
+package assets
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bnema/archup"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// FSSource implements the AssetSource port over a filesystem tree laid out
+// like the archup repository
+type FSSource struct {
+	fsys fs.FS
+	name string
+}
+
+// NewEmbeddedSource returns the assets embedded in the binary
+func NewEmbeddedSource() *FSSource {
+	return &FSSource{fsys: archup.Assets, name: "embedded"}
+}
+
+// NewDirSource returns the assets of a local archup tree, such as a checkout
+// or the assets of an offline bundle
+func NewDirSource(dir string) *FSSource {
+	return &FSSource{fsys: os.DirFS(dir), name: "dir " + dir}
+}
+
+// ReadAsset returns an asset by its repository-relative path
+func (s *FSSource) ReadAsset(name string) ([]byte, error) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	content, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("asset %s: %w", name, err)
+	}
+	return content, nil
+}
+
+// String returns human-readable representation
+func (s *FSSource) String() string {
+	return s.name
+}
+
+// RemoteSource implements the AssetSource port by downloading the assets of
+// a raw repository URL. It is a development override: a binary reading
+// remote assets may install different assets than it was built with.
+type RemoteSource struct {
+	client  ports.HTTPClient
+	baseURL string
+}
+
+// NewRemoteSource returns the assets below baseURL,
+// e.g. "https://raw.githubusercontent.com/bnema/archup/dev"
+func NewRemoteSource(client ports.HTTPClient, baseURL string) *RemoteSource {
+	return &RemoteSource{client: client, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// ReadAsset downloads an asset by its repository-relative path
+func (s *RemoteSource) ReadAsset(name string) ([]byte, error) {
+	url := s.baseURL + "/" + strings.TrimPrefix(name, "/")
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer func() { _ = resp.Close() }()
+
+	if resp.StatusCode() < 200 || resp.StatusCode() > 299 {
+		return nil, fmt.Errorf("unexpected status %d from %s", resp.StatusCode(), url)
+	}
+	return resp.Body(), nil
+}
+
+// String returns human-readable representation
+func (s *RemoteSource) String() string {
+	return "remote " + s.baseURL
+}
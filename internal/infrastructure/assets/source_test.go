@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/config"
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/ports/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEmbeddedSource_HasInstallAssets(t *testing.T) {
+	source := NewEmbeddedSource()
+	for _, asset := range config.InstallAssets() {
+		content, err := source.ReadAsset(asset)
+		if err != nil {
+			t.Errorf("expected %s to be embedded, got %v", asset, err)
+			continue
+		}
+		if len(content) == 0 {
+			t.Errorf("expected %s to have content", asset)
+		}
+	}
+
+	if _, err := source.ReadAsset("install/missing.packages"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+func TestDirSource_ReadAsset(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "install"), 0755); err != nil {
+		t.Fatalf("failed to create install dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "install", "base.packages"), []byte("base\n"), 0644); err != nil {
+		t.Fatalf("failed to write asset: %v", err)
+	}
+
+	source := NewDirSource(dir)
+	content, err := source.ReadAsset("/install/base.packages")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(content) != "base\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if source.String() != "dir "+dir {
+		t.Errorf("unexpected source %q", source.String())
+	}
+}
+
+func TestRemoteSource_ReadAsset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHTTP := mocks.NewMockHTTPClient(ctrl)
+	ok := mocks.NewMockResponse(ctrl)
+	ok.EXPECT().StatusCode().Return(http.StatusOK).AnyTimes()
+	ok.EXPECT().Body().Return([]byte("base\n"))
+	ok.EXPECT().Close().Return(nil)
+	notFound := mocks.NewMockResponse(ctrl)
+	notFound.EXPECT().StatusCode().Return(http.StatusNotFound).AnyTimes()
+	notFound.EXPECT().Close().Return(nil)
+
+	mockHTTP.EXPECT().Get("https://raw.githubusercontent.com/bnema/archup/dev/install/base.packages").Return(ok, nil)
+	mockHTTP.EXPECT().Get("https://raw.githubusercontent.com/bnema/archup/dev/install/extra.packages").Return(notFound, nil)
+
+	source := NewRemoteSource(mockHTTP, "https://raw.githubusercontent.com/bnema/archup/dev/")
+
+	content, err := source.ReadAsset("install/base.packages")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(content) != "base\n" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	_, err = source.ReadAsset("install/extra.packages")
+	if err == nil || !strings.Contains(err.Error(), "unexpected status 404") {
+		t.Errorf("expected status error, got %v", err)
+	}
+}
+
+func TestEmbeddedSource_ProfilesResolve(t *testing.T) {
+	content, err := NewEmbeddedSource().ReadAsset("install/" + config.ProfilesFile)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	catalog, err := packages.ParseProfileCatalog(string(content))
+	if err != nil {
+		t.Fatalf("expected the embedded profiles to parse, got %v", err)
+	}
+
+	for _, name := range []string{"server", "desktop-wayland", "multimedia", "bluetooth", "wifi", "dev-tools", "fonts"} {
+		if _, ok := catalog.Profile(name); !ok {
+			t.Errorf("expected the %s profile", name)
+		}
+	}
+	if _, err := catalog.Resolve(catalog.DefaultSelection()); err != nil {
+		t.Errorf("expected the default selection to resolve, got %v", err)
+	}
+	if _, err := catalog.Resolve([]string{"server", "desktop-wayland"}); err == nil {
+		t.Error("expected server and desktop-wayland to conflict")
+	}
+}
@@ -0,0 +1,85 @@
+package assets
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bnema/archup/internal/domain/manifest"
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// VerifiedSource implements the AssetSource port on top of another source,
+// only returning assets matching the signed release manifest of that source.
+// Verified assets are kept in memory so later reads return the same content
+// even if the source changes.
+type VerifiedSource struct {
+	source    ports.AssetSource
+	publicKey string
+
+	once     sync.Once
+	manifest *manifest.Manifest
+	err      error
+
+	mu       sync.Mutex
+	verified map[string][]byte
+}
+
+// NewVerifiedSource verifies the assets of source against its manifest,
+// signed by the minisign publicKey
+func NewVerifiedSource(source ports.AssetSource, publicKey string) *VerifiedSource {
+	return &VerifiedSource{
+		source:    source,
+		publicKey: publicKey,
+		verified:  map[string][]byte{},
+	}
+}
+
+// ReadAsset returns an asset of the source once checked against the manifest
+func (s *VerifiedSource) ReadAsset(name string) ([]byte, error) {
+	s.once.Do(func() { s.manifest, s.err = s.loadManifest() })
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if content, ok := s.verified[name]; ok {
+		return content, nil
+	}
+
+	content, err := s.source.ReadAsset(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.manifest.Verify(name, content); err != nil {
+		return nil, fmt.Errorf("refusing asset from %s: %w", s.source.String(), err)
+	}
+	s.verified[name] = content
+	return content, nil
+}
+
+// String returns human-readable representation
+func (s *VerifiedSource) String() string {
+	return "verified " + s.source.String()
+}
+
+// loadManifest reads the manifest and its signature and checks the signature
+func (s *VerifiedSource) loadManifest() (*manifest.Manifest, error) {
+	publicKey, err := manifest.ParsePublicKey(s.publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest public key: %w", err)
+	}
+
+	data, err := s.source.ReadAsset(manifest.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	signature, err := s.source.ReadAsset(manifest.SignatureFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest signature: %w", err)
+	}
+	if err := publicKey.Verify(data, signature); err != nil {
+		return nil, err
+	}
+	return manifest.Parse(data)
+}
@@ -0,0 +1,129 @@
+package assets
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bnema/archup/internal/domain/manifest"
+)
+
+// memSource serves assets from memory and counts reads
+type memSource struct {
+	files map[string][]byte
+	reads map[string]int
+}
+
+func (s *memSource) ReadAsset(name string) ([]byte, error) {
+	s.reads[name]++
+	content, ok := s.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: not found", name)
+	}
+	return content, nil
+}
+
+func (s *memSource) String() string { return "memory" }
+
+// signedSource returns a source holding assets, their manifest and its
+// legacy minisign signature, plus the public key it verifies with
+func signedSource(t *testing.T, assets map[string][]byte) (*memSource, string) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	data := []byte(manifest.New(assets).String())
+	sig := ed25519.Sign(priv, data)
+	trusted := "timestamp:1760000000"
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), trusted...))
+	signature := "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), sig...)) + "\n" +
+		"trusted comment: " + trusted + "\n" +
+		base64.StdEncoding.EncodeToString(global) + "\n"
+
+	files := map[string][]byte{
+		manifest.File:          data,
+		manifest.SignatureFile: []byte(signature),
+	}
+	for name, content := range assets {
+		files[name] = content
+	}
+	publicKey := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+	return &memSource{files: files, reads: map[string]int{}}, publicKey
+}
+
+func TestVerifiedSource_ReadAsset(t *testing.T) {
+	source, publicKey := signedSource(t, map[string][]byte{
+		"install/base.packages": []byte("base\n"),
+	})
+	verified := NewVerifiedSource(source, publicKey)
+
+	for range 2 {
+		content, err := verified.ReadAsset("install/base.packages")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(content) != "base\n" {
+			t.Errorf("unexpected content %q", content)
+		}
+	}
+	if source.reads["install/base.packages"] != 1 || source.reads[manifest.File] != 1 {
+		t.Errorf("expected verified assets and manifest to be read once, got %v", source.reads)
+	}
+	if verified.String() != "verified memory" {
+		t.Errorf("unexpected string %q", verified.String())
+	}
+}
+
+func TestVerifiedSource_RefusesTamperedAsset(t *testing.T) {
+	source, publicKey := signedSource(t, map[string][]byte{
+		"install/base.packages": []byte("base\n"),
+	})
+	source.files["install/base.packages"] = []byte("base\nbackdoor\n")
+	source.files["install/extra.packages"] = []byte("limine\n")
+	verified := NewVerifiedSource(source, publicKey)
+
+	_, err := verified.ReadAsset("install/base.packages")
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected mismatch error, got %v", err)
+	}
+	_, err = verified.ReadAsset("install/extra.packages")
+	if err == nil || !strings.Contains(err.Error(), "not listed") {
+		t.Errorf("expected unlisted error, got %v", err)
+	}
+}
+
+func TestVerifiedSource_RefusesBadManifest(t *testing.T) {
+	assets := map[string][]byte{"install/base.packages": []byte("base\n")}
+
+	// Manifest edited after signing
+	source, publicKey := signedSource(t, assets)
+	source.files[manifest.File] = append(source.files[manifest.File], []byte(manifest.Hash([]byte("x"))+"  install/x\n")...)
+	if _, err := NewVerifiedSource(source, publicKey).ReadAsset("install/base.packages"); err == nil {
+		t.Error("expected error for tampered manifest")
+	}
+
+	// Signed by another key
+	source, _ = signedSource(t, assets)
+	_, otherKey := signedSource(t, assets)
+	if _, err := NewVerifiedSource(source, otherKey).ReadAsset("install/base.packages"); err == nil {
+		t.Error("expected error for foreign signature")
+	}
+
+	// Missing signature
+	source, publicKey = signedSource(t, assets)
+	delete(source.files, manifest.SignatureFile)
+	if _, err := NewVerifiedSource(source, publicKey).ReadAsset("install/base.packages"); err == nil {
+		t.Error("expected error for missing signature")
+	}
+
+	// No public key compiled in
+	source, _ = signedSource(t, assets)
+	if _, err := NewVerifiedSource(source, "").ReadAsset("install/base.packages"); err == nil {
+		t.Error("expected error for empty public key")
+	}
+}
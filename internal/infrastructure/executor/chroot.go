@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -66,6 +67,25 @@ func (ce *ChrootExecutor) ExecuteInChrootWithStdin(ctx context.Context, chrootPa
 	return nil
 }
 
+// ExecuteInChrootWithOutput runs a command inside a chroot, streaming its
+// stdout and stderr to output while it runs
+func (ce *ChrootExecutor) ExecuteInChrootWithOutput(ctx context.Context, chrootPath string, output io.Writer, command string, args ...string) error {
+	if _, err := os.Stat(chrootPath); err != nil {
+		return fmt.Errorf("chroot path does not exist: %w", err)
+	}
+
+	allArgs := append([]string{chrootPath, command}, args...)
+	cmd := exec.CommandContext(ctx, "arch-chroot", allArgs...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("chroot command failed: %w", err)
+	}
+
+	return nil
+}
+
 // ChrootSystemctl runs systemctl commands in chroot
 // This is a convenience method that ensures proper logging of systemctl operations
 func (ce *ChrootExecutor) ChrootSystemctl(ctx context.Context, logPath string, chrootPath string, args ...string) error {
@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -258,3 +259,35 @@ func TestChrootExecutor_ExecuteInChrootWithStdin(t *testing.T) {
 		}
 	})
 }
+
+func TestChrootExecutor_ExecuteInChrootWithOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockLogger := mocks.NewMockLogger(ctrl)
+
+	executor := NewChrootExecutor(mockLogger)
+	workspace := t.TempDir()
+	chrootPath := filepath.Join(workspace, "chroot")
+	if err := os.MkdirAll(chrootPath, 0755); err != nil {
+		t.Fatalf("failed to create chroot path: %v", err)
+	}
+
+	// Fake arch-chroot writing its command to stdout and stderr
+	scriptDir := filepath.Join(workspace, "bin")
+	if err := os.MkdirAll(scriptDir, 0755); err != nil {
+		t.Fatalf("failed to create script dir: %v", err)
+	}
+	script := "#!/bin/sh\nshift\necho \"out $*\"\necho err >&2\n"
+	if err := os.WriteFile(filepath.Join(scriptDir, "arch-chroot"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write arch-chroot script: %v", err)
+	}
+	t.Setenv("PATH", scriptDir+":"+os.Getenv("PATH"))
+
+	var output bytes.Buffer
+	if err := executor.ExecuteInChrootWithOutput(context.Background(), chrootPath, &output, "bash", "/root/hook.sh"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if output.String() != "out bash /root/hook.sh\nerr\n" {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
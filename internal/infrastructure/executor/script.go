@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 
@@ -51,3 +52,27 @@ func (se *ScriptExecutor) ExecuteScript(ctx context.Context, scriptPath string,
 
 	return nil
 }
+
+// ExecuteScriptWithOutput runs a shell script with environment variables,
+// streaming its combined stdout and stderr to output
+func (se *ScriptExecutor) ExecuteScriptWithOutput(ctx context.Context, scriptPath string, env map[string]string, output io.Writer) error {
+	if _, err := os.Stat(scriptPath); err != nil {
+		return fmt.Errorf("script not found: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "bash", scriptPath)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	cmdEnv := os.Environ()
+	for key, value := range env {
+		cmdEnv = append(cmdEnv, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Env = cmdEnv
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("script execution failed: %w", err)
+	}
+
+	return nil
+}
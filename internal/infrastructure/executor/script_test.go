@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"path/filepath"
@@ -190,3 +191,30 @@ func TestScriptExecutor_ExecuteScript_ContextCancellation(t *testing.T) {
 		t.Fatal("expected error due to context cancellation")
 	}
 }
+
+func TestScriptExecutor_ExecuteScriptWithOutput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockFS := mocks.NewMockFileSystem(ctrl)
+	mockCmdExec := mocks.NewMockCommandExecutor(ctrl)
+
+	executor := NewScriptExecutor(mockFS, mockCmdExec, "/test/scripts")
+	tmpDir := t.TempDir()
+
+	scriptPath := filepath.Join(tmpDir, "test.sh")
+	scriptContent := "#!/bin/bash\necho \"out $TEST_VAR\"\necho err >&2\n"
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("failed to create test script: %v", err)
+	}
+
+	var output bytes.Buffer
+	ctx := context.Background()
+	err := executor.ExecuteScriptWithOutput(ctx, scriptPath, map[string]string{"TEST_VAR": "value"}, &output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if output.String() != "out value\nerr\n" {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
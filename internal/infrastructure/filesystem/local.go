@@ -66,6 +66,11 @@ func (lfs *LocalFileSystem) Exists(path string) (bool, error) {
 	return false, fmt.Errorf("failed to check file existence: %w", err)
 }
 
+// ReadDir lists a directory sorted by file name
+func (lfs *LocalFileSystem) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
 // localFileHandle wraps os.File to implement the ports.File interface
 type localFileHandle struct {
 	file *os.File
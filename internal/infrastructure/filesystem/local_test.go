@@ -277,3 +277,23 @@ func TestLocalFileSystem_Exists_DirectoryExists(t *testing.T) {
 		t.Error("expected directory to exist")
 	}
 }
+
+func TestLocalFileSystem_ReadDir(t *testing.T) {
+	fs := NewLocalFileSystem()
+	tmpDir := t.TempDir()
+
+	for _, name := range []string{"20-b.sh", "10-a.sh"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("test"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	entries, err := fs.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Name() != "10-a.sh" || entries[1].Name() != "20-b.sh" {
+		t.Errorf("expected sorted entries, got %v", entries)
+	}
+}
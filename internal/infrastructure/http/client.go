@@ -16,22 +16,27 @@ type HTTPClient struct {
 
 // NewHTTPClient creates a new HTTP client with a default timeout
 func NewHTTPClient() *HTTPClient {
-	return &HTTPClient{
-		client: &nethttp.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
+	return NewHTTPClientWithTimeout(30 * time.Second)
 }
 
 // NewHTTPClientWithTimeout creates a new HTTP client with a custom timeout
 func NewHTTPClientWithTimeout(timeout time.Duration) *HTTPClient {
 	return &HTTPClient{
 		client: &nethttp.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: newTransport(),
 		},
 	}
 }
 
+// newTransport returns the default transport also serving file:// URLs, so
+// remote assets can point at a local checkout
+func newTransport() nethttp.RoundTripper {
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	transport.RegisterProtocol("file", nethttp.NewFileTransport(nethttp.Dir("/")))
+	return transport
+}
+
 // Get performs an HTTP GET request
 func (hc *HTTPClient) Get(url string) (ports.Response, error) {
 	resp, err := hc.client.Get(url)
@@ -3,6 +3,8 @@ package http
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -217,3 +219,38 @@ func TestHTTPClient_Get_Timeout(t *testing.T) {
 		t.Fatal("expected error due to timeout")
 	}
 }
+
+func TestHTTPClient_Get_FileURL(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "base.packages"), []byte("base\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	client := NewHTTPClient()
+	resp, err := client.Get("file://" + filepath.Join(dir, "base.packages"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer func() {
+		if err := resp.Close(); err != nil {
+			t.Fatalf("failed to close response: %v", err)
+		}
+	}()
+
+	if resp.StatusCode() != 200 || string(resp.Body()) != "base\n" {
+		t.Errorf("unexpected response %d %q", resp.StatusCode(), resp.Body())
+	}
+
+	missing, err := client.Get("file://" + filepath.Join(dir, "missing"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	defer func() {
+		if err := missing.Close(); err != nil {
+			t.Fatalf("failed to close response: %v", err)
+		}
+	}()
+	if missing.StatusCode() != 404 {
+		t.Errorf("expected status 404 for a missing file, got %d", missing.StatusCode())
+	}
+}
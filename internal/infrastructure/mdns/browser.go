@@ -0,0 +1,268 @@
+package mdns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/bnema/archup/internal/domain/ports"
+)
+
+// DNS record types used by DNS-SD
+const (
+	typeA   = 1
+	typePTR = 12
+	typeTXT = 16
+	typeSRV = 33
+	classIN = 1
+)
+
+// mdnsAddr is the IPv4 multicast DNS group
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Browser implements the ServiceBrowser port with one-shot multicast DNS
+// queries. The query is sent from an ephemeral port, so responders answer
+// it directly (RFC 6762 legacy unicast) and no avahi daemon is needed.
+type Browser struct {
+	timeout time.Duration
+}
+
+// NewBrowser creates a browser collecting answers for two seconds
+func NewBrowser() *Browser {
+	return NewBrowserWithTimeout(2 * time.Second)
+}
+
+// NewBrowserWithTimeout creates a browser collecting answers for timeout
+func NewBrowserWithTimeout(timeout time.Duration) *Browser {
+	return &Browser{timeout: timeout}
+}
+
+// Browse queries the local network for instances of a service type, e.g.
+// "_http._tcp", and returns those announcing a port
+func (b *Browser) Browse(ctx context.Context, service string) ([]ports.ServiceInstance, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mDNS socket: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	name := strings.TrimSuffix(service, ".") + ".local"
+	if _, err := conn.WriteToUDP(buildQuery(name), mdnsAddr); err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %w", err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(b.timeout)); err != nil {
+		return nil, fmt.Errorf("failed to set mDNS deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	var records []record
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				break
+			}
+			return nil, fmt.Errorf("failed to read mDNS answer: %w", err)
+		}
+		// Unrelated or malformed packets are ignored
+		if answer, err := parseMessage(buf[:n]); err == nil {
+			records = append(records, answer...)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return instances(name, records), nil
+}
+
+// record is a decoded resource record of an answer
+type record struct {
+	name   string
+	typ    uint16
+	target string // PTR and SRV target
+	port   int    // SRV port
+	txt    map[string]string
+	ip     net.IP // A address
+}
+
+// buildQuery returns a PTR query for name
+func buildQuery(name string) []byte {
+	msg := make([]byte, 12, 64)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	msg = appendName(msg, name)
+	msg = binary.BigEndian.AppendUint16(msg, typePTR)
+	return binary.BigEndian.AppendUint16(msg, classIN)
+}
+
+// appendName appends name as uncompressed DNS labels
+func appendName(msg []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0)
+}
+
+// parseMessage returns the answer, authority and additional records of a DNS message
+func parseMessage(msg []byte) ([]record, error) {
+	if len(msg) < 12 {
+		return nil, errors.New("short DNS message")
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+
+	off := 12
+	for range questions {
+		_, next, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4
+	}
+
+	var records []record
+	for range count {
+		name, next, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(msg) {
+			return nil, errors.New("truncated DNS record")
+		}
+		rec := record{name: name, typ: binary.BigEndian.Uint16(msg[next:])}
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := next + 10
+		if data+length > len(msg) {
+			return nil, errors.New("truncated DNS record data")
+		}
+
+		switch rec.typ {
+		case typePTR:
+			if rec.target, _, err = readName(msg, data); err != nil {
+				return nil, err
+			}
+		case typeSRV:
+			if length < 7 {
+				return nil, errors.New("short SRV record")
+			}
+			rec.port = int(binary.BigEndian.Uint16(msg[data+4:]))
+			if rec.target, _, err = readName(msg, data+6); err != nil {
+				return nil, err
+			}
+		case typeTXT:
+			rec.txt = parseTXT(msg[data : data+length])
+		case typeA:
+			if length == 4 {
+				rec.ip = net.IP(append([]byte{}, msg[data:data+4]...))
+			}
+		}
+		records = append(records, rec)
+		off = data + length
+	}
+	return records, nil
+}
+
+// readName decodes a possibly compressed name at off, returning it without
+// the trailing dot and the offset following it
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errors.New("name out of bounds")
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return strings.Join(labels, "."), end, nil
+		case length&0xC0 == 0xC0:
+			if off+1 >= len(msg) {
+				return "", 0, errors.New("truncated name pointer")
+			}
+			if jumps++; jumps > 16 {
+				return "", 0, errors.New("name pointer loop")
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errors.New("label out of bounds")
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
+
+// parseTXT decodes the key=value strings of a TXT record
+func parseTXT(data []byte) map[string]string {
+	txt := map[string]string{}
+	for len(data) > 0 {
+		length := int(data[0])
+		if 1+length > len(data) {
+			break
+		}
+		key, value, _ := strings.Cut(string(data[1:1+length]), "=")
+		if key != "" {
+			txt[strings.ToLower(key)] = value
+		}
+		data = data[1+length:]
+	}
+	return txt
+}
+
+// instances resolves the PTR records of service into instances through their
+// SRV, TXT and A records
+func instances(service string, records []record) []ports.ServiceInstance {
+	var result []ports.ServiceInstance
+	seen := map[string]bool{}
+
+	for _, ptr := range records {
+		if ptr.typ != typePTR || !strings.EqualFold(ptr.name, service) || seen[strings.ToLower(ptr.target)] {
+			continue
+		}
+		seen[strings.ToLower(ptr.target)] = true
+
+		instance := ports.ServiceInstance{
+			Name: strings.TrimSuffix(ptr.target, "."+service),
+			TXT:  map[string]string{},
+		}
+		for _, rec := range records {
+			if !strings.EqualFold(rec.name, ptr.target) {
+				continue
+			}
+			switch rec.typ {
+			case typeSRV:
+				instance.Port = rec.port
+				instance.Host = rec.target
+			case typeTXT:
+				instance.TXT = rec.txt
+			}
+		}
+		if instance.Port == 0 {
+			continue
+		}
+		for _, rec := range records {
+			if rec.typ == typeA && rec.ip != nil && strings.EqualFold(rec.name, instance.Host) {
+				instance.Host = rec.ip.String()
+				break
+			}
+		}
+		result = append(result, instance)
+	}
+	return result
+}
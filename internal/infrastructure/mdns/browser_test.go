@@ -0,0 +1,82 @@
+package mdns
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// appendRecord appends a resource record with rdata to msg
+func appendRecord(msg []byte, name []byte, typ uint16, rdata []byte) []byte {
+	msg = append(msg, name...)
+	msg = binary.BigEndian.AppendUint16(msg, typ)
+	msg = binary.BigEndian.AppendUint16(msg, classIN)
+	msg = binary.BigEndian.AppendUint32(msg, 120)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(rdata)))
+	return append(msg, rdata...)
+}
+
+// pointer returns a compressed name pointing at off
+func pointer(off int) []byte {
+	return []byte{0xC0 | byte(off>>8), byte(off)}
+}
+
+func TestBuildQuery(t *testing.T) {
+	query := buildQuery("_pacman-cache._tcp.local")
+	records, err := parseMessage(query)
+	if err != nil || len(records) != 0 {
+		t.Fatalf("expected a question only query, got %v %v", records, err)
+	}
+	name, _, err := readName(query, 12)
+	if err != nil || name != "_pacman-cache._tcp.local" {
+		t.Errorf("unexpected query name %q: %v", name, err)
+	}
+}
+
+func TestParseMessage_Instances(t *testing.T) {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[2:], 0x8400) // authoritative response
+	binary.BigEndian.PutUint16(msg[6:], 1)      // ANCOUNT
+	binary.BigEndian.PutUint16(msg[10:], 3)     // ARCOUNT
+
+	// PTR _pacman-cache._tcp.local -> pacoloco._pacman-cache._tcp.local
+	serviceOff := len(msg)
+	service := appendName(nil, "_pacman-cache._tcp.local")
+	instanceOff := serviceOff + len(service) + 10
+	msg = appendRecord(msg, service, typePTR, append([]byte{8}, append([]byte("pacoloco"), pointer(serviceOff)...)...))
+
+	// SRV pacoloco... -> cache.local:9129
+	srv := []byte{0, 0, 0, 0, 0x23, 0xA9}
+	hostOff := len(msg) + 2 + 10 + len(srv)
+	srv = append(srv, appendName(nil, "cache.local")...)
+	msg = appendRecord(msg, pointer(instanceOff), typeSRV, srv)
+
+	// TXT path=/repo
+	msg = appendRecord(msg, pointer(instanceOff), typeTXT, append([]byte{10}, "path=/repo"...))
+
+	// A cache.local -> 192.168.1.20
+	msg = appendRecord(msg, pointer(hostOff), typeA, []byte{192, 168, 1, 20})
+
+	records, err := parseMessage(msg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(records))
+	}
+
+	found := instances("_pacman-cache._tcp.local", records)
+	if len(found) != 1 {
+		t.Fatalf("expected 1 instance, got %+v", found)
+	}
+	instance := found[0]
+	if instance.Name != "pacoloco" || instance.Host != "192.168.1.20" || instance.Port != 9129 || instance.TXT["path"] != "/repo" {
+		t.Errorf("unexpected instance %+v", instance)
+	}
+}
+
+func TestReadName_PointerLoop(t *testing.T) {
+	msg := append(make([]byte, 12), pointer(12)...)
+	if _, _, err := readName(msg, 12); err == nil {
+		t.Error("expected an error for a pointer loop")
+	}
+}
@@ -2,11 +2,16 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"os"
+	"slices"
+	"strings"
 
 	apphandlers "github.com/bnema/archup/internal/application/handlers"
 	"github.com/bnema/archup/internal/application/services"
+	"github.com/bnema/archup/internal/config"
 	"github.com/bnema/archup/internal/domain/ports"
+	"github.com/bnema/archup/internal/domain/snapshot"
 	"github.com/bnema/archup/internal/domain/system"
 	"github.com/bnema/archup/internal/interfaces/tui/handlers"
 	"github.com/bnema/archup/internal/interfaces/tui/models"
@@ -23,6 +28,11 @@ type App struct {
 	installService  *services.InstallationService
 	progressTracker *services.ProgressTracker
 	gpuHandler      *apphandlers.GPUHandler
+	laptopHandler   *apphandlers.LaptopHandler
+	packagesHandler *apphandlers.PackagesHandler
+
+	// Installer configuration (answer file defaults)
+	cfg *config.Config
 
 	// Infrastructure ports
 	logger ports.Logger
@@ -33,8 +43,15 @@ type App struct {
 	encryptionModel   *models.EncryptionModelImpl
 	kernelModel       *models.KernelModelImpl
 	amdPstateModel    *models.AMDPStateModelImpl
+	intelPstateModel  *models.IntelPStateModelImpl
+	powerModel        *models.PowerModelImpl
+	laptopModel       *models.LaptopModelImpl
 	gpuModel          *models.GPUModelImpl
+	bootOptionsModel  *models.BootOptionsModelImpl
+	snapperModel      *models.SnapperModelImpl
 	reposModel        *models.ReposModelImpl
+	profilesModel     *models.ProfilesModelImpl
+	packagePicker     *models.PackagePickerModelImpl
 	dankLinuxModel    *models.DankLinuxModelImpl
 	installationModel *models.InstallationModelImpl
 	progressModel     *models.ProgressModelImpl
@@ -56,18 +73,25 @@ type App struct {
 type Screen string
 
 const (
-	ScreenForm       Screen = "form"
-	ScreenDisk       Screen = "disk"
-	ScreenEncryption Screen = "encryption"
-	ScreenKernel     Screen = "kernel"
-	ScreenAMDPState  Screen = "amd-pstate"
-	ScreenGPU        Screen = "gpu"
-	ScreenRepos      Screen = "repos"
-	ScreenDankLinux  Screen = "danklinux"
-	ScreenInstalling Screen = "installing"
-	ScreenProgress   Screen = "progress"
-	ScreenSummary    Screen = "summary"
-	ScreenError      Screen = "error"
+	ScreenForm        Screen = "form"
+	ScreenDisk        Screen = "disk"
+	ScreenEncryption  Screen = "encryption"
+	ScreenKernel      Screen = "kernel"
+	ScreenAMDPState   Screen = "amd-pstate"
+	ScreenIntelPState Screen = "intel-pstate"
+	ScreenPower       Screen = "power"
+	ScreenLaptop      Screen = "laptop"
+	ScreenGPU         Screen = "gpu"
+	ScreenBootOpts    Screen = "boot-options"
+	ScreenSnapper     Screen = "snapper"
+	ScreenRepos       Screen = "repos"
+	ScreenProfiles    Screen = "profiles"
+	ScreenPackages    Screen = "packages"
+	ScreenDankLinux   Screen = "danklinux"
+	ScreenInstalling  Screen = "installing"
+	ScreenProgress    Screen = "progress"
+	ScreenSummary     Screen = "summary"
+	ScreenError       Screen = "error"
 )
 
 // NewApp creates a new TUI application
@@ -75,15 +99,30 @@ func NewApp(
 	installService *services.InstallationService,
 	progressTracker *services.ProgressTracker,
 	gpuHandler *apphandlers.GPUHandler,
+	laptopHandler *apphandlers.LaptopHandler,
+	packagesHandler *apphandlers.PackagesHandler,
+	cfg *config.Config,
 	logger ports.Logger,
 	version string,
 ) *App {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	snapperModel := models.NewSnapperModel()
+	if cfg != nil && cfg.SnapperConfigs != "" {
+		if configs, err := snapshot.ParseSnapperConfigs(cfg.SnapperConfigs); err != nil {
+			logger.Warn("Invalid ARCHUP_SNAPPER_CONFIGS, using defaults", "error", err)
+		} else {
+			snapperModel.SetConfigs(configs)
+		}
+	}
+
 	return &App{
 		installService:    installService,
 		progressTracker:   progressTracker,
 		gpuHandler:        gpuHandler,
+		laptopHandler:     laptopHandler,
+		packagesHandler:   packagesHandler,
+		cfg:               cfg,
 		logger:            logger,
 		version:           version,
 		formModel:         models.NewFormModel(),
@@ -91,8 +130,15 @@ func NewApp(
 		encryptionModel:   models.NewEncryptionModel(),
 		kernelModel:       models.NewKernelModel(),
 		amdPstateModel:    models.NewAMDPStateModel(),
+		intelPstateModel:  models.NewIntelPStateModel(),
+		powerModel:        newPowerModel(cfg, logger),
+		laptopModel:       models.NewLaptopModel(),
 		gpuModel:          models.NewGPUModel(),
+		bootOptionsModel:  models.NewBootOptionsModel(),
+		snapperModel:      snapperModel,
 		reposModel:        models.NewReposModel(),
+		profilesModel:     newProfilesModel(packagesHandler, cfg, logger),
+		packagePicker:     newPackagePicker(cfg),
 		dankLinuxModel:    models.NewDankLinuxModel(),
 		installationModel: models.NewInstallationModel(),
 		progressModel:     models.NewProgressModel(),
@@ -119,6 +165,11 @@ func (a *App) GetProgressTracker() *services.ProgressTracker {
 	return a.progressTracker
 }
 
+// GetConfig returns the installer configuration
+func (a *App) GetConfig() *config.Config {
+	return a.cfg
+}
+
 // GetContext returns the app context
 func (a *App) GetContext() context.Context {
 	return a.ctx
@@ -148,10 +199,15 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.handleCPUDetected(msg)
 	case GPUDetectedMsg:
 		return a.handleGPUDetected(msg)
+	case LaptopDetectedMsg:
+		return a.handleLaptopDetected(msg)
 	case TimezoneDetectedMsg:
 		return a.handleTimezoneDetected(msg)
 	case DisksDetectedMsg:
 		return a.handleDisksDetected(msg)
+	case PackageSearchMsg:
+		a.packagePicker.SetResults(msg.Query, msg.Results, msg.Err)
+		return a, nil
 	}
 
 	return a, nil
@@ -170,10 +226,24 @@ func (a *App) View() string {
 		return views.RenderKernelSelection(a.kernelModel)
 	case ScreenAMDPState:
 		return views.RenderAMDPStateSelection(a.amdPstateModel)
+	case ScreenIntelPState:
+		return views.RenderIntelPStateSelection(a.intelPstateModel)
+	case ScreenPower:
+		return views.RenderPowerSelection(a.powerModel)
+	case ScreenLaptop:
+		return views.RenderLaptopSelection(a.laptopModel)
 	case ScreenGPU:
 		return views.RenderGPUSelection(a.gpuModel)
+	case ScreenBootOpts:
+		return views.RenderBootOptions(a.bootOptionsModel)
+	case ScreenSnapper:
+		return views.RenderSnapperOptions(a.snapperModel)
 	case ScreenRepos:
 		return views.RenderReposSelection(a.reposModel)
+	case ScreenProfiles:
+		return views.RenderProfilesSelection(a.profilesModel)
+	case ScreenPackages:
+		return views.RenderPackagePicker(a.packagePicker)
 	case ScreenDankLinux:
 		return views.RenderDankLinuxSelection(a.dankLinuxModel)
 	case ScreenInstalling, ScreenProgress:
@@ -181,7 +251,7 @@ func (a *App) View() string {
 	case ScreenSummary:
 		return views.RenderSummary(a.installationModel)
 	case ScreenError:
-		return views.RenderError(a.installationModel.GetError())
+		return views.RenderInstallationError(a.installationModel)
 	default:
 		return views.RenderForm(a.formModel, a.version)
 	}
@@ -200,10 +270,24 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a.handleKernelInput(msg)
 	case ScreenAMDPState:
 		return a.handleAMDPStateInput(msg)
+	case ScreenIntelPState:
+		return a.handleIntelPStateInput(msg)
+	case ScreenPower:
+		return a.handlePowerInput(msg)
+	case ScreenLaptop:
+		return a.handleLaptopInput(msg)
 	case ScreenGPU:
 		return a.handleGPUInput(msg)
+	case ScreenBootOpts:
+		return a.handleBootOptionsInput(msg)
+	case ScreenSnapper:
+		return a.handleSnapperInput(msg)
 	case ScreenRepos:
 		return a.handleReposInput(msg)
+	case ScreenProfiles:
+		return a.handleProfilesInput(msg)
+	case ScreenPackages:
+		return a.handlePackagePickerInput(msg)
 	case ScreenDankLinux:
 		return a.handleDankLinuxInput(msg)
 	case ScreenProgress:
@@ -220,7 +304,8 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return a, nil
 		}
 	case ScreenError:
-		if msg.String() == "q" || msg.String() == "ctrl+c" {
+		// Quitting with q waits for the rollback, ctrl+c still forces it
+		if msg.String() == "ctrl+c" || (msg.String() == "q" && !a.installationModel.IsRollingBack()) {
 			return a, tea.Quit
 		}
 	}
@@ -284,16 +369,24 @@ func (a *App) handleInstallationComplete(msg handlers.InstallationCompleteMsg) (
 
 // handleGPUDetected updates GPU model with detection result.
 func (a *App) handleGPUDetected(msg GPUDetectedMsg) (tea.Model, tea.Cmd) {
-	a.gpuModel.SetDetectedGPU(msg.GPU)
+	a.gpuModel.SetDetectedGPUs(msg.GPUs)
+	a.formData.HardwareGPUs = nil
+	if msg.GPUs != nil {
+		for _, gpu := range msg.GPUs.GPUs() {
+			a.formData.HardwareGPUs = append(a.formData.HardwareGPUs, system.InventoryGPUOf(gpu))
+		}
+	}
 	return a, nil
 }
 
-// handleCancel cancels the installation
+// handleCancel cancels the installation. The pipeline rolls back the
+// completed phases and reports them through an InstallationErrorMsg.
 func (a *App) handleCancel() (tea.Model, tea.Cmd) {
 	a.logger.Info("Installation cancelled by user")
 	a.cancel()
 	a.currentScreen = ScreenError
 	a.installationModel.SetError("Installation cancelled by user")
+	a.installationModel.SetRollingBack(true)
 	return a, nil
 }
 
@@ -324,7 +417,11 @@ func (a *App) handleGPUInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+c":
 		return a, tea.Quit
 	case "esc", "backspace":
-		return a.startAMDPStateSelection()
+		if a.laptopModel.ShouldPrompt() {
+			a.currentScreen = ScreenLaptop
+			return a, nil
+		}
+		return a.startPowerSelection()
 	case "up", "shift+tab":
 		a.gpuModel.MoveUp()
 		return a, nil
@@ -335,17 +432,103 @@ func (a *App) handleGPUInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		selected := a.gpuModel.SelectedOption()
 		// Update stored form data directly
 		a.formData.GPUVendor = string(selected.Vendor)
+		a.formData.GPUVendors = []string{}
+		for _, vendor := range selected.Vendors {
+			a.formData.GPUVendors = append(a.formData.GPUVendors, string(vendor))
+		}
+		a.formData.GPUProfile = string(selected.Profile)
 		a.formData.GPUDrivers = append([]string{}, selected.Drivers...)
-		return a.startReposSelection()
+
+		// NVIDIA needs DRM modesetting for Wayland and PRIME, default it with the vendor choice
+		presets := a.bootOptionsModel.Presets()
+		presets.NVIDIAModeset = slices.Contains(selected.Vendors, system.GPUVendorNVIDIA)
+		a.bootOptionsModel.SetPresets(presets)
+		return a.startBootOptions()
 	}
 
 	return a, nil
 }
 
-func (a *App) handleDankLinuxInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+func (a *App) startBootOptions() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenBootOpts
+
+	presets := a.bootOptionsModel.Presets()
+	if cpuInfo := a.amdPstateModel.CPUInfo(); cpuInfo != nil {
+		presets.CPUVendor = string(cpuInfo.Vendor)
+	}
+	a.bootOptionsModel.SetPresets(presets)
+	// Extra parameters start from the P-State selection
+	a.bootOptionsModel.SetExtraParams(a.formData.KernelParamsExtra)
+	return a, nil
+}
+
+func (a *App) handleBootOptionsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc":
+		return a.startGPUSelection()
+	case "up", "shift+tab":
+		a.bootOptionsModel.MoveUp()
+		return a, nil
+	case "down", "tab":
+		a.bootOptionsModel.MoveDown()
+		return a, nil
+	case "enter":
+		if _, err := a.bootOptionsModel.Preview(); err != nil {
+			a.logger.Warn("Invalid kernel command line", "error", err)
+			return a, nil
+		}
+		a.formData.CmdlinePresets = a.bootOptionsModel.Presets()
+		a.formData.KernelParamsExtra = a.bootOptionsModel.ExtraParams()
+		return a.startSnapperOptions()
+	}
+
+	if a.bootOptionsModel.EditingExtra() {
+		return a, a.bootOptionsModel.Update(msg)
+	}
+
+	switch msg.String() {
+	case "left", "right", " ":
+		a.bootOptionsModel.Toggle()
+	case "backspace":
+		return a.startGPUSelection()
+	}
+	return a, nil
+}
+
+func (a *App) startSnapperOptions() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenSnapper
+	return a, nil
+}
+
+func (a *App) handleSnapperInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
 	case "esc", "backspace":
+		return a.startBootOptions()
+	case "up", "shift+tab":
+		a.snapperModel.MoveUp()
+	case "down", "tab":
+		a.snapperModel.MoveDown()
+	case "left", "-":
+		a.snapperModel.Adjust(-1)
+	case "right", "+":
+		a.snapperModel.Adjust(1)
+	case " ":
+		a.snapperModel.Toggle()
+	case "enter":
+		a.formData.SnapperConfigs = a.snapperModel.Configs()
 		return a.startReposSelection()
+	}
+	return a, nil
+}
+
+func (a *App) handleDankLinuxInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		return a.startPackagePicker()
 	case "up", "shift+tab":
 		a.dankLinuxModel.MoveUp()
 		return a, nil
@@ -371,7 +554,7 @@ func (a *App) handleReposInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+c", "q":
 		return a, tea.Quit
 	case "esc", "backspace":
-		return a.startGPUSelection()
+		return a.startSnapperOptions()
 	case "up":
 		a.reposModel.MoveUp()
 		return a, nil
@@ -384,12 +567,110 @@ func (a *App) handleReposInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	case "enter":
 		a.formData.AURHelper = a.reposModel.SelectedAURHelper()
-		a.currentScreen = ScreenDankLinux
-		return a, nil
+		return a.startProfilesSelection()
 	}
 	return a, nil
 }
 
+func (a *App) startProfilesSelection() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenProfiles
+	return a, nil
+}
+
+func (a *App) handleProfilesInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return a, tea.Quit
+	case "esc", "backspace":
+		return a.startReposSelection()
+	case "up", "shift+tab":
+		a.profilesModel.MoveUp()
+	case "down", "tab":
+		a.profilesModel.MoveDown()
+	case " ", "left", "right":
+		a.profilesModel.Toggle()
+	case "enter":
+		selection, err := a.profilesModel.Resolve()
+		if err != nil {
+			a.logger.Warn("Invalid package profile selection", "error", err)
+			return a, nil
+		}
+		// Without a catalog the repositories phase installs the defaults
+		if selection != nil {
+			a.formData.PackageProfiles = a.profilesModel.Selected()
+		}
+		return a.startPackagePicker()
+	}
+	return a, nil
+}
+
+func (a *App) startPackagePicker() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenPackages
+	return a, nil
+}
+
+func (a *App) handlePackagePickerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc":
+		return a.startProfilesSelection()
+	}
+
+	if a.packagePicker.EditingQuery() {
+		switch msg.String() {
+		case "enter":
+			if a.packagePicker.Searching() {
+				return a, nil
+			}
+			query := strings.TrimSpace(a.packagePicker.Query())
+			if query == "" {
+				return a.confirmPackages()
+			}
+			a.packagePicker.StartSearch()
+			return a, a.searchPackagesCmd(query)
+		case "tab", "down":
+			a.packagePicker.FocusResults()
+			return a, nil
+		}
+		return a, a.packagePicker.Update(msg)
+	}
+
+	switch msg.String() {
+	case "up", "shift+tab":
+		a.packagePicker.MoveUp()
+	case "down":
+		a.packagePicker.MoveDown()
+	case " ":
+		a.packagePicker.Toggle()
+	case "/", "tab", "backspace":
+		a.packagePicker.FocusQuery()
+	case "enter":
+		return a.confirmPackages()
+	}
+	return a, nil
+}
+
+func (a *App) confirmPackages() (tea.Model, tea.Cmd) {
+	a.formData.Packages = a.packagePicker.Selected()
+	a.formData.AURPackages = a.packagePicker.AURPackages()
+	a.currentScreen = ScreenDankLinux
+	return a, nil
+}
+
+func (a *App) searchPackagesCmd(query string) tea.Cmd {
+	return func() tea.Msg {
+		if a.packagesHandler == nil {
+			return PackageSearchMsg{Query: query, Err: errors.New("package search unavailable")}
+		}
+		results, err := a.packagesHandler.Search(a.ctx, query)
+		if err != nil {
+			a.logger.Warn("Package search failed", "query", query, "error", err)
+		}
+		return PackageSearchMsg{Query: query, Results: results, Err: err}
+	}
+}
+
 func (a *App) handleKernelInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c":
@@ -402,9 +683,13 @@ func (a *App) handleKernelInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "down", "tab":
 		a.kernelModel.MoveDown()
 		return a, nil
+	case " ":
+		a.kernelModel.ToggleExtra()
+		return a, nil
 	case "enter":
 		selected := a.kernelModel.SelectedOption()
 		a.formData.KernelVariant = selected.Package
+		a.formData.ExtraKernels = a.kernelModel.ExtraPackages()
 		return a.startAMDPStateSelection()
 	}
 
@@ -417,16 +702,89 @@ func (a *App) handleCPUDetected(msg CPUDetectedMsg) (tea.Model, tea.Cmd) {
 	}
 
 	a.amdPstateModel.SetCPUInfo(msg.CPU)
+	a.intelPstateModel.SetCPUInfo(msg.CPU)
+	if a.cfg != nil && a.cfg.IntelPState != "" {
+		a.intelPstateModel.SetSelectedMode(legacysystem.IntelPStateMode(a.cfg.IntelPState))
+	}
+
+	if msg.CPU != nil {
+		a.formData.HardwareCPU = system.InventoryCPU{Vendor: string(msg.CPU.Vendor), Model: msg.CPU.ModelName}
+		if msg.CPU.AMDZenGen != nil {
+			a.formData.HardwareCPU.ZenGeneration = msg.CPU.AMDZenGen.Label
+		}
+	}
 
 	// Always include microcode when CPU vendor is known
 	if msg.CPU != nil && msg.CPU.Vendor != "" && msg.CPU.Vendor != legacysystem.CPUVendorUnknown {
 		a.formData.Microcode = true
+		a.formData.CPUVendor = string(msg.CPU.Vendor)
+	}
+
+	if a.intelPstateModel.ShouldPrompt() {
+		a.currentScreen = ScreenIntelPState
+		return a, nil
 	}
 
 	if !a.amdPstateModel.ShouldPrompt() {
 		a.formData.AMDPState = ""
+		a.formData.IntelPState = ""
 		a.formData.KernelParamsExtra = ""
-		return a.startGPUSelection()
+		return a.startPowerSelection()
+	}
+
+	return a, nil
+}
+
+func (a *App) handleIntelPStateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "backspace":
+		return a.startKernelSelection()
+	case "up", "shift+tab":
+		a.intelPstateModel.MoveUp()
+		return a, nil
+	case "down", "tab":
+		a.intelPstateModel.MoveDown()
+		return a, nil
+	case "enter":
+		selected := a.intelPstateModel.SelectedOption()
+		a.formData.AMDPState = ""
+		a.formData.IntelPState = string(selected.Mode)
+		a.formData.KernelParamsExtra = legacysystem.GetIntelPStateKernelParams(selected.Mode)
+		return a.startPowerSelection()
+	}
+
+	return a, nil
+}
+
+func (a *App) handlePowerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "backspace":
+		switch {
+		case a.intelPstateModel.ShouldPrompt():
+			a.currentScreen = ScreenIntelPState
+		case a.amdPstateModel.ShouldPrompt():
+			a.currentScreen = ScreenAMDPState
+		default:
+			return a.startKernelSelection()
+		}
+		return a, nil
+	case "up", "shift+tab":
+		a.powerModel.MoveUp()
+		return a, nil
+	case "down", "tab":
+		a.powerModel.MoveDown()
+		return a, nil
+	case "left", "right", " ":
+		a.powerModel.Toggle()
+		return a, nil
+	case "enter":
+		a.formData.PowerDaemon = string(a.powerModel.Daemon())
+		a.formData.CPUEPP = string(a.powerModel.EPP())
+		return a.startLaptopSelection()
 	}
 
 	return a, nil
@@ -448,6 +806,7 @@ func (a *App) handleAMDPStateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		selected := a.amdPstateModel.SelectedOption()
 		mode := string(selected.Mode)
 		a.formData.AMDPState = mode
+		a.formData.IntelPState = ""
 		a.formData.KernelParamsExtra = ""
 
 		cpuInfo := a.amdPstateModel.CPUInfo()
@@ -460,7 +819,7 @@ func (a *App) handleAMDPStateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.formData.KernelParamsExtra = "amd_pstate=" + mode
 		}
 
-		return a.startGPUSelection()
+		return a.startPowerSelection()
 	}
 
 	return a, nil
@@ -515,9 +874,135 @@ func (a *App) startAMDPStateSelection() (tea.Model, tea.Cmd) {
 	return a, a.detectCPUCmd()
 }
 
+// startPowerSelection shows the power screen, with the EPP row when the CPU
+// takes EPP hints in the selected P-State mode
+func (a *App) startPowerSelection() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenPower
+
+	eppAvailable := false
+	if cpuInfo := a.intelPstateModel.CPUInfo(); cpuInfo != nil {
+		switch cpuInfo.Vendor {
+		case legacysystem.CPUVendorIntel:
+			eppAvailable = cpuInfo.IntelEPP && a.formData.IntelPState != string(legacysystem.IntelPStateModePassive)
+		case legacysystem.CPUVendorAMD:
+			eppAvailable = a.formData.AMDPState == string(legacysystem.AMDPStateModeActive)
+		}
+	}
+	a.powerModel.SetEPPAvailable(eppAvailable)
+	return a, nil
+}
+
+func (a *App) startLaptopSelection() (tea.Model, tea.Cmd) {
+	a.currentScreen = ScreenLaptop
+	return a, a.detectLaptopCmd()
+}
+
+func (a *App) detectLaptopCmd() tea.Cmd {
+	return func() tea.Msg {
+		chassis, err := a.laptopHandler.Detect(a.ctx)
+		if err != nil {
+			a.logger.Warn("Chassis detection failed", "error", err)
+		}
+		return LaptopDetectedMsg{Chassis: chassis, Err: err}
+	}
+}
+
+func (a *App) handleLaptopDetected(msg LaptopDetectedMsg) (tea.Model, tea.Cmd) {
+	vendor := system.CPUVendorUnknown
+	if a.formData.CPUVendor == string(legacysystem.CPUVendorIntel) {
+		vendor = system.CPUVendorIntel
+	}
+	a.laptopModel.SetChassis(msg.Chassis, vendor)
+	if a.cfg != nil && a.cfg.LaptopProfile != "" {
+		a.laptopModel.SetSelectedKey(a.cfg.LaptopProfile)
+	}
+
+	if !a.laptopModel.ShouldPrompt() {
+		a.formData.LaptopProfile = false
+		a.formData.SuspendThenHibernate = false
+		return a.startGPUSelection()
+	}
+
+	return a, nil
+}
+
+func (a *App) handleLaptopInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "backspace":
+		return a.startPowerSelection()
+	case "up", "shift+tab":
+		a.laptopModel.MoveUp()
+		return a, nil
+	case "down", "tab":
+		a.laptopModel.MoveDown()
+		return a, nil
+	case "enter":
+		selected := a.laptopModel.SelectedOption()
+		a.formData.LaptopProfile = selected.Enabled
+		a.formData.SuspendThenHibernate = selected.Hibernate
+		return a.startGPUSelection()
+	}
+
+	return a, nil
+}
+
+// newPowerModel creates the power model with the answer file defaults
+func newPowerModel(cfg *config.Config, logger ports.Logger) *models.PowerModelImpl {
+	pm := models.NewPowerModel()
+	if cfg == nil {
+		return pm
+	}
+	if daemon, err := system.ParsePowerDaemon(cfg.PowerDaemon); err != nil {
+		logger.Warn("Invalid ARCHUP_POWER_DAEMON, using default", "error", err)
+	} else {
+		pm.SetDaemon(daemon)
+	}
+	if epp, err := system.ParseEPP(cfg.CPUEPP); err != nil {
+		logger.Warn("Invalid ARCHUP_CPU_EPP, using default", "error", err)
+	} else {
+		pm.SetEPP(epp)
+	}
+	return pm
+}
+
+func newProfilesModel(packagesHandler *apphandlers.PackagesHandler, cfg *config.Config, logger ports.Logger) *models.ProfilesModelImpl {
+	if packagesHandler == nil {
+		return models.NewProfilesModel(nil)
+	}
+	catalog, err := packagesHandler.Profiles()
+	if err != nil {
+		logger.Warn("Package profiles unavailable, installing the defaults", "error", err)
+		return models.NewProfilesModel(nil)
+	}
+
+	pm := models.NewProfilesModel(catalog)
+	if cfg != nil && len(cfg.PackageProfiles) > 0 {
+		if _, err := catalog.Resolve(cfg.PackageProfiles); err != nil {
+			logger.Warn("Invalid ARCHUP_PACKAGE_PROFILES, using defaults", "error", err)
+		} else {
+			pm.SetSelected(cfg.PackageProfiles)
+		}
+	}
+	return pm
+}
+
+// newPackagePicker starts the package picker from the answer file packages,
+// validated at startup
+func newPackagePicker(cfg *config.Config) *models.PackagePickerModelImpl {
+	pm := models.NewPackagePickerModel()
+	if cfg != nil {
+		pm.SetSelected(cfg.Packages)
+		pm.SetAURPackages(cfg.AURPackages)
+	}
+	return pm
+}
+
 func (a *App) startKernelSelection() (tea.Model, tea.Cmd) {
 	a.currentScreen = ScreenKernel
 	a.kernelModel.SetSelectedPackage(a.formData.KernelVariant)
+	a.kernelModel.SetExtraPackages(a.formData.ExtraKernels)
 	return a, nil
 }
 
@@ -538,11 +1023,11 @@ func (a *App) startGPUSelection() (tea.Model, tea.Cmd) {
 
 func (a *App) detectGPUCmd() tea.Cmd {
 	return func() tea.Msg {
-		gpu, err := a.gpuHandler.Detect(a.ctx)
+		gpus, err := a.gpuHandler.Detect(a.ctx)
 		if err != nil {
 			a.logger.Warn("GPU detection failed", "error", err)
 		}
-		return GPUDetectedMsg{GPU: gpu}
+		return GPUDetectedMsg{GPUs: gpus}
 	}
 }
 
@@ -631,6 +1116,16 @@ func (a *App) handleDisksDetected(msg DisksDetectedMsg) (tea.Model, tea.Cmd) {
 		a.diskModel.SetError(msg.Err)
 	} else {
 		a.diskModel.SetDisks(msg.Disks)
+		a.formData.HardwareDisks = nil
+		for _, d := range msg.Disks {
+			a.formData.HardwareDisks = append(a.formData.HardwareDisks, system.InventoryDisk{
+				Path:      d.Path,
+				Size:      d.Size,
+				Model:     d.Model,
+				Serial:    d.Serial,
+				Transport: d.Tran,
+			})
+		}
 	}
 	return a, nil
 }
@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/bnema/archup/internal/application/commands"
 	"github.com/bnema/archup/internal/application/dto"
+	"github.com/bnema/archup/internal/application/services"
 	"github.com/bnema/archup/internal/config"
 	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/bundle"
 	"github.com/bnema/archup/internal/domain/disk"
+	"github.com/bnema/archup/internal/domain/installation"
 	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/bnema/archup/internal/domain/system"
 	"github.com/bnema/archup/internal/interfaces/tui/models"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -44,7 +51,17 @@ func HandleProgressUpdate(app AppContext, msg interface{}, progressModel *models
 func HandleInstallationError(app AppContext, msg interface{}, installModel *models.InstallationModelImpl) (*models.InstallationModelImpl, tea.Cmd) {
 	if errMsg, ok := msg.(InstallationErrorMsg); ok {
 		app.GetLogger().Error("Installation error", "error", errMsg.Err)
-		installModel.SetError(errMsg.Err.Error())
+		installModel.SetRollingBack(false)
+		if errors.Is(errMsg.Err, context.Canceled) {
+			installModel.SetError("Installation cancelled by user")
+		} else {
+			installModel.SetError(errMsg.Err.Error())
+		}
+
+		var pipelineErr *services.PipelineError
+		if errors.As(errMsg.Err, &pipelineErr) {
+			installModel.SetRollback(pipelineErr.RolledBack, pipelineErr.RollbackErrors)
+		}
 	}
 	return installModel, nil
 }
@@ -52,6 +69,7 @@ func HandleInstallationError(app AppContext, msg interface{}, installModel *mode
 // HandleInstallationComplete processes installation completion messages
 func HandleInstallationComplete(app AppContext, msg interface{}, installModel *models.InstallationModelImpl) (*models.InstallationModelImpl, tea.Cmd) {
 	app.GetLogger().Info("Installation completed successfully")
+	installModel.SetRollingBack(false)
 	installModel.SetComplete()
 	// Update status with final timestamps for duration display
 	if svc := app.GetInstallService(); svc != nil {
@@ -68,9 +86,16 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 		svc := app.GetInstallService()
 		logger := app.GetLogger()
 		program := app.GetProgram()
+		cfg := app.GetConfig()
 
 		encryptionType := normalizeEncryptionType(formData.EncryptionType)
 
+		hooks, hookPolicy, err := loadHooks(svc, cfg)
+		if err != nil {
+			logger.Error("Failed to load hooks", "error", err)
+			return InstallationErrorMsg{Err: err}
+		}
+
 		// Start installation via service
 		if err := svc.Start(ctx, formData.Hostname, formData.Username, formData.TargetDisk, encryptionType); err != nil {
 			logger.Error("Failed to start installation", "error", err)
@@ -96,29 +121,16 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 			}
 		}()
 
-		// Run installation phases in background goroutine
-		go func() {
-			// Phase 1: Preflight
-			if _, err := svc.RunPreflight(ctx); err != nil {
-				logger.Error("Preflight checks failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 1.5: Bootstrap (clone repo / download files)
-			if _, err := svc.RunBootstrap(ctx); err != nil {
-				logger.Error("Bootstrap failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 2: Partition disk with chosen encryption using user password
-			isEncrypted := parseEncryptionType(formData.EncryptionType) != disk.EncryptionTypeNone
-			partitionCmd := commands.PartitionDiskCommand{
+		isEncrypted := parseEncryptionType(formData.EncryptionType) != disk.EncryptionTypeNone
+		plan := services.InstallPlan{
+			// Mirrors are ranked in the country of the selected timezone
+			Mirrors: commands.SelectMirrorsCommand{
+				Timezone: formData.Timezone,
+				Country:  cfg.MirrorCountry,
+				Servers:  cfg.Mirrors,
+			},
+			// Partition disk with chosen encryption using user password
+			Partition: commands.PartitionDiskCommand{
 				TargetDisk:         formData.TargetDisk,
 				RootSizeGB:         0, // Use all available space
 				BootSizeGB:         4, // 4GB for limine-snapper-sync
@@ -126,34 +138,17 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 				EncryptionPassword: formData.UserPassword, // Use account password for disk encryption
 				FilesystemType:     disk.FilesystemBtrfs,
 				WipeDisks:          true,
-			}
-			partitionResult, err := svc.RunPartition(ctx, partitionCmd)
-			if err != nil {
-				logger.Error("Partitioning failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 3: Base install
-			baseCmd := commands.InstallBaseCommand{
+			},
+			Base: commands.InstallBaseCommand{
 				TargetDisk:       formData.TargetDisk,
 				MountPoint:       "/mnt",
 				KernelVariant:    parseKernelVariant(formData.KernelVariant),
+				ExtraKernels:     parseKernelVariants(formData.ExtraKernels),
 				IncludeMicrocode: formData.Microcode,
 				Encrypted:        isEncrypted,
-			}
-			if _, err := svc.RunBaseInstall(ctx, baseCmd); err != nil {
-				logger.Error("Base installation failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 4: Configure system
-			configCmd := commands.ConfigureSystemCommand{
+				Packages:         formData.Packages,
+			},
+			Config: commands.ConfigureSystemCommand{
 				MountPoint:   "/mnt",
 				Hostname:     formData.Hostname,
 				Timezone:     formData.Timezone,
@@ -163,54 +158,58 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 				UserShell:    "/bin/bash",
 				UserPassword: formData.UserPassword,
 				RootPassword: formData.RootPassword,
-			}
-			if _, err := svc.RunConfigSystem(ctx, configCmd); err != nil {
-				logger.Error("System configuration failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 5: Bootloader
-			bootCmd := commands.InstallBootloaderCommand{
+			},
+			// Root and EFI partitions are filled in by the bootloader phase
+			Bootloader: commands.InstallBootloaderCommand{
 				MountPoint:        "/mnt",
 				BootloaderType:    bootloader.BootloaderTypeLimine,
-				TimeoutSeconds:    5,
+				TimeoutSeconds:    cfg.BootTimeout,
 				Branding:          "Arch Linux",
 				KernelVariant:     parseKernelVariant(formData.KernelVariant),
-				RootPartition:     partitionResult.RootPartition,
-				EncryptionType:    partitionCmd.EncryptionType,
-				EFIPartition:      partitionResult.EFIPartition,
+				ExtraKernels:      parseKernelVariants(formData.ExtraKernels),
+				EncryptionType:    parseEncryptionType(formData.EncryptionType),
 				TargetDisk:        formData.TargetDisk,
 				KernelParamsExtra: formData.KernelParamsExtra,
-				GPUVendor:         formData.GPUVendor,
-			}
-			if _, err := svc.RunBootloaderSetup(ctx, bootCmd); err != nil {
-				logger.Error("Bootloader setup failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 6: Repository setup
-			repoCmd := commands.SetupRepositoriesCommand{
+				CmdlinePresets:    formData.CmdlinePresets,
+				GPUVendors:        formData.GPUVendors,
+				ExtraEntries:      cfg.BootEntries,
+			},
+			Repositories: commands.SetupRepositoriesCommand{
 				MountPoint:     "/mnt",
 				EnableMultilib: true,
 				AURHelper:      parseAURHelper(formData.AURHelper),
 				KernelVariant:  parseKernelVariant(formData.KernelVariant),
-			}
-			if _, err := svc.RunRepositorySetup(ctx, repoCmd); err != nil {
-				logger.Error("Repository setup failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
-			}
-
-			// Phase 7: Post-install
-			postCmd := commands.PostInstallCommand{
+				ExtraKernels:   parseKernelVariants(formData.ExtraKernels),
+				// Required and always installed profiles are added by the domain
+				PackageProfiles: formData.PackageProfiles,
+				AURPackages:     formData.AURPackages,
+				Username:        formData.Username,
+			},
+			GPUDrivers: commands.InstallGPUDriversCommand{
+				MountPoint:     "/mnt",
+				GPUVendor:      formData.GPUVendor,
+				Profile:        system.HybridProfile(formData.GPUProfile),
+				Drivers:        formData.GPUDrivers,
+				EnableMultilib: true,
+				KernelVariant:  parseKernelVariant(formData.KernelVariant),
+				ExtraKernels:   parseKernelVariants(formData.ExtraKernels),
+			},
+			CPUPower: cpuPowerCommand(formData),
+			Laptop: commands.ConfigureLaptopCommand{
+				MountPoint:           "/mnt",
+				Enabled:              formData.LaptopProfile,
+				CPUVendor:            parseCPUVendor(formData.CPUVendor),
+				SuspendThenHibernate: formData.SuspendThenHibernate,
+			},
+			// Architecture and firmware mode are filled in from preflight
+			Inventory: commands.WriteInventoryCommand{
+				MountPoint: "/mnt",
+				CPU:        formData.HardwareCPU,
+				GPUs:       formData.HardwareGPUs,
+				Disks:      formData.HardwareDisks,
+			},
+			// Root device is filled in by the post-install phase
+			PostInstall: commands.PostInstallCommand{
 				MountPoint:         "/mnt",
 				Username:           formData.Username,
 				UserEmail:          formData.UserEmail,
@@ -219,17 +218,33 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 				InstallDankLinux:   formData.InstallDankLinux,
 				TargetDisk:         formData.TargetDisk,
 				Encrypted:          isEncrypted,
+				SnapperConfigs:     formData.SnapperConfigs,
+			},
+			Hooks:      hooks,
+			HookPolicy: hookPolicy,
+		}
+		if cfg.PacmanCache != "" {
+			cache, err := packages.NewPacmanCache(cfg.PacmanCache, cfg.KeepPacmanCache)
+			if err != nil {
+				logger.Error("Invalid pacman cache", "error", err)
+				return InstallationErrorMsg{Err: err}
 			}
-			if _, err := svc.RunPostInstall(ctx, postCmd); err != nil {
-				logger.Error("Post-installation failed", "error", err)
-				if program != nil {
-					program.Send(InstallationErrorMsg{Err: err})
-				}
-				return
+			plan.Base.PacmanCache = cache
+			plan.Repositories.PacmanCache = cache
+			plan.PostInstall.KeepPacmanCache = cache.Keep()
+		}
+		if cfg.OfflineBundle != "" {
+			if plan.Offline, err = bundle.NewBundle(cfg.OfflineBundle); err != nil {
+				logger.Error("Invalid offline bundle", "error", err)
+				return InstallationErrorMsg{Err: err}
 			}
-			// Complete installation
-			if err := svc.Complete(ctx); err != nil {
-				logger.Error("Failed to complete installation", "error", err)
+		}
+		pipeline := svc.NewPipeline(plan)
+
+		// Run installation phases in background goroutine
+		go func() {
+			if err := pipeline.Run(ctx); err != nil {
+				logger.Error("Installation failed", "error", err)
 				if program != nil {
 					program.Send(InstallationErrorMsg{Err: err})
 				}
@@ -246,6 +261,27 @@ func CreateInstallationCommand(app AppContext, formData models.FormData) tea.Cmd
 	}
 }
 
+// loadHooks returns the hooks of the hook directory followed by those of the answer file
+func loadHooks(svc *services.InstallationService, cfg *config.Config) (installation.Hooks, installation.HookFailurePolicy, error) {
+	policy, err := installation.ParseHookFailurePolicy(cfg.HookPolicy)
+	if err != nil {
+		return nil, policy, fmt.Errorf("invalid ARCHUP_HOOK_POLICY: %w", err)
+	}
+
+	var hooks installation.Hooks
+	if cfg.HooksDir != "" {
+		if hooks, err = svc.DiscoverHooks(cfg.HooksDir); err != nil {
+			return nil, policy, err
+		}
+	}
+
+	answerHooks, err := installation.ParseHooks(cfg.Hooks)
+	if err != nil {
+		return nil, policy, fmt.Errorf("invalid ARCHUP_HOOKS: %w", err)
+	}
+	return append(hooks, answerHooks...), policy, nil
+}
+
 // parseKernelVariant converts string to KernelVariant
 func parseKernelVariant(s string) packages.KernelVariant {
 	switch s {
@@ -262,6 +298,15 @@ func parseKernelVariant(s string) packages.KernelVariant {
 	}
 }
 
+// parseKernelVariants converts kernel package names to KernelVariants
+func parseKernelVariants(names []string) []packages.KernelVariant {
+	variants := make([]packages.KernelVariant, 0, len(names))
+	for _, name := range names {
+		variants = append(variants, parseKernelVariant(name))
+	}
+	return variants
+}
+
 // parseAURHelper converts string to AURHelper
 func parseAURHelper(s string) packages.AURHelper {
 	switch s {
@@ -299,3 +344,33 @@ func parseEncryptionType(value string) disk.EncryptionType {
 		return disk.EncryptionTypeLUKS
 	}
 }
+
+// cpuPowerCommand returns the CPU power command of the P-State and power screens
+func cpuPowerCommand(formData models.FormData) commands.ConfigureCPUPowerCommand {
+	cmd := commands.ConfigureCPUPowerCommand{
+		MountPoint: "/mnt",
+		EPP:        system.EPP(formData.CPUEPP),
+		Daemon:     system.PowerDaemon(formData.PowerDaemon),
+	}
+	switch {
+	case formData.IntelPState != "":
+		cmd.Driver = system.PStateDriverIntel
+		cmd.Mode = formData.IntelPState
+	case formData.AMDPState != "":
+		cmd.Driver = system.PStateDriverAMD
+		cmd.Mode = formData.AMDPState
+	}
+	return cmd
+}
+
+// parseCPUVendor converts the detected vendor name ("Intel", "AMD") to the domain vendor
+func parseCPUVendor(vendor string) system.CPUVendor {
+	switch strings.ToLower(vendor) {
+	case "intel":
+		return system.CPUVendorIntel
+	case "amd":
+		return system.CPUVendorAMD
+	default:
+		return system.CPUVendorUnknown
+	}
+}
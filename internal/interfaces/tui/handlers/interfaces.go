@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/bnema/archup/internal/application/services"
+	"github.com/bnema/archup/internal/config"
 	"github.com/bnema/archup/internal/domain/ports"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -20,6 +21,9 @@ type AppContext interface {
 	// GetProgressTracker returns the progress tracker
 	GetProgressTracker() *services.ProgressTracker
 
+	// GetConfig returns the installer configuration (answer file defaults)
+	GetConfig() *config.Config
+
 	// GetContext returns the app context
 	GetContext() context.Context
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/bnema/archup/internal/domain/packages"
 	"github.com/bnema/archup/internal/domain/system"
 	legacysystem "github.com/bnema/archup/internal/system"
 )
@@ -20,7 +21,13 @@ type ScreenChangeMsg struct {
 
 // GPUDetectedMsg is sent when GPU detection completes
 type GPUDetectedMsg struct {
-	GPU *system.GPU
+	GPUs *system.GPUSetup
+}
+
+// LaptopDetectedMsg is sent when chassis detection completes
+type LaptopDetectedMsg struct {
+	Chassis *system.Chassis
+	Err     error
 }
 
 // CPUDetectedMsg is sent when CPU detection completes
@@ -39,3 +46,10 @@ type DisksDetectedMsg struct {
 	Disks []legacysystem.Disk
 	Err   error
 }
+
+// PackageSearchMsg is sent when a package search completes
+type PackageSearchMsg struct {
+	Query   string
+	Results []packages.SearchResult
+	Err     error
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Boot option rows, in display order
+const (
+	BootOptionVerbosity = iota
+	BootOptionMitigations
+	BootOptionIOMMU
+	BootOptionNVIDIAModeset
+	BootOptionExtraParams
+	bootOptionCount
+)
+
+// BootOptionsModelImpl holds the advanced kernel command line state:
+// presets cycled in place plus free-form extra parameters.
+type BootOptionsModelImpl struct {
+	presets bootloader.CmdlinePresets
+	extra   textinput.Model
+	cursor  int
+}
+
+// NewBootOptionsModel creates a new boot options model.
+func NewBootOptionsModel() *BootOptionsModelImpl {
+	extra := createTextInput("Extra parameters", "e.g. amd_pstate=active", "")
+	extra.Width = 60
+
+	return &BootOptionsModelImpl{
+		extra: extra,
+	}
+}
+
+// CursorIndex returns the current row.
+func (bm *BootOptionsModelImpl) CursorIndex() int { return bm.cursor }
+
+// Presets returns the selected presets.
+func (bm *BootOptionsModelImpl) Presets() bootloader.CmdlinePresets { return bm.presets }
+
+// SetPresets replaces the selected presets.
+func (bm *BootOptionsModelImpl) SetPresets(presets bootloader.CmdlinePresets) {
+	bm.presets = presets
+}
+
+// ExtraParams returns the extra parameters as typed.
+func (bm *BootOptionsModelImpl) ExtraParams() string { return bm.extra.Value() }
+
+// SetExtraParams sets the extra parameters.
+func (bm *BootOptionsModelImpl) SetExtraParams(params string) {
+	bm.extra.SetValue(params)
+}
+
+// ExtraInput returns the extra parameters text input for rendering.
+func (bm *BootOptionsModelImpl) ExtraInput() textinput.Model { return bm.extra }
+
+// EditingExtra returns true if the cursor is on the extra parameters input.
+func (bm *BootOptionsModelImpl) EditingExtra() bool {
+	return bm.cursor == BootOptionExtraParams
+}
+
+// MoveUp moves the cursor up.
+func (bm *BootOptionsModelImpl) MoveUp() {
+	if bm.cursor > 0 {
+		bm.cursor--
+	}
+	bm.syncFocus()
+}
+
+// MoveDown moves the cursor down.
+func (bm *BootOptionsModelImpl) MoveDown() {
+	if bm.cursor < bootOptionCount-1 {
+		bm.cursor++
+	}
+	bm.syncFocus()
+}
+
+// Toggle cycles the preset under the cursor.
+func (bm *BootOptionsModelImpl) Toggle() {
+	switch bm.cursor {
+	case BootOptionVerbosity:
+		if bm.presets.Verbosity == bootloader.VerbosityQuiet {
+			bm.presets.Verbosity = bootloader.VerbosityVerbose
+		} else {
+			bm.presets.Verbosity = bootloader.VerbosityQuiet
+		}
+	case BootOptionMitigations:
+		bm.presets.Mitigations = (bm.presets.Mitigations + 1) % (bootloader.MitigationsOff + 1)
+	case BootOptionIOMMU:
+		bm.presets.IOMMU = !bm.presets.IOMMU
+	case BootOptionNVIDIAModeset:
+		bm.presets.NVIDIAModeset = !bm.presets.NVIDIAModeset
+	}
+}
+
+// Update forwards input to the extra parameters field.
+func (bm *BootOptionsModelImpl) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	bm.extra, cmd = bm.extra.Update(msg)
+	return cmd
+}
+
+// Preview returns the command line produced by the presets and extra
+// parameters, without the root parameters which depend on the disk layout.
+func (bm *BootOptionsModelImpl) Preview() (string, error) {
+	cmdline, err := bootloader.NewKernelCmdline()
+	if err != nil {
+		return "", err
+	}
+
+	cmdline, err = cmdline.ApplyPresets(bm.presets)
+	if err != nil {
+		return "", err
+	}
+
+	extra, err := bootloader.ParseKernelCmdline(bm.extra.Value())
+	if err != nil {
+		return "", err
+	}
+
+	return cmdline.Merge(extra).Render(bootloader.CmdlineTargetLimine), nil
+}
+
+func (bm *BootOptionsModelImpl) syncFocus() {
+	if bm.EditingExtra() {
+		bm.extra.Focus()
+	} else {
+		bm.extra.Blur()
+	}
+}
@@ -3,6 +3,9 @@ package models
 import (
 	"strings"
 
+	"github.com/bnema/archup/internal/domain/bootloader"
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"github.com/bnema/archup/internal/domain/system"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -22,24 +25,43 @@ func (bm *BaseModel) SetSize(width, height int) {
 
 // FormData contains the data from the form
 type FormData struct {
-	Hostname          string
-	Username          string
-	UserEmail         string
-	UserPassword      string
-	RootPassword      string
-	TargetDisk        string
-	EncryptionType    string
-	AMDPState         string
-	KernelParamsExtra string
-	GPUVendor         string
-	GPUDrivers        []string
-	Timezone          string
-	Locale            string
-	Keymap            string
-	KernelVariant     string
-	AURHelper         string
-	Microcode         bool
-	InstallDankLinux  bool
+	Hostname             string
+	Username             string
+	UserEmail            string
+	UserPassword         string
+	RootPassword         string
+	TargetDisk           string
+	EncryptionType       string
+	AMDPState            string
+	IntelPState          string
+	PowerDaemon          string // Power daemon, power-profiles-daemon when empty
+	CPUEPP               string // Energy performance preference default, empty for the firmware default
+	CPUVendor            string // "Intel", "AMD" or empty when unknown
+	LaptopProfile        bool   // Install the laptop packages and configure lid handling
+	SuspendThenHibernate bool   // Hibernate after a delay in suspend on lid close
+	KernelParamsExtra    string
+	CmdlinePresets       bootloader.CmdlinePresets
+	SnapperConfigs       []*snapshot.SnapperConfig
+	GPUVendor            string   // Vendor of the GPU driving the display
+	GPUVendors           []string // Vendors of every GPU used, GPUVendor first
+	GPUProfile           string   // Hybrid graphics profile, empty on single GPU systems
+	GPUDrivers           []string
+	Timezone             string
+	Locale               string
+	Keymap               string
+	KernelVariant        string
+	ExtraKernels         []string
+	AURHelper            string
+	PackageProfiles      []string // Selected package profiles, required ones are added at install
+	Packages             []string // Extra packages from the official repositories
+	AURPackages          []string // AUR packages built as the user
+	Microcode            bool
+	InstallDankLinux     bool
+
+	// Detected hardware, for the hardware inventory
+	HardwareCPU   system.InventoryCPU
+	HardwareGPUs  []system.InventoryGPU
+	HardwareDisks []system.InventoryDisk
 }
 
 // FormModelImpl implements FormModel interface
@@ -299,19 +321,25 @@ func (fm *FormModelImpl) extractData() {
 	// Use fm.fields slice values, not the original fields (which are copies)
 	// Field order: hostname(0), username(1), email(2), password(3), timezone(4), locale(5), keymap(6)
 	fm.data = FormData{
-		Hostname:          fm.fields[0].Value(),
-		Username:          fm.fields[1].Value(),
-		UserEmail:         fm.fields[2].Value(), // Optional - for git config
-		UserPassword:      fm.fields[3].Value(),
-		RootPassword:      "", // No root password - root account locked, user is sudoer
-		TargetDisk:        fm.targetDisk.Value(),
-		EncryptionType:    fm.data.EncryptionType,
-		AMDPState:         fm.data.AMDPState,
-		KernelParamsExtra: fm.data.KernelParamsExtra,
-		Timezone:          fm.fields[4].Value(),
-		Locale:            fm.fields[5].Value(),
-		Keymap:            fm.fields[6].Value(),
-		InstallDankLinux:  fm.data.InstallDankLinux,
+		Hostname:             fm.fields[0].Value(),
+		Username:             fm.fields[1].Value(),
+		UserEmail:            fm.fields[2].Value(), // Optional - for git config
+		UserPassword:         fm.fields[3].Value(),
+		RootPassword:         "", // No root password - root account locked, user is sudoer
+		TargetDisk:           fm.targetDisk.Value(),
+		EncryptionType:       fm.data.EncryptionType,
+		AMDPState:            fm.data.AMDPState,
+		IntelPState:          fm.data.IntelPState,
+		PowerDaemon:          fm.data.PowerDaemon,
+		CPUEPP:               fm.data.CPUEPP,
+		CPUVendor:            fm.data.CPUVendor,
+		LaptopProfile:        fm.data.LaptopProfile,
+		SuspendThenHibernate: fm.data.SuspendThenHibernate,
+		KernelParamsExtra:    fm.data.KernelParamsExtra,
+		Timezone:             fm.fields[4].Value(),
+		Locale:               fm.fields[5].Value(),
+		Keymap:               fm.fields[6].Value(),
+		InstallDankLinux:     fm.data.InstallDankLinux,
 	}
 }
 
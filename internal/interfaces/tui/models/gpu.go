@@ -9,19 +9,30 @@ import (
 // GPUOption represents a selectable GPU driver option.
 type GPUOption struct {
 	Label   string
-	Vendor  system.GPUVendor
+	Vendor  system.GPUVendor   // Vendor of the GPU driving the display
+	Vendors []system.GPUVendor // Vendors of every GPU used, Vendor first
+	Profile system.HybridProfile
 	Drivers []string
+	Reason  string // Why the drivers were chosen, e.g. the NVIDIA generation
 }
 
 // GPUModelImpl holds GPU selection state.
 type GPUModelImpl struct {
-	detected *system.GPU
+	detected *system.GPUSetup
 	options  []GPUOption
 	selected int
 }
 
 // NewGPUModel creates a new GPU selection model.
 func NewGPUModel() *GPUModelImpl {
+	return &GPUModelImpl{
+		options:  vendorOptions(),
+		selected: 0,
+	}
+}
+
+// vendorOptions returns the single GPU options, one per vendor.
+func vendorOptions() []GPUOption {
 	options := []GPUOption{
 		{
 			Label:   "AMD",
@@ -34,7 +45,7 @@ func NewGPUModel() *GPUModelImpl {
 			Drivers: []string{"mesa", "vulkan-intel", "intel-media-driver"},
 		},
 		{
-			Label:   "NVIDIA (open)",
+			Label:   "NVIDIA",
 			Vendor:  system.GPUVendorNVIDIA,
 			Drivers: []string{"nvidia-open", "nvidia-utils", "libva-nvidia-driver"},
 		},
@@ -44,19 +55,65 @@ func NewGPUModel() *GPUModelImpl {
 			Drivers: []string{},
 		},
 	}
+	for i := range options {
+		options[i].Vendors = []system.GPUVendor{options[i].Vendor}
+	}
+	return options
+}
 
-	return &GPUModelImpl{
-		options:  options,
-		selected: 0,
+// hybridOptions returns one option per hybrid profile of a hybrid setup.
+func hybridOptions(setup *system.GPUSetup) []GPUOption {
+	options := make([]GPUOption, 0, len(system.HybridProfiles()))
+	for _, profile := range system.HybridProfiles() {
+		gpus := setup.ProfileGPUs(profile)
+		vendors := make([]system.GPUVendor, 0, len(gpus))
+		names := make([]string, 0, len(gpus))
+		for _, gpu := range gpus {
+			vendors = append(vendors, gpu.Vendor())
+			names = append(names, gpu.Vendor().String())
+		}
+		reason := ""
+		for _, gpu := range gpus {
+			if gpu.DriverReason() != "" {
+				reason = gpu.DriverReason()
+			}
+		}
+		options = append(options, GPUOption{
+			Label:   "Hybrid: " + profile.String() + " (" + strings.Join(names, " + ") + ")",
+			Vendor:  vendors[0],
+			Vendors: vendors,
+			Profile: profile,
+			Drivers: setup.ProfileDrivers(profile),
+			Reason:  reason,
+		})
 	}
+	return options
 }
 
-// SetDetectedGPU sets the detected GPU and aligns selection.
-func (gm *GPUModelImpl) SetDetectedGPU(gpu *system.GPU) {
-	gm.detected = gpu
-	if gpu == nil {
+// SetDetectedGPUs sets the detected GPUs and aligns selection. Hybrid
+// setups get their profiles on top, PRIME render offload selected.
+func (gm *GPUModelImpl) SetDetectedGPUs(setup *system.GPUSetup) {
+	gm.detected = setup
+	gm.options = vendorOptions()
+	gm.selected = 0
+	if setup == nil {
+		return
+	}
+	// Drivers chosen for the detected model (NVIDIA generation) replace the vendor defaults
+	for _, gpu := range setup.GPUs() {
+		for i := range gm.options {
+			if gm.options[i].Vendor == gpu.Vendor() && gpu.DriverReason() != "" {
+				gm.options[i].Drivers = gpu.Drivers()
+				gm.options[i].Reason = gpu.DriverReason()
+			}
+		}
+	}
+	if setup.IsHybrid() {
+		gm.options = append(hybridOptions(setup), gm.options...)
 		return
 	}
+
+	gpu := setup.Primary()
 	if isVirtualGPUModel(gpu.Model()) || gpu.Vendor() == system.GPUVendorUnknown {
 		gm.selectUnknown()
 		return
@@ -98,8 +155,8 @@ func isVirtualGPUModel(model string) bool {
 	return false
 }
 
-// DetectedGPU returns the detected GPU.
-func (gm *GPUModelImpl) DetectedGPU() *system.GPU {
+// DetectedGPUs returns the detected GPUs.
+func (gm *GPUModelImpl) DetectedGPUs() *system.GPUSetup {
 	return gm.detected
 }
 
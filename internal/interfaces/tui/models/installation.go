@@ -18,6 +18,11 @@ type InstallationModelImpl struct {
 	err      string
 	notice   string
 	complete bool
+
+	// Rollback after a failed or cancelled installation
+	rollingBack    bool
+	rolledBack     []string
+	rollbackErrors []string
 }
 
 // NewInstallationModel creates a new installation model
@@ -89,6 +94,17 @@ func (im *InstallationModelImpl) SetError(message string) {
 	im.err = message
 }
 
+// SetRollingBack marks the completed phases as being rolled back
+func (im *InstallationModelImpl) SetRollingBack(rollingBack bool) {
+	im.rollingBack = rollingBack
+}
+
+// SetRollback sets the phases rolled back and the rollback failures
+func (im *InstallationModelImpl) SetRollback(rolledBack, rollbackErrors []string) {
+	im.rolledBack = rolledBack
+	im.rollbackErrors = rollbackErrors
+}
+
 func (im *InstallationModelImpl) SetNotice(message string) {
 	im.notice = message
 }
@@ -112,6 +128,21 @@ func (im *InstallationModelImpl) GetNotice() string {
 	return im.notice
 }
 
+// IsRollingBack returns whether completed phases are being rolled back
+func (im *InstallationModelImpl) IsRollingBack() bool {
+	return im.rollingBack
+}
+
+// GetRolledBack returns the phases rolled back, latest first
+func (im *InstallationModelImpl) GetRolledBack() []string {
+	return im.rolledBack
+}
+
+// GetRollbackErrors returns the phases whose rollback failed
+func (im *InstallationModelImpl) GetRollbackErrors() []string {
+	return im.rollbackErrors
+}
+
 // IsComplete returns whether the installation is complete
 func (im *InstallationModelImpl) IsComplete() bool {
 	return im.complete
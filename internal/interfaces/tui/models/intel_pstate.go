@@ -0,0 +1,122 @@
+package models
+
+import legacysystem "github.com/bnema/archup/internal/system"
+
+// IntelPStateOption represents a selectable intel_pstate mode.
+type IntelPStateOption struct {
+	Mode        legacysystem.IntelPStateMode
+	Label       string
+	Recommended bool
+}
+
+// IntelPStateModelImpl holds intel_pstate selection state.
+type IntelPStateModelImpl struct {
+	cpuInfo  *legacysystem.CPUInfo
+	options  []IntelPStateOption
+	selected int
+}
+
+// NewIntelPStateModel creates a new intel_pstate selection model.
+func NewIntelPStateModel() *IntelPStateModelImpl {
+	return &IntelPStateModelImpl{
+		options:  []IntelPStateOption{},
+		selected: 0,
+	}
+}
+
+// SetCPUInfo sets CPU info and rebuilds options.
+func (im *IntelPStateModelImpl) SetCPUInfo(info *legacysystem.CPUInfo) {
+	im.cpuInfo = info
+	im.options = []IntelPStateOption{}
+	im.selected = 0
+
+	if info == nil {
+		return
+	}
+
+	for _, mode := range info.IntelPStateModes {
+		label := string(mode)
+		if desc := legacysystem.GetIntelPStateModeDescription(mode); desc != "" {
+			label = label + " - " + desc
+		}
+		im.options = append(im.options, IntelPStateOption{
+			Mode:        mode,
+			Label:       label,
+			Recommended: mode == info.RecommendedIntelPStateMode,
+		})
+	}
+
+	for i, option := range im.options {
+		if option.Recommended {
+			im.selected = i
+			break
+		}
+	}
+}
+
+// SetSelectedMode selects the option of a mode, e.g. from the answer file.
+func (im *IntelPStateModelImpl) SetSelectedMode(mode legacysystem.IntelPStateMode) {
+	for i, option := range im.options {
+		if option.Mode == mode {
+			im.selected = i
+			return
+		}
+	}
+}
+
+// CPUInfo returns detected CPU info.
+func (im *IntelPStateModelImpl) CPUInfo() *legacysystem.CPUInfo {
+	return im.cpuInfo
+}
+
+// Options returns the selectable options.
+func (im *IntelPStateModelImpl) Options() []IntelPStateOption {
+	return im.options
+}
+
+// SelectedIndex returns the current selection index.
+func (im *IntelPStateModelImpl) SelectedIndex() int {
+	return im.selected
+}
+
+// SelectedOption returns the currently selected option.
+func (im *IntelPStateModelImpl) SelectedOption() IntelPStateOption {
+	if len(im.options) == 0 {
+		return IntelPStateOption{}
+	}
+	if im.selected < 0 || im.selected >= len(im.options) {
+		return im.options[0]
+	}
+	return im.options[im.selected]
+}
+
+// MoveUp moves selection up.
+func (im *IntelPStateModelImpl) MoveUp() {
+	if len(im.options) == 0 {
+		return
+	}
+	if im.selected == 0 {
+		im.selected = len(im.options) - 1
+		return
+	}
+	im.selected--
+}
+
+// MoveDown moves selection down.
+func (im *IntelPStateModelImpl) MoveDown() {
+	if len(im.options) == 0 {
+		return
+	}
+	im.selected = (im.selected + 1) % len(im.options)
+}
+
+// ShouldPrompt returns true if intel_pstate selection should be shown.
+func (im *IntelPStateModelImpl) ShouldPrompt() bool {
+	if im.cpuInfo == nil {
+		return false
+	}
+	if im.cpuInfo.Vendor != legacysystem.CPUVendorIntel {
+		return false
+	}
+	return len(im.options) > 0
+}
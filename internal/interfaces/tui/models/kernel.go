@@ -10,10 +10,12 @@ type KernelOption struct {
 	Recommended bool
 }
 
-// KernelModelImpl holds kernel selection state.
+// KernelModelImpl holds kernel selection state: the primary kernel under the
+// cursor plus extra kernels installed as boot fallbacks.
 type KernelModelImpl struct {
 	options  []KernelOption
 	selected int
+	extras   map[packages.KernelVariant]bool
 }
 
 // NewKernelModel creates a new kernel selection model.
@@ -58,6 +60,8 @@ func NewKernelModel() *KernelModelImpl {
 	return &KernelModelImpl{
 		options:  options,
 		selected: selected,
+		// linux-lts is kept as rescue kernel by default
+		extras: map[packages.KernelVariant]bool{packages.KernelLTS: true},
 	}
 }
 
@@ -114,3 +118,47 @@ func (km *KernelModelImpl) SetSelectedPackage(pkg string) {
 		}
 	}
 }
+
+// IsExtra returns true if the option is installed as an extra kernel.
+// The primary kernel is never reported as extra.
+func (km *KernelModelImpl) IsExtra(index int) bool {
+	if index < 0 || index >= len(km.options) || index == km.selected {
+		return false
+	}
+	return km.extras[km.options[index].Variant]
+}
+
+// ToggleExtra toggles the kernel under the cursor as an extra kernel.
+func (km *KernelModelImpl) ToggleExtra() {
+	if len(km.options) == 0 {
+		return
+	}
+	variant := km.SelectedOption().Variant
+	km.extras[variant] = !km.extras[variant]
+}
+
+// ExtraPackages returns the package names of the extra kernels, excluding the primary kernel.
+func (km *KernelModelImpl) ExtraPackages() []string {
+	extras := []string{}
+	for i, option := range km.options {
+		if km.IsExtra(i) {
+			extras = append(extras, option.Package)
+		}
+	}
+	return extras
+}
+
+// SetExtraPackages marks the given kernels as extra kernels.
+func (km *KernelModelImpl) SetExtraPackages(pkgs []string) {
+	if pkgs == nil {
+		return
+	}
+	km.extras = map[packages.KernelVariant]bool{}
+	for _, pkg := range pkgs {
+		for _, option := range km.options {
+			if option.Package == pkg {
+				km.extras[option.Variant] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/system"
+)
+
+// Laptop profile answer file values
+const (
+	LaptopProfileOn        = "on"
+	LaptopProfileHibernate = "hibernate"
+	LaptopProfileOff       = "off"
+)
+
+// LaptopOption represents a selectable laptop profile.
+type LaptopOption struct {
+	Key       string
+	Label     string
+	Enabled   bool
+	Hibernate bool
+}
+
+// LaptopModelImpl holds laptop profile selection state.
+type LaptopModelImpl struct {
+	chassis  *system.Chassis
+	options  []LaptopOption
+	selected int
+}
+
+// NewLaptopModel creates a new laptop profile model.
+func NewLaptopModel() *LaptopModelImpl {
+	return &LaptopModelImpl{
+		options:  []LaptopOption{},
+		selected: 0,
+	}
+}
+
+// SetChassis sets the detected chassis and rebuilds options. Intel CPUs
+// also get thermald.
+func (lm *LaptopModelImpl) SetChassis(chassis *system.Chassis, vendor system.CPUVendor) {
+	lm.chassis = chassis
+	lm.selected = 0
+
+	pkgs := strings.Join(system.LaptopPackages(vendor), ", ")
+	lm.options = []LaptopOption{
+		{Key: LaptopProfileOn, Label: "Laptop profile - " + pkgs + ", suspend on lid close", Enabled: true},
+		{Key: LaptopProfileHibernate, Label: "Laptop profile with suspend-then-hibernate - needs a disk swap in fstab", Enabled: true, Hibernate: true},
+		{Key: LaptopProfileOff, Label: "No laptop profile"},
+	}
+}
+
+// SetSelectedKey selects the option of an answer file value.
+func (lm *LaptopModelImpl) SetSelectedKey(key string) {
+	for i, option := range lm.options {
+		if option.Key == key {
+			lm.selected = i
+			return
+		}
+	}
+}
+
+// Chassis returns the detected chassis.
+func (lm *LaptopModelImpl) Chassis() *system.Chassis {
+	return lm.chassis
+}
+
+// Options returns the selectable options.
+func (lm *LaptopModelImpl) Options() []LaptopOption {
+	return lm.options
+}
+
+// SelectedIndex returns the current selection index.
+func (lm *LaptopModelImpl) SelectedIndex() int {
+	return lm.selected
+}
+
+// SelectedOption returns the currently selected option.
+func (lm *LaptopModelImpl) SelectedOption() LaptopOption {
+	if len(lm.options) == 0 {
+		return LaptopOption{}
+	}
+	if lm.selected < 0 || lm.selected >= len(lm.options) {
+		return lm.options[0]
+	}
+	return lm.options[lm.selected]
+}
+
+// MoveUp moves selection up.
+func (lm *LaptopModelImpl) MoveUp() {
+	if len(lm.options) == 0 {
+		return
+	}
+	if lm.selected == 0 {
+		lm.selected = len(lm.options) - 1
+		return
+	}
+	lm.selected--
+}
+
+// MoveDown moves selection down.
+func (lm *LaptopModelImpl) MoveDown() {
+	if len(lm.options) == 0 {
+		return
+	}
+	lm.selected = (lm.selected + 1) % len(lm.options)
+}
+
+// ShouldPrompt returns true if the machine was detected as a laptop.
+func (lm *LaptopModelImpl) ShouldPrompt() bool {
+	return lm.chassis != nil && lm.chassis.IsLaptop()
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"slices"
+
+	"github.com/bnema/archup/internal/domain/packages"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PackagePickerModelImpl holds the extra package selection state: a search
+// of the host sync databases and the packages picked from its results.
+// AUR packages come from the answer file and are only listed.
+type PackagePickerModelImpl struct {
+	query       textinput.Model
+	results     []packages.SearchResult
+	selected    []string
+	aurPackages []string
+	cursor      int
+	searching   bool
+	searched    string // Query of the results, empty before the first search
+	err         string
+}
+
+// NewPackagePickerModel creates a new package picker model.
+func NewPackagePickerModel() *PackagePickerModelImpl {
+	query := createTextInput("Search", "e.g. htop", "")
+	query.Width = 40
+	query.Focus()
+
+	return &PackagePickerModelImpl{
+		query: query,
+	}
+}
+
+// QueryInput returns the search text input for rendering.
+func (pm *PackagePickerModelImpl) QueryInput() textinput.Model { return pm.query }
+
+// Query returns the search as typed.
+func (pm *PackagePickerModelImpl) Query() string { return pm.query.Value() }
+
+// EditingQuery returns true if keys go to the search input.
+func (pm *PackagePickerModelImpl) EditingQuery() bool { return pm.query.Focused() }
+
+// FocusQuery moves the keys to the search input.
+func (pm *PackagePickerModelImpl) FocusQuery() { pm.query.Focus() }
+
+// FocusResults moves the keys to the search results.
+func (pm *PackagePickerModelImpl) FocusResults() {
+	if len(pm.results) > 0 {
+		pm.query.Blur()
+	}
+}
+
+// Update forwards input to the search field.
+func (pm *PackagePickerModelImpl) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	pm.query, cmd = pm.query.Update(msg)
+	return cmd
+}
+
+// StartSearch marks a search as running.
+func (pm *PackagePickerModelImpl) StartSearch() {
+	pm.searching = true
+	pm.err = ""
+}
+
+// Searching returns true while a search runs.
+func (pm *PackagePickerModelImpl) Searching() bool { return pm.searching }
+
+// SetResults records the results of the search for query.
+func (pm *PackagePickerModelImpl) SetResults(query string, results []packages.SearchResult, err error) {
+	pm.searching = false
+	pm.searched = query
+	pm.results = results
+	pm.cursor = 0
+	pm.err = ""
+	if err != nil {
+		pm.err = err.Error()
+	}
+	pm.FocusResults()
+}
+
+// Results returns the results of the last search.
+func (pm *PackagePickerModelImpl) Results() []packages.SearchResult { return pm.results }
+
+// Searched returns the query of the results, empty before the first search.
+func (pm *PackagePickerModelImpl) Searched() string { return pm.searched }
+
+// Error returns why the last search failed.
+func (pm *PackagePickerModelImpl) Error() string { return pm.err }
+
+// CursorIndex returns the result under the cursor.
+func (pm *PackagePickerModelImpl) CursorIndex() int { return pm.cursor }
+
+// MoveUp moves the cursor up.
+func (pm *PackagePickerModelImpl) MoveUp() {
+	if pm.cursor > 0 {
+		pm.cursor--
+	}
+}
+
+// MoveDown moves the cursor down.
+func (pm *PackagePickerModelImpl) MoveDown() {
+	if pm.cursor < len(pm.results)-1 {
+		pm.cursor++
+	}
+}
+
+// IsSelected returns true if the package is picked.
+func (pm *PackagePickerModelImpl) IsSelected(name string) bool {
+	return slices.Contains(pm.selected, name)
+}
+
+// Toggle picks or drops the result under the cursor.
+func (pm *PackagePickerModelImpl) Toggle() {
+	if pm.cursor >= len(pm.results) {
+		return
+	}
+	name := pm.results[pm.cursor].Name
+	if i := slices.Index(pm.selected, name); i >= 0 {
+		pm.selected = slices.Delete(pm.selected, i, i+1)
+		return
+	}
+	pm.selected = append(pm.selected, name)
+}
+
+// Selected returns the picked packages, in picking order.
+func (pm *PackagePickerModelImpl) Selected() []string { return append([]string{}, pm.selected...) }
+
+// SetSelected replaces the picked packages.
+func (pm *PackagePickerModelImpl) SetSelected(names []string) {
+	pm.selected = append([]string{}, names...)
+}
+
+// AURPackages returns the AUR packages of the answer file.
+func (pm *PackagePickerModelImpl) AURPackages() []string { return pm.aurPackages }
+
+// SetAURPackages sets the AUR packages of the answer file.
+func (pm *PackagePickerModelImpl) SetAURPackages(names []string) {
+	pm.aurPackages = append([]string{}, names...)
+}
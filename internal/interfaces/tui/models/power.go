@@ -0,0 +1,99 @@
+package models
+
+import "github.com/bnema/archup/internal/domain/system"
+
+// Power option rows, in display order
+const (
+	PowerOptionDaemon = iota
+	PowerOptionEPP
+	powerOptionCount
+)
+
+// PowerModelImpl holds the power daemon and EPP default selection, both
+// cycled in place.
+type PowerModelImpl struct {
+	daemons      []system.PowerDaemon
+	epps         []system.EPP
+	daemon       int
+	epp          int
+	eppAvailable bool
+	cursor       int
+}
+
+// NewPowerModel creates a new power model, power-profiles-daemon and the
+// firmware EPP default selected.
+func NewPowerModel() *PowerModelImpl {
+	return &PowerModelImpl{
+		daemons: system.PowerDaemons(),
+		epps:    system.EPPs(),
+	}
+}
+
+// CursorIndex returns the current row.
+func (pm *PowerModelImpl) CursorIndex() int { return pm.cursor }
+
+// Daemon returns the selected power daemon.
+func (pm *PowerModelImpl) Daemon() system.PowerDaemon { return pm.daemons[pm.daemon] }
+
+// SetDaemon selects a power daemon.
+func (pm *PowerModelImpl) SetDaemon(daemon system.PowerDaemon) {
+	for i, d := range pm.daemons {
+		if d == daemon {
+			pm.daemon = i
+			return
+		}
+	}
+}
+
+// EPP returns the selected EPP default, EPPDefault when the CPU has no EPP.
+func (pm *PowerModelImpl) EPP() system.EPP {
+	if !pm.eppAvailable {
+		return system.EPPDefault
+	}
+	return pm.epps[pm.epp]
+}
+
+// SetEPP selects an EPP default.
+func (pm *PowerModelImpl) SetEPP(epp system.EPP) {
+	for i, e := range pm.epps {
+		if e == epp {
+			pm.epp = i
+			return
+		}
+	}
+}
+
+// EPPAvailable returns true if the CPU takes EPP hints in the selected P-State mode.
+func (pm *PowerModelImpl) EPPAvailable() bool { return pm.eppAvailable }
+
+// SetEPPAvailable shows or hides the EPP row.
+func (pm *PowerModelImpl) SetEPPAvailable(available bool) {
+	pm.eppAvailable = available
+	if !available {
+		pm.cursor = PowerOptionDaemon
+	}
+}
+
+// MoveUp moves the cursor up.
+func (pm *PowerModelImpl) MoveUp() {
+	if pm.cursor > 0 {
+		pm.cursor--
+	}
+}
+
+// MoveDown moves the cursor down.
+func (pm *PowerModelImpl) MoveDown() {
+	if pm.eppAvailable && pm.cursor < powerOptionCount-1 {
+		pm.cursor++
+	}
+}
+
+// Toggle cycles the value under the cursor.
+func (pm *PowerModelImpl) Toggle() {
+	switch pm.cursor {
+	case PowerOptionDaemon:
+		pm.daemon = (pm.daemon + 1) % len(pm.daemons)
+	case PowerOptionEPP:
+		pm.epp = (pm.epp + 1) % len(pm.epps)
+	}
+}
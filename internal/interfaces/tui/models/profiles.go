@@ -0,0 +1,105 @@
+package models
+
+import "github.com/bnema/archup/internal/domain/packages"
+
+// ProfilesModelImpl holds the package profile selection state.
+// Profiles installed with every selection are not offered.
+type ProfilesModelImpl struct {
+	catalog  *packages.ProfileCatalog
+	options  []*packages.PackageProfile
+	selected map[string]bool
+	cursor   int
+	err      string // Why the selection cannot be installed, e.g. a conflict
+}
+
+// NewProfilesModel creates a new profile selection model, the default
+// profiles of the catalog selected.
+func NewProfilesModel(catalog *packages.ProfileCatalog) *ProfilesModelImpl {
+	pm := &ProfilesModelImpl{
+		catalog:  catalog,
+		selected: map[string]bool{},
+	}
+	if catalog != nil {
+		pm.options = catalog.Selectable()
+		pm.SetSelected(catalog.DefaultSelection())
+	}
+	return pm
+}
+
+// Options returns the selectable profiles.
+func (pm *ProfilesModelImpl) Options() []*packages.PackageProfile { return pm.options }
+
+// CursorIndex returns the current cursor position.
+func (pm *ProfilesModelImpl) CursorIndex() int { return pm.cursor }
+
+// IsSelected returns true if the profile at index is selected.
+func (pm *ProfilesModelImpl) IsSelected(index int) bool {
+	if index < 0 || index >= len(pm.options) {
+		return false
+	}
+	return pm.selected[pm.options[index].Name()]
+}
+
+// Error returns why the selection cannot be installed, empty when it can.
+func (pm *ProfilesModelImpl) Error() string { return pm.err }
+
+// Selected returns the names of the selected profiles, in catalog order.
+func (pm *ProfilesModelImpl) Selected() []string {
+	names := []string{}
+	for _, option := range pm.options {
+		if pm.selected[option.Name()] {
+			names = append(names, option.Name())
+		}
+	}
+	return names
+}
+
+// SetSelected replaces the selection, unknown profiles are ignored.
+func (pm *ProfilesModelImpl) SetSelected(names []string) {
+	if names == nil {
+		return
+	}
+	pm.selected = map[string]bool{}
+	for _, name := range names {
+		pm.selected[name] = true
+	}
+	pm.err = ""
+}
+
+// MoveUp moves the cursor up.
+func (pm *ProfilesModelImpl) MoveUp() {
+	if pm.cursor > 0 {
+		pm.cursor--
+	}
+}
+
+// MoveDown moves the cursor down.
+func (pm *ProfilesModelImpl) MoveDown() {
+	if pm.cursor < len(pm.options)-1 {
+		pm.cursor++
+	}
+}
+
+// Toggle selects or deselects the profile under the cursor.
+func (pm *ProfilesModelImpl) Toggle() {
+	if pm.cursor < len(pm.options) {
+		name := pm.options[pm.cursor].Name()
+		pm.selected[name] = !pm.selected[name]
+		pm.err = ""
+	}
+}
+
+// Resolve returns the selection with the required and always installed
+// profiles, recording the error when the selection conflicts.
+func (pm *ProfilesModelImpl) Resolve() (*packages.ProfileSelection, error) {
+	if pm.catalog == nil {
+		return nil, nil
+	}
+	selection, err := pm.catalog.Resolve(pm.Selected())
+	if err != nil {
+		pm.err = err.Error()
+		return nil, err
+	}
+	pm.err = ""
+	return selection, nil
+}
@@ -0,0 +1,81 @@
+package models
+
+import "github.com/bnema/archup/internal/domain/snapshot"
+
+// RollbackModelImpl holds snapshot selection state for archup rollback:
+// the snapshot under the cursor, the rollback mode and the confirmation step.
+type RollbackModelImpl struct {
+	snapshots  snapshot.List
+	selected   int
+	mode       snapshot.RollbackMode
+	confirming bool
+}
+
+// NewRollbackModel creates a rollback model with the newest snapshot selected.
+func NewRollbackModel(snapshots snapshot.List, mode snapshot.RollbackMode) *RollbackModelImpl {
+	selected := 0
+	if len(snapshots) > 0 {
+		selected = len(snapshots) - 1
+	}
+	return &RollbackModelImpl{
+		snapshots: snapshots,
+		selected:  selected,
+		mode:      mode,
+	}
+}
+
+// Snapshots returns the selectable snapshots, oldest first.
+func (rm *RollbackModelImpl) Snapshots() snapshot.List {
+	return rm.snapshots
+}
+
+// SelectedIndex returns the current selection index.
+func (rm *RollbackModelImpl) SelectedIndex() int {
+	return rm.selected
+}
+
+// SelectedSnapshot returns the snapshot under the cursor.
+func (rm *RollbackModelImpl) SelectedSnapshot() (snapshot.Snapshot, bool) {
+	if len(rm.snapshots) == 0 {
+		return snapshot.Snapshot{}, false
+	}
+	return rm.snapshots[rm.selected], true
+}
+
+// MoveUp moves selection up.
+func (rm *RollbackModelImpl) MoveUp() {
+	if rm.selected > 0 {
+		rm.selected--
+	}
+}
+
+// MoveDown moves selection down.
+func (rm *RollbackModelImpl) MoveDown() {
+	if rm.selected < len(rm.snapshots)-1 {
+		rm.selected++
+	}
+}
+
+// Mode returns the rollback mode.
+func (rm *RollbackModelImpl) Mode() snapshot.RollbackMode {
+	return rm.mode
+}
+
+// ToggleMode switches between replacing @ and setting the default subvolume.
+func (rm *RollbackModelImpl) ToggleMode() {
+	if rm.mode == snapshot.RollbackNewRoot {
+		rm.mode = snapshot.RollbackSetDefault
+	} else {
+		rm.mode = snapshot.RollbackNewRoot
+	}
+}
+
+// Confirming returns true while the rollback waits for confirmation.
+func (rm *RollbackModelImpl) Confirming() bool {
+	return rm.confirming
+}
+
+// SetConfirming enters or leaves the confirmation step.
+func (rm *RollbackModelImpl) SetConfirming(confirming bool) {
+	rm.confirming = confirming && len(rm.snapshots) > 0
+}
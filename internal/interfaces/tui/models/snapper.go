@@ -0,0 +1,149 @@
+package models
+
+import "github.com/bnema/archup/internal/domain/snapshot"
+
+// Snapper rows of each config, in display order
+const (
+	SnapperRowEnabled = iota
+	SnapperRowTimeline
+	SnapperRowHourly
+	SnapperRowDaily
+	SnapperRowWeekly
+	SnapperRowMonthly
+	SnapperRowYearly
+	SnapperRowNumber
+	SnapperRowImportant
+	snapperRowCount
+)
+
+// SnapperModelImpl holds the snapper config state: which configs are
+// created, timeline on/off and the retention limits of each.
+type SnapperModelImpl struct {
+	configs []*snapshot.SnapperConfig
+	enabled []bool
+	cursor  int
+}
+
+// NewSnapperModel creates a snapper model with the default root and home configs.
+func NewSnapperModel() *SnapperModelImpl {
+	sm := &SnapperModelImpl{}
+	sm.SetConfigs(snapshot.DefaultSnapperConfigs())
+	return sm
+}
+
+// SetConfigs replaces the configs, all enabled.
+func (sm *SnapperModelImpl) SetConfigs(configs []*snapshot.SnapperConfig) {
+	sm.configs = configs
+	sm.enabled = make([]bool, len(configs))
+	for i := range sm.enabled {
+		sm.enabled[i] = true
+	}
+	sm.cursor = 0
+}
+
+// AllConfigs returns every config, enabled or not.
+func (sm *SnapperModelImpl) AllConfigs() []*snapshot.SnapperConfig { return sm.configs }
+
+// IsEnabled returns true if the config at index is created.
+func (sm *SnapperModelImpl) IsEnabled(index int) bool {
+	return index >= 0 && index < len(sm.enabled) && sm.enabled[index]
+}
+
+// Configs returns the enabled configs.
+func (sm *SnapperModelImpl) Configs() []*snapshot.SnapperConfig {
+	var configs []*snapshot.SnapperConfig
+	for i, c := range sm.configs {
+		if sm.enabled[i] {
+			configs = append(configs, c)
+		}
+	}
+	return configs
+}
+
+// Cursor returns the config index and row under the cursor.
+func (sm *SnapperModelImpl) Cursor() (config, row int) {
+	return sm.cursor / snapperRowCount, sm.cursor % snapperRowCount
+}
+
+// MoveUp moves the cursor up, skipping the rows of disabled configs.
+func (sm *SnapperModelImpl) MoveUp() {
+	for next := sm.cursor - 1; next >= 0; next-- {
+		if sm.visible(next) {
+			sm.cursor = next
+			return
+		}
+	}
+}
+
+// MoveDown moves the cursor down, skipping the rows of disabled configs.
+func (sm *SnapperModelImpl) MoveDown() {
+	for next := sm.cursor + 1; next < len(sm.configs)*snapperRowCount; next++ {
+		if sm.visible(next) {
+			sm.cursor = next
+			return
+		}
+	}
+}
+
+// visible returns true if the row at position is shown: disabled configs only show their enable row
+func (sm *SnapperModelImpl) visible(position int) bool {
+	return sm.enabled[position/snapperRowCount] || position%snapperRowCount == SnapperRowEnabled
+}
+
+// Toggle flips the enabled or timeline row under the cursor.
+func (sm *SnapperModelImpl) Toggle() {
+	index, row := sm.Cursor()
+	if index >= len(sm.configs) {
+		return
+	}
+	switch row {
+	case SnapperRowEnabled:
+		sm.enabled[index] = !sm.enabled[index]
+	case SnapperRowTimeline:
+		sm.configs[index] = sm.configs[index].WithTimeline(!sm.configs[index].Timeline())
+	}
+}
+
+// Adjust changes the retention limit under the cursor by delta, never below zero.
+func (sm *SnapperModelImpl) Adjust(delta int) {
+	index, row := sm.Cursor()
+	if index >= len(sm.configs) {
+		return
+	}
+	if row == SnapperRowEnabled || row == SnapperRowTimeline {
+		sm.Toggle()
+		return
+	}
+
+	retention := sm.configs[index].Retention()
+	limit := RetentionLimit(&retention, row)
+	if limit == nil || *limit+delta < 0 {
+		return
+	}
+	*limit += delta
+	if updated, err := sm.configs[index].WithRetention(retention); err == nil {
+		sm.configs[index] = updated
+	}
+}
+
+// RetentionLimit returns the retention field shown on a row, nil for other rows.
+func RetentionLimit(r *snapshot.Retention, row int) *int {
+	switch row {
+	case SnapperRowHourly:
+		return &r.Hourly
+	case SnapperRowDaily:
+		return &r.Daily
+	case SnapperRowWeekly:
+		return &r.Weekly
+	case SnapperRowMonthly:
+		return &r.Monthly
+	case SnapperRowYearly:
+		return &r.Yearly
+	case SnapperRowNumber:
+		return &r.Number
+	case SnapperRowImportant:
+		return &r.NumberImportant
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/bnema/archup/internal/interfaces/tui/views"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RollbackPicker is the Bubble Tea program of archup rollback: it lets the
+// user pick a snapshot and a mode, the rollback itself runs after it quits.
+type RollbackPicker struct {
+	model     *models.RollbackModelImpl
+	confirmed bool
+}
+
+// NewRollbackPicker creates a snapshot picker
+func NewRollbackPicker(snapshots snapshot.List, mode snapshot.RollbackMode) *RollbackPicker {
+	return &RollbackPicker{model: models.NewRollbackModel(snapshots, mode)}
+}
+
+// Choice returns the confirmed snapshot and mode, ok is false when the user quit
+func (p *RollbackPicker) Choice() (selected snapshot.Snapshot, mode snapshot.RollbackMode, ok bool) {
+	selected, ok = p.model.SelectedSnapshot()
+	return selected, p.model.Mode(), ok && p.confirmed
+}
+
+// Init is called when the program starts
+func (p *RollbackPicker) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles keyboard input
+func (p *RollbackPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	if p.model.Confirming() {
+		switch key.String() {
+		case "y", "Y":
+			p.confirmed = true
+			return p, tea.Quit
+		case "ctrl+c":
+			return p, tea.Quit
+		default:
+			p.model.SetConfirming(false)
+		}
+		return p, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "q", "esc":
+		return p, tea.Quit
+	case "up", "k", "shift+tab":
+		p.model.MoveUp()
+	case "down", "j", "tab":
+		p.model.MoveDown()
+	case "m":
+		p.model.ToggleMode()
+	case "enter":
+		p.model.SetConfirming(true)
+	}
+	return p, nil
+}
+
+// View renders the snapshot list
+func (p *RollbackPicker) View() string {
+	return views.RenderRollbackSelection(p.model)
+}
@@ -0,0 +1,76 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderBootOptions renders the advanced kernel command line screen.
+func RenderBootOptions(bm *models.BootOptionsModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	desc := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Faint(true)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Advanced Boot Options"))
+	b.WriteString("\n\n")
+
+	presets := bm.Presets()
+	rows := []struct {
+		label string
+		value string
+		help  string
+	}{
+		{"Boot messages", presets.Verbosity.String(), "quiet shows the splash screen, verbose prints all messages"},
+		{"CPU mitigations", presets.Mitigations.String(), "off trades security for performance"},
+		{"IOMMU passthrough", onOff(presets.IOMMU), "needed for PCI passthrough to virtual machines"},
+		{"NVIDIA DRM modeset", onOff(presets.NVIDIAModeset), "required by Wayland compositors on NVIDIA"},
+	}
+
+	for i, row := range rows {
+		line := row.label + ": " + row.value
+		if bm.CursorIndex() == i {
+			b.WriteString(cursorStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+		b.WriteString(desc.Render("    " + row.help))
+		b.WriteString("\n")
+	}
+
+	label := "Extra parameters:"
+	if bm.EditingExtra() {
+		b.WriteString(cursorStyle.Render("> " + label))
+	} else {
+		b.WriteString("  " + label)
+	}
+	b.WriteString("\n  ")
+	b.WriteString(bm.ExtraInput().View())
+	b.WriteString("\n\n")
+
+	preview, err := bm.Preview()
+	if err != nil {
+		b.WriteString(errStyle.Render("  " + err.Error()))
+	} else {
+		b.WriteString(info.Render("  Command line: " + preview + " (root parameters added at install)"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(info.Render("↑/↓ move • ←/→ or space change • enter confirm • esc back"))
+
+	return b.String()
+}
+
+func onOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
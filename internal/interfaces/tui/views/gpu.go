@@ -3,6 +3,7 @@ package views
 import (
 	"strings"
 
+	"github.com/bnema/archup/internal/domain/system"
 	"github.com/bnema/archup/internal/interfaces/tui/models"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -19,12 +20,24 @@ func RenderGPUSelection(gm *models.GPUModelImpl) string {
 	b.WriteString(title.Render("GPU Driver Selection"))
 	b.WriteString("\n\n")
 
-	if detected := gm.DetectedGPU(); detected != nil {
-		b.WriteString(info.Render("Detected: "))
-		b.WriteString(detected.Vendor().String())
-		b.WriteString(" - ")
-		b.WriteString(detected.Model())
-		b.WriteString("\n\n")
+	if detected := gm.DetectedGPUs(); detected != nil && len(detected.GPUs()) > 0 {
+		b.WriteString(info.Render("Detected:"))
+		b.WriteString("\n")
+		for _, gpu := range detected.GPUs() {
+			b.WriteString("  " + gpu.Vendor().String() + " - " + gpu.Model())
+			if gpu.Slot() != "" {
+				b.WriteString(info.Render(" [" + gpu.Slot() + "]"))
+			}
+			if gpu.BootVGA() {
+				b.WriteString(info.Render(" (boot VGA)"))
+			}
+			b.WriteString("\n")
+		}
+		if detected.IsHybrid() {
+			b.WriteString(info.Render("Hybrid graphics: choose which GPUs to use"))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
 	}
 
 	for i, option := range gm.Options() {
@@ -39,6 +52,16 @@ func RenderGPUSelection(gm *models.GPUModelImpl) string {
 			b.WriteString("\n")
 			b.WriteString(info.Render("    " + strings.Join(option.Drivers, ", ")))
 		}
+		if option.Reason != "" {
+			b.WriteString("\n")
+			b.WriteString(info.Render("    " + option.Reason))
+		}
+		b.WriteString("\n")
+	}
+
+	if option := gm.SelectedOption(); len(system.NVIDIADriverPackages(option.Drivers)) > 0 {
+		b.WriteString("\n")
+		b.WriteString(info.Render("NVIDIA modules are built with DKMS (-dkms) for kernels other than linux"))
 		b.WriteString("\n")
 	}
 
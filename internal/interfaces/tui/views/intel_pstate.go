@@ -0,0 +1,80 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	legacysystem "github.com/bnema/archup/internal/system"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderIntelPStateSelection renders the intel_pstate selection screen.
+func RenderIntelPStateSelection(im *models.IntelPStateModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	active := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Intel P-State Configuration"))
+	b.WriteString("\n\n")
+
+	if im == nil || im.CPUInfo() == nil {
+		b.WriteString(info.Render("Detecting CPU..."))
+		return b.String()
+	}
+
+	cpuInfo := im.CPUInfo()
+	if cpuInfo.Vendor != legacysystem.CPUVendorIntel {
+		b.WriteString(info.Render("Non-Intel CPU detected. Skipping Intel P-State configuration."))
+		return b.String()
+	}
+
+	if cpuInfo.ModelName != "" {
+		b.WriteString(info.Render("CPU: "))
+		b.WriteString(cpuInfo.ModelName)
+		b.WriteString("\n")
+	}
+	if cpuInfo.IntelHybrid() {
+		b.WriteString(info.Render("Cores: "))
+		b.WriteString(fmt.Sprintf("hybrid, %d P-core and %d E-core threads", cpuInfo.IntelPCores, cpuInfo.IntelECores))
+		b.WriteString("\n")
+	}
+	b.WriteString(info.Render("HWP: "))
+	switch {
+	case cpuInfo.IntelEPP:
+		b.WriteString("yes, with EPP hints")
+	case cpuInfo.IntelHWP:
+		b.WriteString("yes, without EPP hints")
+	default:
+		b.WriteString("no (pre-Skylake or disabled in firmware)")
+	}
+	b.WriteString("\n\n")
+
+	if cpuInfo.IntelHybrid() {
+		b.WriteString(info.Render("Note: active mode lets the hardware steer work between P-cores and E-cores"))
+		b.WriteString("\n\n")
+	}
+
+	for i, option := range im.Options() {
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		label := option.Label
+		if option.Recommended {
+			label = label + " (recommended)"
+		}
+		if i == im.SelectedIndex() {
+			prefix = "> "
+			style = active
+		}
+		b.WriteString(style.Render(prefix + label))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(info.Render("↑/↓ navigate • enter confirm • esc back • ctrl+c quit"))
+
+	return b.String()
+}
@@ -26,16 +26,22 @@ func RenderKernelSelection(km *models.KernelModelImpl) string {
 
 	for i, option := range km.Options() {
 		prefix := "  "
+		mark := "[ ] "
 		style := lipgloss.NewStyle()
 		label := option.Package
 		if option.Recommended {
 			label = label + " (recommended)"
 		}
+		if km.IsExtra(i) {
+			mark = "[x] "
+			label = label + " (fallback)"
+		}
 		if i == km.SelectedIndex() {
 			prefix = "> "
+			mark = "(*) "
 			style = active
 		}
-		b.WriteString(style.Render(prefix + label))
+		b.WriteString(style.Render(prefix + mark + label))
 		if option.Description != "" {
 			b.WriteString("\n")
 			b.WriteString(info.Render("    " + option.Description))
@@ -44,7 +50,9 @@ func RenderKernelSelection(km *models.KernelModelImpl) string {
 	}
 
 	b.WriteString("\n")
-	b.WriteString(info.Render("↑/↓ navigate • enter confirm • esc back • ctrl+c quit"))
+	b.WriteString(info.Render("Extra kernels get their own boot entries and keep the system bootable if the default kernel breaks."))
+	b.WriteString("\n")
+	b.WriteString(info.Render("↑/↓ navigate • space toggle fallback • enter confirm default • esc back • ctrl+c quit"))
 
 	return b.String()
 }
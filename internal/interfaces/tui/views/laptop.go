@@ -0,0 +1,55 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderLaptopSelection renders the laptop profile screen.
+func RenderLaptopSelection(lm *models.LaptopModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	active := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	warn := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Laptop Profile"))
+	b.WriteString("\n\n")
+
+	if lm == nil || lm.Chassis() == nil {
+		b.WriteString(info.Render("Detecting chassis..."))
+		return b.String()
+	}
+
+	chassis := lm.Chassis()
+	b.WriteString(info.Render("Detected: "))
+	b.WriteString("laptop (" + chassis.Reason() + ")")
+	b.WriteString("\n")
+	if chassis.OnBattery() {
+		b.WriteString(warn.Render("Running on battery: connect the charger before installing"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	for i, option := range lm.Options() {
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if i == lm.SelectedIndex() {
+			prefix = "> "
+			style = active
+		}
+		b.WriteString(style.Render(prefix + option.Label))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(info.Render("Hibernation falls back to suspend when the target has no swap partition or file"))
+	b.WriteString("\n\n")
+	b.WriteString(info.Render("↑/↓ navigate • enter confirm • esc back • ctrl+c quit"))
+
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// packagePickerVisibleResults is the number of search results shown around the cursor
+const packagePickerVisibleResults = 10
+
+// RenderPackagePicker renders the extra package selection screen.
+func RenderPackagePicker(pm *models.PackagePickerModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	section := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("14"))
+	desc := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Faint(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	selectedMark := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	normalMark := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Extra Packages"))
+	b.WriteString("\n\n")
+
+	input := pm.QueryInput()
+	b.WriteString(input.View())
+	b.WriteString("\n\n")
+
+	results := pm.Results()
+	switch {
+	case pm.Searching():
+		b.WriteString(info.Render("  Searching the package databases..."))
+		b.WriteString("\n")
+	case pm.Error() != "":
+		b.WriteString(errStyle.Render("  " + pm.Error()))
+		b.WriteString("\n")
+	case pm.Searched() != "" && len(results) == 0:
+		b.WriteString(info.Render("  No package matches " + pm.Searched()))
+		b.WriteString("\n")
+	}
+
+	// Keep the cursor in a window of results
+	start := max(0, pm.CursorIndex()-packagePickerVisibleResults/2)
+	end := min(len(results), start+packagePickerVisibleResults)
+	start = max(0, end-packagePickerVisibleResults)
+	for i := start; i < end; i++ {
+		result := results[i]
+		if pm.IsSelected(result.Name) {
+			b.WriteString(selectedMark.Render(" [x] "))
+		} else {
+			b.WriteString(normalMark.Render(" [ ] "))
+		}
+
+		label := result.Repository + "/" + result.Name + " " + result.Version
+		if !pm.EditingQuery() && pm.CursorIndex() == i {
+			b.WriteString(cursorStyle.Render("> " + label))
+		} else {
+			b.WriteString(label)
+		}
+		b.WriteString("\n")
+		if result.Description != "" {
+			b.WriteString(desc.Render("      " + result.Description))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(section.Render("Selected"))
+	b.WriteString("\n")
+	if selected := pm.Selected(); len(selected) > 0 {
+		b.WriteString("  " + strings.Join(selected, " "))
+	} else {
+		b.WriteString(info.Render("  none"))
+	}
+	b.WriteString("\n")
+	if aur := pm.AURPackages(); len(aur) > 0 {
+		b.WriteString(section.Render("AUR (answer file)"))
+		b.WriteString("\n")
+		b.WriteString("  " + strings.Join(aur, " "))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if pm.EditingQuery() {
+		b.WriteString(info.Render("type to search • enter search (empty: continue) • tab results • esc back"))
+	} else {
+		b.WriteString(info.Render("↑/↓ move • space toggle • / or tab search • enter continue • esc back"))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/system"
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderPowerSelection renders the power daemon and EPP default screen.
+func RenderPowerSelection(pm *models.PowerModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	desc := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Faint(true)
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Power Management"))
+	b.WriteString("\n\n")
+
+	daemon := pm.Daemon()
+	rows := []struct {
+		label string
+		value string
+		help  string
+	}{
+		{"Power daemon", daemon.String(), daemon.Description()},
+	}
+	if pm.EPPAvailable() {
+		help := "energy performance preference applied at boot"
+		switch daemon {
+		case system.PowerDaemonPPD:
+			help = "ignored: power-profiles-daemon sets it from the active profile"
+		case system.PowerDaemonTuned:
+			help = "selects the closest TuneD profile"
+		case system.PowerDaemonTLP:
+			help = "on AC, TLP uses one step towards power saving on battery"
+		}
+		rows = append(rows, struct {
+			label string
+			value string
+			help  string
+		}{"EPP default", pm.EPP().String(), help})
+	}
+
+	for i, row := range rows {
+		line := row.label + ": " + row.value
+		if pm.CursorIndex() == i {
+			b.WriteString(cursorStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+		b.WriteString(desc.Render("    " + row.help))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(info.Render("↑/↓ move • ←/→ or space change • enter confirm • esc back"))
+
+	return b.String()
+}
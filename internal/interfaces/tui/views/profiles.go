@@ -0,0 +1,73 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderProfilesSelection renders the package profile selection screen.
+func RenderProfilesSelection(pm *models.ProfilesModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	desc := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Faint(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	selectedMark := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	normalMark := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Package Profiles"))
+	b.WriteString("\n\n")
+
+	if pm == nil || len(pm.Options()) == 0 {
+		b.WriteString(info.Render("No package profiles available."))
+		b.WriteString("\n\n")
+		b.WriteString(info.Render("enter continue • esc back"))
+		return b.String()
+	}
+
+	for i, profile := range pm.Options() {
+		if pm.IsSelected(i) {
+			b.WriteString(selectedMark.Render(" [x] "))
+		} else {
+			b.WriteString(normalMark.Render(" [ ] "))
+		}
+
+		label := fmt.Sprintf("%s (%d packages)", profile.Name(), len(profile.Packages()))
+		if pm.CursorIndex() == i {
+			b.WriteString(cursorStyle.Render("> " + label))
+		} else {
+			b.WriteString(label)
+		}
+		b.WriteString("\n")
+
+		details := profile.Description()
+		if requires := profile.Requires(); len(requires) > 0 {
+			details += " • adds " + strings.Join(requires, ", ")
+		}
+		if conflicts := profile.Conflicts(); len(conflicts) > 0 {
+			details += " • not with " + strings.Join(conflicts, ", ")
+		}
+		if details != "" {
+			b.WriteString(desc.Render("      " + details))
+			b.WriteString("\n")
+		}
+	}
+
+	if err := pm.Error(); err != "" {
+		b.WriteString("\n")
+		b.WriteString(errStyle.Render("  " + err))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(info.Render("↑/↓ move • space toggle • enter confirm • esc back"))
+
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RenderRollbackSelection renders the snapshot selection screen of archup rollback.
+func RenderRollbackSelection(rm *models.RollbackModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	active := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	warning := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Snapshot Rollback"))
+	b.WriteString("\n\n")
+
+	if rm == nil || len(rm.Snapshots()) == 0 {
+		b.WriteString(info.Render("No snapper snapshots found for the root config."))
+		b.WriteString("\n\n")
+		b.WriteString(info.Render("q quit"))
+		return b.String()
+	}
+
+	snapshots := rm.Snapshots()
+	for i, s := range snapshots {
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if i == rm.SelectedIndex() {
+			prefix = "› "
+			style = active
+		}
+		label := fmt.Sprintf("#%-4d %-6s %s", s.Number, s.Type, s.Date)
+		if s.Important() {
+			label += " (important)"
+		}
+		b.WriteString(style.Render(prefix + label))
+		if desc := snapshots.TransactionDescription(s); desc != "" {
+			b.WriteString("\n")
+			b.WriteString(info.Render("    " + desc))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(info.Render("Mode: "))
+	b.WriteString(rm.Mode().String())
+	b.WriteString("\n")
+	if rm.Mode() == snapshot.RollbackNewRoot {
+		b.WriteString(info.Render("The snapshot becomes the new @, the current root is kept as @.pre-rollback-<date>."))
+	} else {
+		b.WriteString(info.Render("The snapshot becomes the default subvolume, subvol=@ pins are removed from fstab and limine.conf."))
+	}
+	b.WriteString("\n\n")
+
+	if rm.Confirming() {
+		selected, _ := rm.SelectedSnapshot()
+		b.WriteString(warning.Render(fmt.Sprintf("Roll back to snapshot #%d? A reboot is required afterwards. (y/n)", selected.Number)))
+		return b.String()
+	}
+
+	b.WriteString(info.Render("↑/↓ navigate • m toggle mode • enter roll back • q quit"))
+	return b.String()
+}
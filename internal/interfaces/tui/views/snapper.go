@@ -0,0 +1,86 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/archup/internal/domain/snapshot"
+	"github.com/bnema/archup/internal/interfaces/tui/models"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// snapperRowLabels are the labels of the snapper rows, indexed by models.SnapperRow*
+var snapperRowLabels = []string{
+	"Create config",
+	"Timeline snapshots",
+	"Keep hourly",
+	"Keep daily",
+	"Keep weekly",
+	"Keep monthly",
+	"Keep yearly",
+	"Keep pacman snapshots",
+	"Keep important snapshots",
+}
+
+// RenderSnapperOptions renders the snapper configs screen.
+func RenderSnapperOptions(sm *models.SnapperModelImpl) string {
+	var b strings.Builder
+
+	title := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	section := lipgloss.NewStyle().Bold(true)
+	info := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
+	warning := lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+
+	b.WriteString("\n")
+	b.WriteString(title.Render("Snapshots"))
+	b.WriteString("\n\n")
+
+	cursorConfig, cursorRow := sm.Cursor()
+	rootEnabled := false
+	for i, c := range sm.AllConfigs() {
+		enabled := sm.IsEnabled(i)
+		if enabled && c.Name() == snapshot.RootSnapperConfig {
+			rootEnabled = true
+		}
+
+		b.WriteString(section.Render(fmt.Sprintf("%s (%s)", c.Name(), c.Subvolume())))
+		b.WriteString("\n")
+
+		retention := c.Retention()
+		for row, label := range snapperRowLabels {
+			// A disabled config only shows its enable row
+			if !enabled && row != models.SnapperRowEnabled {
+				continue
+			}
+
+			var value string
+			switch row {
+			case models.SnapperRowEnabled:
+				value = onOff(enabled)
+			case models.SnapperRowTimeline:
+				value = onOff(c.Timeline())
+			default:
+				value = fmt.Sprintf("%d", *models.RetentionLimit(&retention, row))
+			}
+
+			line := label + ": " + value
+			if i == cursorConfig && row == cursorRow {
+				b.WriteString(cursorStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if !rootEnabled {
+		b.WriteString(warning.Render("Without a root config there are no pacman snapshots, boot entries or archup rollback."))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(info.Render("↑/↓ move • ←/→ change • space toggle • enter confirm • esc back"))
+
+	return b.String()
+}
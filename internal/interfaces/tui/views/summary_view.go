@@ -5,14 +5,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bnema/archup/internal/domain/system"
 	"github.com/bnema/archup/internal/interfaces/tui/models"
 	"github.com/charmbracelet/lipgloss"
 )
 
 // RenderSummary renders the installation summary (success case)
 func RenderSummary(im *models.InstallationModelImpl) string {
-	if err := im.GetError(); err != "" {
-		return RenderError(err)
+	if im.GetError() != "" {
+		return RenderInstallationError(im)
 	}
 
 	_ = im.IsComplete() // keep for potential future use
@@ -53,12 +54,34 @@ func RenderSummary(im *models.InstallationModelImpl) string {
 		b.WriteString("\n")
 	}
 
+	if status.BaselineSnapshot != "" {
+		b.WriteString("Baseline Snapshot: ")
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")).
+			Render(status.BaselineSnapshot))
+		b.WriteString(lipgloss.NewStyle().
+			Faint(true).
+			Render(" (restore with: archup rollback --baseline)"))
+		b.WriteString("\n")
+	}
+
 	// Duration
 	if status.StartedAt != nil && status.CompletedAt != nil {
 		duration := status.CompletedAt.Sub(*status.StartedAt)
 		b.WriteString(fmt.Sprintf("Duration: %s\n", formatDuration(duration)))
 	}
 
+	if len(status.Hardware) > 0 {
+		b.WriteString("\nHardware")
+		b.WriteString(lipgloss.NewStyle().
+			Faint(true).
+			Render(" (saved to " + system.HardwareInventoryFile + ")"))
+		b.WriteString("\n")
+		for _, line := range status.Hardware {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
 	b.WriteString("\n")
 	b.WriteString(lipgloss.NewStyle().
 		Bold(true).
@@ -66,6 +89,13 @@ func RenderSummary(im *models.InstallationModelImpl) string {
 		Render("You can now reboot into your new system!"))
 	b.WriteString("\n\n")
 
+	if len(status.AURFailed) > 0 {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("3")).
+			Render("AUR packages not installed: " + strings.Join(status.AURFailed, ", ")))
+		b.WriteString("\n\n")
+	}
+
 	if notice := im.GetNotice(); notice != "" {
 		b.WriteString(lipgloss.NewStyle().
 			Foreground(lipgloss.Color("3")).
@@ -82,6 +112,16 @@ func RenderSummary(im *models.InstallationModelImpl) string {
 
 // RenderError renders the error screen
 func RenderError(errorMsg string) string {
+	return renderError(errorMsg, false, nil, nil)
+}
+
+// RenderInstallationError renders the error screen with the rollback of the
+// completed phases
+func RenderInstallationError(im *models.InstallationModelImpl) string {
+	return renderError(im.GetError(), im.IsRollingBack(), im.GetRolledBack(), im.GetRollbackErrors())
+}
+
+func renderError(errorMsg string, rollingBack bool, rolledBack, rollbackErrors []string) string {
 	var b strings.Builder
 
 	b.WriteString("\n")
@@ -96,9 +136,42 @@ func RenderError(errorMsg string) string {
 		Render(errorMsg))
 	b.WriteString("\n\n")
 
+	if rollingBack {
+		b.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")).
+			Render("Rolling back completed phases..."))
+		b.WriteString("\n\n")
+	}
+
+	if len(rolledBack) > 0 {
+		b.WriteString("Rolled back:\n")
+		for _, phase := range rolledBack {
+			b.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color("10")).
+				Render("  ✓ " + phase))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(rollbackErrors) > 0 {
+		b.WriteString("Rollback failed, clean up manually:\n")
+		for _, failure := range rollbackErrors {
+			b.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color("1")).
+				Render("  ✗ " + failure))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	help := "Press 'q' or Ctrl+C to exit"
+	if rollingBack {
+		help = "Please wait for the rollback, Ctrl+C exits immediately"
+	}
 	b.WriteString(lipgloss.NewStyle().
 		Faint(true).
-		Render("Press 'q' or Ctrl+C to exit"))
+		Render(help))
 
 	return b.String()
 }
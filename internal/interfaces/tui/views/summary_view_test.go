@@ -16,14 +16,16 @@ func TestRenderSummary(t *testing.T) {
 	now := time.Now()
 	completedAt := now.Add(1 * time.Hour)
 	status := &dto.InstallationStatus{
-		Hostname:     "test-host",
-		Username:     "testuser",
-		TargetDisk:   "/dev/sda",
-		Progress:     100,
-		State:        "Complete",
-		CurrentPhase: "PostInstallation",
-		StartedAt:    &now,
-		CompletedAt:  &completedAt,
+		Hostname:         "test-host",
+		Username:         "testuser",
+		TargetDisk:       "/dev/sda",
+		Progress:         100,
+		State:            "Complete",
+		CurrentPhase:     "PostInstallation",
+		StartedAt:        &now,
+		CompletedAt:      &completedAt,
+		BaselineSnapshot: "@archup-baseline",
+		AURFailed:        []string{"spotify", "visual-studio-code-bin"},
 	}
 
 	im.SetStatus(status)
@@ -34,11 +36,13 @@ func TestRenderSummary(t *testing.T) {
 
 	// Verify key content is present
 	checks := []string{
-		"Installation Complete!",          // Title
-		"test-host",                       // Hostname
-		"testuser",                        // Username
-		"/dev/sda",                        // Disk
-		"Press 'r' to unmount and reboot", // Instructions
+		"Installation Complete!", // Title
+		"test-host",              // Hostname
+		"testuser",               // Username
+		"/dev/sda",               // Disk
+		"@archup-baseline",       // Baseline snapshot
+		"AUR packages not installed: spotify, visual-studio-code-bin", // Failed AUR builds
+		"Press 'r' to unmount and reboot",                             // Instructions
 	}
 
 	for _, check := range checks {
@@ -79,6 +83,32 @@ func TestRenderError(t *testing.T) {
 	}
 }
 
+func TestRenderInstallationError_Rollback(t *testing.T) {
+	im := models.NewInstallationModel()
+	im.SetError("Bootloader Setup: efibootmgr failed")
+	im.SetRollback([]string{"Bootloader Setup", "Disk Partitioning"}, []string{"Base Installation: restore failed"})
+
+	output := RenderInstallationError(im)
+
+	checks := []string{
+		"Rolled back:",
+		"Bootloader Setup",
+		"Disk Partitioning",
+		"Base Installation: restore failed",
+		"Press 'q' or Ctrl+C to exit",
+	}
+	for _, check := range checks {
+		if !strings.Contains(output, check) {
+			t.Errorf("Expected error output to contain '%s', but it didn't", check)
+		}
+	}
+
+	im.SetRollingBack(true)
+	if output := RenderInstallationError(im); !strings.Contains(output, "Rolling back completed phases") {
+		t.Error("Expected rolling back notice while the rollback runs")
+	}
+}
+
 func TestRenderStatus(t *testing.T) {
 	im := models.NewInstallationModel()
 
@@ -26,6 +26,15 @@ const (
 	AMDPStateModeNone    AMDPStateMode = ""
 )
 
+// IntelPStateMode represents intel_pstate driver mode
+type IntelPStateMode string
+
+const (
+	IntelPStateModeActive  IntelPStateMode = "active"
+	IntelPStateModePassive IntelPStateMode = "passive"
+	IntelPStateModeNone    IntelPStateMode = ""
+)
+
 // AMDZenGen represents AMD Zen generation
 type AMDZenGen struct {
 	Generation string // "1", "1+", "2", "3", "3+", "4", "5", "unknown"
@@ -40,6 +49,18 @@ type CPUInfo struct {
 	AMDZenGen             *AMDZenGen // AMD Zen generation (nil for non-AMD)
 	AMDPStateModes        []AMDPStateMode
 	RecommendedPStateMode AMDPStateMode // Recommended mode for this CPU
+
+	IntelHWP                   bool // Hardware P-States (Speed Shift)
+	IntelEPP                   bool // HWP energy performance preference hints
+	IntelPCores                int  // Performance cores of a hybrid CPU (0 if not hybrid)
+	IntelECores                int  // Efficiency cores of a hybrid CPU (0 if not hybrid)
+	IntelPStateModes           []IntelPStateMode
+	RecommendedIntelPStateMode IntelPStateMode
+}
+
+// IntelHybrid returns true if the CPU mixes performance and efficiency cores
+func (c *CPUInfo) IntelHybrid() bool {
+	return c.IntelPCores > 0 && c.IntelECores > 0
 }
 
 // DetectCPUVendor reads /proc/cpuinfo and returns the CPU vendor
@@ -347,6 +368,108 @@ func GetAMDPStateKernelParams(zenGen *AMDZenGen, mode AMDPStateMode) string {
 	return fmt.Sprintf("amd_pstate=%s", mode)
 }
 
+// DetectIntelHWP checks the /proc/cpuinfo flags for hardware P-States and
+// their energy performance preference hints
+func DetectIntelHWP() (hwp bool, epp bool) {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return false, false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) < 2 {
+			continue
+		}
+		for _, flag := range strings.Fields(parts[1]) {
+			switch flag {
+			case "hwp":
+				hwp = true
+			case "hwp_epp":
+				epp = true
+			}
+		}
+		return hwp, epp
+	}
+
+	return false, false
+}
+
+// DetectIntelHybridCores counts the performance and efficiency cores of a
+// hybrid CPU (Alder Lake and later), which expose one PMU per core type
+func DetectIntelHybridCores() (pCores int, eCores int) {
+	return countCPUList("/sys/devices/cpu_core/cpus"), countCPUList("/sys/devices/cpu_atom/cpus")
+}
+
+// countCPUList counts the CPUs of a sysfs CPU list file (e.g. "0-15,20")
+func countCPUList(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	count := 0
+	for _, part := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		if part == "" {
+			continue
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(bounds[1]); err != nil {
+				continue
+			}
+		}
+		count += last - first + 1
+	}
+	return count
+}
+
+// GetAvailableIntelPStateModes returns the intel_pstate modes for a CPU.
+// Active mode without HWP falls back to the driver's own governor, passive
+// (intel_cpufreq) is always available.
+func GetAvailableIntelPStateModes(hwp bool) []IntelPStateMode {
+	if hwp {
+		return []IntelPStateMode{IntelPStateModeActive, IntelPStateModePassive}
+	}
+	return []IntelPStateMode{IntelPStateModePassive, IntelPStateModeActive}
+}
+
+// GetRecommendedIntelPStateMode returns the best recommended intel_pstate mode
+func GetRecommendedIntelPStateMode(hwp bool) IntelPStateMode {
+	if hwp {
+		return IntelPStateModeActive // Hardware managed with EPP hints
+	}
+	return IntelPStateModePassive // Kernel default without HWP, schedutil governor
+}
+
+// GetIntelPStateModeDescription returns human-readable description for intel_pstate mode
+func GetIntelPStateModeDescription(mode IntelPStateMode) string {
+	switch mode {
+	case IntelPStateModeActive:
+		return "hardware managed P-States with EPP hints (best efficiency)"
+	case IntelPStateModePassive:
+		return "intel_cpufreq with a generic governor (schedutil, manual control)"
+	default:
+		return string(mode)
+	}
+}
+
+// GetIntelPStateKernelParams returns kernel parameters needed for intel_pstate mode
+func GetIntelPStateKernelParams(mode IntelPStateMode) string {
+	if mode == IntelPStateModeNone {
+		return ""
+	}
+	return fmt.Sprintf("intel_pstate=%s", mode)
+}
+
 // GetCPUModelName extracts the CPU model name from /proc/cpuinfo
 func GetCPUModelName() string {
 	data, err := os.ReadFile("/proc/cpuinfo")
@@ -380,6 +503,14 @@ func DetectCPUInfo() (*CPUInfo, error) {
 		Microcode: DetectMicrocode(vendor),
 	}
 
+	// Detect Intel-specific features only for Intel CPUs
+	if vendor == CPUVendorIntel {
+		info.IntelHWP, info.IntelEPP = DetectIntelHWP()
+		info.IntelPCores, info.IntelECores = DetectIntelHybridCores()
+		info.IntelPStateModes = GetAvailableIntelPStateModes(info.IntelHWP)
+		info.RecommendedIntelPStateMode = GetRecommendedIntelPStateMode(info.IntelHWP)
+	}
+
 	// Detect AMD-specific features only for AMD CPUs
 	if vendor == CPUVendorAMD {
 		zenGen, err := DetectAMDZenGeneration()
@@ -13,6 +13,7 @@ type Disk struct {
 	Model  string `json:"model"`
 	Serial string `json:"serial"`
 	Vendor string `json:"vendor"`
+	Tran   string `json:"tran"` // Transport: nvme, sata, usb...
 	Path   string `json:"path"`
 }
 
@@ -25,12 +26,13 @@ type lsblkOutput struct {
 		Model  string `json:"model"`
 		Serial string `json:"serial"`
 		Vendor string `json:"vendor"`
+		Tran   string `json:"tran"`
 	} `json:"blockdevices"`
 }
 
 // ListDisks returns a list of available disks (excluding loop devices)
 func ListDisks() ([]Disk, error) {
-	result := RunSimple("lsblk", "-J", "-o", "NAME,SIZE,TYPE,MODEL,SERIAL,VENDOR", "-d", "-e", "7")
+	result := RunSimple("lsblk", "-J", "-o", "NAME,SIZE,TYPE,MODEL,SERIAL,VENDOR,TRAN", "-d", "-e", "7")
 	if result.Error != nil {
 		return nil, fmt.Errorf("failed to list disks: %w", result.Error)
 	}
@@ -50,6 +52,7 @@ func ListDisks() ([]Disk, error) {
 				Model:  dev.Model,
 				Serial: dev.Serial,
 				Vendor: dev.Vendor,
+				Tran:   dev.Tran,
 				Path:   "/dev/" + dev.Name,
 			})
 		}
@@ -91,7 +91,7 @@ if [ "$LOCAL_MODE" = "1" ]; then
 
     # install/ configs (bootstrap files land here: /tmp/archup-install/<file>)
     rsync_cmd "$REPO_ROOT/install/base.packages"                     "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/"
-    rsync_cmd "$REPO_ROOT/install/extra.packages"                    "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/"
+    rsync_cmd "$REPO_ROOT/install/profiles.packages"                 "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/"
     rsync_cmd "$REPO_ROOT/install/configs/limine.conf.template"      "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/configs/"
     rsync_cmd "$REPO_ROOT/install/configs/chaotic-aur.conf"          "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/configs/"
     rsync_cmd "$REPO_ROOT/install/configs/limine-update.hook"        "$VM_USER@$VM_HOST:$REMOTE_INSTALL_DIR/configs/"